@@ -0,0 +1,130 @@
+// Command import-incomes loads a CSV or XLSX sheet of historical incomes,
+// the same way POST /api/income/import does, or rolls an import back.
+//
+//	import-incomes -file jobs-2023.xlsx -dry-run
+//	import-incomes -file jobs-2023.xlsx -create-missing -mapping mapping.json
+//	import-incomes -rollback 12
+//
+// It reads the database settings from the environment like the server and
+// prints the result as JSON. It exits with status 1 when the import fails
+// or, on a dry run, when the sheet has errors.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"mtii-backend/config"
+	"mtii-backend/dtos"
+	"mtii-backend/migrations"
+	"mtii-backend/repositories"
+	"mtii-backend/services"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	var (
+		file          = flag.String("file", "", "CSV or XLSX sheet of incomes to import")
+		dryRun        = flag.Bool("dry-run", false, "check the sheet without saving anything")
+		createMissing = flag.Bool("create-missing", false, "create the platforms and statuses named in the sheet that do not exist yet")
+		mappingFile   = flag.String("mapping", "", "JSON file mapping column headers to income fields")
+		rollback      = flag.Int("rollback", 0, "roll back the import batch with this id instead of importing")
+	)
+	flag.Parse()
+
+	if *file == "" && *rollback == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config.SetUpLogger()
+	db := config.SetUpDatabaseConnection()
+	defer config.ClosDatabaseConnection(db)
+
+	if err := migrations.Migrate(db); err != nil {
+		fail(err)
+	}
+
+	incRepo := repositories.NewIncomeRepository(db)
+	rateRepo := repositories.NewCurrencyRateRepository(db)
+	saleRepo := repositories.NewSalePersonRepository(db)
+	commissionSvc := services.NewCommissionService(
+		repositories.NewCommissionRepository(db),
+		repositories.NewCommissionPlanRepository(db),
+		saleRepo,
+		incRepo,
+		rateRepo,
+	)
+	incSvc := services.NewIncomeService(
+		incRepo,
+		rateRepo,
+		repositories.NewAgencyRepository(db),
+		repositories.NewContactRepository(db),
+		repositories.NewBrandRepository(db),
+		repositories.NewImportBatchRepository(db),
+		commissionSvc,
+	)
+
+	ctx := context.Background()
+	if *rollback != 0 {
+		batch, err := incSvc.RollbackImportBatch(ctx, *rollback)
+		if err != nil {
+			fail(err)
+		}
+		printJSON(batch)
+		return
+	}
+
+	opts := dtos.ImportOptions{
+		FileName:      filepath.Base(*file),
+		Source:        services.ImportSourceCLI,
+		DryRun:        *dryRun,
+		CreateMissing: *createMissing,
+	}
+	if *mappingFile != "" {
+		data, err := os.ReadFile(*mappingFile)
+		if err != nil {
+			fail(err)
+		}
+		if err := json.Unmarshal(data, &opts.Mapping); err != nil {
+			fail(fmt.Errorf("invalid mapping file: %w", err))
+		}
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		fail(err)
+	}
+	defer f.Close()
+
+	result, err := incSvc.ImportIncomes(ctx, f, opts)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(result)
+	if len(result.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// fail prints err, with the fields of a validation error one per line, and
+// exits.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "import-incomes:", err)
+	var domainErr *services.DomainError
+	if errors.As(err, &domainErr) {
+		for _, fe := range domainErr.Fields {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", fe.Field, fe.Message)
+		}
+	}
+	os.Exit(1)
+}
@@ -52,11 +52,14 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"mtii-backend/metrics"
 	"os"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 func SetUpDatabaseConnection() *gorm.DB {
@@ -64,7 +67,7 @@ func SetUpDatabaseConnection() *gorm.DB {
 	if os.Getenv("APP_ENV") != "Production" {
 		err := godotenv.Load(".env")
 		if err != nil {
-			fmt.Println("Error loading .env file:", err)
+			slog.Error("failed to load .env file", "error", err)
 			panic(err)
 		}
 	}
@@ -89,20 +92,32 @@ func SetUpDatabaseConnection() *gorm.DB {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dsn,
 		PreferSimpleProtocol: true,
-	}), &gorm.Config{})
+	}), &gorm.Config{
+		TranslateError: true,
+	})
 	if err != nil {
-		fmt.Println("Failed to connect to database:", err)
+		slog.Error("failed to connect to database", "error", err)
 		panic(err)
 	}
 
-	fmt.Println("Database Connected")
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		slog.Error("failed to register gorm metrics", "error", err)
+		panic(err)
+	}
+
+	if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+		slog.Error("failed to register gorm tracing", "error", err)
+		panic(err)
+	}
+
+	slog.Info("database connected")
 	return db
 }
 
 func ClosDatabaseConnection(db *gorm.DB) {
 	dbSQL, err := db.DB()
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("failed to get database handle", "error", err)
 		panic(err)
 	}
 	dbSQL.Close()
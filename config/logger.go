@@ -0,0 +1,62 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"mtii-backend/helpers"
+	"os"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func SetUpLogger() *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
+		level = slog.LevelInfo
+	}
+
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Value.Kind() == slog.KindString && helpers.IsSecretKey(a.Key) {
+				return slog.String(a.Key, helpers.Redacted)
+			}
+			return a
+		},
+	})
+
+	logger := slog.New(&contextHandler{Handler: handler})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// contextHandler adds the request id, the authenticated user id and the
+// active trace stored in the context to every record logged with one of the
+// *Context functions.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if requestId, ok := helpers.RequestIdFromContext(ctx); ok {
+		r.AddAttrs(slog.String("request_id", requestId))
+	}
+	if userId, ok := helpers.UserIdFromContext(ctx); ok {
+		r.AddAttrs(slog.Int("user_id", userId))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
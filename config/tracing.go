@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// SetUpTracing installs the global tracer provider. OTEL_TRACES_EXPORTER
+// selects "otlp" (configured through the standard OTEL_EXPORTER_OTLP_* env
+// variables) or "console" for local runs; tracing is disabled otherwise.
+// The returned function flushes pending spans and must be called on exit.
+func SetUpTracing(ctx context.Context) func(context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporterName := os.Getenv("OTEL_TRACES_EXPORTER")
+	if exporterName == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" {
+		exporterName = "otlp"
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch exporterName {
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "console", "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return func(context.Context) error { return nil }
+	}
+	if err != nil {
+		slog.Error("failed to create trace exporter", "exporter", exporterName, "error", err)
+		panic(err)
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "mtii-backend"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		slog.Error("failed to create trace resource", "error", err)
+		panic(err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	slog.Info("tracing enabled", "exporter", exporterName)
+	return provider.Shutdown
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AgencyController interface {
+	GetAllAgency(ctx *gin.Context)
+	GetAgencyById(ctx *gin.Context)
+	CreateAgency(ctx *gin.Context)
+	UpdateAgency(ctx *gin.Context)
+	DeleteAgency(ctx *gin.Context)
+}
+
+type agencyController struct {
+	tokenService  services.TokenService
+	agencyService services.AgencyService
+}
+
+func NewAgencyController(
+	tokenService services.TokenService,
+	agencyService services.AgencyService,
+) AgencyController {
+	return &agencyController{
+		tokenService:  tokenService,
+		agencyService: agencyService,
+	}
+}
+
+func (c *agencyController) GetAllAgency(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "AgencyController.GetAllAgency")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.AgencyQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	agencies, err := c.agencyService.GetAllAgency(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve agency")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved agency", agencies)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *agencyController) GetAgencyById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "AgencyController.GetAgencyById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	agencyId := ctx.Param("agency_id")
+	parsedAgencyId, err := strconv.Atoi(agencyId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Agency Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	agency, err := c.agencyService.GetAgencyById(ctx.Request.Context(), parsedAgencyId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve agency")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved agency", agency)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *agencyController) CreateAgency(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "AgencyController.CreateAgency")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateAgencyRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	agency, err := c.agencyService.CreateAgency(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save agency")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data agency successfully saved", agency)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *agencyController) UpdateAgency(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "AgencyController.UpdateAgency")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateAgencyRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	agencyId := ctx.Param("agency_id")
+	parsedAgencyId, err := strconv.Atoi(agencyId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Agency Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	agency, err := c.agencyService.UpdateAgency(ctx.Request.Context(), parsedAgencyId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update agency")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Agency successfully updated", agency)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *agencyController) DeleteAgency(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "AgencyController.DeleteAgency")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	agencyId := ctx.Param("agency_id")
+	parsedAgencyId, err := strconv.Atoi(agencyId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Agency Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.agencyService.DeleteAgency(ctx.Request.Context(), parsedAgencyId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete agency")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Agency successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
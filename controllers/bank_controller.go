@@ -3,6 +3,7 @@ package controllers
 import (
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
@@ -34,6 +35,10 @@ func NewBankController(
 }
 
 func (c *bankController) GetAllBank(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankController.GetAllBank")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -44,8 +49,7 @@ func (c *bankController) GetAllBank(ctx *gin.Context) {
 
 	banks, err := c.bankService.GetAllBank(ctx.Request.Context())
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve bank information", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve bank information")
 		return
 	}
 
@@ -54,6 +58,10 @@ func (c *bankController) GetAllBank(ctx *gin.Context) {
 }
 
 func (c *bankController) GetBankById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankController.GetBankById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +80,7 @@ func (c *bankController) GetBankById(ctx *gin.Context) {
 
 	bank, err := c.bankService.GetBankById(ctx.Request.Context(), parsedBankId)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve bank information", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve bank information")
 		return
 	}
 
@@ -82,6 +89,10 @@ func (c *bankController) GetBankById(ctx *gin.Context) {
 }
 
 func (c *bankController) CreateBank(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankController.CreateBank")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +103,13 @@ func (c *bankController) CreateBank(ctx *gin.Context) {
 
 	var req dtos.BankRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	bank, err := c.bankService.CreateBank(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save bank", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save bank")
 		return
 	}
 
@@ -109,6 +118,10 @@ func (c *bankController) CreateBank(ctx *gin.Context) {
 }
 
 func (c *bankController) UpdateBank(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankController.UpdateBank")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +132,7 @@ func (c *bankController) UpdateBank(ctx *gin.Context) {
 
 	var req dtos.BankRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +144,9 @@ func (c *bankController) UpdateBank(ctx *gin.Context) {
 		return
 	}
 
-	bank, err := c.bankService.UpdateBank(ctx, parsedBankId, req)
+	bank, err := c.bankService.UpdateBank(ctx.Request.Context(), parsedBankId, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update bank", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update bank")
 		return
 	}
 
@@ -144,6 +155,10 @@ func (c *bankController) UpdateBank(ctx *gin.Context) {
 }
 
 func (c *bankController) DeleteBank(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankController.DeleteBank")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,9 +175,8 @@ func (c *bankController) DeleteBank(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.bankService.DeleteBank(ctx, parsedBankId); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete bank", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.bankService.DeleteBank(ctx.Request.Context(), parsedBankId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete bank")
 		return
 	}
 
@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BankStatementController interface {
+	GetStatementFormat(ctx *gin.Context)
+	SaveStatementFormat(ctx *gin.Context)
+	UploadStatement(ctx *gin.Context)
+	GetAllBankTransaction(ctx *gin.Context)
+	RematchBankTransactions(ctx *gin.Context)
+	ConfirmMatch(ctx *gin.Context)
+	RejectMatch(ctx *gin.Context)
+}
+
+type bankStatementController struct {
+	tokenService         services.TokenService
+	bankStatementService services.BankStatementService
+}
+
+func NewBankStatementController(
+	tokenService services.TokenService,
+	bankStatementService services.BankStatementService,
+) BankStatementController {
+	return &bankStatementController{
+		tokenService:         tokenService,
+		bankStatementService: bankStatementService,
+	}
+}
+
+func (c *bankStatementController) GetStatementFormat(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankStatementController.GetStatementFormat")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedBankId, err := strconv.Atoi(ctx.Param("bank_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Bank Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	format, err := c.bankStatementService.GetStatementFormat(ctx.Request.Context(), parsedBankId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve bank statement format")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved bank statement format", format)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *bankStatementController) SaveStatementFormat(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankStatementController.SaveStatementFormat")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedBankId, err := strconv.Atoi(ctx.Param("bank_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Bank Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.SaveBankStatementFormatRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	format, err := c.bankStatementService.SaveStatementFormat(ctx.Request.Context(), parsedBankId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save bank statement format")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Bank statement format successfully saved", format)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *bankStatementController) UploadStatement(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankStatementController.UploadStatement")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedBankId, err := strconv.Atoi(ctx.Param("bank_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Bank Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.UploadBankStatementRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+	defer file.Close()
+
+	result, err := c.bankStatementService.UploadStatement(ctx.Request.Context(), parsedBankId, req.File.Filename, file)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to upload bank statement")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Bank statement successfully uploaded", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *bankStatementController) GetAllBankTransaction(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankStatementController.GetAllBankTransaction")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.BankTransactionQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	transactions, err := c.bankStatementService.GetAllBankTransaction(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve bank transaction information")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved bank transaction", transactions)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *bankStatementController) RematchBankTransactions(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankStatementController.RematchBankTransactions")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	result, err := c.bankStatementService.RematchBankTransactions(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to rematch bank transaction")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Bank transaction successfully rematched", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *bankStatementController) ConfirmMatch(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankStatementController.ConfirmMatch")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedMatchId, err := strconv.Atoi(ctx.Param("match_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Match Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	transaction, err := c.bankStatementService.ConfirmMatch(ctx.Request.Context(), parsedMatchId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to confirm bank transaction match")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Bank transaction match successfully confirmed", transaction)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *bankStatementController) RejectMatch(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BankStatementController.RejectMatch")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedMatchId, err := strconv.Atoi(ctx.Param("match_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Match Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	transaction, err := c.bankStatementService.RejectMatch(ctx.Request.Context(), parsedMatchId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to reject bank transaction match")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Bank transaction match successfully rejected", transaction)
+	ctx.JSON(http.StatusOK, res)
+}
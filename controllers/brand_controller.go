@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BrandController interface {
+	GetAllBrand(ctx *gin.Context)
+	GetBrandById(ctx *gin.Context)
+	CreateBrand(ctx *gin.Context)
+	UpdateBrand(ctx *gin.Context)
+	DeleteBrand(ctx *gin.Context)
+}
+
+type brandController struct {
+	tokenService services.TokenService
+	brandService services.BrandService
+}
+
+func NewBrandController(
+	tokenService services.TokenService,
+	brandService services.BrandService,
+) BrandController {
+	return &brandController{
+		tokenService: tokenService,
+		brandService: brandService,
+	}
+}
+
+func (c *brandController) GetAllBrand(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BrandController.GetAllBrand")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.BrandQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	brands, err := c.brandService.GetAllBrand(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve brand")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved brand", brands)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *brandController) GetBrandById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BrandController.GetBrandById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	brandId := ctx.Param("brand_id")
+	parsedBrandId, err := strconv.Atoi(brandId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Brand Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	brand, err := c.brandService.GetBrandById(ctx.Request.Context(), parsedBrandId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve brand")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved brand", brand)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *brandController) CreateBrand(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BrandController.CreateBrand")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateBrandRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	brand, err := c.brandService.CreateBrand(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save brand")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data brand successfully saved", brand)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *brandController) UpdateBrand(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BrandController.UpdateBrand")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateBrandRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	brandId := ctx.Param("brand_id")
+	parsedBrandId, err := strconv.Atoi(brandId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Brand Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	brand, err := c.brandService.UpdateBrand(ctx.Request.Context(), parsedBrandId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update brand")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Brand successfully updated", brand)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *brandController) DeleteBrand(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "BrandController.DeleteBrand")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	brandId := ctx.Param("brand_id")
+	parsedBrandId, err := strconv.Atoi(brandId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Brand Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.brandService.DeleteBrand(ctx.Request.Context(), parsedBrandId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete brand")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Brand successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
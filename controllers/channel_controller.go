@@ -3,6 +3,7 @@ package controllers
 import (
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
@@ -34,6 +35,10 @@ func NewChannelController(
 }
 
 func (c *channelController) GetAllChannel(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ChannelController.GetAllChannel")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -44,8 +49,7 @@ func (c *channelController) GetAllChannel(ctx *gin.Context) {
 
 	channels, err := c.channelService.GetAllChannel(ctx.Request.Context())
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve channel", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve channel")
 		return
 	}
 
@@ -54,6 +58,10 @@ func (c *channelController) GetAllChannel(ctx *gin.Context) {
 }
 
 func (c *channelController) GetChannelById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ChannelController.GetChannelById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +80,7 @@ func (c *channelController) GetChannelById(ctx *gin.Context) {
 
 	channel, err := c.channelService.GetChannelById(ctx.Request.Context(), parsedChannelId)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve channel", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve channel")
 		return
 	}
 
@@ -82,6 +89,10 @@ func (c *channelController) GetChannelById(ctx *gin.Context) {
 }
 
 func (c *channelController) CreateChannel(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ChannelController.CreateChannel")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +103,13 @@ func (c *channelController) CreateChannel(ctx *gin.Context) {
 
 	var req dtos.ChannelRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	channel, err := c.channelService.CreateChannel(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save channel", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save channel")
 		return
 	}
 
@@ -109,6 +118,10 @@ func (c *channelController) CreateChannel(ctx *gin.Context) {
 }
 
 func (c *channelController) UpdateChannel(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ChannelController.UpdateChannel")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +132,7 @@ func (c *channelController) UpdateChannel(ctx *gin.Context) {
 
 	var req dtos.ChannelRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +144,9 @@ func (c *channelController) UpdateChannel(ctx *gin.Context) {
 		return
 	}
 
-	channel, err := c.channelService.UpdateChannel(ctx, parsedChannelId, req)
+	channel, err := c.channelService.UpdateChannel(ctx.Request.Context(), parsedChannelId, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update channel", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update channel")
 		return
 	}
 
@@ -144,6 +155,10 @@ func (c *channelController) UpdateChannel(ctx *gin.Context) {
 }
 
 func (c *channelController) DeleteChannel(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ChannelController.DeleteChannel")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,9 +175,8 @@ func (c *channelController) DeleteChannel(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.channelService.DeleteChannel(ctx, parsedChannelId); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete channel", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.channelService.DeleteChannel(ctx.Request.Context(), parsedChannelId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete channel")
 		return
 	}
 
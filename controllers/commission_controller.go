@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/exports"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommissionController interface {
+	GetSalePersonCommissionPlans(ctx *gin.Context)
+	AssignCommissionPlan(ctx *gin.Context)
+	UnassignCommissionPlan(ctx *gin.Context)
+	GetCommissionStatement(ctx *gin.Context)
+	RecalculateCommissions(ctx *gin.Context)
+}
+
+type commissionController struct {
+	tokenService      services.TokenService
+	commissionService services.CommissionService
+}
+
+func NewCommissionController(
+	tokenService services.TokenService,
+	commissionService services.CommissionService,
+) CommissionController {
+	return &commissionController{
+		tokenService:      tokenService,
+		commissionService: commissionService,
+	}
+}
+
+func (c *commissionController) GetSalePersonCommissionPlans(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionController.GetSalePersonCommissionPlans")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedSalePersonId, err := strconv.Atoi(ctx.Param("sale_person_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Sale Person Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	assignments, err := c.commissionService.GetSalePersonCommissionPlans(ctx.Request.Context(), parsedSalePersonId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve commission plan")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved commission plan", assignments)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *commissionController) AssignCommissionPlan(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionController.AssignCommissionPlan")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedSalePersonId, err := strconv.Atoi(ctx.Param("sale_person_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Sale Person Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.AssignCommissionPlanRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	assignment, err := c.commissionService.AssignCommissionPlan(ctx.Request.Context(), parsedSalePersonId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save commission plan")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Commission plan successfully assigned", assignment)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *commissionController) UnassignCommissionPlan(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionController.UnassignCommissionPlan")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedSalePersonId, err := strconv.Atoi(ctx.Param("sale_person_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Sale Person Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	parsedAssignmentId, err := strconv.Atoi(ctx.Param("assignment_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Assignment Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.commissionService.UnassignCommissionPlan(ctx.Request.Context(), parsedSalePersonId, parsedAssignmentId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete commission plan")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Commission plan successfully unassigned", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *commissionController) GetCommissionStatement(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionController.GetCommissionStatement")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedSalePersonId, err := strconv.Atoi(ctx.Param("sale_person_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Sale Person Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var query dtos.CommissionStatementQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	statement, err := c.commissionService.GetCommissionStatement(ctx.Request.Context(), parsedSalePersonId, query.Period)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve commission statement")
+		return
+	}
+
+	if query.Format != "" && query.Format != "json" {
+		filename := fmt.Sprintf("commissions-%d-%s", statement.SalePerson.Id, statement.Period)
+		writeExport(ctx, query.Format, filename, commissionStatementTable(statement))
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved commission statement", statement)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *commissionController) RecalculateCommissions(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionController.RecalculateCommissions")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedSalePersonId, err := strconv.Atoi(ctx.Param("sale_person_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Sale Person Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var query dtos.CommissionStatementQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	statement, err := c.commissionService.RecalculateCommissions(ctx.Request.Context(), parsedSalePersonId, query.Period)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to recalculate commission")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Commission successfully recalculated", statement)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// commissionStatementTable lays the statement out with one row per income
+// and a closing total row.
+func commissionStatementTable(statement dtos.CommissionStatement) exports.Table {
+	table := exports.Table{
+		Title:  "Commission statement",
+		Header: []string{statement.SalePerson.Name, "Period: " + statement.Period},
+		Columns: []exports.Column{
+			{Title: "invoice_id_number", Width: 12},
+			{Title: "paid_date", Width: 11},
+			{Title: "commission_plan", Width: 25},
+			{Title: "revenue", Width: 14},
+			{Title: "rate", Width: 8},
+			{Title: "commission", Width: 14},
+			{Title: "currency", Width: 8},
+		},
+		Rows:   [][]any{},
+		Footer: []any{"Total", nil, nil, statement.TotalRevenue, nil, statement.TotalCommission, statement.Currency},
+	}
+	for _, l := range statement.Lines {
+		table.Rows = append(table.Rows, []any{
+			l.IncomeInvoiceIdNumber, l.PaidDate, l.CommissionPlanName, l.Revenue, l.Rate, l.Amount, statement.Currency,
+		})
+	}
+	return table
+}
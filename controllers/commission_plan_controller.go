@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommissionPlanController interface {
+	GetAllCommissionPlan(ctx *gin.Context)
+	GetCommissionPlanById(ctx *gin.Context)
+	CreateCommissionPlan(ctx *gin.Context)
+	UpdateCommissionPlan(ctx *gin.Context)
+	DeleteCommissionPlan(ctx *gin.Context)
+}
+
+type commissionPlanController struct {
+	tokenService          services.TokenService
+	commissionPlanService services.CommissionPlanService
+}
+
+func NewCommissionPlanController(
+	tokenService services.TokenService,
+	commissionPlanService services.CommissionPlanService,
+) CommissionPlanController {
+	return &commissionPlanController{
+		tokenService:          tokenService,
+		commissionPlanService: commissionPlanService,
+	}
+}
+
+func (c *commissionPlanController) GetAllCommissionPlan(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionPlanController.GetAllCommissionPlan")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	commissionPlans, err := c.commissionPlanService.GetAllCommissionPlan(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve commission plan")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved commission plan", commissionPlans)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *commissionPlanController) GetCommissionPlanById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionPlanController.GetCommissionPlanById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	commissionPlanId := ctx.Param("commission_plan_id")
+	parsedCommissionPlanId, err := strconv.Atoi(commissionPlanId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Commission Plan Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	commissionPlan, err := c.commissionPlanService.GetCommissionPlanById(ctx.Request.Context(), parsedCommissionPlanId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve commission plan")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved commission plan", commissionPlan)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *commissionPlanController) CreateCommissionPlan(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionPlanController.CreateCommissionPlan")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateCommissionPlanRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	commissionPlan, err := c.commissionPlanService.CreateCommissionPlan(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save commission plan")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data commission plan successfully saved", commissionPlan)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *commissionPlanController) UpdateCommissionPlan(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionPlanController.UpdateCommissionPlan")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateCommissionPlanRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	commissionPlanId := ctx.Param("commission_plan_id")
+	parsedCommissionPlanId, err := strconv.Atoi(commissionPlanId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Commission Plan Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	commissionPlan, err := c.commissionPlanService.UpdateCommissionPlan(ctx.Request.Context(), parsedCommissionPlanId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update commission plan")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("CommissionPlan successfully updated", commissionPlan)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *commissionPlanController) DeleteCommissionPlan(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CommissionPlanController.DeleteCommissionPlan")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	commissionPlanId := ctx.Param("commission_plan_id")
+	parsedCommissionPlanId, err := strconv.Atoi(commissionPlanId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Commission Plan Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.commissionPlanService.DeleteCommissionPlan(ctx.Request.Context(), parsedCommissionPlanId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete commission plan")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("CommissionPlan successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ContactController interface {
+	GetAllContact(ctx *gin.Context)
+	GetContactById(ctx *gin.Context)
+	CreateContact(ctx *gin.Context)
+	UpdateContact(ctx *gin.Context)
+	DeleteContact(ctx *gin.Context)
+}
+
+type contactController struct {
+	tokenService   services.TokenService
+	contactService services.ContactService
+}
+
+func NewContactController(
+	tokenService services.TokenService,
+	contactService services.ContactService,
+) ContactController {
+	return &contactController{
+		tokenService:   tokenService,
+		contactService: contactService,
+	}
+}
+
+func (c *contactController) GetAllContact(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ContactController.GetAllContact")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.ContactQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	contacts, err := c.contactService.GetAllContact(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve contact")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved contact", contacts)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *contactController) GetContactById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ContactController.GetContactById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	contactId := ctx.Param("contact_id")
+	parsedContactId, err := strconv.Atoi(contactId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Contact Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	contact, err := c.contactService.GetContactById(ctx.Request.Context(), parsedContactId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve contact")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved contact", contact)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *contactController) CreateContact(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ContactController.CreateContact")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateContactRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	contact, err := c.contactService.CreateContact(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save contact")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data contact successfully saved", contact)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *contactController) UpdateContact(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ContactController.UpdateContact")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateContactRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	contactId := ctx.Param("contact_id")
+	parsedContactId, err := strconv.Atoi(contactId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Contact Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	contact, err := c.contactService.UpdateContact(ctx.Request.Context(), parsedContactId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update contact")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Contact successfully updated", contact)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *contactController) DeleteContact(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ContactController.DeleteContact")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	contactId := ctx.Param("contact_id")
+	parsedContactId, err := strconv.Atoi(contactId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Contact Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.contactService.DeleteContact(ctx.Request.Context(), parsedContactId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete contact")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Contact successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
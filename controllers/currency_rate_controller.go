@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CurrencyRateController interface {
+	GetAllCurrencyRate(ctx *gin.Context)
+	GetCurrencyRateById(ctx *gin.Context)
+	CreateCurrencyRate(ctx *gin.Context)
+	UpdateCurrencyRate(ctx *gin.Context)
+	DeleteCurrencyRate(ctx *gin.Context)
+}
+
+type currencyRateController struct {
+	tokenService        services.TokenService
+	currencyRateService services.CurrencyRateService
+}
+
+func NewCurrencyRateController(
+	tokenService services.TokenService,
+	currencyRateService services.CurrencyRateService,
+) CurrencyRateController {
+	return &currencyRateController{
+		tokenService:        tokenService,
+		currencyRateService: currencyRateService,
+	}
+}
+
+func (c *currencyRateController) GetAllCurrencyRate(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CurrencyRateController.GetAllCurrencyRate")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	currency := ctx.Query("currency")
+
+	currencyRates, err := c.currencyRateService.GetAllCurrencyRate(ctx.Request.Context(), currency)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve currency rate information")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved currency rate", currencyRates)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *currencyRateController) GetCurrencyRateById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CurrencyRateController.GetCurrencyRateById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed(" Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	currencyRateId := ctx.Param("currency_rate_id")
+	parsedCurrencyRateId, err := strconv.Atoi(currencyRateId)
+	if err != nil {
+		res := utils.BuildResponseFailed(" Failed to process the request", "Currency Rate Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	currencyRate, err := c.currencyRateService.GetCurrencyRateById(ctx.Request.Context(), parsedCurrencyRateId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve currency rate information")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved currency rate", currencyRate)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *currencyRateController) CreateCurrencyRate(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CurrencyRateController.CreateCurrencyRate")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed(" Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateCurrencyRateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	currencyRate, err := c.currencyRateService.CreateCurrencyRate(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save currency rate")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Currency rate data successfully saved", currencyRate)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *currencyRateController) UpdateCurrencyRate(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CurrencyRateController.UpdateCurrencyRate")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed(" Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateCurrencyRateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	currencyRateId := ctx.Param("currency_rate_id")
+	parsedCurrencyRateId, err := strconv.Atoi(currencyRateId)
+	if err != nil {
+		res := utils.BuildResponseFailed(" Failed to process the request", "Currency Rate Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	currencyRate, err := c.currencyRateService.UpdateCurrencyRate(ctx.Request.Context(), parsedCurrencyRateId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update currency rate")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Currency rate successfully updated", currencyRate)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *currencyRateController) DeleteCurrencyRate(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "CurrencyRateController.DeleteCurrencyRate")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	currencyRateId := ctx.Param("currency_rate_id")
+	parsedCurrencyRateId, err := strconv.Atoi(currencyRateId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Currency Rate Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.currencyRateService.DeleteCurrencyRate(ctx.Request.Context(), parsedCurrencyRateId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete currency rate")
+		return
+	}
+
+	res := utils.BuildResponseSuccess(" Currency rate successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
@@ -16,6 +17,12 @@ type DetailController interface {
 	CreateDetail(ctx *gin.Context)
 	UpdateDetail(ctx *gin.Context)
 	DeleteDetail(ctx *gin.Context)
+
+	GetIncomeDetails(ctx *gin.Context)
+	CreateIncomeDetail(ctx *gin.Context)
+	UpdateIncomeDetail(ctx *gin.Context)
+	DeleteIncomeDetail(ctx *gin.Context)
+	ReorderIncomeDetails(ctx *gin.Context)
 }
 
 type detailController struct {
@@ -34,6 +41,10 @@ func NewDetailController(
 }
 
 func (c *detailController) GetAllDetail(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.GetAllDetail")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -42,10 +53,11 @@ func (c *detailController) GetAllDetail(ctx *gin.Context) {
 		return
 	}
 
-	details, err := c.detailService.GetAllDetail(ctx.Request.Context())
+	includeIncome := ctx.Query("include") == "income"
+
+	details, err := c.detailService.GetAllDetail(ctx.Request.Context(), includeIncome)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve detail", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve detail")
 		return
 	}
 
@@ -54,6 +66,10 @@ func (c *detailController) GetAllDetail(ctx *gin.Context) {
 }
 
 func (c *detailController) GetDetailById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.GetDetailById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +88,7 @@ func (c *detailController) GetDetailById(ctx *gin.Context) {
 
 	detail, err := c.detailService.GetDetailById(ctx.Request.Context(), parsedDetailId)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve detail", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve detail")
 		return
 	}
 
@@ -82,6 +97,10 @@ func (c *detailController) GetDetailById(ctx *gin.Context) {
 }
 
 func (c *detailController) CreateDetail(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.CreateDetail")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +111,13 @@ func (c *detailController) CreateDetail(ctx *gin.Context) {
 
 	var req dtos.CreateDetailRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	detail, err := c.detailService.CreateDetail(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save detail", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save detail")
 		return
 	}
 
@@ -109,6 +126,10 @@ func (c *detailController) CreateDetail(ctx *gin.Context) {
 }
 
 func (c *detailController) UpdateDetail(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.UpdateDetail")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +140,7 @@ func (c *detailController) UpdateDetail(ctx *gin.Context) {
 
 	var req dtos.UpdateDetailRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +152,9 @@ func (c *detailController) UpdateDetail(ctx *gin.Context) {
 		return
 	}
 
-	detail, err := c.detailService.UpdateDetail(ctx, parsedDetailId, req)
+	detail, err := c.detailService.UpdateDetail(ctx.Request.Context(), parsedDetailId, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update detail", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update detail")
 		return
 	}
 
@@ -144,6 +163,10 @@ func (c *detailController) UpdateDetail(ctx *gin.Context) {
 }
 
 func (c *detailController) DeleteDetail(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.DeleteDetail")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,12 +183,199 @@ func (c *detailController) DeleteDetail(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.detailService.DeleteDetail(ctx, parsedDetailId); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete detail", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.detailService.DeleteDetail(ctx.Request.Context(), parsedDetailId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete detail")
 		return
 	}
 
 	res := utils.BuildResponseSuccess("Detail successfully deleted", utils.EmptyObj{})
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (c *detailController) GetIncomeDetails(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.GetIncomeDetails")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	incomeInvoiceIdNumber := ctx.Param("income_invoice_id_number")
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(incomeInvoiceIdNumber)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	details, err := c.detailService.GetIncomeDetails(ctx.Request.Context(), parsedIncomeInvoiceIdNumber)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve detail")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved detail", details)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *detailController) CreateIncomeDetail(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.CreateIncomeDetail")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	incomeInvoiceIdNumber := ctx.Param("income_invoice_id_number")
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(incomeInvoiceIdNumber)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.CreateIncomeDetailRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	detail, err := c.detailService.CreateIncomeDetail(ctx.Request.Context(), parsedIncomeInvoiceIdNumber, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save detail")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data detail successfully saved", detail)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *detailController) UpdateIncomeDetail(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.UpdateIncomeDetail")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	incomeInvoiceIdNumber := ctx.Param("income_invoice_id_number")
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(incomeInvoiceIdNumber)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	detailId := ctx.Param("detail_id")
+	parsedDetailId, err := strconv.Atoi(detailId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Detail Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.UpdateIncomeDetailRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	detail, err := c.detailService.UpdateIncomeDetail(ctx.Request.Context(), parsedIncomeInvoiceIdNumber, parsedDetailId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update detail")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Detail successfully updated", detail)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *detailController) DeleteIncomeDetail(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.DeleteIncomeDetail")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	incomeInvoiceIdNumber := ctx.Param("income_invoice_id_number")
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(incomeInvoiceIdNumber)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	detailId := ctx.Param("detail_id")
+	parsedDetailId, err := strconv.Atoi(detailId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Detail Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.detailService.DeleteIncomeDetail(ctx.Request.Context(), parsedIncomeInvoiceIdNumber, parsedDetailId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete detail")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Detail successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *detailController) ReorderIncomeDetails(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "DetailController.ReorderIncomeDetails")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	incomeInvoiceIdNumber := ctx.Param("income_invoice_id_number")
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(incomeInvoiceIdNumber)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.ReorderIncomeDetailsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	details, err := c.detailService.ReorderIncomeDetails(ctx.Request.Context(), parsedIncomeInvoiceIdNumber, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to reorder detail")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Detail successfully reordered", details)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,320 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExpenseController interface {
+	GetAllExpense(ctx *gin.Context)
+	GetExpenseById(ctx *gin.Context)
+	CreateExpense(ctx *gin.Context)
+	UpdateExpense(ctx *gin.Context)
+	DeleteExpense(ctx *gin.Context)
+	CreateExpenseAttachment(ctx *gin.Context)
+	GetExpenseAttachment(ctx *gin.Context)
+	DeleteExpenseAttachment(ctx *gin.Context)
+}
+
+type expenseController struct {
+	tokenService   services.TokenService
+	expenseService services.ExpenseService
+}
+
+func NewExpenseController(
+	tokenService services.TokenService,
+	expenseService services.ExpenseService,
+) ExpenseController {
+	return &expenseController{
+		tokenService:   tokenService,
+		expenseService: expenseService,
+	}
+}
+
+func (c *expenseController) GetAllExpense(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ExpenseController.GetAllExpense")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.ExpenseQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	expenses, err := c.expenseService.GetAllExpense(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve expense")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved expense", expenses)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *expenseController) GetExpenseById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ExpenseController.GetExpenseById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	expenseId := ctx.Param("expense_id")
+	parsedExpenseId, err := strconv.Atoi(expenseId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Expense Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	expense, err := c.expenseService.GetExpenseById(ctx.Request.Context(), parsedExpenseId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve expense")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved expense", expense)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *expenseController) CreateExpense(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ExpenseController.CreateExpense")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateExpenseRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	expense, err := c.expenseService.CreateExpense(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save expense")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data expense successfully saved", expense)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *expenseController) UpdateExpense(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ExpenseController.UpdateExpense")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateExpenseRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	expenseId := ctx.Param("expense_id")
+	parsedExpenseId, err := strconv.Atoi(expenseId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Expense Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	expense, err := c.expenseService.UpdateExpense(ctx.Request.Context(), parsedExpenseId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update expense")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Expense successfully updated", expense)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *expenseController) DeleteExpense(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ExpenseController.DeleteExpense")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	expenseId := ctx.Param("expense_id")
+	parsedExpenseId, err := strconv.Atoi(expenseId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Expense Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.expenseService.DeleteExpense(ctx.Request.Context(), parsedExpenseId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete expense")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Expense successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *expenseController) CreateExpenseAttachment(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ExpenseController.CreateExpenseAttachment")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	expenseId := ctx.Param("expense_id")
+	parsedExpenseId, err := strconv.Atoi(expenseId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Expense Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.UploadExpenseAttachmentRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+	defer file.Close()
+
+	// Read one byte past the limit so the service can reject larger files.
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAttachmentSize+1))
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	attachment, err := c.expenseService.CreateExpenseAttachment(ctx.Request.Context(), parsedExpenseId, req.File.Filename, req.File.Header.Get("Content-Type"), data)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save expense attachment")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Expense attachment successfully saved", attachment)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *expenseController) GetExpenseAttachment(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ExpenseController.GetExpenseAttachment")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedExpenseId, err := strconv.Atoi(ctx.Param("expense_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Expense Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	parsedAttachmentId, err := strconv.Atoi(ctx.Param("attachment_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Attachment Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	file, err := c.expenseService.GetExpenseAttachment(ctx.Request.Context(), parsedExpenseId, parsedAttachmentId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve expense attachment")
+		return
+	}
+
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.FileName}))
+	ctx.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+func (c *expenseController) DeleteExpenseAttachment(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ExpenseController.DeleteExpenseAttachment")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedExpenseId, err := strconv.Atoi(ctx.Param("expense_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Expense Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	parsedAttachmentId, err := strconv.Atoi(ctx.Param("attachment_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Attachment Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.expenseService.DeleteExpenseAttachment(ctx.Request.Context(), parsedExpenseId, parsedAttachmentId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete expense attachment")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Expense attachment successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
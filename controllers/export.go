@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mtii-backend/exports"
+	"mtii-backend/taxid"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeExport sends table as a download named name plus the format's
+// extension.
+func writeExport(ctx *gin.Context, format, name string, table exports.Table) {
+	contentType, ok := exports.ContentType(format)
+	if !ok {
+		ctx.Error(fmt.Errorf("unknown export format %q", format)).SetMeta("Failed to export")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := exports.Write(&buf, format, table); err != nil {
+		ctx.Error(err).SetMeta("Failed to export")
+		return
+	}
+
+	filename := name + "." + format
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// downloadWriter streams a download, sending its headers with the first
+// bytes written so an error raised before then still gets a JSON response.
+type downloadWriter struct {
+	ctx         *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.ctx.Header("Content-Type", w.contentType)
+		w.ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": w.filename}))
+		w.ctx.Status(http.StatusOK)
+	}
+	return w.ctx.Writer.Write(p)
+}
+
+// branchLabel names a taxpayer's branch the way tax forms do.
+func branchLabel(branch string) string {
+	if branch == "" || branch == taxid.HeadOffice {
+		return "Head office"
+	}
+	return "Branch " + branch
+}
@@ -1,11 +1,19 @@
 package controllers
 
 import (
+	"encoding/json"
+	"fmt"
+	"mime"
 	"mtii-backend/dtos"
+	"mtii-backend/exports"
+	"mtii-backend/helpers"
+	"mtii-backend/promptpay"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +24,16 @@ type IncomeController interface {
 	CreateIncome(ctx *gin.Context)
 	UpdateIncome(ctx *gin.Context)
 	DeleteIncome(ctx *gin.Context)
+	ConvertToInvoice(ctx *gin.Context)
+	IssueReceipt(ctx *gin.Context)
+	ExportIncomes(ctx *gin.Context)
+	ImportIncomes(ctx *gin.Context)
+	GetAllImportBatch(ctx *gin.Context)
+	RollbackImportBatch(ctx *gin.Context)
+	GetPromptPay(ctx *gin.Context)
+	GetPromptPayQR(ctx *gin.Context)
+	GetETaxInvoice(ctx *gin.Context)
+	GetInvoicePDF(ctx *gin.Context)
 }
 
 type incomeController struct {
@@ -34,6 +52,10 @@ func NewIncomeController(
 }
 
 func (c *incomeController) GetAllIncome(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.GetAllIncome")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -42,10 +64,15 @@ func (c *incomeController) GetAllIncome(ctx *gin.Context) {
 		return
 	}
 
-	incomes, err := c.incomeService.GetAllIncome(ctx.Request.Context())
+	var query dtos.IncomeQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	incomes, err := c.incomeService.GetAllIncome(ctx.Request.Context(), query)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve income", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve income")
 		return
 	}
 
@@ -54,6 +81,10 @@ func (c *incomeController) GetAllIncome(ctx *gin.Context) {
 }
 
 func (c *incomeController) GetIncomeByInvoiceIdNumber(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.GetIncomeByInvoiceIdNumber")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +103,7 @@ func (c *incomeController) GetIncomeByInvoiceIdNumber(ctx *gin.Context) {
 
 	income, err := c.incomeService.GetIncomeByInvoiceIdNumber(ctx.Request.Context(), parsedIncomeInvoiceIdNumber)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve income", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve income")
 		return
 	}
 
@@ -82,6 +112,10 @@ func (c *incomeController) GetIncomeByInvoiceIdNumber(ctx *gin.Context) {
 }
 
 func (c *incomeController) CreateIncome(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.CreateIncome")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +126,13 @@ func (c *incomeController) CreateIncome(ctx *gin.Context) {
 
 	var req dtos.CreateIncomeRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	income, err := c.incomeService.CreateIncome(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save income", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save income")
 		return
 	}
 
@@ -109,6 +141,10 @@ func (c *incomeController) CreateIncome(ctx *gin.Context) {
 }
 
 func (c *incomeController) UpdateIncome(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.UpdateIncome")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +155,7 @@ func (c *incomeController) UpdateIncome(ctx *gin.Context) {
 
 	var req dtos.UpdateIncomeRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +167,9 @@ func (c *incomeController) UpdateIncome(ctx *gin.Context) {
 		return
 	}
 
-	income, err := c.incomeService.UpdateIncome(ctx, parsedIncomeInvoiceIdNumber, req)
+	income, err := c.incomeService.UpdateIncome(ctx.Request.Context(), parsedIncomeInvoiceIdNumber, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update income", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update income")
 		return
 	}
 
@@ -144,6 +178,10 @@ func (c *incomeController) UpdateIncome(ctx *gin.Context) {
 }
 
 func (c *incomeController) DeleteIncome(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.DeleteIncome")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,12 +198,364 @@ func (c *incomeController) DeleteIncome(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.incomeService.DeleteIncome(ctx, parsedIncomeInvoiceIdNumber); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete income", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.incomeService.DeleteIncome(ctx.Request.Context(), parsedIncomeInvoiceIdNumber); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete income")
 		return
 	}
 
 	res := utils.BuildResponseSuccess("Income successfully deleted", utils.EmptyObj{})
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (c *incomeController) ConvertToInvoice(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.ConvertToInvoice")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(ctx.Param("income_invoice_id_number"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.ConvertToInvoiceRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	income, err := c.incomeService.ConvertToInvoice(ctx.Request.Context(), parsedIncomeInvoiceIdNumber, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to convert quotation to invoice")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Quotation successfully converted to invoice", income)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *incomeController) IssueReceipt(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.IssueReceipt")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(ctx.Param("income_invoice_id_number"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dtos.IssueReceiptRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	income, err := c.incomeService.IssueReceipt(ctx.Request.Context(), parsedIncomeInvoiceIdNumber, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to issue receipt")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Receipt successfully issued", income)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *incomeController) ExportIncomes(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.ExportIncomes")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.IncomeExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	format := helpers.DefaultIfEmpty(query.Format, exports.FormatCSV)
+	contentType, _ := exports.ContentType(format)
+	w := &downloadWriter{
+		ctx:         ctx,
+		contentType: contentType,
+		filename:    "incomes-" + time.Now().Format("2006-01-02") + "." + format,
+	}
+
+	// Once the download has started, a failure can only cut it short.
+	if err := c.incomeService.ExportIncomes(ctx.Request.Context(), query, w); err != nil {
+		ctx.Error(err).SetMeta("Failed to export income")
+		return
+	}
+}
+
+func (c *incomeController) ImportIncomes(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.ImportIncomes")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.ImportIncomesRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	opts := dtos.ImportOptions{
+		FileName:      req.File.Filename,
+		Source:        services.ImportSourceAPI,
+		DryRun:        req.DryRun,
+		CreateMissing: req.CreateMissing,
+	}
+	if req.Mapping != "" {
+		if err := json.Unmarshal([]byte(req.Mapping), &opts.Mapping); err != nil {
+			ctx.Error(err).SetMeta("Failed to retrieve request")
+			return
+		}
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+	defer file.Close()
+
+	result, err := c.incomeService.ImportIncomes(ctx.Request.Context(), file, opts)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to import income")
+		return
+	}
+
+	if result.DryRun {
+		res := utils.BuildResponseSuccess("Income import successfully checked", result)
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Income successfully imported", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *incomeController) GetAllImportBatch(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.GetAllImportBatch")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	batches, err := c.incomeService.GetAllImportBatch(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to get import batches")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Import batches successfully retrieved", batches)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *incomeController) RollbackImportBatch(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.RollbackImportBatch")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedImportBatchId, err := strconv.Atoi(ctx.Param("import_batch_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Import Batch Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	batch, err := c.incomeService.RollbackImportBatch(ctx.Request.Context(), parsedImportBatchId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to roll back import batch")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Import batch successfully rolled back", batch)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *incomeController) GetPromptPay(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.GetPromptPay")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(ctx.Param("income_invoice_id_number"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	payment, err := c.incomeService.GetPromptPay(ctx.Request.Context(), parsedIncomeInvoiceIdNumber)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve PromptPay payment")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved PromptPay payment", payment)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *incomeController) GetPromptPayQR(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.GetPromptPayQR")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(ctx.Param("income_invoice_id_number"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var query dtos.PromptPayQRQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	payment, err := c.incomeService.GetPromptPay(ctx.Request.Context(), parsedIncomeInvoiceIdNumber)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve PromptPay payment")
+		return
+	}
+
+	png, err := promptpay.PNG(payment.Payload, helpers.DefaultIfEmpty(query.Size, 256))
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to draw PromptPay QR code")
+		return
+	}
+
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+func (c *incomeController) GetETaxInvoice(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.GetETaxInvoice")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(ctx.Param("income_invoice_id_number"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var query dtos.ETaxInvoiceQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	document, err := c.incomeService.GetETaxInvoice(ctx.Request.Context(), parsedIncomeInvoiceIdNumber, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to generate e-Tax invoice")
+		return
+	}
+
+	filename := fmt.Sprintf("etax-%s-%d.xml", helpers.DefaultIfEmpty(query.Type, services.ETaxTaxInvoice), parsedIncomeInvoiceIdNumber)
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	ctx.Data(http.StatusOK, "application/xml", document)
+}
+
+func (c *incomeController) GetInvoicePDF(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "IncomeController.GetInvoicePDF")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	parsedIncomeInvoiceIdNumber, err := strconv.Atoi(ctx.Param("income_invoice_id_number"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Income Invoice Id Number tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	document, err := c.incomeService.GetInvoicePDF(ctx.Request.Context(), parsedIncomeInvoiceIdNumber)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to print income")
+		return
+	}
+
+	filename := fmt.Sprintf("income-%d.pdf", parsedIncomeInvoiceIdNumber)
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	ctx.Data(http.StatusOK, "application/pdf", document)
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InfluencerAssignmentController interface {
+	GetAllInfluencerAssignment(ctx *gin.Context)
+	GetInfluencerAssignmentById(ctx *gin.Context)
+	GetInfluencerJobs(ctx *gin.Context)
+	CreateInfluencerAssignment(ctx *gin.Context)
+	UpdateInfluencerAssignment(ctx *gin.Context)
+	DeleteInfluencerAssignment(ctx *gin.Context)
+}
+
+type influencerAssignmentController struct {
+	tokenService                services.TokenService
+	influencerAssignmentService services.InfluencerAssignmentService
+}
+
+func NewInfluencerAssignmentController(
+	tokenService services.TokenService,
+	influencerAssignmentService services.InfluencerAssignmentService,
+) InfluencerAssignmentController {
+	return &influencerAssignmentController{
+		tokenService:                tokenService,
+		influencerAssignmentService: influencerAssignmentService,
+	}
+}
+
+func (c *influencerAssignmentController) GetAllInfluencerAssignment(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerAssignmentController.GetAllInfluencerAssignment")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.InfluencerAssignmentQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	influencerAssignments, err := c.influencerAssignmentService.GetAllInfluencerAssignment(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve influencer assignment")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved influencer assignment", influencerAssignments)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *influencerAssignmentController) GetInfluencerAssignmentById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerAssignmentController.GetInfluencerAssignmentById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	influencerAssignmentId := ctx.Param("influencer_assignment_id")
+	parsedInfluencerAssignmentId, err := strconv.Atoi(influencerAssignmentId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Influencer assignment Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	influencerAssignment, err := c.influencerAssignmentService.GetInfluencerAssignmentById(ctx.Request.Context(), parsedInfluencerAssignmentId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve influencer assignment")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved influencer assignment", influencerAssignment)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *influencerAssignmentController) GetInfluencerJobs(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerAssignmentController.GetInfluencerJobs")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	influencerId := ctx.Param("influencer_id")
+	parsedInfluencerId, err := strconv.Atoi(influencerId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Influencer Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var query dtos.InfluencerAssignmentQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	jobs, err := c.influencerAssignmentService.GetInfluencerJobs(ctx.Request.Context(), parsedInfluencerId, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve influencer jobs")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved influencer jobs", jobs)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *influencerAssignmentController) CreateInfluencerAssignment(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerAssignmentController.CreateInfluencerAssignment")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateInfluencerAssignmentRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	influencerAssignment, err := c.influencerAssignmentService.CreateInfluencerAssignment(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save influencer assignment")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data influencer assignment successfully saved", influencerAssignment)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *influencerAssignmentController) UpdateInfluencerAssignment(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerAssignmentController.UpdateInfluencerAssignment")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateInfluencerAssignmentRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	influencerAssignmentId := ctx.Param("influencer_assignment_id")
+	parsedInfluencerAssignmentId, err := strconv.Atoi(influencerAssignmentId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Influencer assignment Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	influencerAssignment, err := c.influencerAssignmentService.UpdateInfluencerAssignment(ctx.Request.Context(), parsedInfluencerAssignmentId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update influencer assignment")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Influencer assignment successfully updated", influencerAssignment)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *influencerAssignmentController) DeleteInfluencerAssignment(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerAssignmentController.DeleteInfluencerAssignment")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	influencerAssignmentId := ctx.Param("influencer_assignment_id")
+	parsedInfluencerAssignmentId, err := strconv.Atoi(influencerAssignmentId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Influencer assignment Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.influencerAssignmentService.DeleteInfluencerAssignment(ctx.Request.Context(), parsedInfluencerAssignmentId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete influencer assignment")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Influencer assignment successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InfluencerController interface {
+	GetAllInfluencer(ctx *gin.Context)
+	GetInfluencerById(ctx *gin.Context)
+	CreateInfluencer(ctx *gin.Context)
+	UpdateInfluencer(ctx *gin.Context)
+	DeleteInfluencer(ctx *gin.Context)
+}
+
+type influencerController struct {
+	tokenService      services.TokenService
+	influencerService services.InfluencerService
+}
+
+func NewInfluencerController(
+	tokenService services.TokenService,
+	influencerService services.InfluencerService,
+) InfluencerController {
+	return &influencerController{
+		tokenService:      tokenService,
+		influencerService: influencerService,
+	}
+}
+
+func (c *influencerController) GetAllInfluencer(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerController.GetAllInfluencer")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.InfluencerQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	influencers, err := c.influencerService.GetAllInfluencer(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve influencer")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved influencer", influencers)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *influencerController) GetInfluencerById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerController.GetInfluencerById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	influencerId := ctx.Param("influencer_id")
+	parsedInfluencerId, err := strconv.Atoi(influencerId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Influencer Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	influencer, err := c.influencerService.GetInfluencerById(ctx.Request.Context(), parsedInfluencerId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve influencer")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved influencer", influencer)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *influencerController) CreateInfluencer(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerController.CreateInfluencer")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateInfluencerRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	influencer, err := c.influencerService.CreateInfluencer(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save influencer")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data influencer successfully saved", influencer)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *influencerController) UpdateInfluencer(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerController.UpdateInfluencer")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateInfluencerRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	influencerId := ctx.Param("influencer_id")
+	parsedInfluencerId, err := strconv.Atoi(influencerId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Influencer Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	influencer, err := c.influencerService.UpdateInfluencer(ctx.Request.Context(), parsedInfluencerId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update influencer")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Influencer successfully updated", influencer)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *influencerController) DeleteInfluencer(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "InfluencerController.DeleteInfluencer")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	influencerId := ctx.Param("influencer_id")
+	parsedInfluencerId, err := strconv.Atoi(influencerId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Influencer Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.influencerService.DeleteInfluencer(ctx.Request.Context(), parsedInfluencerId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete influencer")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Influencer successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
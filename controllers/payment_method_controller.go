@@ -3,6 +3,7 @@ package controllers
 import (
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
@@ -34,6 +35,10 @@ func NewPaymentMethodController(
 }
 
 func (c *paymentMethodController) GetAllPaymentMethod(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PaymentMethodController.GetAllPaymentMethod")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -44,8 +49,7 @@ func (c *paymentMethodController) GetAllPaymentMethod(ctx *gin.Context) {
 
 	paymentMethods, err := c.paymentMethodService.GetAllPaymentMethod(ctx.Request.Context())
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve payment method", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve payment method")
 		return
 	}
 
@@ -54,6 +58,10 @@ func (c *paymentMethodController) GetAllPaymentMethod(ctx *gin.Context) {
 }
 
 func (c *paymentMethodController) GetPaymentMethodById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PaymentMethodController.GetPaymentMethodById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +80,7 @@ func (c *paymentMethodController) GetPaymentMethodById(ctx *gin.Context) {
 
 	paymentMethod, err := c.paymentMethodService.GetPaymentMethodById(ctx.Request.Context(), parsedPaymentMethodId)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve payment method", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve payment method")
 		return
 	}
 
@@ -82,6 +89,10 @@ func (c *paymentMethodController) GetPaymentMethodById(ctx *gin.Context) {
 }
 
 func (c *paymentMethodController) CreatePaymentMethod(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PaymentMethodController.CreatePaymentMethod")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +103,13 @@ func (c *paymentMethodController) CreatePaymentMethod(ctx *gin.Context) {
 
 	var req dtos.PaymentMethodRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	paymentMethod, err := c.paymentMethodService.CreatePaymentMethod(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save payment method", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save payment method")
 		return
 	}
 
@@ -109,6 +118,10 @@ func (c *paymentMethodController) CreatePaymentMethod(ctx *gin.Context) {
 }
 
 func (c *paymentMethodController) UpdatePaymentMethod(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PaymentMethodController.UpdatePaymentMethod")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +132,7 @@ func (c *paymentMethodController) UpdatePaymentMethod(ctx *gin.Context) {
 
 	var req dtos.PaymentMethodRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +144,9 @@ func (c *paymentMethodController) UpdatePaymentMethod(ctx *gin.Context) {
 		return
 	}
 
-	paymentMethod, err := c.paymentMethodService.UpdatePaymentMethod(ctx, parsedPaymentMethodId, req)
+	paymentMethod, err := c.paymentMethodService.UpdatePaymentMethod(ctx.Request.Context(), parsedPaymentMethodId, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update payment method", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update payment method")
 		return
 	}
 
@@ -144,6 +155,10 @@ func (c *paymentMethodController) UpdatePaymentMethod(ctx *gin.Context) {
 }
 
 func (c *paymentMethodController) DeletePaymentMethod(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PaymentMethodController.DeletePaymentMethod")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,9 +175,8 @@ func (c *paymentMethodController) DeletePaymentMethod(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.paymentMethodService.DeletePaymentMethod(ctx, parsedPaymentMethodId); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete payment method", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.paymentMethodService.DeletePaymentMethod(ctx.Request.Context(), parsedPaymentMethodId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete payment method")
 		return
 	}
 
@@ -3,6 +3,7 @@ package controllers
 import (
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
@@ -34,6 +35,10 @@ func NewPlatformController(
 }
 
 func (c *platformController) GetAllPlatform(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PlatformController.GetAllPlatform")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -44,8 +49,7 @@ func (c *platformController) GetAllPlatform(ctx *gin.Context) {
 
 	platforms, err := c.platformService.GetAllPlatform(ctx.Request.Context())
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve platform", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve platform")
 		return
 	}
 
@@ -54,6 +58,10 @@ func (c *platformController) GetAllPlatform(ctx *gin.Context) {
 }
 
 func (c *platformController) GetPlatformById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PlatformController.GetPlatformById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +80,7 @@ func (c *platformController) GetPlatformById(ctx *gin.Context) {
 
 	platform, err := c.platformService.GetPlatformById(ctx.Request.Context(), parsedPlatformId)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve platform", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve platform")
 		return
 	}
 
@@ -82,6 +89,10 @@ func (c *platformController) GetPlatformById(ctx *gin.Context) {
 }
 
 func (c *platformController) CreatePlatform(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PlatformController.CreatePlatform")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +103,13 @@ func (c *platformController) CreatePlatform(ctx *gin.Context) {
 
 	var req dtos.PlatformRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	platform, err := c.platformService.CreatePlatform(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save platform", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save platform")
 		return
 	}
 
@@ -109,6 +118,10 @@ func (c *platformController) CreatePlatform(ctx *gin.Context) {
 }
 
 func (c *platformController) UpdatePlatform(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PlatformController.UpdatePlatform")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +132,7 @@ func (c *platformController) UpdatePlatform(ctx *gin.Context) {
 
 	var req dtos.PlatformRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +144,9 @@ func (c *platformController) UpdatePlatform(ctx *gin.Context) {
 		return
 	}
 
-	platform, err := c.platformService.UpdatePlatform(ctx, parsedPlatformId, req)
+	platform, err := c.platformService.UpdatePlatform(ctx.Request.Context(), parsedPlatformId, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update platform", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update platform")
 		return
 	}
 
@@ -144,6 +155,10 @@ func (c *platformController) UpdatePlatform(ctx *gin.Context) {
 }
 
 func (c *platformController) DeletePlatform(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "PlatformController.DeletePlatform")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,9 +175,8 @@ func (c *platformController) DeletePlatform(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.platformService.DeletePlatform(ctx, parsedPlatformId); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete platform", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.platformService.DeletePlatform(ctx.Request.Context(), parsedPlatformId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete platform")
 		return
 	}
 
@@ -3,6 +3,7 @@ package controllers
 import (
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
@@ -34,6 +35,10 @@ func NewReceiverController(
 }
 
 func (c *receiverController) GetAllReceiver(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReceiverController.GetAllReceiver")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -42,10 +47,15 @@ func (c *receiverController) GetAllReceiver(ctx *gin.Context) {
 		return
 	}
 
-	receivers, err := c.receiverService.GetAllReceiver(ctx.Request.Context())
+	var query dtos.ReceiverQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	receivers, err := c.receiverService.GetAllReceiver(ctx.Request.Context(), query)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve receiver", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve receiver")
 		return
 	}
 
@@ -54,6 +64,10 @@ func (c *receiverController) GetAllReceiver(ctx *gin.Context) {
 }
 
 func (c *receiverController) GetReceiverById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReceiverController.GetReceiverById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +86,7 @@ func (c *receiverController) GetReceiverById(ctx *gin.Context) {
 
 	receiver, err := c.receiverService.GetReceiverById(ctx.Request.Context(), parsedReceiverId)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve receiver", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve receiver")
 		return
 	}
 
@@ -82,6 +95,10 @@ func (c *receiverController) GetReceiverById(ctx *gin.Context) {
 }
 
 func (c *receiverController) CreateReceiver(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReceiverController.CreateReceiver")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +109,13 @@ func (c *receiverController) CreateReceiver(ctx *gin.Context) {
 
 	var req dtos.CreateReceiverRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	receiver, err := c.receiverService.CreateReceiver(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save receiver", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save receiver")
 		return
 	}
 
@@ -109,6 +124,10 @@ func (c *receiverController) CreateReceiver(ctx *gin.Context) {
 }
 
 func (c *receiverController) UpdateReceiver(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReceiverController.UpdateReceiver")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +138,7 @@ func (c *receiverController) UpdateReceiver(ctx *gin.Context) {
 
 	var req dtos.UpdateReceiverRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +150,9 @@ func (c *receiverController) UpdateReceiver(ctx *gin.Context) {
 		return
 	}
 
-	receiver, err := c.receiverService.UpdateReceiver(ctx, parsedReceiverId, req)
+	receiver, err := c.receiverService.UpdateReceiver(ctx.Request.Context(), parsedReceiverId, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update receiver", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update receiver")
 		return
 	}
 
@@ -144,6 +161,10 @@ func (c *receiverController) UpdateReceiver(ctx *gin.Context) {
 }
 
 func (c *receiverController) DeleteReceiver(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReceiverController.DeleteReceiver")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,9 +181,8 @@ func (c *receiverController) DeleteReceiver(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.receiverService.DeleteReceiver(ctx, parsedReceiverId); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete receiver", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.receiverService.DeleteReceiver(ctx.Request.Context(), parsedReceiverId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete receiver")
 		return
 	}
 
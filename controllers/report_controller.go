@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportController interface {
+	GetProfitabilityReport(ctx *gin.Context)
+	GetRevenueReport(ctx *gin.Context)
+	GetARAgingReport(ctx *gin.Context)
+	GetARAgingInvoices(ctx *gin.Context)
+	GetDSOReport(ctx *gin.Context)
+	GetCashFlowReport(ctx *gin.Context)
+	GetSalesVatReport(ctx *gin.Context)
+	GetWithholdingTaxRegister(ctx *gin.Context)
+}
+
+type reportController struct {
+	tokenService  services.TokenService
+	reportService services.ReportService
+}
+
+func NewReportController(
+	tokenService services.TokenService,
+	reportService services.ReportService,
+) ReportController {
+	return &reportController{
+		tokenService:  tokenService,
+		reportService: reportService,
+	}
+}
+
+func (c *reportController) GetProfitabilityReport(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReportController.GetProfitabilityReport")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.ProfitabilityQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	report, err := c.reportService.GetProfitabilityReport(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve profitability report")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved profitability report", report)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *reportController) GetRevenueReport(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReportController.GetRevenueReport")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.RevenueQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	report, err := c.reportService.GetRevenueReport(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve revenue report")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved revenue report", report)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *reportController) GetARAgingReport(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReportController.GetARAgingReport")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.ARAgingQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	report, err := c.reportService.GetARAgingReport(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve aging report")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved aging report", report)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *reportController) GetARAgingInvoices(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReportController.GetARAgingInvoices")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.ARAgingInvoiceQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	invoices, err := c.reportService.GetARAgingInvoices(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve aging invoices")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved aging invoices", invoices)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *reportController) GetDSOReport(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReportController.GetDSOReport")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.DSOQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	report, err := c.reportService.GetDSOReport(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve days sales outstanding")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved days sales outstanding", report)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *reportController) GetCashFlowReport(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReportController.GetCashFlowReport")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.CashFlowQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	report, err := c.reportService.GetCashFlowReport(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve cash flow forecast")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved cash flow forecast", report)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *reportController) GetSalesVatReport(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReportController.GetSalesVatReport")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.TaxReportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	report, err := c.reportService.GetSalesVatReport(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve sales VAT report")
+		return
+	}
+
+	if query.Format != "" && query.Format != "json" {
+		writeExport(ctx, query.Format, taxReportFilename("sales-vat", report.Month, report.Receiver), salesVatTable(report))
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved sales VAT report", report)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *reportController) GetWithholdingTaxRegister(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "ReportController.GetWithholdingTaxRegister")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.TaxReportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	register, err := c.reportService.GetWithholdingTaxRegister(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve withholding tax register")
+		return
+	}
+
+	if query.Format != "" && query.Format != "json" {
+		writeExport(ctx, query.Format, taxReportFilename("withholding-tax", register.Month, register.Receiver), withholdingTaxTable(register))
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved withholding tax register", register)
+	ctx.JSON(http.StatusOK, res)
+}
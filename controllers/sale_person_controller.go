@@ -3,6 +3,7 @@ package controllers
 import (
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
@@ -34,6 +35,10 @@ func NewSalePersonController(
 }
 
 func (c *salePersonController) GetAllSalePerson(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "SalePersonController.GetAllSalePerson")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -44,8 +49,7 @@ func (c *salePersonController) GetAllSalePerson(ctx *gin.Context) {
 
 	salePeople, err := c.salePersonService.GetAllSalePerson(ctx.Request.Context())
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve sale person", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve sale person")
 		return
 	}
 
@@ -54,6 +58,10 @@ func (c *salePersonController) GetAllSalePerson(ctx *gin.Context) {
 }
 
 func (c *salePersonController) GetSalePersonById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "SalePersonController.GetSalePersonById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +80,7 @@ func (c *salePersonController) GetSalePersonById(ctx *gin.Context) {
 
 	salePerson, err := c.salePersonService.GetSalePersonById(ctx.Request.Context(), parsedSalePersonId)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve sale person", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve sale person")
 		return
 	}
 
@@ -82,6 +89,10 @@ func (c *salePersonController) GetSalePersonById(ctx *gin.Context) {
 }
 
 func (c *salePersonController) CreateSalePerson(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "SalePersonController.CreateSalePerson")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +103,13 @@ func (c *salePersonController) CreateSalePerson(ctx *gin.Context) {
 
 	var req dtos.SalePersonRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	salePerson, err := c.salePersonService.CreateSalePerson(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save sale person", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save sale person")
 		return
 	}
 
@@ -109,6 +118,10 @@ func (c *salePersonController) CreateSalePerson(ctx *gin.Context) {
 }
 
 func (c *salePersonController) UpdateSalePerson(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "SalePersonController.UpdateSalePerson")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +132,7 @@ func (c *salePersonController) UpdateSalePerson(ctx *gin.Context) {
 
 	var req dtos.SalePersonRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +144,9 @@ func (c *salePersonController) UpdateSalePerson(ctx *gin.Context) {
 		return
 	}
 
-	salePerson, err := c.salePersonService.UpdateSalePerson(ctx, parsedSalePersonId, req)
+	salePerson, err := c.salePersonService.UpdateSalePerson(ctx.Request.Context(), parsedSalePersonId, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update sale person", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update sale person")
 		return
 	}
 
@@ -144,6 +155,10 @@ func (c *salePersonController) UpdateSalePerson(ctx *gin.Context) {
 }
 
 func (c *salePersonController) DeleteSalePerson(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "SalePersonController.DeleteSalePerson")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,9 +175,8 @@ func (c *salePersonController) DeleteSalePerson(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.salePersonService.DeleteSalePerson(ctx, parsedSalePersonId); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete sale person", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.salePersonService.DeleteSalePerson(ctx.Request.Context(), parsedSalePersonId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete sale person")
 		return
 	}
 
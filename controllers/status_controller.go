@@ -3,6 +3,7 @@ package controllers
 import (
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 	"net/http"
 	"strconv"
@@ -34,6 +35,10 @@ func NewStatusController(
 }
 
 func (c *statusController) GetAllStatus(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "StatusController.GetAllStatus")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -44,8 +49,7 @@ func (c *statusController) GetAllStatus(ctx *gin.Context) {
 
 	statuses, err := c.statusService.GetAllStatus(ctx.Request.Context())
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve status", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve status")
 		return
 	}
 
@@ -54,6 +58,10 @@ func (c *statusController) GetAllStatus(ctx *gin.Context) {
 }
 
 func (c *statusController) GetStatusById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "StatusController.GetStatusById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -72,8 +80,7 @@ func (c *statusController) GetStatusById(ctx *gin.Context) {
 
 	status, err := c.statusService.GetStatusById(ctx.Request.Context(), parsedStatusId)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to retrieve status", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to retrieve status")
 		return
 	}
 
@@ -82,6 +89,10 @@ func (c *statusController) GetStatusById(ctx *gin.Context) {
 }
 
 func (c *statusController) CreateStatus(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "StatusController.CreateStatus")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -92,15 +103,13 @@ func (c *statusController) CreateStatus(ctx *gin.Context) {
 
 	var req dtos.StatusRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
 	status, err := c.statusService.CreateStatus(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to save status", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to save status")
 		return
 	}
 
@@ -109,6 +118,10 @@ func (c *statusController) CreateStatus(ctx *gin.Context) {
 }
 
 func (c *statusController) UpdateStatus(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "StatusController.UpdateStatus")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -119,8 +132,7 @@ func (c *statusController) UpdateStatus(ctx *gin.Context) {
 
 	var req dtos.StatusRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		response := utils.BuildResponseFailed("Failed to retrieve request", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(err).SetMeta("Failed to retrieve request")
 		return
 	}
 
@@ -132,10 +144,9 @@ func (c *statusController) UpdateStatus(ctx *gin.Context) {
 		return
 	}
 
-	status, err := c.statusService.UpdateStatus(ctx, parsedStatusId, req)
+	status, err := c.statusService.UpdateStatus(ctx.Request.Context(), parsedStatusId, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("Failed to update status", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.Error(err).SetMeta("Failed to update status")
 		return
 	}
 
@@ -144,6 +155,10 @@ func (c *statusController) UpdateStatus(ctx *gin.Context) {
 }
 
 func (c *statusController) DeleteStatus(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "StatusController.DeleteStatus")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.MustGet("token").(string)
 	_, err := c.tokenService.GetUserIdByToken(token)
 	if err != nil {
@@ -160,9 +175,8 @@ func (c *statusController) DeleteStatus(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.statusService.DeleteStatus(ctx, parsedStatusId); err != nil {
-		res := utils.BuildResponseFailed("Failed to delete status", err.Error(), utils.EmptyObj{})
-		ctx.JSON(http.StatusInternalServerError, res)
+	if err := c.statusService.DeleteStatus(ctx.Request.Context(), parsedStatusId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete status")
 		return
 	}
 
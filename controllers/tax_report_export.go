@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/exports"
+	"strconv"
+	"strings"
+)
+
+func salesVatTable(report dtos.SalesVatReport) exports.Table {
+	table := exports.Table{
+		Title:  "Sales VAT report (รายงานภาษีขาย)",
+		Header: taxReportHeader(report.Month, report.Receiver, report.Currency, report.UnconvertedInvoices),
+		Columns: []exports.Column{
+			{Title: "No.", Width: 5},
+			{Title: "Date", Width: 11},
+			{Title: "Invoice no.", Width: 10},
+			{Title: "Receipt no.", Width: 10},
+			{Title: "Customer", Width: 30},
+			{Title: "Tax ID", Width: 15},
+			{Title: "Branch", Width: 12},
+			{Title: "Vatable amount", Width: 14},
+			{Title: "VAT exempt", Width: 14},
+			{Title: "VAT", Width: 12},
+			{Title: "Total", Width: 14},
+		},
+		Rows: [][]any{},
+		Footer: []any{
+			"Total", nil, nil, nil, nil, nil, nil,
+			report.Total.VatableAmount, report.Total.VatExemptAmount, report.Total.VatAmount, report.Total.Total,
+		},
+	}
+	for _, l := range report.Lines {
+		table.Rows = append(table.Rows, []any{
+			l.Sequence, l.Date, l.InvoiceIdNumber, l.ReceiptIdNumber,
+			l.CustomerName, l.CustomerTaxId.String(), branchLabel(l.CustomerBranch),
+			l.VatableAmount, l.VatExemptAmount, l.VatAmount, l.Total,
+		})
+	}
+	return table
+}
+
+func withholdingTaxTable(register dtos.WithholdingTaxRegister) exports.Table {
+	table := exports.Table{
+		Title:  "Withholding tax certificates received (50 ทวิ)",
+		Header: taxReportHeader(register.Month, register.Receiver, register.Currency, register.UnconvertedInvoices),
+		Columns: []exports.Column{
+			{Title: "No.", Width: 5},
+			{Title: "Certificate no.", Width: 14},
+			{Title: "Certificate date", Width: 11},
+			{Title: "Payer", Width: 30},
+			{Title: "Tax ID", Width: 15},
+			{Title: "Branch", Width: 12},
+			{Title: "Invoice no.", Width: 10},
+			{Title: "Receipt date", Width: 11},
+			{Title: "Rate %", Width: 7},
+			{Title: "Base amount", Width: 14},
+			{Title: "Tax withheld", Width: 12},
+		},
+		Rows: [][]any{},
+		Footer: []any{
+			"Total", nil, nil, nil, nil, nil, nil, nil, nil,
+			register.Total.BaseAmount, register.Total.TaxAmount,
+		},
+	}
+	for _, l := range register.Lines {
+		certificate := l.CertificateNumber
+		if !l.Received {
+			certificate = "Not received"
+		}
+		table.Rows = append(table.Rows, []any{
+			l.Sequence, certificate, l.CertificateDate,
+			l.PayerName, l.PayerTaxId.String(), branchLabel(l.PayerBranch),
+			l.InvoiceIdNumber, l.ReceiptIssueDate, l.WithholdingTaxRate,
+			l.BaseAmount, l.TaxAmount,
+		})
+	}
+	return table
+}
+
+// taxReportHeader describes the month and the company a tax report is for,
+// and warns about the invoices left out for want of an exchange rate.
+func taxReportHeader(month string, receiver *dtos.Receiver, currency string, unconverted []int) []string {
+	header := []string{"Tax month: " + month}
+	if receiver != nil {
+		header = append(header,
+			receiver.Name,
+			fmt.Sprintf("Tax ID: %s  %s", receiver.TaxPayerId, branchLabel(receiver.BranchNumber)),
+		)
+	}
+	header = append(header, "Amounts in "+currency)
+	if len(unconverted) > 0 {
+		numbers := make([]string, len(unconverted))
+		for i, n := range unconverted {
+			numbers[i] = strconv.Itoa(n)
+		}
+		header = append(header, "Left out, no exchange rate: invoices "+strings.Join(numbers, ", "))
+	}
+	return header
+}
+
+// taxReportFilename names an export after the report, month and receiver.
+func taxReportFilename(report, month string, receiver *dtos.Receiver) string {
+	if receiver == nil {
+		return fmt.Sprintf("%s-%s", report, month)
+	}
+	return fmt.Sprintf("%s-%s-receiver-%d", report, month, receiver.Id)
+}
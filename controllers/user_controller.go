@@ -77,12 +77,12 @@
 package controllers
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
 	"mtii-backend/dtos"
 	"mtii-backend/services"
+	"mtii-backend/telemetry"
 	"mtii-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -117,25 +117,22 @@ func NewUserController(
 /* ────────────────────────────────────────────────────────── */
 
 func (c *userController) LoginUser(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "UserController.LoginUser")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	var req dtos.LoginRequest
 
 	// 1) Bind ONLY JSON
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest,
-			utils.BuildResponseFailed("Bad JSON", err.Error(), utils.EmptyObj{}))
+		ctx.Error(err).SetMeta("Bad JSON")
 		return
 	}
 
-	// 2) DEBUG: print exactly what arrived
-	fmt.Printf("LOGIN DEBUG %#v\n", req)
-	//     Example output:
-	//     LOGIN DEBUG dtos.LoginRequest{Username:"user1", Password:"password "}
-
-	// 3) Verify credentials
-	res, err := c.userService.VerifyCredential(ctx, req)
+	// 2) Verify credentials
+	res, err := c.userService.VerifyCredential(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusForbidden,
-			utils.BuildResponseFailed("nvalid credentials", err.Error(), utils.EmptyObj{}))
+		ctx.Error(err).SetMeta("Invalid credentials")
 		return
 	}
 
@@ -147,6 +144,10 @@ func (c *userController) LoginUser(ctx *gin.Context) {
 /* ────────────────────────────────────────────────────────── */
 
 func (c *userController) LogoutUser(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "UserController.LogoutUser")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
 	token := ctx.GetHeader("Authorization")
 	if token == "" {
 		ctx.JSON(http.StatusBadRequest,
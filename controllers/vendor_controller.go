@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/services"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VendorController interface {
+	GetAllVendor(ctx *gin.Context)
+	GetVendorById(ctx *gin.Context)
+	CreateVendor(ctx *gin.Context)
+	UpdateVendor(ctx *gin.Context)
+	DeleteVendor(ctx *gin.Context)
+}
+
+type vendorController struct {
+	tokenService  services.TokenService
+	vendorService services.VendorService
+}
+
+func NewVendorController(
+	tokenService services.TokenService,
+	vendorService services.VendorService,
+) VendorController {
+	return &vendorController{
+		tokenService:  tokenService,
+		vendorService: vendorService,
+	}
+}
+
+func (c *vendorController) GetAllVendor(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "VendorController.GetAllVendor")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var query dtos.VendorQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	vendors, err := c.vendorService.GetAllVendor(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve vendor")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved vendor", vendors)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *vendorController) GetVendorById(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "VendorController.GetVendorById")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	vendorId := ctx.Param("vendor_id")
+	parsedVendorId, err := strconv.Atoi(vendorId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Vendor Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	vendor, err := c.vendorService.GetVendorById(ctx.Request.Context(), parsedVendorId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve vendor")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Successfully retrieved vendor", vendor)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *vendorController) CreateVendor(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "VendorController.CreateVendor")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.CreateVendorRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	vendor, err := c.vendorService.CreateVendor(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to save vendor")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Data vendor successfully saved", vendor)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *vendorController) UpdateVendor(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "VendorController.UpdateVendor")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	var req dtos.UpdateVendorRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err).SetMeta("Failed to retrieve request")
+		return
+	}
+
+	vendorId := ctx.Param("vendor_id")
+	parsedVendorId, err := strconv.Atoi(vendorId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Vendor Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	vendor, err := c.vendorService.UpdateVendor(ctx.Request.Context(), parsedVendorId, req)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to update vendor")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Vendor successfully updated", vendor)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *vendorController) DeleteVendor(ctx *gin.Context) {
+	spanCtx, span := telemetry.Start(ctx.Request.Context(), "VendorController.DeleteVendor")
+	defer span.End()
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	token := ctx.MustGet("token").(string)
+	_, err := c.tokenService.GetUserIdByToken(token)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Invalid token", utils.EmptyObj{})
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	vendorId := ctx.Param("vendor_id")
+	parsedVendorId, err := strconv.Atoi(vendorId)
+	if err != nil {
+		res := utils.BuildResponseFailed("Failed to process the request", "Vendor Id tidak valid", utils.EmptyObj{})
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.vendorService.DeleteVendor(ctx.Request.Context(), parsedVendorId); err != nil {
+		ctx.Error(err).SetMeta("Failed to delete vendor")
+		return
+	}
+
+	res := utils.BuildResponseSuccess("Vendor successfully deleted", utils.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
+}
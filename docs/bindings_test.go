@@ -0,0 +1,216 @@
+package docs_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"mtii-backend/docs"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// binding is what a controller method binds: the DTO read from the body
+// and the DTO read from the query string, by type name.
+type binding struct {
+	body, query string
+}
+
+// TestOperationsMatchControllers reads the DTOs each controller method
+// binds from its source and fails when the document describes another
+// request body or other query parameters for its route.
+func TestOperationsMatchControllers(t *testing.T) {
+	routes := registeredRoutes(t)
+	doc, err := docs.Build(routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bindings := controllerBindings(t)
+	forms := dtoFormFields(t)
+
+	for _, route := range routes {
+		handler, ok := strings.CutPrefix(strings.TrimSuffix(route.Handler, "-fm"), "mtii-backend/controllers.")
+		if !ok {
+			continue
+		}
+		controller, method, _ := strings.Cut(handler, ".")
+		b, ok := bindings[lowerFirst(controller)+"."+method]
+		if !ok {
+			t.Errorf("%s %s: no method %s in controllers", route.Method, route.Path, handler)
+			continue
+		}
+		item := doc.Paths[docPath(route.Path)][strings.ToLower(route.Method)]
+
+		documented := ""
+		if item.RequestBody != nil {
+			for _, media := range item.RequestBody.Content {
+				documented = path.Base(media.Schema.Ref)
+			}
+		}
+		if documented != b.body {
+			t.Errorf("%s %s documents body %q, %s binds %q", route.Method, route.Path, documented, handler, b.body)
+		}
+
+		if b.query == "" {
+			continue
+		}
+		var params, fields []string
+		for _, p := range item.Parameters {
+			if p.In == "query" {
+				params = append(params, p.Name)
+			}
+		}
+		for _, f := range forms[b.query] {
+			if !strings.Contains(route.Path, ":"+f) {
+				fields = append(fields, f)
+			}
+		}
+		slices.Sort(params)
+		slices.Sort(fields)
+		if !slices.Equal(params, fields) {
+			t.Errorf("%s %s documents query %v, %s binds %s with %v", route.Method, route.Path, params, handler, b.query, fields)
+		}
+	}
+}
+
+// controllerBindings maps "receiver.Method" of every controller method to
+// the dtos types it passes to ctx.ShouldBind and ctx.ShouldBindQuery.
+func controllerBindings(t *testing.T) map[string]binding {
+	t.Helper()
+	bindings := map[string]binding{}
+	for _, file := range parseDir(t, "../controllers") {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Body == nil {
+				continue
+			}
+			receiver := fn.Recv.List[0].Type
+			if star, ok := receiver.(*ast.StarExpr); ok {
+				receiver = star.X
+			}
+			ident, ok := receiver.(*ast.Ident)
+			if !ok {
+				continue
+			}
+
+			types := map[string]string{}
+			var b binding
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.ValueSpec:
+					if sel, ok := n.Type.(*ast.SelectorExpr); ok && isIdent(sel.X, "dtos") {
+						for _, name := range n.Names {
+							types[name.Name] = sel.Sel.Name
+						}
+					}
+				case *ast.CallExpr:
+					sel, ok := n.Fun.(*ast.SelectorExpr)
+					if !ok || !strings.HasPrefix(sel.Sel.Name, "ShouldBind") || len(n.Args) != 1 {
+						break
+					}
+					arg, ok := n.Args[0].(*ast.UnaryExpr)
+					if !ok {
+						break
+					}
+					if v, ok := arg.X.(*ast.Ident); ok {
+						if sel.Sel.Name == "ShouldBindQuery" {
+							b.query = types[v.Name]
+						} else {
+							b.body = types[v.Name]
+						}
+					}
+				}
+				return true
+			})
+			bindings[ident.Name+"."+fn.Name.Name] = b
+		}
+	}
+	return bindings
+}
+
+// dtoFormFields maps every struct in dtos to the form names of its fields,
+// following embedded structs.
+func dtoFormFields(t *testing.T) map[string][]string {
+	t.Helper()
+	structs := map[string]*ast.StructType{}
+	for _, file := range parseDir(t, "../dtos") {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					structs[spec.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+
+	var fieldsOf func(name string) []string
+	fieldsOf = func(name string) []string {
+		var fields []string
+		for _, field := range structs[name].Fields.List {
+			if len(field.Names) == 0 {
+				if ident, ok := field.Type.(*ast.Ident); ok {
+					fields = append(fields, fieldsOf(ident.Name)...)
+				}
+				continue
+			}
+			if field.Tag == nil {
+				continue
+			}
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			if form := reflect.StructTag(tag).Get("form"); form != "" && form != "-" {
+				fields = append(fields, form)
+			}
+		}
+		return fields
+	}
+
+	forms := map[string][]string{}
+	for name := range structs {
+		forms[name] = fieldsOf(name)
+	}
+	return forms
+}
+
+func parseDir(t *testing.T, dir string) []*ast.File {
+	t.Helper()
+	packages, err := parser.ParseDir(token.NewFileSet(), dir, func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []*ast.File
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func docPath(ginPath string) string {
+	parts := strings.Split(ginPath, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func isIdent(e ast.Expr, name string) bool {
+	ident, ok := e.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
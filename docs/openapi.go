@@ -0,0 +1,371 @@
+package docs
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"mtii-backend/utils"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operation documents one route. Request, Query and Response are zero
+// values of the DTOs bound from the body, bound from the query string and
+// returned in the envelope's data field. Params documents parameters no DTO
+// describes.
+type Operation struct {
+	Tag         string
+	Summary     string
+	Public      bool
+	Params      []Parameter
+	Request     any
+	Query       any
+	Response    any
+	Status      int
+	ContentType string
+
+	// RequestContentType is the media type of Request, JSON by default.
+	RequestContentType string
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+func QueryParam(name, schemaType, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: schemaType}}
+}
+
+func PathParam(name, schemaType, description string) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Schema: &Schema{Type: schemaType}}
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*PathItem `json:"paths"`
+	Components Components                      `json:"components"`
+	Security   []map[string][]string           `json:"security,omitempty"`
+	Tags       []map[string]string             `json:"tags,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]map[string]any `json:"securitySchemes"`
+}
+
+type PathItem struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationId string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *Body                 `json:"requestBody,omitempty"`
+	Responses   map[string]*Body      `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Body struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+var pathParam = regexp.MustCompile(`[:*](\w+)`)
+
+// Verify reports every registered route that has no entry in operations.
+func Verify(routes gin.RoutesInfo) error {
+	var missing []string
+	for _, route := range routes {
+		if _, ok := operations[routeKey(route.Method, route.Path)]; !ok {
+			missing = append(missing, routeKey(route.Method, route.Path))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes missing from the OpenAPI spec: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Build generates the OpenAPI 3 document for the registered routes.
+func Build(routes gin.RoutesInfo) (*Document, error) {
+	if err := Verify(routes); err != nil {
+		return nil, err
+	}
+
+	builder := newSchemaBuilder()
+	errorSchema := envelope(builder, reflect.TypeOf(utils.ErrorDetail{}), "error")
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: "MTII Backend API", Version: "1.0.0"},
+		Paths:   map[string]map[string]*PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]map[string]any{
+				"bearerAuth": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+	}
+
+	tags := map[string]bool{}
+	for _, route := range routes {
+		op := operations[routeKey(route.Method, route.Path)]
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*PathItem{}
+		}
+
+		item := &PathItem{
+			Summary:     op.Summary,
+			OperationId: operationId(route.Method, route.Path),
+			Parameters:  parameters(route.Path, append(queryParams(builder, op.Query, route.Path), op.Params...)),
+			Responses:   map[string]*Body{},
+			Security:    []map[string][]string{{"bearerAuth": {}}},
+		}
+		if op.Tag != "" {
+			item.Tags = []string{op.Tag}
+			tags[op.Tag] = true
+		}
+		if op.Public {
+			item.Security = []map[string][]string{}
+		}
+
+		if op.Request != nil {
+			requestType := op.RequestContentType
+			if requestType == "" {
+				requestType = gin.MIMEJSON
+			}
+			item.RequestBody = &Body{
+				Required: true,
+				Content: map[string]*MediaType{
+					requestType: {Schema: builder.schemaOf(reflect.TypeOf(op.Request))},
+				},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		item.Responses[strconv.Itoa(status)] = successBody(builder, op)
+
+		errorBody := func(description string) *Body {
+			return &Body{Description: description, Content: map[string]*MediaType{gin.MIMEJSON: {Schema: errorSchema}}}
+		}
+		if op.Request != nil || len(item.Parameters) > 0 {
+			item.Responses["400"] = errorBody("Malformed request")
+			item.Responses["422"] = errorBody("Validation failed")
+		}
+		if !op.Public {
+			item.Responses["401"] = errorBody("Missing or invalid token")
+		}
+		if pathParam.MatchString(route.Path) {
+			item.Responses["404"] = errorBody("Resource not found")
+		}
+		if route.Method == http.MethodPost || route.Method == http.MethodPatch || route.Method == http.MethodPut {
+			item.Responses["409"] = errorBody("Conflicts with an existing resource")
+		}
+		item.Responses["500"] = errorBody("Unexpected server error")
+
+		doc.Paths[path][strings.ToLower(route.Method)] = item
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, map[string]string{"name": tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i]["name"] < doc.Tags[j]["name"] })
+
+	doc.Components.Schemas = builder.components
+	return doc, nil
+}
+
+// SpecHandler serves the document for every route registered on engine. It
+// is built on the first request, after all routes have been added.
+func SpecHandler(engine *gin.Engine) gin.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *Document
+		err  error
+	)
+	return func(ctx *gin.Context) {
+		once.Do(func() {
+			doc, err = Build(engine.Routes())
+		})
+		if err != nil {
+			ctx.Error(err).SetMeta("Failed to build the API specification")
+			return
+		}
+		ctx.JSON(http.StatusOK, doc)
+	}
+}
+
+//go:generate go run gen_swagger_ui.go
+
+var (
+	//go:embed swagger.html
+	swaggerPage []byte
+
+	//go:embed swagger_missing.html
+	swaggerMissingPage []byte
+
+	//go:embed swagger-ui
+	swaggerUI embed.FS
+)
+
+// UIHandler serves Swagger UI pointed at /api/openapi.json, or a page
+// explaining how to bundle it when the build does not carry its files.
+func UIHandler(ctx *gin.Context) {
+	if _, err := fs.Stat(swaggerUI, "swagger-ui/swagger-ui-bundle.js"); err != nil {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", swaggerMissingPage)
+		return
+	}
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", swaggerPage)
+}
+
+// AssetHandler serves the Swagger UI files embedded in the binary.
+func AssetHandler(ctx *gin.Context) {
+	assets, _ := fs.Sub(swaggerUI, "swagger-ui")
+	ctx.FileFromFS(ctx.Param("file"), http.FS(assets))
+}
+
+func successBody(builder *schemaBuilder, op Operation) *Body {
+	if op.ContentType != "" {
+		return &Body{
+			Description: "Successful response",
+			Content: map[string]*MediaType{
+				op.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}},
+			},
+		}
+	}
+
+	var data reflect.Type
+	if op.Response != nil {
+		data = reflect.TypeOf(op.Response)
+	}
+	return &Body{
+		Description: "Successful response",
+		Content: map[string]*MediaType{
+			gin.MIMEJSON: {Schema: envelope(builder, data, "data")},
+		},
+	}
+}
+
+// envelope describes utils.Response with field narrowed to the given type.
+func envelope(builder *schemaBuilder, t reflect.Type, field string) *Schema {
+	base := builder.schemaOf(reflect.TypeOf(utils.Response{}))
+	if t == nil {
+		return base
+	}
+	return &Schema{AllOf: []*Schema{
+		base,
+		{Type: "object", Properties: map[string]*Schema{field: builder.schemaOf(t)}},
+	}}
+}
+
+func parameters(path string, extra []Parameter) []Parameter {
+	var params []Parameter
+	overrides := map[string]Parameter{}
+	for _, p := range extra {
+		if p.In == "path" {
+			overrides[p.Name] = p
+			continue
+		}
+		params = append(params, p)
+	}
+
+	var pathParams []Parameter
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		p, ok := overrides[match[1]]
+		if !ok {
+			p = Parameter{Name: match[1], In: "path", Schema: &Schema{Type: "integer"}}
+		}
+		p.Required = true
+		pathParams = append(pathParams, p)
+	}
+	return append(pathParams, params...)
+}
+
+// queryParams describes the fields of query, gin binds by their form
+// tag, leaving out those the path already carries.
+func queryParams(builder *schemaBuilder, query any, path string) []Parameter {
+	if query == nil {
+		return nil
+	}
+	inPath := map[string]bool{}
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		inPath[match[1]] = true
+	}
+
+	var params []Parameter
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				add(field.Type)
+				continue
+			}
+			name := field.Tag.Get("form")
+			if name == "" || name == "-" || inPath[name] {
+				continue
+			}
+
+			schema := builder.schemaOf(field.Type)
+			switch field.Tag.Get("time_format") {
+			case "":
+			case "2006-01-02":
+				schema = &Schema{Type: "string", Format: "date"}
+			case "2006-01":
+				schema = &Schema{Type: "string", Pattern: `^[0-9]{4}-[0-9]{2}$`}
+			default:
+				schema = &Schema{Type: "string", Description: "Formatted as " + field.Tag.Get("time_format")}
+			}
+			required := applyBinding(schema, field.Tag.Get("binding"))
+			params = append(params, Parameter{
+				Name:        name,
+				In:          "query",
+				Description: field.Tag.Get("doc"),
+				Required:    required,
+				Schema:      schema,
+			})
+		}
+	}
+	add(reflect.TypeOf(query))
+	return params
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+func operationId(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '_' || r == ':' || r == '*' || r == '.' || r == '-' }) {
+		if part == "api" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
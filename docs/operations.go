@@ -0,0 +1,319 @@
+package docs
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/utils"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// operations documents every route registered in routes.Router, keyed by
+// "METHOD path" exactly as gin reports it. TestEveryRouteIsDocumented fails
+// when a route has no entry here, and TestOperationsMatchControllers when an
+// entry's Request or Query is not the DTO its controller binds.
+var operations = merge(
+	map[string]Operation{
+		"GET /api/openapi.json": {
+			Tag: "Operations", Summary: "OpenAPI specification", Public: true,
+			ContentType: gin.MIMEJSON,
+		},
+		"GET /api/docs": {
+			Tag: "Operations", Summary: "Interactive API documentation", Public: true,
+			ContentType: "text/html",
+		},
+		"GET /api/docs/swagger-ui/*file": {
+			Tag: "Operations", Summary: "Swagger UI files used by the documentation page", Public: true,
+			ContentType: "application/octet-stream",
+			Params:      []Parameter{PathParam("file", "string", "File name, such as swagger-ui.css")},
+		},
+		"POST /api/user/login": {
+			Tag: "User", Summary: "Log in and receive a bearer token", Public: true,
+			Request: dtos.LoginRequest{}, Response: dtos.LoginResponse{},
+		},
+		"POST /api/user/logout": {
+			Tag: "User", Summary: "Invalidate the current token",
+			Response: utils.EmptyObj{},
+		},
+	},
+	crud("/api/platform/", "platform_id", "Platform",
+		dtos.Platform{}, dtos.PlatformRequest{}, dtos.PlatformRequest{}, dtos.PlatformResponse{}),
+	crud("/api/status/", "status_id", "Status",
+		dtos.Status{}, dtos.StatusRequest{}, dtos.StatusRequest{}, dtos.StatusResponse{}),
+	crud("/api/payment_method/", "payment_method_id", "Payment method",
+		dtos.PaymentMethod{}, dtos.PaymentMethodRequest{}, dtos.PaymentMethodRequest{}, dtos.PaymentMethodResponse{}),
+	crud("/api/sale_person/", "sale_person_id", "Sale person",
+		dtos.SalePerson{}, dtos.SalePersonRequest{}, dtos.SalePersonRequest{}, dtos.SalePersonResponse{}),
+	crud("/api/commission_plan/", "commission_plan_id", "Commission plan",
+		dtos.CommissionPlan{}, dtos.CreateCommissionPlanRequest{}, dtos.UpdateCommissionPlanRequest{}, dtos.CommissionPlanResponse{}),
+	crud("/api/channel/", "channel_id", "Channel",
+		dtos.Channel{}, dtos.ChannelRequest{}, dtos.ChannelRequest{}, dtos.ChannelResponse{}),
+	crud("/api/bank/", "bank_id", "Bank",
+		dtos.Bank{}, dtos.BankRequest{}, dtos.BankRequest{}, dtos.BankResponse{}),
+	crud("/api/receiver/", "receiver_id", "Receiver",
+		dtos.Receiver{}, dtos.CreateReceiverRequest{}, dtos.UpdateReceiverRequest{}, dtos.ReceiverResponse{}),
+	crud("/api/income/", "income_invoice_id_number", "Income",
+		dtos.Income{}, dtos.CreateIncomeRequest{}, dtos.UpdateIncomeRequest{}, dtos.Income{}),
+	crud("/api/detail/", "detail_id", "Detail",
+		dtos.Detail{}, dtos.CreateDetailRequest{}, dtos.UpdateDetailRequest{}, dtos.DetailResponse{}),
+	crud("/api/currency_rate/", "currency_rate_id", "Currency rate",
+		dtos.CurrencyRate{}, dtos.CreateCurrencyRateRequest{}, dtos.UpdateCurrencyRateRequest{}, dtos.CurrencyRateResponse{}),
+	crud("/api/agency/", "agency_id", "Agency",
+		dtos.Agency{}, dtos.CreateAgencyRequest{}, dtos.UpdateAgencyRequest{}, dtos.AgencyResponse{}),
+	crud("/api/contact/", "contact_id", "Contact",
+		dtos.Contact{}, dtos.CreateContactRequest{}, dtos.UpdateContactRequest{}, dtos.ContactResponse{}),
+	crud("/api/brand/", "brand_id", "Brand",
+		dtos.Brand{}, dtos.CreateBrandRequest{}, dtos.UpdateBrandRequest{}, dtos.BrandResponse{}),
+	crud("/api/influencer/", "influencer_id", "Influencer",
+		dtos.Influencer{}, dtos.CreateInfluencerRequest{}, dtos.UpdateInfluencerRequest{}, dtos.InfluencerResponse{}),
+	crud("/api/influencer_assignment/", "influencer_assignment_id", "Influencer assignment",
+		dtos.InfluencerAssignment{}, dtos.CreateInfluencerAssignmentRequest{}, dtos.UpdateInfluencerAssignmentRequest{}, dtos.InfluencerAssignmentResponse{}),
+	crud("/api/vendor/", "vendor_id", "Vendor",
+		dtos.Vendor{}, dtos.CreateVendorRequest{}, dtos.UpdateVendorRequest{}, dtos.VendorResponse{}),
+	crud("/api/expense/", "expense_id", "Expense",
+		dtos.Expense{}, dtos.CreateExpenseRequest{}, dtos.UpdateExpenseRequest{}, dtos.ExpenseResponse{}),
+	map[string]Operation{
+		"GET /api/income/": {
+			Tag: "Income", Summary: "List Income", Response: []dtos.Income{},
+			Query: dtos.IncomeQuery{},
+		},
+		"GET /api/income/export": {
+			Tag: "Income", Summary: "Download incomes as CSV or XLSX, streamed from the database",
+			ContentType: "application/octet-stream",
+			Query:       dtos.IncomeExportQuery{},
+		},
+		"POST /api/income/:income_invoice_id_number/convert-to-invoice": {
+			Tag: "Income", Summary: "Convert a quotation to an invoice, dating it and opening its balance",
+			Request: dtos.ConvertToInvoiceRequest{}, Response: dtos.Income{},
+		},
+		"POST /api/income/:income_invoice_id_number/issue-receipt": {
+			Tag: "Income", Summary: "Issue the receipt of an invoice paid in full, numbering and dating it",
+			Request: dtos.IssueReceiptRequest{}, Response: dtos.Income{},
+		},
+		"GET /api/income/:income_invoice_id_number/promptpay": {
+			Tag: "Income", Summary: "Get the PromptPay QR payload paying the income's outstanding balance, less any withholding tax, to its receiver",
+			Response: dtos.PromptPay{},
+		},
+		"GET /api/income/:income_invoice_id_number/promptpay.png": {
+			Tag: "Income", Summary: "Get the PromptPay QR code paying the income's outstanding balance, less any withholding tax, as a PNG image",
+			ContentType: "image/png",
+			Query:       dtos.PromptPayQRQuery{},
+		},
+		"GET /api/income/:income_invoice_id_number/etax.xml": {
+			Tag: "Income", Summary: "Get the income as an ETDA e-Tax invoice XML document, optionally signed",
+			ContentType: "application/xml",
+			Query:       dtos.ETaxInvoiceQuery{},
+		},
+		"GET /api/income/:income_invoice_id_number/document.pdf": {
+			Tag: "Income", Summary: "Print the income as a quotation, invoice or receipt PDF; invoices with a balance carry its PromptPay QR code",
+			ContentType: "application/pdf",
+		},
+		"POST /api/income/import": {
+			Tag: "Income", Summary: "Import incomes from a CSV or XLSX sheet, or check one with dry_run",
+			Request: dtos.ImportIncomesRequest{}, RequestContentType: "multipart/form-data",
+			Response: dtos.ImportResult{}, Status: http.StatusCreated,
+		},
+		"GET /api/income/import": {
+			Tag: "Income", Summary: "List income imports, newest first", Response: []dtos.ImportBatch{},
+		},
+		"DELETE /api/income/import/:import_batch_id": {
+			Tag: "Income", Summary: "Roll back an income import, deleting its incomes and the records it created",
+			Response: dtos.ImportBatch{},
+		},
+		"GET /api/bank/:bank_id/statement_format": {
+			Tag: "Bank", Summary: "Get how the bank's CSV statements are laid out", Response: dtos.BankStatementFormat{},
+		},
+		"PUT /api/bank/:bank_id/statement_format": {
+			Tag: "Bank", Summary: "Set how the bank's CSV statements are laid out",
+			Request: dtos.SaveBankStatementFormatRequest{}, Response: dtos.BankStatementFormat{},
+		},
+		"POST /api/bank/:bank_id/statements": {
+			Tag: "Bank", Summary: "Upload a CSV statement, saving its new credits and suggesting the incomes they pay",
+			Request: dtos.UploadBankStatementRequest{}, RequestContentType: "multipart/form-data",
+			Response: dtos.BankStatementResult{}, Status: http.StatusCreated,
+		},
+		"GET /api/bank_transaction/": {
+			Tag: "Bank transaction", Summary: "List bank credits, newest first, with their suggested matches",
+			Response: []dtos.BankTransaction{},
+			Query:    dtos.BankTransactionQuery{},
+		},
+		"POST /api/bank_transaction/rematch": {
+			Tag: "Bank transaction", Summary: "Match the unmatched credits again against the open incomes",
+			Response: dtos.BankRematchResult{},
+		},
+		"POST /api/bank_transaction/match/:match_id/confirm": {
+			Tag: "Bank transaction", Summary: "Confirm a match, recording the credit as a payment of the income",
+			Response: dtos.BankTransaction{},
+		},
+		"POST /api/bank_transaction/match/:match_id/reject": {
+			Tag: "Bank transaction", Summary: "Reject a match, which is not suggested again",
+			Response: dtos.BankTransaction{},
+		},
+		"GET /api/receiver/": {
+			Tag: "Receiver", Summary: "List Receiver", Response: []dtos.Receiver{},
+			Query: dtos.ReceiverQuery{},
+		},
+		"GET /api/agency/": {
+			Tag: "Agency", Summary: "List Agency with their contacts and brands", Response: []dtos.Agency{},
+			Query: dtos.AgencyQuery{},
+		},
+		"GET /api/contact/": {
+			Tag: "Contact", Summary: "List Contact", Response: []dtos.Contact{},
+			Query: dtos.ContactQuery{},
+		},
+		"GET /api/brand/": {
+			Tag: "Brand", Summary: "List Brand", Response: []dtos.Brand{},
+			Query: dtos.BrandQuery{},
+		},
+		"GET /api/influencer/": {
+			Tag: "Influencer", Summary: "List Influencer", Response: []dtos.Influencer{},
+			Query: dtos.InfluencerQuery{},
+		},
+		"GET /api/influencer/:influencer_id/jobs": {
+			Tag: "Influencer", Summary: "List the jobs of an influencer", Response: []dtos.InfluencerJob{},
+			Query: dtos.InfluencerAssignmentQuery{},
+		},
+		"GET /api/influencer_assignment/": {
+			Tag: "Influencer assignment", Summary: "List Influencer assignment", Response: []dtos.InfluencerAssignment{},
+			Query: dtos.InfluencerAssignmentQuery{},
+		},
+		"GET /api/vendor/": {
+			Tag: "Vendor", Summary: "List Vendor", Response: []dtos.Vendor{},
+			Query: dtos.VendorQuery{},
+		},
+		"GET /api/expense/": {
+			Tag: "Expense", Summary: "List Expense", Response: []dtos.Expense{},
+			Query: dtos.ExpenseQuery{},
+		},
+		"POST /api/expense/:expense_id/attachments": {
+			Tag: "Expense", Summary: "Attach a file to an expense",
+			Request: dtos.UploadExpenseAttachmentRequest{}, RequestContentType: "multipart/form-data",
+			Response: dtos.ExpenseAttachment{}, Status: http.StatusCreated,
+		},
+		"GET /api/expense/:expense_id/attachments/:attachment_id": {
+			Tag: "Expense", Summary: "Download an expense attachment",
+			ContentType: "application/octet-stream",
+		},
+		"DELETE /api/expense/:expense_id/attachments/:attachment_id": {
+			Tag: "Expense", Summary: "Remove an attachment from an expense",
+			Response: utils.EmptyObj{},
+		},
+		"GET /api/sale_person/:sale_person_id/commission_plans": {
+			Tag: "Commission", Summary: "List the commission plans of a sale person with their effective dates",
+			Response: []dtos.SalePersonCommissionPlan{},
+		},
+		"POST /api/sale_person/:sale_person_id/commission_plans": {
+			Tag: "Commission", Summary: "Put a sale person on a commission plan",
+			Request: dtos.AssignCommissionPlanRequest{}, Response: dtos.SalePersonCommissionPlan{}, Status: http.StatusCreated,
+		},
+		"DELETE /api/sale_person/:sale_person_id/commission_plans/:assignment_id": {
+			Tag: "Commission", Summary: "Take a sale person off a commission plan",
+			Response: utils.EmptyObj{},
+		},
+		"GET /api/sale_person/:sale_person_id/commissions": {
+			Tag: "Commission", Summary: "Commission statement of a sale person for a month or quarter",
+			Response: dtos.CommissionStatement{},
+			Query:    dtos.CommissionStatementQuery{},
+		},
+		"POST /api/sale_person/:sale_person_id/commissions/recalculate": {
+			Tag: "Commission", Summary: "Recalculate the commissions of a period from current incomes and plans",
+			Response: dtos.CommissionStatement{},
+			Query:    dtos.CommissionStatementQuery{},
+		},
+		"GET /api/reports/profitability": {
+			Tag: "Reports", Summary: "Revenue, expenses and gross margin by platform, channel or sales person",
+			Response: dtos.ProfitabilityReport{},
+			Query:    dtos.ProfitabilityQuery{},
+		},
+		"GET /api/reports/revenue": {
+			Tag: "Reports", Summary: "Invoiced, received and outstanding totals by period or dimension",
+			Response: dtos.RevenueReport{},
+			Query:    dtos.RevenueQuery{},
+		},
+		"GET /api/reports/ar_aging": {
+			Tag: "Reports", Summary: "Outstanding balances by days past due, per agency or sale person",
+			Response: dtos.ARAgingReport{},
+			Query:    dtos.ARAgingQuery{},
+		},
+		"GET /api/reports/ar_aging/invoices": {
+			Tag: "Reports", Summary: "Invoices behind the aging report",
+			Response: []dtos.ARAgingInvoice{},
+			Query:    dtos.ARAgingInvoiceQuery{},
+		},
+		"GET /api/reports/dso": {
+			Tag: "Reports", Summary: "Days sales outstanding per month",
+			Response: dtos.DSOReport{},
+			Query:    dtos.DSOQuery{},
+		},
+		"GET /api/reports/cash_flow": {
+			Tag: "Reports", Summary: "Expected inflows per week or month against actual receipts",
+			Response: dtos.CashFlowReport{},
+			Query:    dtos.CashFlowQuery{},
+		},
+		"GET /api/reports/sales_vat": {
+			Tag: "Reports", Summary: "Sales VAT report (รายงานภาษีขาย) of a tax month",
+			Response: dtos.SalesVatReport{},
+			Query:    dtos.TaxReportQuery{},
+		},
+		"GET /api/reports/withholding_tax": {
+			Tag: "Reports", Summary: "Register of 50 Tawi withholding tax certificates received in a tax month",
+			Response: dtos.WithholdingTaxRegister{},
+			Query:    dtos.TaxReportQuery{},
+		},
+		"GET /api/currency_rate/": {
+			Tag: "Currency rate", Summary: "List Currency rate", Response: []dtos.CurrencyRate{},
+			Params: []Parameter{QueryParam("currency", "string", "Only rates of this ISO 4217 currency")},
+		},
+		"GET /api/detail/": {
+			Tag: "Detail", Summary: "List Detail", Response: []dtos.Detail{},
+			Params: []Parameter{QueryParam("include", "string", "Set to \"income\" to embed each line's income")},
+		},
+		"GET /api/income/:income_invoice_id_number/details": {
+			Tag: "Income details", Summary: "List the line items of an income in order",
+			Response: dtos.IncomeDetailList{},
+		},
+		"POST /api/income/:income_invoice_id_number/details": {
+			Tag: "Income details", Summary: "Append a line item to an income",
+			Request: dtos.CreateIncomeDetailRequest{}, Response: dtos.IncomeDetail{}, Status: http.StatusCreated,
+		},
+		"PUT /api/income/:income_invoice_id_number/details/order": {
+			Tag: "Income details", Summary: "Reorder the line items of an income",
+			Request: dtos.ReorderIncomeDetailsRequest{}, Response: dtos.IncomeDetailList{},
+		},
+		"PATCH /api/income/:income_invoice_id_number/details/:detail_id": {
+			Tag: "Income details", Summary: "Update a line item of an income",
+			Request: dtos.UpdateIncomeDetailRequest{}, Response: dtos.IncomeDetail{},
+		},
+		"DELETE /api/income/:income_invoice_id_number/details/:detail_id": {
+			Tag: "Income details", Summary: "Remove a line item from an income",
+			Response: utils.EmptyObj{},
+		},
+	},
+)
+
+// crud documents the five routes every master-data resource registers.
+func crud(base, idParam, tag string, item, create, update, response any) map[string]Operation {
+	byId := base + ":" + idParam
+	return map[string]Operation{
+		"GET " + base:    {Tag: tag, Summary: "List " + tag, Response: sliceOf(item)},
+		"GET " + byId:    {Tag: tag, Summary: "Get " + tag, Response: item},
+		"POST " + base:   {Tag: tag, Summary: "Create " + tag, Request: create, Response: response, Status: http.StatusCreated},
+		"PATCH " + byId:  {Tag: tag, Summary: "Update " + tag, Request: update, Response: response},
+		"DELETE " + byId: {Tag: tag, Summary: "Delete " + tag, Response: utils.EmptyObj{}},
+	}
+}
+
+func merge(groups ...map[string]Operation) map[string]Operation {
+	merged := map[string]Operation{}
+	for _, group := range groups {
+		for key, op := range group {
+			merged[key] = op
+		}
+	}
+	return merged
+}
+
+func sliceOf(item any) any {
+	return reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(item)), 0, 0).Interface()
+}
@@ -0,0 +1,212 @@
+package docs
+
+import (
+	"mime/multipart"
+	"mtii-backend/money"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// knownSchemas describes types whose JSON form differs from their Go shape.
+var knownSchemas = map[reflect.Type]Schema{
+	reflect.TypeOf(time.Time{}):            {Type: "string", Format: "date-time"},
+	reflect.TypeOf(money.Amount(0)):        {Type: "string", Format: "decimal", Pattern: `^-?[0-9]+(\.[0-9]{1,2})?$`},
+	reflect.TypeOf(money.Rate(0)):          {Type: "string", Format: "decimal", Pattern: `^[0-9]+(\.[0-9]{1,6})?$`},
+	reflect.TypeOf(multipart.FileHeader{}): {Type: "string", Format: "binary"},
+}
+
+// RegisterSchema documents a type that marshals to something other than its
+// Go structure, e.g. a struct encoded as a string.
+func RegisterSchema(value any, schema Schema) {
+	knownSchemas[reflect.TypeOf(value)] = schema
+}
+
+// schemaBuilder turns Go types into schemas, collecting named structs as
+// reusable components.
+type schemaBuilder struct {
+	components map[string]*Schema
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: map[string]*Schema{}}
+}
+
+func (b *schemaBuilder) schemaOf(t reflect.Type) *Schema {
+	if known, ok := knownSchemas[t]; ok {
+		schema := known
+		return &schema
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := b.schemaOf(t.Elem())
+		if schema.Ref != "" {
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := b.components[name]; !ok {
+			// Reserve the name first so recursive types terminate.
+			b.components[name] = &Schema{}
+			*b.components[name] = *b.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(schema, t)
+	return schema
+}
+
+func (b *schemaBuilder) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.addFields(schema, embedded)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := b.schemaOf(field.Type)
+		if description := field.Tag.Get("doc"); description != "" {
+			if property.Ref != "" {
+				property = &Schema{AllOf: []*Schema{property}}
+			}
+			property.Description = description
+		}
+		if applyBinding(property, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyBinding copies the validator rules gin enforces into the schema and
+// reports whether the field is required.
+func applyBinding(schema *Schema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "oneof":
+			for _, option := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, option)
+			}
+		case "min", "gte":
+			setLowerBound(schema, param)
+		case "max", "lte":
+			setUpperBound(schema, param)
+		case "len":
+			setLowerBound(schema, param)
+			setUpperBound(schema, param)
+		case "numeric":
+			schema.Pattern = "^[0-9]+$"
+		case "taxid":
+			schema.Pattern = "^[0-9]{13}$"
+		case "branch":
+			schema.Pattern = "^[0-9]{5}$"
+		case "promptpay":
+			schema.Pattern = "^(0[0-9]{9}|[0-9]{13})$"
+		}
+	}
+	return required
+}
+
+func setLowerBound(schema *Schema, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string":
+		length := int(n)
+		schema.MinLength = &length
+	case "array":
+		items := int(n)
+		schema.MinItems = &items
+	default:
+		schema.Minimum = &n
+	}
+}
+
+func setUpperBound(schema *Schema, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string":
+		length := int(n)
+		schema.MaxLength = &length
+	case "array":
+	default:
+		schema.Maximum = &n
+	}
+}
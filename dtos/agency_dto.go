@@ -0,0 +1,42 @@
+package dtos
+
+import "mtii-backend/taxid"
+
+type (
+	Agency struct {
+		Id           int       `json:"id"`
+		Name         string    `json:"name"`
+		Address      string    `json:"address"`
+		PhoneNumber  string    `json:"phone_number"`
+		TaxPayerId   taxid.ID  `json:"tax_payer_id"`
+		BranchNumber string    `json:"branch_number"`
+		Contacts     []Contact `json:"contacts"`
+		Brands       []Brand   `json:"brands"`
+	}
+
+	CreateAgencyRequest struct {
+		Name         string   `json:"name" binding:"required"`
+		Address      string   `json:"address"`
+		PhoneNumber  string   `json:"phone_number"`
+		TaxPayerId   taxid.ID `json:"tax_payer_id" binding:"omitempty,taxid"`
+		BranchNumber string   `json:"branch_number" binding:"omitempty,branch" doc:"00000 for the head office, the default"`
+	}
+
+	UpdateAgencyRequest struct {
+		Name         string   `json:"name"`
+		Address      string   `json:"address"`
+		PhoneNumber  string   `json:"phone_number"`
+		TaxPayerId   taxid.ID `json:"tax_payer_id" binding:"omitempty,taxid"`
+		BranchNumber string   `json:"branch_number" binding:"omitempty,branch"`
+	}
+
+	// AgencyQuery filters the agency list. TaxId matches a whole tax ID or,
+	// with fewer than 13 digits, its prefix.
+	AgencyQuery struct {
+		TaxId string `json:"tax_id" form:"tax_id" binding:"omitempty,numeric,max=13" doc:"Tax ID, or the leading digits of one"`
+	}
+
+	AgencyResponse struct {
+		Id int `json:"id"`
+	}
+)
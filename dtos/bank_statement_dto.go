@@ -0,0 +1,97 @@
+package dtos
+
+import (
+	"mime/multipart"
+	"mtii-backend/money"
+	"time"
+)
+
+type (
+	BankStatementFormat struct {
+		BankId            int    `json:"bank_id"`
+		Encoding          string `json:"encoding"`
+		SkipRows          int    `json:"skip_rows"`
+		DateColumn        string `json:"date_column"`
+		DateLayout        string `json:"date_layout"`
+		DescriptionColumn string `json:"description_column"`
+		ReferenceColumn   string `json:"reference_column"`
+		CreditColumn      string `json:"credit_column"`
+		DebitColumn       string `json:"debit_column"`
+		Currency          string `json:"currency"`
+	}
+
+	// SaveBankStatementFormatRequest describes a bank's CSV statements.
+	// Columns are named by their header text. DateLayout is a Go time
+	// layout; years past 2400 are read as Buddhist Era years.
+	SaveBankStatementFormatRequest struct {
+		Encoding          string `json:"encoding" binding:"omitempty,oneof=utf-8 windows-874" doc:"utf-8, the default, or windows-874 for Thai files from older banking software"`
+		SkipRows          int    `json:"skip_rows" binding:"min=0" doc:"Lines of preamble before the header row"`
+		DateColumn        string `json:"date_column" binding:"required,max=100"`
+		DateLayout        string `json:"date_layout" binding:"max=50" doc:"Go time layout of the dates, 02/01/2006 by default"`
+		DescriptionColumn string `json:"description_column" binding:"required,max=100"`
+		ReferenceColumn   string `json:"reference_column" binding:"max=100"`
+		CreditColumn      string `json:"credit_column" binding:"required,max=100" doc:"Deposits, or signed amounts when there is no debit column"`
+		DebitColumn       string `json:"debit_column" binding:"max=100"`
+		Currency          string `json:"currency" binding:"omitempty,iso4217" doc:"Currency of the account, THB by default"`
+	}
+
+	UploadBankStatementRequest struct {
+		File *multipart.FileHeader `json:"file" form:"file" binding:"required"`
+	}
+
+	// BankStatementResult reports an upload: how many credits were new,
+	// how many had been uploaded before and how many got suggested
+	// matches.
+	BankStatementResult struct {
+		Id         int       `json:"id"`
+		BankId     int       `json:"bank_id"`
+		FileName   string    `json:"file_name"`
+		Credits    int       `json:"credits"`
+		Duplicates int       `json:"duplicates"`
+		Suggested  int       `json:"suggested"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	BankTransactionQuery struct {
+		Status string `json:"status" form:"status" binding:"omitempty,oneof=unmatched pending confirmed" doc:"pending lists the review queue"`
+		BankId int    `json:"bank_id" form:"bank_id" doc:"Only credits of this bank"`
+	}
+
+	BankTransaction struct {
+		Id                    int                    `json:"id"`
+		BankId                int                    `json:"bank_id"`
+		BankStatementId       int                    `json:"bank_statement_id"`
+		Date                  time.Time              `json:"date"`
+		Description           string                 `json:"description"`
+		Reference             string                 `json:"reference"`
+		Amount                money.Amount           `json:"amount"`
+		Currency              string                 `json:"currency"`
+		Status                string                 `json:"status"`
+		IncomeInvoiceIdNumber *int                   `json:"income_invoice_id_number"`
+		ConfirmedAt           time.Time              `json:"confirmed_at"`
+		Matches               []BankTransactionMatch `json:"matches"`
+	}
+
+	// BankTransactionMatch is an income the transaction may pay, with the
+	// matcher's confidence from 0 to 100 and the signals behind it:
+	// reference, invoice_number, amount, amount_net_of_withholding_tax,
+	// agency and agency_partial.
+	BankTransactionMatch struct {
+		Id                    int          `json:"id"`
+		IncomeInvoiceIdNumber int          `json:"income_invoice_id_number"`
+		AgencyName            string       `json:"agency_name"`
+		InvoiceIssueDate      time.Time    `json:"invoice_issue_date"`
+		UnpaidPaymentAmount   money.Amount `json:"unpaid_payment_amount"`
+		Score                 int          `json:"score"`
+		Reasons               []string     `json:"reasons"`
+		Status                string       `json:"status"`
+		ReviewedAt            time.Time    `json:"reviewed_at"`
+	}
+
+	// BankRematchResult counts the unmatched transactions matched again
+	// and those that got suggestions.
+	BankRematchResult struct {
+		Transactions int `json:"transactions"`
+		Suggested    int `json:"suggested"`
+	}
+)
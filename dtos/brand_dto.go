@@ -0,0 +1,28 @@
+package dtos
+
+type (
+	Brand struct {
+		Id       int    `json:"id"`
+		AgencyId int    `json:"agency_id"`
+		Name     string `json:"name"`
+	}
+
+	CreateBrandRequest struct {
+		AgencyId int    `json:"agency_id" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+	}
+
+	UpdateBrandRequest struct {
+		AgencyId int    `json:"agency_id"`
+		Name     string `json:"name"`
+	}
+
+	// BrandQuery filters the brand list by agency.
+	BrandQuery struct {
+		AgencyId int `json:"agency_id" form:"agency_id" doc:"Only brands of this agency"`
+	}
+
+	BrandResponse struct {
+		Id int `json:"id"`
+	}
+)
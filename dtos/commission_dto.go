@@ -0,0 +1,93 @@
+package dtos
+
+import (
+	"mtii-backend/money"
+	"time"
+)
+
+type (
+	CommissionPlan struct {
+		Id         int              `json:"id"`
+		Name       string           `json:"name"`
+		Type       string           `json:"type"`
+		Rate       money.Amount     `json:"rate"`
+		PlatformId *int             `json:"platform_id"`
+		ChannelId  *int             `json:"channel_id"`
+		Tiers      []CommissionTier `json:"tiers"`
+	}
+
+	CommissionTier struct {
+		MinRevenue money.Amount `json:"min_revenue" binding:"min=0" doc:"Monthly revenue in the base currency from which Rate applies"`
+		Rate       money.Amount `json:"rate" binding:"min=0" doc:"Percent of revenue"`
+	}
+
+	CreateCommissionPlanRequest struct {
+		Name       string           `json:"name" binding:"required"`
+		Type       string           `json:"type" binding:"required,oneof=flat tiered"`
+		Rate       money.Amount     `json:"rate" binding:"min=0" doc:"Percent of revenue, for flat plans"`
+		PlatformId *int             `json:"platform_id" doc:"Only incomes on this platform"`
+		ChannelId  *int             `json:"channel_id" doc:"Only incomes from this channel"`
+		Tiers      []CommissionTier `json:"tiers" binding:"required_if=Type tiered,dive" doc:"For tiered plans"`
+	}
+
+	// UpdateCommissionPlanRequest changes the fields that are set. Tiers,
+	// when present, replace the stored tiers. Setting PlatformId or
+	// ChannelId to 0 removes the restriction.
+	UpdateCommissionPlanRequest struct {
+		Name       string           `json:"name"`
+		Type       string           `json:"type" binding:"omitempty,oneof=flat tiered"`
+		Rate       *money.Amount    `json:"rate" binding:"omitempty,min=0"`
+		PlatformId *int             `json:"platform_id"`
+		ChannelId  *int             `json:"channel_id"`
+		Tiers      []CommissionTier `json:"tiers" binding:"omitempty,dive"`
+	}
+
+	CommissionPlanResponse struct {
+		Id int `json:"id"`
+	}
+
+	SalePersonCommissionPlan struct {
+		Id             int            `json:"id"`
+		SalePersonId   int            `json:"sale_person_id"`
+		CommissionPlan CommissionPlan `json:"commission_plan"`
+		EffectiveFrom  time.Time      `json:"effective_from"`
+		EffectiveTo    time.Time      `json:"effective_to"`
+	}
+
+	AssignCommissionPlanRequest struct {
+		CommissionPlanId int       `json:"commission_plan_id" binding:"required"`
+		EffectiveFrom    time.Time `json:"effective_from" binding:"required"`
+		EffectiveTo      time.Time `json:"effective_to" doc:"Last day of the plan; leave out for open-ended"`
+	}
+
+	// CommissionStatementQuery selects a month such as 2026-10 or a quarter
+	// such as 2026-Q4. Format csv, xlsx or pdf downloads the statement
+	// instead.
+	CommissionStatementQuery struct {
+		Period string `json:"period" form:"period" binding:"required" doc:"Month such as 2026-10 or quarter such as 2026-Q4"`
+		Format string `json:"format" form:"format" binding:"omitempty,oneof=json csv xlsx pdf" doc:"json (default), or csv, xlsx or pdf to download the statement"`
+	}
+
+	// CommissionStatement lists a sale person's commissions on incomes paid
+	// in the period. Amounts are in Currency, the base currency.
+	CommissionStatement struct {
+		SalePerson      SalePerson       `json:"sale_person"`
+		Period          string           `json:"period"`
+		From            time.Time        `json:"from"`
+		To              time.Time        `json:"to"`
+		Currency        string           `json:"currency"`
+		Lines           []CommissionLine `json:"lines"`
+		TotalRevenue    money.Amount     `json:"total_revenue"`
+		TotalCommission money.Amount     `json:"total_commission"`
+	}
+
+	CommissionLine struct {
+		IncomeInvoiceIdNumber int          `json:"income_invoice_id_number"`
+		PaidDate              time.Time    `json:"paid_date"`
+		CommissionPlanId      int          `json:"commission_plan_id"`
+		CommissionPlanName    string       `json:"commission_plan_name"`
+		Revenue               money.Amount `json:"revenue"`
+		Rate                  money.Amount `json:"rate"`
+		Amount                money.Amount `json:"amount"`
+	}
+)
@@ -0,0 +1,37 @@
+package dtos
+
+type (
+	Contact struct {
+		Id          int    `json:"id"`
+		AgencyId    int    `json:"agency_id"`
+		Name        string `json:"name"`
+		PhoneNumber string `json:"phone_number"`
+		Line        string `json:"line"`
+		Email       string `json:"email"`
+	}
+
+	CreateContactRequest struct {
+		AgencyId    int    `json:"agency_id" binding:"required"`
+		Name        string `json:"name" binding:"required"`
+		PhoneNumber string `json:"phone_number"`
+		Line        string `json:"line"`
+		Email       string `json:"email" binding:"omitempty,email"`
+	}
+
+	UpdateContactRequest struct {
+		AgencyId    int    `json:"agency_id"`
+		Name        string `json:"name"`
+		PhoneNumber string `json:"phone_number"`
+		Line        string `json:"line"`
+		Email       string `json:"email" binding:"omitempty,email"`
+	}
+
+	// ContactQuery filters the contact list by agency.
+	ContactQuery struct {
+		AgencyId int `json:"agency_id" form:"agency_id" doc:"Only contacts of this agency"`
+	}
+
+	ContactResponse struct {
+		Id int `json:"id"`
+	}
+)
@@ -0,0 +1,31 @@
+package dtos
+
+import (
+	"mtii-backend/money"
+	"time"
+)
+
+type (
+	CurrencyRate struct {
+		Id       int        `json:"id"`
+		Date     time.Time  `json:"date"`
+		Currency string     `json:"currency"`
+		Rate     money.Rate `json:"rate" doc:"Baht per one unit of the currency"`
+	}
+
+	CreateCurrencyRateRequest struct {
+		Date     time.Time  `json:"date" binding:"required"`
+		Currency string     `json:"currency" binding:"required,iso4217"`
+		Rate     money.Rate `json:"rate" binding:"required,gt=0"`
+	}
+
+	UpdateCurrencyRateRequest struct {
+		Date     time.Time  `json:"date"`
+		Currency string     `json:"currency" binding:"omitempty,iso4217"`
+		Rate     money.Rate `json:"rate" binding:"omitempty,gt=0"`
+	}
+
+	CurrencyRateResponse struct {
+		Id int `json:"id"`
+	}
+)
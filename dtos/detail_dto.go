@@ -1,32 +1,90 @@
 package dtos
 
+import "mtii-backend/money"
+
 type (
 	Detail struct {
-		Id          int    `json:"id"`
-		Description string `json:"description"`
-		Notes       string `json:"notes"`
-		Quantity    int    `json:"quantity"`
-		UnitPrice   int    `json:"unit_price"`
-		Income      Income `json:"income"`
+		Id                    int          `json:"id"`
+		Description           string       `json:"description"`
+		Notes                 string       `json:"notes"`
+		Quantity              int          `json:"quantity"`
+		Unit                  string       `json:"unit"`
+		UnitPrice             money.Amount `json:"unit_price"`
+		Category              string       `json:"category"`
+		DiscountType          string       `json:"discount_type"`
+		DiscountValue         money.Amount `json:"discount_value"`
+		VatExempt             bool         `json:"vat_exempt"`
+		Position              int          `json:"position"`
+		LineTotal             money.Amount `json:"line_total"`
+		DiscountAmount        money.Amount `json:"discount_amount"`
+		NetAmount             money.Amount `json:"net_amount"`
+		IncomeInvoiceIdNumber int          `json:"income_invoice_id_number"`
+		Income                *Income      `json:"income,omitempty"`
 	}
 
 	CreateDetailRequest struct {
-		Description           string `json:"description" binding:"required"`
-		Notes                 string `json:"notes" binding:"required"`
-		Quantity              int    `json:"quantity" binding:"required"`
-		UnitPrice             int    `json:"unit_price" binding:"required"`
-		IncomeInvoiceIdNumber int    `json:"income_invoice_id_number" binding:"required"`
+		Description           string       `json:"description" binding:"required"`
+		Notes                 string       `json:"notes" binding:"required"`
+		Quantity              int          `json:"quantity" binding:"required"`
+		Unit                  string       `json:"unit" binding:"omitempty,oneof=post video story hour item package"`
+		UnitPrice             money.Amount `json:"unit_price" binding:"required"`
+		Category              string       `json:"category" binding:"omitempty,oneof=influencer_fee production ads_boost other"`
+		DiscountType          string       `json:"discount_type" binding:"omitempty,oneof=percent fixed"`
+		DiscountValue         money.Amount `json:"discount_value" binding:"min=0"`
+		VatExempt             bool         `json:"vat_exempt"`
+		IncomeInvoiceIdNumber int          `json:"income_invoice_id_number" binding:"required"`
 	}
 
 	UpdateDetailRequest struct {
-		Description           string `json:"description"`
-		Notes                 string `json:"notes"`
-		Quantity              int    `json:"quantity"`
-		UnitPrice             int    `json:"unit_price"`
-		IncomeInvoiceIdNumber int    `json:"income_invoice_id_number"`
+		Description           string       `json:"description"`
+		Notes                 string       `json:"notes"`
+		Quantity              int          `json:"quantity"`
+		Unit                  string       `json:"unit" binding:"omitempty,oneof=post video story hour item package"`
+		UnitPrice             money.Amount `json:"unit_price"`
+		Category              string       `json:"category" binding:"omitempty,oneof=influencer_fee production ads_boost other"`
+		DiscountType          string       `json:"discount_type" binding:"omitempty,oneof=none percent fixed"`
+		DiscountValue         money.Amount `json:"discount_value" binding:"min=0"`
+		VatExempt             *bool        `json:"vat_exempt"`
+		IncomeInvoiceIdNumber int          `json:"income_invoice_id_number"`
 	}
 
 	DetailResponse struct {
 		Id int `json:"id"`
 	}
+
+	IncomeDetailList struct {
+		InvoiceIdNumber int            `json:"invoice_id_number"`
+		Details         []IncomeDetail `json:"details"`
+		IncomeTotals
+	}
+
+	CreateIncomeDetailRequest struct {
+		Description   string       `json:"description" binding:"required"`
+		Notes         string       `json:"notes"`
+		Quantity      int          `json:"quantity" binding:"required,min=1"`
+		Unit          string       `json:"unit" binding:"omitempty,oneof=post video story hour item package"`
+		UnitPrice     money.Amount `json:"unit_price" binding:"min=0"`
+		Category      string       `json:"category" binding:"omitempty,oneof=influencer_fee production ads_boost other"`
+		DiscountType  string       `json:"discount_type" binding:"omitempty,oneof=percent fixed"`
+		DiscountValue money.Amount `json:"discount_value" binding:"min=0"`
+		VatExempt     bool         `json:"vat_exempt"`
+	}
+
+	// UpdateIncomeDetailRequest changes only the fields that are set.
+	// DiscountType "none" removes the line discount.
+	UpdateIncomeDetailRequest struct {
+		Description   string       `json:"description"`
+		Notes         string       `json:"notes"`
+		Quantity      int          `json:"quantity" binding:"omitempty,min=1"`
+		Unit          string       `json:"unit" binding:"omitempty,oneof=post video story hour item package"`
+		UnitPrice     money.Amount `json:"unit_price" binding:"omitempty,min=0"`
+		Category      string       `json:"category" binding:"omitempty,oneof=influencer_fee production ads_boost other"`
+		DiscountType  string       `json:"discount_type" binding:"omitempty,oneof=none percent fixed"`
+		DiscountValue money.Amount `json:"discount_value" binding:"min=0"`
+		VatExempt     *bool        `json:"vat_exempt"`
+	}
+
+	ReorderIncomeDetailsRequest struct {
+		DetailIds []int `json:"detail_ids" binding:"required"`
+	}
 )
@@ -0,0 +1,93 @@
+package dtos
+
+import (
+	"mime/multipart"
+	"mtii-backend/money"
+	"time"
+)
+
+type (
+	// Expense amounts are in Currency, the currency of the income.
+	Expense struct {
+		Id                    int                 `json:"id"`
+		IncomeInvoiceIdNumber int                 `json:"income_invoice_id_number"`
+		InfluencerId          *int                `json:"influencer_id"`
+		VendorId              *int                `json:"vendor_id"`
+		PayeeName             string              `json:"payee_name"`
+		Category              string              `json:"category"`
+		Description           string              `json:"description"`
+		Currency              string              `json:"currency"`
+		Amount                money.Amount        `json:"amount"`
+		WithholdingTaxRate    money.Amount        `json:"withholding_tax_rate"`
+		WithholdingTaxAmount  money.Amount        `json:"withholding_tax_amount"`
+		NetPayable            money.Amount        `json:"net_payable"`
+		DueDate               time.Time           `json:"due_date"`
+		PaidDate              time.Time           `json:"paid_date"`
+		Attachments           []ExpenseAttachment `json:"attachments"`
+	}
+
+	ExpenseAttachment struct {
+		Id          int       `json:"id"`
+		FileName    string    `json:"file_name"`
+		ContentType string    `json:"content_type"`
+		Size        int64     `json:"size"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+
+	CreateExpenseRequest struct {
+		IncomeInvoiceIdNumber int          `json:"income_invoice_id_number" binding:"required"`
+		InfluencerId          *int         `json:"influencer_id" binding:"excluded_with=VendorId"`
+		VendorId              *int         `json:"vendor_id" binding:"excluded_with=InfluencerId"`
+		Category              string       `json:"category" binding:"omitempty,oneof=influencer_fee production ads_boost other"`
+		Description           string       `json:"description" binding:"required"`
+		Amount                money.Amount `json:"amount" binding:"required,min=0" doc:"Excluding VAT, in the currency of the income"`
+		WithholdingTaxRate    money.Amount `json:"withholding_tax_rate" binding:"min=0" doc:"Percent withheld from the amount, e.g. 3"`
+		DueDate               time.Time    `json:"due_date"`
+		PaidDate              time.Time    `json:"paid_date"`
+	}
+
+	// UpdateExpenseRequest changes the fields that are set. Setting
+	// InfluencerId or VendorId to 0 removes the payee.
+	UpdateExpenseRequest struct {
+		InfluencerId       *int          `json:"influencer_id"`
+		VendorId           *int          `json:"vendor_id"`
+		Category           string        `json:"category" binding:"omitempty,oneof=influencer_fee production ads_boost other"`
+		Description        string        `json:"description"`
+		Amount             money.Amount  `json:"amount" binding:"min=0"`
+		WithholdingTaxRate *money.Amount `json:"withholding_tax_rate" binding:"omitempty,min=0"`
+		DueDate            time.Time     `json:"due_date"`
+		PaidDate           time.Time     `json:"paid_date"`
+	}
+
+	ExpenseQuery struct {
+		IncomeInvoiceIdNumber int  `json:"income_invoice_id_number" form:"income_invoice_id_number" doc:"Only expenses of this income"`
+		InfluencerId          int  `json:"influencer_id" form:"influencer_id" doc:"Only expenses paid to this influencer"`
+		VendorId              int  `json:"vendor_id" form:"vendor_id" doc:"Only expenses paid to this vendor"`
+		Unpaid                bool `json:"unpaid" form:"unpaid" doc:"Only expenses without a paid date"`
+	}
+
+	ExpenseResponse struct {
+		Id int `json:"id"`
+	}
+
+	UploadExpenseAttachmentRequest struct {
+		File *multipart.FileHeader `json:"file" form:"file" binding:"required"`
+	}
+
+	// ExpenseAttachmentFile is an attachment with its contents, for
+	// downloading.
+	ExpenseAttachmentFile struct {
+		FileName    string
+		ContentType string
+		Data        []byte
+	}
+
+	// IncomeProfitability compares what the job earns, excluding VAT, with
+	// what was paid out of it. GrossMarginPercent is relative to Revenue.
+	IncomeProfitability struct {
+		Revenue            money.Amount `json:"revenue"`
+		Expenses           money.Amount `json:"expenses"`
+		GrossMargin        money.Amount `json:"gross_margin"`
+		GrossMarginPercent money.Amount `json:"gross_margin_percent"`
+	}
+)
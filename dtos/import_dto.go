@@ -0,0 +1,61 @@
+package dtos
+
+import (
+	"mime/multipart"
+	"mtii-backend/utils"
+	"time"
+)
+
+type (
+	// ImportIncomesRequest uploads a CSV or XLSX sheet of incomes, one
+	// row per income or per line item. Mapping is a JSON object naming the
+	// field each column header fills, for headers that are not already
+	// field names; mapping a header to "" skips its column.
+	ImportIncomesRequest struct {
+		File          *multipart.FileHeader `json:"file" form:"file" binding:"required"`
+		DryRun        bool                  `json:"dry_run" form:"dry_run" doc:"Check the file without saving anything"`
+		CreateMissing bool                  `json:"create_missing" form:"create_missing" doc:"Create the platforms and statuses named in the file that do not exist yet"`
+		Mapping       string                `json:"mapping" form:"mapping" doc:"JSON object such as {\"Invoice No.\": \"invoice_id_number\"}"`
+	}
+
+	// ImportOptions are the settings of one import, whether it comes
+	// through the API or the command line.
+	ImportOptions struct {
+		FileName      string
+		Source        string
+		DryRun        bool
+		CreateMissing bool
+		Mapping       map[string]string
+	}
+
+	// ImportResult reports an import. Rows counts the data rows read and
+	// Incomes the incomes they describe. CreatedPlatforms and
+	// CreatedStatuses list the records the import creates, or would
+	// create on a dry run. BatchId is set once the import is saved.
+	ImportResult struct {
+		BatchId          int              `json:"batch_id"`
+		DryRun           bool             `json:"dry_run"`
+		Rows             int              `json:"rows"`
+		Incomes          int              `json:"incomes"`
+		CreatedPlatforms []string         `json:"created_platforms"`
+		CreatedStatuses  []string         `json:"created_statuses"`
+		Errors           []ImportRowError `json:"errors"`
+	}
+
+	// ImportRowError lists the problems found in one row of the sheet.
+	// Row counts from 1, the header row.
+	ImportRowError struct {
+		Row             int                `json:"row"`
+		InvoiceIdNumber int                `json:"invoice_id_number"`
+		Fields          []utils.FieldError `json:"fields"`
+	}
+
+	ImportBatch struct {
+		Id           int       `json:"id"`
+		FileName     string    `json:"file_name"`
+		Source       string    `json:"source"`
+		Incomes      int       `json:"incomes"`
+		CreatedAt    time.Time `json:"created_at"`
+		RolledBackAt time.Time `json:"rolled_back_at"`
+	}
+)
@@ -1,38 +1,55 @@
 package dtos
 
-import "time"
+import (
+	"mtii-backend/money"
+	"mtii-backend/taxid"
+	"time"
+)
 
 type (
 	Income struct {
-		QuotationIdNumber          int       `json:"quotation_id_number"`
-		QuotationIssueDate         time.Time `json:"quotation_issue_date"`
-		QuotationDueDate           time.Time `json:"quotation_due_date"`
-		InvoiceIdNumber            int       `json:"invoice_id_number"`
-		InvoiceIssueDate           time.Time `json:"invoice_issue_date"`
-		InvoiceDueDate             time.Time `json:"invoice_due_date"`
-		ReceiptIssueDate           time.Time `json:"receipt_issue_date"`
-		ReceiptIdNumber            int       `json:"receipt_id_number"`
-		AgencyTaxPayerIdNumber     int       `json:"agency_tax_payer_id_number"`
-		InfluencerPostingDate      time.Time `json:"influencer_posting_date"`
-		AgencyAgencyName           string    `json:"agency_agency_name"`
-		AgencyAddress              string    `json:"agency_address"`
-		AgencyPhoneNumber          string    `json:"agency_phone_number"`
-		ContactorContactorName     string    `json:"contactor_contactor_name"`
-		ContactorPhoneNumber       string    `json:"contactor_phone_number"`
-		ContactorLine              string    `json:"contactor_line"`
-		ContactorEmail             string    `json:"contactor_email"`
-		BrandBrandName             string    `json:"brand_brand_name"`
-		BrandProduct               string    `json:"brand_product"`
-		TransactionReferenceNumber int       `json:"transaction_reference_number"`
-		TermsAndConditions         string    `json:"terms_and_conditions"`
-		TotalPaymentAmount         int       `json:"total_payment_amount"`
-		NotesForTheTotalPayment    string    `json:"notes_for_the_total_payment"`
-		FirstPayment               int       `json:"first_payment"`
-		NotesForTheFirstPayment    string    `json:"notes_for_the_first_payment"`
-		SecondPayment              int       `json:"second_payment"`
-		NotesForTheSecondPayment   string    `json:"notes_for_the_second_payment"`
-		UnpaidPaymentAmount        int       `json:"unpaid_payment_amount"`
-		NotesForTheUnpaidPayment   string    `json:"notes_for_the_unpaid_payment"`
+		QuotationIdNumber          int          `json:"quotation_id_number"`
+		QuotationIssueDate         time.Time    `json:"quotation_issue_date"`
+		QuotationDueDate           time.Time    `json:"quotation_due_date"`
+		InvoiceIdNumber            int          `json:"invoice_id_number" doc:"The key of the income; a quotation's is the negative of its quotation number until it is invoiced"`
+		InvoiceIssueDate           time.Time    `json:"invoice_issue_date"`
+		InvoiceDueDate             time.Time    `json:"invoice_due_date"`
+		ReceiptIssueDate           time.Time    `json:"receipt_issue_date"`
+		ReceiptIdNumber            int          `json:"receipt_id_number"`
+		Stage                      string       `json:"stage"`
+		AgencyTaxPayerIdNumber     taxid.ID     `json:"agency_tax_payer_id_number"`
+		AgencyBranchNumber         string       `json:"agency_branch_number"`
+		InfluencerPostingDate      time.Time    `json:"influencer_posting_date"`
+		AgencyAgencyName           string       `json:"agency_agency_name"`
+		AgencyAddress              string       `json:"agency_address"`
+		AgencyPhoneNumber          string       `json:"agency_phone_number"`
+		ContactorContactorName     string       `json:"contactor_contactor_name"`
+		ContactorPhoneNumber       string       `json:"contactor_phone_number"`
+		ContactorLine              string       `json:"contactor_line"`
+		ContactorEmail             string       `json:"contactor_email"`
+		BrandBrandName             string       `json:"brand_brand_name"`
+		BrandProduct               string       `json:"brand_product"`
+		AgencyId                   *int         `json:"agency_id"`
+		ContactId                  *int         `json:"contact_id"`
+		BrandId                    *int         `json:"brand_id"`
+		TransactionReferenceNumber int          `json:"transaction_reference_number"`
+		TermsAndConditions         string       `json:"terms_and_conditions"`
+		TotalPaymentAmount         money.Amount `json:"total_payment_amount"`
+		NotesForTheTotalPayment    string       `json:"notes_for_the_total_payment"`
+		FirstPayment               money.Amount `json:"first_payment"`
+		NotesForTheFirstPayment    string       `json:"notes_for_the_first_payment"`
+		SecondPayment              money.Amount `json:"second_payment"`
+		NotesForTheSecondPayment   string       `json:"notes_for_the_second_payment"`
+		UnpaidPaymentAmount        money.Amount `json:"unpaid_payment_amount"`
+		NotesForTheUnpaidPayment   string       `json:"notes_for_the_unpaid_payment"`
+		Currency                   string       `json:"currency"`
+		DiscountType               string       `json:"discount_type"`
+		DiscountValue              money.Amount `json:"discount_value"`
+
+		WithholdingTaxRate           money.Amount `json:"withholding_tax_rate"`
+		WithholdingTaxAmount         money.Amount `json:"withholding_tax_amount"`
+		WithholdingCertificateNumber string       `json:"withholding_certificate_number"`
+		WithholdingCertificateDate   time.Time    `json:"withholding_certificate_date"`
 
 		Platform      Platform      `json:"platform"`
 		Status        Status        `json:"status"`
@@ -41,78 +58,208 @@ type (
 		SalePerson    SalePerson    `json:"sale_person"`
 		Channel       Channel       `json:"channel"`
 		Bank          Bank          `json:"bank"`
+
+		Details []IncomeDetail `json:"details"`
+		IncomeTotals
+		Profitability IncomeProfitability `json:"profitability"`
+
+		// Base repeats the amounts in the base currency. It is left out when
+		// no exchange rate is on file for the income's currency.
+		Base *IncomeBaseAmounts `json:"base,omitempty"`
+	}
+
+	// IncomeBaseAmounts converts the billed amounts and expenses at the rate
+	// on the invoice date and the received payments at the rate on the
+	// receipt date, falling back to the invoice rate when the receipt has no
+	// rate.
+	IncomeBaseAmounts struct {
+		Currency            string       `json:"currency"`
+		InvoiceRate         money.Rate   `json:"invoice_rate"`
+		InvoiceRateDate     time.Time    `json:"invoice_rate_date"`
+		PaymentRate         money.Rate   `json:"payment_rate"`
+		PaymentRateDate     time.Time    `json:"payment_rate_date"`
+		TotalPaymentAmount  money.Amount `json:"total_payment_amount"`
+		FirstPayment        money.Amount `json:"first_payment"`
+		SecondPayment       money.Amount `json:"second_payment"`
+		UnpaidPaymentAmount money.Amount `json:"unpaid_payment_amount"`
+		Subtotal            money.Amount `json:"subtotal"`
+		DiscountAmount      money.Amount `json:"discount_amount"`
+		VatAmount           money.Amount `json:"vat_amount"`
+		GrandTotal          money.Amount `json:"grand_total"`
+		Revenue             money.Amount `json:"revenue"`
+		Expenses            money.Amount `json:"expenses"`
+		GrossMargin         money.Amount `json:"gross_margin"`
+	}
+
+	// IncomeTotals are computed from the lines: Subtotal is the sum of the
+	// lines' net amounts, the document discount comes off the subtotal and
+	// VAT is charged on what remains of the lines that are not exempt.
+	IncomeTotals struct {
+		Subtotal        money.Amount `json:"subtotal"`
+		DiscountAmount  money.Amount `json:"discount_amount"`
+		VatableAmount   money.Amount `json:"vatable_amount"`
+		VatExemptAmount money.Amount `json:"vat_exempt_amount"`
+		VatAmount       money.Amount `json:"vat_amount"`
+		GrandTotal      money.Amount `json:"grand_total"`
+	}
+
+	IncomeDetail struct {
+		Id             int          `json:"id"`
+		Description    string       `json:"description"`
+		Notes          string       `json:"notes"`
+		Quantity       int          `json:"quantity"`
+		Unit           string       `json:"unit"`
+		UnitPrice      money.Amount `json:"unit_price"`
+		Category       string       `json:"category"`
+		DiscountType   string       `json:"discount_type"`
+		DiscountValue  money.Amount `json:"discount_value"`
+		VatExempt      bool         `json:"vat_exempt"`
+		Position       int          `json:"position"`
+		LineTotal      money.Amount `json:"line_total"`
+		DiscountAmount money.Amount `json:"discount_amount"`
+		NetAmount      money.Amount `json:"net_amount"`
+	}
+
+	// IncomeQuery filters the income list. TaxId matches the agency's whole
+	// tax ID or, with fewer than 13 digits, its prefix.
+	IncomeQuery struct {
+		TaxId string `json:"tax_id" form:"tax_id" binding:"omitempty,numeric,max=13" doc:"Agency tax ID, or the leading digits of one"`
+	}
+
+	// IncomeExportQuery takes the list filters and lays out the export.
+	// Columns is a comma-separated list of column keys, all of them by
+	// default. Details gives each line a row of its own, repeating the
+	// income's columns, and allows the detail columns.
+	IncomeExportQuery struct {
+		IncomeQuery
+		Format  string `json:"format" form:"format" binding:"omitempty,oneof=csv xlsx" doc:"csv (default) or xlsx"`
+		Columns string `json:"columns" form:"columns" doc:"Comma-separated column keys such as invoice_id_number,agency,grand_total; all columns by default"`
+		Details bool   `json:"details" form:"details" doc:"One row per line, allowing the detail_ columns"`
+		Lang    string `json:"lang" form:"lang" binding:"omitempty,oneof=en th" doc:"Language of the column titles: en (default) or th"`
+	}
+
+	IncomeDetailRequest struct {
+		Id            int          `json:"id"`
+		Description   string       `json:"description" binding:"required"`
+		Notes         string       `json:"notes"`
+		Quantity      int          `json:"quantity" binding:"required,min=1"`
+		Unit          string       `json:"unit" binding:"omitempty,oneof=post video story hour item package"`
+		UnitPrice     money.Amount `json:"unit_price" binding:"min=0"`
+		Category      string       `json:"category" binding:"omitempty,oneof=influencer_fee production ads_boost other"`
+		DiscountType  string       `json:"discount_type" binding:"omitempty,oneof=percent fixed"`
+		DiscountValue money.Amount `json:"discount_value" binding:"min=0"`
+		VatExempt     bool         `json:"vat_exempt"`
 	}
 
+	// CreateIncomeRequest creates an income at the stage its fields reach:
+	// a quotation needs only the quotation fields, an invoice its invoice
+	// dates as well and a receipt its receipt number and date too. Later
+	// stages are reached with the convert-to-invoice and issue-receipt
+	// actions.
 	CreateIncomeRequest struct {
-		QuotationIdNumber          int       `json:"quotation_id_number" binding:"required"`
-		QuotationIssueDate         time.Time `json:"quotation_issue_date" binding:"required"`
-		QuotationDueDate           time.Time `json:"quotation_due_date" binding:"required"`
-		InvoiceIdNumber            int       `json:"invoice_id_number" binding:"required"`
-		InvoiceIssueDate           time.Time `json:"invoice_issue_date" binding:"required"`
-		InvoiceDueDate             time.Time `json:"invoice_due_date" binding:"required"`
-		ReceiptIssueDate           time.Time `json:"receipt_issue_date" binding:"required"`
-		ReceiptIdNumber            int       `json:"receipt_id_number" binding:"required"`
-		AgencyTaxPayerIdNumber     int       `json:"agency_tax_payer_id_number" binding:"required"`
-		InfluencerPostingDate      time.Time `json:"influencer_posting_date" binding:"required"`
-		AgencyAgencyName           string    `json:"agency_agency_name" binding:"required"`
-		AgencyAddress              string    `json:"agency_address" binding:"required"`
-		AgencyPhoneNumber          string    `json:"agency_phone_number" binding:"required"`
-		ContactorContactorName     string    `json:"contactor_contactor_name" binding:"required"`
-		ContactorPhoneNumber       string    `json:"contactor_phone_number" binding:"required"`
-		ContactorLine              string    `json:"contactor_line" binding:"required"`
-		ContactorEmail             string    `json:"contactor_email" binding:"required"`
-		BrandBrandName             string    `json:"brand_brand_name" binding:"required"`
-		BrandProduct               string    `json:"brand_product" binding:"required"`
-		TransactionReferenceNumber int       `json:"transaction_reference_number" binding:"required"`
-		TermsAndConditions         string    `json:"terms_and_conditions" binding:"required"`
-		TotalPaymentAmount         int       `json:"total_payment_amount" binding:"required"`
-		NotesForTheTotalPayment    string    `json:"notes_for_the_total_payment" binding:"required"`
-		FirstPayment               int       `json:"first_payment" binding:"required"`
-		NotesForTheFirstPayment    string    `json:"notes_for_the_first_payment" binding:"required"`
-		SecondPayment              int       `json:"second_payment" binding:"required"`
-		NotesForTheSecondPayment   string    `json:"notes_for_the_second_payment" binding:"required"`
-		UnpaidPaymentAmount        int       `json:"unpaid_payment_amount"`
-		NotesForTheUnpaidPayment   string    `json:"notes_for_the_unpaid_payment" binding:"required"`
-
-		PlatformId      int `json:"platform_id" binding:"required"`
-		StatusId        int `json:"status_id" binding:"required"`
-		PaymentMethodId int `json:"payment_method_id" binding:"required"`
-		ReceiverId      int `json:"receiver_id" binding:"required"`
-		SalePersonId    int `json:"sale_person_id" binding:"required"`
-		ChannelId       int `json:"channel_id" binding:"required"`
-		BankId          int `json:"bank_id" binding:"required"`
+		QuotationIdNumber          int          `json:"quotation_id_number" binding:"omitempty,min=1" doc:"The next free number when a quotation leaves it out"`
+		QuotationIssueDate         time.Time    `json:"quotation_issue_date" binding:"required"`
+		QuotationDueDate           time.Time    `json:"quotation_due_date" binding:"required"`
+		InvoiceIdNumber            int          `json:"invoice_id_number" binding:"omitempty,min=1" doc:"The next free number when an invoice leaves it out; not for quotations, which are numbered when converted"`
+		InvoiceIssueDate           time.Time    `json:"invoice_issue_date" binding:"required_with=InvoiceDueDate ReceiptIdNumber"`
+		InvoiceDueDate             time.Time    `json:"invoice_due_date" binding:"required_with=InvoiceIssueDate"`
+		ReceiptIssueDate           time.Time    `json:"receipt_issue_date" binding:"required_with=ReceiptIdNumber" doc:"Not for quotations; on an invoice, the day it was paid"`
+		ReceiptIdNumber            int          `json:"receipt_id_number" binding:"omitempty,min=1"`
+		AgencyTaxPayerIdNumber     taxid.ID     `json:"agency_tax_payer_id_number" binding:"required_without=AgencyId,omitempty,taxid"`
+		AgencyBranchNumber         string       `json:"agency_branch_number" binding:"omitempty,branch" doc:"00000 for the head office, the default"`
+		InfluencerPostingDate      time.Time    `json:"influencer_posting_date"`
+		AgencyAgencyName           string       `json:"agency_agency_name" binding:"required_without=AgencyId"`
+		AgencyAddress              string       `json:"agency_address" binding:"required_without=AgencyId"`
+		AgencyPhoneNumber          string       `json:"agency_phone_number" binding:"required_without=AgencyId"`
+		ContactorContactorName     string       `json:"contactor_contactor_name" binding:"required_without=ContactId"`
+		ContactorPhoneNumber       string       `json:"contactor_phone_number" binding:"required_without=ContactId"`
+		ContactorLine              string       `json:"contactor_line" binding:"required_without=ContactId"`
+		ContactorEmail             string       `json:"contactor_email" binding:"required_without=ContactId"`
+		BrandBrandName             string       `json:"brand_brand_name" binding:"required_without=BrandId"`
+		BrandProduct               string       `json:"brand_product" doc:"Required once the income is invoiced"`
+		TransactionReferenceNumber int          `json:"transaction_reference_number"`
+		TermsAndConditions         string       `json:"terms_and_conditions" doc:"Required once the income is invoiced"`
+		TotalPaymentAmount         money.Amount `json:"total_payment_amount" doc:"Required once the income is invoiced"`
+		NotesForTheTotalPayment    string       `json:"notes_for_the_total_payment"`
+		FirstPayment               money.Amount `json:"first_payment" binding:"min=0"`
+		NotesForTheFirstPayment    string       `json:"notes_for_the_first_payment"`
+		SecondPayment              money.Amount `json:"second_payment" binding:"min=0"`
+		NotesForTheSecondPayment   string       `json:"notes_for_the_second_payment"`
+		UnpaidPaymentAmount        money.Amount `json:"unpaid_payment_amount"`
+		NotesForTheUnpaidPayment   string       `json:"notes_for_the_unpaid_payment"`
+		Currency                   string       `json:"currency" binding:"omitempty,iso4217"`
+		DiscountType               string       `json:"discount_type" binding:"omitempty,oneof=percent fixed"`
+		DiscountValue              money.Amount `json:"discount_value" binding:"min=0"`
+
+		// The client withholds WithholdingTaxRate percent of the amount
+		// before VAT. The certificate fields record the 50 Tawi certificate
+		// once it is received.
+		WithholdingTaxRate           money.Amount `json:"withholding_tax_rate" binding:"min=0" doc:"Percent withheld by the client, e.g. 3"`
+		WithholdingCertificateNumber string       `json:"withholding_certificate_number" binding:"max=50"`
+		WithholdingCertificateDate   time.Time    `json:"withholding_certificate_date"`
+
+		PlatformId      int `json:"platform_id" doc:"Required once the income is invoiced"`
+		StatusId        int `json:"status_id" doc:"Required once the income is invoiced"`
+		PaymentMethodId int `json:"payment_method_id" doc:"Required once the income is invoiced"`
+		ReceiverId      int `json:"receiver_id" doc:"Required once the income is invoiced"`
+		SalePersonId    int `json:"sale_person_id" doc:"Required once the income is invoiced"`
+		ChannelId       int `json:"channel_id" doc:"Required once the income is invoiced"`
+		BankId          int `json:"bank_id" doc:"Required once the income is invoiced"`
+
+		// AgencyId, ContactId and BrandId link the income to master data.
+		// The referenced records' current values are copied into the agency,
+		// contactor and brand fields, which may then be left out.
+		AgencyId  *int `json:"agency_id"`
+		ContactId *int `json:"contact_id"`
+		BrandId   *int `json:"brand_id"`
+
+		// Details are created together with the income. When ValidateTotal
+		// is set, the grand total after discounts and VAT must equal
+		// TotalPaymentAmount.
+		Details       []IncomeDetailRequest `json:"details" binding:"omitempty,dive"`
+		ValidateTotal bool                  `json:"validate_total"`
 	}
 
 	UpdateIncomeRequest struct {
-		QuotationIdNumber          int       `json:"quotation_id_number"`
-		QuotationIssueDate         time.Time `json:"quotation_issue_date"`
-		QuotationDueDate           time.Time `json:"quotation_due_date"`
-		InvoiceIdNumber            int       `json:"invoice_id_number"`
-		InvoiceIssueDate           time.Time `json:"invoice_issue_date"`
-		InvoiceDueDate             time.Time `json:"invoice_due_date"`
-		ReceiptIssueDate           time.Time `json:"receipt_issue_date"`
-		ReceiptIdNumber            int       `json:"receipt_id_number"`
-		AgencyTaxPayerIdNumber     int       `json:"agency_tax_payer_id_number"`
-		InfluencerPostingDate      time.Time `json:"influencer_posting_date"`
-		AgencyAgencyName           string    `json:"agency_agency_name"`
-		AgencyAddress              string    `json:"agency_address"`
-		AgencyPhoneNumber          string    `json:"agency_phone_number"`
-		ContactorContactorName     string    `json:"contactor_contactor_name"`
-		ContactorPhoneNumber       string    `json:"contactor_phone_number"`
-		ContactorLine              string    `json:"contactor_line"`
-		ContactorEmail             string    `json:"contactor_email"`
-		BrandBrandName             string    `json:"brand_brand_name"`
-		BrandProduct               string    `json:"brand_product"`
-		TransactionReferenceNumber int       `json:"transaction_reference_number"`
-		TermsAndConditions         string    `json:"terms_and_conditions"`
-		TotalPaymentAmount         int       `json:"total_payment_amount"`
-		NotesForTheTotalPayment    string    `json:"notes_for_the_total_payment"`
-		FirstPayment               int       `json:"first_payment"`
-		NotesForTheFirstPayment    string    `json:"notes_for_the_first_payment"`
-		SecondPayment              int       `json:"second_payment"`
-		NotesForTheSecondPayment   string    `json:"notes_for_the_second_payment"`
-		UnpaidPaymentAmount        int       `json:"unpaid_payment_amount"`
-		NotesForTheUnpaidPayment   string    `json:"notes_for_the_unpaid_payment"`
+		QuotationIdNumber          int          `json:"quotation_id_number" binding:"omitempty,min=1" doc:"Renumbering a quotation moves it to the negative of its new number"`
+		QuotationIssueDate         time.Time    `json:"quotation_issue_date"`
+		QuotationDueDate           time.Time    `json:"quotation_due_date"`
+		InvoiceIdNumber            int          `json:"invoice_id_number" doc:"Not for quotations; they are numbered by convert-to-invoice"`
+		InvoiceIssueDate           time.Time    `json:"invoice_issue_date" doc:"Not for quotations; they are dated by convert-to-invoice"`
+		InvoiceDueDate             time.Time    `json:"invoice_due_date" doc:"Not for quotations; they are dated by convert-to-invoice"`
+		ReceiptIssueDate           time.Time    `json:"receipt_issue_date" doc:"Not for quotations; on an invoice, the day it was paid, which its receipt takes"`
+		ReceiptIdNumber            int          `json:"receipt_id_number" doc:"Receipts only; invoices are numbered by issue-receipt"`
+		AgencyTaxPayerIdNumber     taxid.ID     `json:"agency_tax_payer_id_number" binding:"omitempty,taxid"`
+		AgencyBranchNumber         string       `json:"agency_branch_number" binding:"omitempty,branch"`
+		InfluencerPostingDate      time.Time    `json:"influencer_posting_date"`
+		AgencyAgencyName           string       `json:"agency_agency_name"`
+		AgencyAddress              string       `json:"agency_address"`
+		AgencyPhoneNumber          string       `json:"agency_phone_number"`
+		ContactorContactorName     string       `json:"contactor_contactor_name"`
+		ContactorPhoneNumber       string       `json:"contactor_phone_number"`
+		ContactorLine              string       `json:"contactor_line"`
+		ContactorEmail             string       `json:"contactor_email"`
+		BrandBrandName             string       `json:"brand_brand_name"`
+		BrandProduct               string       `json:"brand_product"`
+		TransactionReferenceNumber int          `json:"transaction_reference_number"`
+		TermsAndConditions         string       `json:"terms_and_conditions"`
+		TotalPaymentAmount         money.Amount `json:"total_payment_amount"`
+		NotesForTheTotalPayment    string       `json:"notes_for_the_total_payment"`
+		FirstPayment               money.Amount `json:"first_payment"`
+		NotesForTheFirstPayment    string       `json:"notes_for_the_first_payment"`
+		SecondPayment              money.Amount `json:"second_payment"`
+		NotesForTheSecondPayment   string       `json:"notes_for_the_second_payment"`
+		UnpaidPaymentAmount        money.Amount `json:"unpaid_payment_amount"`
+		NotesForTheUnpaidPayment   string       `json:"notes_for_the_unpaid_payment"`
+		Currency                   string       `json:"currency" binding:"omitempty,iso4217"`
+		// DiscountType "none" removes the document discount.
+		DiscountType  string       `json:"discount_type" binding:"omitempty,oneof=none percent fixed"`
+		DiscountValue money.Amount `json:"discount_value" binding:"min=0"`
+
+		// A withholding tax rate of 0 clears the withholding.
+		WithholdingTaxRate           *money.Amount `json:"withholding_tax_rate" binding:"omitempty,min=0"`
+		WithholdingCertificateNumber string        `json:"withholding_certificate_number" binding:"max=50"`
+		WithholdingCertificateDate   time.Time     `json:"withholding_certificate_date"`
 
 		PlatformId      int `json:"platform_id"`
 		StatusId        int `json:"status_id"`
@@ -121,9 +268,60 @@ type (
 		SalePersonId    int `json:"sale_person_id"`
 		ChannelId       int `json:"channel_id"`
 		BankId          int `json:"bank_id"`
+
+		// Setting AgencyId, ContactId or BrandId copies the referenced
+		// record's current values into the snapshot fields.
+		AgencyId  *int `json:"agency_id"`
+		ContactId *int `json:"contact_id"`
+		BrandId   *int `json:"brand_id"`
+
+		// Details, when present, replace the income's lines: lines with an id
+		// are updated, lines without one are added and omitted lines are
+		// deleted. Leaving details out keeps the current lines.
+		Details       []IncomeDetailRequest `json:"details" binding:"omitempty,dive"`
+		ValidateTotal bool                  `json:"validate_total"`
+	}
+
+	// PromptPay is the QR payload paying an income's outstanding balance
+	// into the receiver's PromptPay account. When the client withholds tax,
+	// Amount is the balance less the withholding tax, the transfer the bank
+	// reconciliation accepts as settling the balance.
+	PromptPay struct {
+		IncomeInvoiceIdNumber int          `json:"income_invoice_id_number"`
+		ReceiverName          string       `json:"receiver_name"`
+		PromptPayId           string       `json:"promptpay_id"`
+		UnpaidPaymentAmount   money.Amount `json:"unpaid_payment_amount" doc:"Outstanding balance of the income"`
+		WithholdingTaxAmount  money.Amount `json:"withholding_tax_amount" doc:"Withholding tax deducted from the balance, 0 when the client withholds none"`
+		Amount                money.Amount `json:"amount" doc:"Amount encoded in the QR: unpaid_payment_amount less withholding_tax_amount"`
+		Currency              string       `json:"currency"`
+		Payload               string       `json:"payload"`
+	}
+
+	PromptPayQRQuery struct {
+		Size int `json:"size" form:"size" binding:"omitempty,min=128,max=1024" doc:"Width of the image in pixels, 256 by default"`
+	}
+
+	// ETaxInvoiceQuery picks the e-Tax document of an income: the tax
+	// invoice sent with the invoice, or the receipt and tax invoice issued
+	// once it is paid.
+	ETaxInvoiceQuery struct {
+		Type string `json:"type" form:"type" binding:"omitempty,oneof=tax_invoice receipt" doc:"tax_invoice, the default, or receipt once the income is paid"`
+		Sign bool   `json:"sign" form:"sign" doc:"Sign the document with the certificate in ETAX_CERT_PATH"`
+	}
+
+	// ConvertToInvoiceRequest turns a quotation into an invoice, which
+	// moves from the quotation's provisional key to its invoice number.
+	ConvertToInvoiceRequest struct {
+		InvoiceIdNumber  int       `json:"invoice_id_number" binding:"omitempty,min=1" doc:"The next free number when left out"`
+		InvoiceIssueDate time.Time `json:"invoice_issue_date" doc:"Today when left out"`
+		InvoiceDueDate   time.Time `json:"invoice_due_date" binding:"required"`
 	}
 
-	IncomeResponse struct {
-		InvoiceIdNumber int `json:"invoice_id_number"`
+	// IssueReceiptRequest issues the receipt of a paid invoice.
+	IssueReceiptRequest struct {
+		ReceiptIdNumber         int          `json:"receipt_id_number" binding:"omitempty,min=1" doc:"The next free number when left out"`
+		ReceiptIssueDate        time.Time    `json:"receipt_issue_date" doc:"The date the invoice was paid in full, or today, when left out"`
+		FinalPayment            money.Amount `json:"final_payment" binding:"omitempty,min=0" doc:"A payment received outside bank reconciliation that settles the unpaid balance, less any withholding tax"`
+		NotesForTheFinalPayment string       `json:"notes_for_the_final_payment" binding:"omitempty,max=255"`
 	}
 )
@@ -0,0 +1,48 @@
+package dtos
+
+import "time"
+
+type (
+	InfluencerAssignment struct {
+		Id                    int       `json:"id"`
+		InfluencerId          int       `json:"influencer_id"`
+		InfluencerName        string    `json:"influencer_name"`
+		IncomeInvoiceIdNumber int       `json:"income_invoice_id_number"`
+		DetailId              *int      `json:"detail_id"`
+		Deliverables          string    `json:"deliverables"`
+		PostingDate           time.Time `json:"posting_date"`
+		Notes                 string    `json:"notes"`
+	}
+
+	CreateInfluencerAssignmentRequest struct {
+		InfluencerId          int       `json:"influencer_id" binding:"required"`
+		IncomeInvoiceIdNumber int       `json:"income_invoice_id_number" binding:"required"`
+		DetailId              *int      `json:"detail_id" doc:"Assign the influencer to one line item instead of the whole income"`
+		Deliverables          string    `json:"deliverables"`
+		PostingDate           time.Time `json:"posting_date" doc:"Defaults to the income's influencer posting date"`
+		Notes                 string    `json:"notes"`
+	}
+
+	UpdateInfluencerAssignmentRequest struct {
+		InfluencerId int       `json:"influencer_id"`
+		DetailId     *int      `json:"detail_id" doc:"0 moves the assignment back to the whole income"`
+		Deliverables string    `json:"deliverables"`
+		PostingDate  time.Time `json:"posting_date"`
+		Notes        string    `json:"notes"`
+	}
+
+	// InfluencerAssignmentQuery filters assignments. From and To bound the
+	// posting date, both inclusive; Quarter, such as 2026-Q3, sets both.
+	InfluencerAssignmentQuery struct {
+		InfluencerId          int       `json:"influencer_id" form:"influencer_id" doc:"Only assignments of this influencer"`
+		IncomeInvoiceIdNumber int       `json:"income_invoice_id_number" form:"income_invoice_id_number" doc:"Only assignments on this income"`
+		PlatformId            int       `json:"platform_id" form:"platform_id" doc:"Only jobs on this platform"`
+		From                  time.Time `json:"from" form:"from" time_format:"2006-01-02" doc:"First posting date"`
+		To                    time.Time `json:"to" form:"to" time_format:"2006-01-02" doc:"Last posting date"`
+		Quarter               string    `json:"quarter" form:"quarter" doc:"A quarter such as 2026-Q3; overrides from and to"`
+	}
+
+	InfluencerAssignmentResponse struct {
+		Id int `json:"id"`
+	}
+)
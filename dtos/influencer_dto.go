@@ -0,0 +1,84 @@
+package dtos
+
+import (
+	"mtii-backend/money"
+	"time"
+)
+
+type (
+	Influencer struct {
+		Id                int                `json:"id"`
+		Name              string             `json:"name"`
+		Email             string             `json:"email"`
+		PhoneNumber       string             `json:"phone_number"`
+		BankId            *int               `json:"bank_id"`
+		BankAccountName   string             `json:"bank_account_name"`
+		BankAccountNumber string             `json:"bank_account_number"`
+		Handles           []InfluencerHandle `json:"handles"`
+		RateCard          []InfluencerRate   `json:"rate_card"`
+	}
+
+	InfluencerHandle struct {
+		PlatformId    int    `json:"platform_id" binding:"required"`
+		Handle        string `json:"handle" binding:"required"`
+		FollowerCount int64  `json:"follower_count" binding:"min=0"`
+	}
+
+	InfluencerRate struct {
+		PlatformId  *int         `json:"platform_id"`
+		Deliverable string       `json:"deliverable" binding:"required,oneof=post video story live reel package"`
+		Rate        money.Amount `json:"rate" binding:"min=0" doc:"In the base currency"`
+	}
+
+	CreateInfluencerRequest struct {
+		Name              string             `json:"name" binding:"required"`
+		Email             string             `json:"email" binding:"omitempty,email"`
+		PhoneNumber       string             `json:"phone_number"`
+		BankId            *int               `json:"bank_id"`
+		BankAccountName   string             `json:"bank_account_name"`
+		BankAccountNumber string             `json:"bank_account_number" binding:"omitempty,numeric"`
+		Handles           []InfluencerHandle `json:"handles" binding:"dive"`
+		RateCard          []InfluencerRate   `json:"rate_card" binding:"dive"`
+	}
+
+	// UpdateInfluencerRequest changes the fields that are set. Handles and
+	// RateCard, when present, replace the stored lists; an empty list clears
+	// them.
+	UpdateInfluencerRequest struct {
+		Name              string             `json:"name"`
+		Email             string             `json:"email" binding:"omitempty,email"`
+		PhoneNumber       string             `json:"phone_number"`
+		BankId            *int               `json:"bank_id"`
+		BankAccountName   string             `json:"bank_account_name"`
+		BankAccountNumber string             `json:"bank_account_number" binding:"omitempty,numeric"`
+		Handles           []InfluencerHandle `json:"handles" binding:"omitempty,dive"`
+		RateCard          []InfluencerRate   `json:"rate_card" binding:"omitempty,dive"`
+	}
+
+	// InfluencerQuery filters the influencer list to those with a handle on
+	// a platform.
+	InfluencerQuery struct {
+		PlatformId int `json:"platform_id" form:"platform_id" doc:"Only influencers with a handle on this platform"`
+	}
+
+	InfluencerResponse struct {
+		Id int `json:"id"`
+	}
+
+	// InfluencerJob is one assignment of an influencer together with the
+	// income it belongs to.
+	InfluencerJob struct {
+		AssignmentId          int       `json:"assignment_id"`
+		IncomeInvoiceIdNumber int       `json:"income_invoice_id_number"`
+		DetailId              *int      `json:"detail_id"`
+		DetailDescription     string    `json:"detail_description"`
+		AgencyName            string    `json:"agency_name"`
+		BrandName             string    `json:"brand_name"`
+		BrandProduct          string    `json:"brand_product"`
+		Platform              Platform  `json:"platform"`
+		Status                Status    `json:"status"`
+		Deliverables          string    `json:"deliverables"`
+		PostingDate           time.Time `json:"posting_date"`
+		Notes                 string    `json:"notes"`
+	}
+)
@@ -1,29 +1,43 @@
 package dtos
 
+import "mtii-backend/taxid"
+
 type (
 	Receiver struct {
-		Id         int    `json:"id"`
-		Name       string `json:"name"`
-		Address    string `json:"address"`
-		Email      string `json:"email"`
-		Phone      string `json:"phone"`
-		TaxPayerId string `json:"tax_payer_id"`
+		Id           int      `json:"id"`
+		Name         string   `json:"name"`
+		Address      string   `json:"address"`
+		Email        string   `json:"email"`
+		Phone        string   `json:"phone"`
+		TaxPayerId   taxid.ID `json:"tax_payer_id"`
+		BranchNumber string   `json:"branch_number"`
+		PromptPayId  string   `json:"promptpay_id"`
 	}
 
 	CreateReceiverRequest struct {
-		Name       string `json:"name" binding:"required"`
-		Address    string `json:"address" binding:"required"`
-		Email      string `json:"email" binding:"required"`
-		Phone      string `json:"phone" binding:"required"`
-		TaxPayerId string `json:"tax_payer_id" binding:"required"`
+		Name         string   `json:"name" binding:"required"`
+		Address      string   `json:"address" binding:"required"`
+		Email        string   `json:"email" binding:"required"`
+		Phone        string   `json:"phone" binding:"required"`
+		TaxPayerId   taxid.ID `json:"tax_payer_id" binding:"required,taxid"`
+		BranchNumber string   `json:"branch_number" binding:"omitempty,branch" doc:"00000 for the head office, the default"`
+		PromptPayId  string   `json:"promptpay_id" binding:"omitempty,promptpay" doc:"Mobile number or tax ID of the PromptPay account clients pay into"`
 	}
 
 	UpdateReceiverRequest struct {
-		Name       string `json:"name"`
-		Address    string `json:"address"`
-		Email      string `json:"email"`
-		Phone      string `json:"phone"`
-		TaxPayerId string `json:"tax_payer_id"`
+		Name         string   `json:"name"`
+		Address      string   `json:"address"`
+		Email        string   `json:"email"`
+		Phone        string   `json:"phone"`
+		TaxPayerId   taxid.ID `json:"tax_payer_id" binding:"omitempty,taxid"`
+		BranchNumber string   `json:"branch_number" binding:"omitempty,branch"`
+		PromptPayId  string   `json:"promptpay_id" binding:"omitempty,promptpay"`
+	}
+
+	// ReceiverQuery filters the receiver list. TaxId matches a whole tax ID
+	// or, with fewer than 13 digits, its prefix.
+	ReceiverQuery struct {
+		TaxId string `json:"tax_id" form:"tax_id" binding:"omitempty,numeric,max=13" doc:"Tax ID, or the leading digits of one"`
 	}
 
 	ReceiverResponse struct {
@@ -0,0 +1,219 @@
+package dtos
+
+import (
+	"mtii-backend/money"
+	"time"
+)
+
+type (
+	// ProfitabilityQuery selects the incomes invoiced between From and To,
+	// both inclusive, and how to group them.
+	ProfitabilityQuery struct {
+		GroupBy string    `json:"group_by" form:"group_by" binding:"omitempty,oneof=platform channel sale_person" doc:"platform (default), channel or sale_person"`
+		From    time.Time `json:"from" form:"from" time_format:"2006-01-02" doc:"First invoice date"`
+		To      time.Time `json:"to" form:"to" time_format:"2006-01-02" doc:"Last invoice date"`
+	}
+
+	// ProfitabilityReport totals revenue, expenses and gross margin per
+	// group in the base currency. Incomes whose currency has no rate on
+	// their invoice date cannot be converted; they are listed in
+	// UnconvertedInvoices and left out of the totals.
+	ProfitabilityReport struct {
+		GroupBy             string             `json:"group_by"`
+		From                time.Time          `json:"from"`
+		To                  time.Time          `json:"to"`
+		Currency            string             `json:"currency"`
+		Rows                []ProfitabilityRow `json:"rows"`
+		Total               ProfitabilityRow   `json:"total"`
+		UnconvertedInvoices []int              `json:"unconverted_invoices"`
+	}
+
+	ProfitabilityRow struct {
+		Id                 int          `json:"id"`
+		Name               string       `json:"name"`
+		Jobs               int          `json:"jobs"`
+		Revenue            money.Amount `json:"revenue"`
+		Expenses           money.Amount `json:"expenses"`
+		GrossMargin        money.Amount `json:"gross_margin"`
+		GrossMarginPercent money.Amount `json:"gross_margin_percent"`
+	}
+)
+
+type (
+	// RevenueQuery selects the incomes whose DateBasis date falls between
+	// From and To, both inclusive, and how to group them.
+	RevenueQuery struct {
+		GroupBy   string    `json:"group_by" form:"group_by" binding:"omitempty,oneof=month quarter year platform channel sale_person status payment_method receiver bank" doc:"month by default"`
+		DateBasis string    `json:"date_basis" form:"date_basis" binding:"omitempty,oneof=invoice receipt posting" doc:"Date the period is read from: invoice (default), receipt or posting"`
+		From      time.Time `json:"from" form:"from" time_format:"2006-01-02" doc:"First date"`
+		To        time.Time `json:"to" form:"to" time_format:"2006-01-02" doc:"Last date"`
+	}
+
+	// RevenueReport totals invoiced, received and outstanding amounts per
+	// group in the base currency. Incomes whose currency has no rate on
+	// their invoice date are counted in UnconvertedInvoices and left out
+	// of the amounts.
+	RevenueReport struct {
+		GroupBy             string       `json:"group_by"`
+		DateBasis           string       `json:"date_basis"`
+		From                time.Time    `json:"from"`
+		To                  time.Time    `json:"to"`
+		Currency            string       `json:"currency"`
+		Rows                []RevenueRow `json:"rows"`
+		Total               RevenueRow   `json:"total"`
+		UnconvertedInvoices int          `json:"unconverted_invoices"`
+	}
+
+	// RevenueRow is one group: a period such as 2026-10, 2026-Q4 or 2026,
+	// or the id and name of a platform, channel, sale person, status,
+	// payment method, receiver or bank.
+	RevenueRow struct {
+		Key         string       `json:"key"`
+		Name        string       `json:"name"`
+		Invoices    int          `json:"invoices"`
+		Invoiced    money.Amount `json:"invoiced"`
+		Received    money.Amount `json:"received"`
+		Outstanding money.Amount `json:"outstanding"`
+	}
+)
+
+type (
+	// ARAgingQuery ages the balances owed at the end of AsOf, today when
+	// left out, per agency or per sale person.
+	ARAgingQuery struct {
+		AsOf    time.Time `json:"as_of" form:"as_of" time_format:"2006-01-02" doc:"Day the balances are aged on; today by default"`
+		GroupBy string    `json:"group_by" form:"group_by" binding:"omitempty,oneof=agency sale_person" doc:"agency (default) or sale_person"`
+	}
+
+	// ARAgingInvoiceQuery lists the invoices behind an aging report,
+	// optionally only those of an agency, a sale person or a bucket.
+	// AgencyName selects the invoices not linked to an agency by the agency
+	// name they carry, as the report groups them.
+	ARAgingInvoiceQuery struct {
+		AsOf         time.Time `json:"as_of" form:"as_of" time_format:"2006-01-02" doc:"Day the balances are aged on; today by default"`
+		AgencyId     int       `json:"agency_id" form:"agency_id" doc:"Only invoices of this agency"`
+		AgencyName   string    `json:"agency_name" form:"agency_name" doc:"Only invoices not linked to an agency that carry this agency name; Unassigned for those without one"`
+		SalePersonId int       `json:"sale_person_id" form:"sale_person_id" doc:"Only invoices of this sale person"`
+		Bucket       string    `json:"bucket" form:"bucket" binding:"omitempty,oneof=current 1_30 31_60 61_90 over_90" doc:"Only invoices in this bucket"`
+	}
+
+	// ARAgingReport splits what is owed by how many days it is past due,
+	// in the base currency. Invoices whose currency has no rate on their
+	// invoice date are listed in UnconvertedInvoices and left out.
+	ARAgingReport struct {
+		AsOf                time.Time    `json:"as_of"`
+		GroupBy             string       `json:"group_by"`
+		Currency            string       `json:"currency"`
+		Rows                []ARAgingRow `json:"rows"`
+		Total               ARAgingRow   `json:"total"`
+		UnconvertedInvoices []int        `json:"unconverted_invoices"`
+	}
+
+	// ARAgingRow is the balance of an agency or sale person by bucket. An
+	// agency row without an id groups incomes not linked to an agency by
+	// their agency name.
+	ARAgingRow struct {
+		Id         int          `json:"id"`
+		Name       string       `json:"name"`
+		Invoices   int          `json:"invoices"`
+		Current    money.Amount `json:"current"`
+		Days1To30  money.Amount `json:"days_1_30"`
+		Days31To60 money.Amount `json:"days_31_60"`
+		Days61To90 money.Amount `json:"days_61_90"`
+		Over90     money.Amount `json:"over_90"`
+		Total      money.Amount `json:"total"`
+	}
+
+	// ARAgingInvoice is one invoice with a balance. Balance is in the
+	// invoice's currency and BaseBalance in the base currency, or null
+	// when no rate converts it.
+	ARAgingInvoice struct {
+		InvoiceIdNumber  int           `json:"invoice_id_number"`
+		AgencyId         *int          `json:"agency_id"`
+		AgencyName       string        `json:"agency_name"`
+		SalePersonId     int           `json:"sale_person_id"`
+		SalePersonName   string        `json:"sale_person_name"`
+		InvoiceIssueDate time.Time     `json:"invoice_issue_date"`
+		InvoiceDueDate   time.Time     `json:"invoice_due_date"`
+		DaysPastDue      int           `json:"days_past_due"`
+		Bucket           string        `json:"bucket"`
+		Currency         string        `json:"currency"`
+		Balance          money.Amount  `json:"balance"`
+		BaseBalance      *money.Amount `json:"base_balance"`
+	}
+
+	// DSOQuery selects the months from From to To, both given as YYYY-MM.
+	// They default to the twelve months up to the current one.
+	DSOQuery struct {
+		From time.Time `json:"from" form:"from" time_format:"2006-01" doc:"First month, YYYY-MM; eleven months before to by default"`
+		To   time.Time `json:"to" form:"to" time_format:"2006-01" doc:"Last month, YYYY-MM; the current month by default"`
+	}
+
+	DSOReport struct {
+		Currency string     `json:"currency"`
+		Months   []DSOMonth `json:"months"`
+	}
+
+	// DSOMonth is the days sales outstanding of a month: what was owed at
+	// its end divided by what was invoiced in it, times its number of days.
+	// It is a number of days to two decimal places, null for a month
+	// without sales.
+	DSOMonth struct {
+		Month                string       `json:"month"`
+		Days                 int          `json:"days"`
+		Sales                money.Amount `json:"sales"`
+		Receivables          money.Amount `json:"receivables"`
+		DaysSalesOutstanding *float64     `json:"days_sales_outstanding"`
+	}
+)
+
+type (
+	// CashFlowQuery selects the weeks or months from From to To, both
+	// inclusive. They default to the two months before the current one
+	// through the three after it.
+	CashFlowQuery struct {
+		From          time.Time `json:"from" form:"from" time_format:"2006-01-02" doc:"First day; two months before the current one by default"`
+		To            time.Time `json:"to" form:"to" time_format:"2006-01-02" doc:"Last day; three months after the current one by default"`
+		Interval      string    `json:"interval" form:"interval" binding:"omitempty,oneof=week month" doc:"month (default) or week"`
+		ApplyLateness bool      `json:"apply_lateness" form:"apply_lateness" doc:"Shift due dates by each agency's average days late"`
+	}
+
+	// CashFlowReport projects cash inflows in the base currency. Incomes
+	// whose currency has no rate on their invoice date are listed in
+	// UnconvertedInvoices and left out.
+	CashFlowReport struct {
+		Interval            string               `json:"interval"`
+		From                time.Time            `json:"from"`
+		To                  time.Time            `json:"to"`
+		AsOf                time.Time            `json:"as_of"`
+		ApplyLateness       bool                 `json:"apply_lateness"`
+		Currency            string               `json:"currency"`
+		Periods             []CashFlowPeriod     `json:"periods"`
+		AgencyLateness      []AgencyLatenessItem `json:"agency_lateness"`
+		UnconvertedInvoices []int                `json:"unconverted_invoices"`
+	}
+
+	// CashFlowPeriod is one week or month. Scheduled is what fell due in
+	// it and Actual what was receipted in it. Forecast is the unpaid
+	// balance expected in it, for the current and later periods only;
+	// balances already overdue are expected in the current period.
+	// Variance is Actual less Scheduled, for periods that have ended.
+	CashFlowPeriod struct {
+		Label     string        `json:"label"`
+		Start     time.Time     `json:"start"`
+		End       time.Time     `json:"end"`
+		Scheduled money.Amount  `json:"scheduled"`
+		Actual    money.Amount  `json:"actual"`
+		Forecast  money.Amount  `json:"forecast"`
+		Variance  *money.Amount `json:"variance"`
+	}
+
+	// AgencyLatenessItem is how many days after the due date an agency
+	// pays on average, over its fully paid incomes.
+	AgencyLatenessItem struct {
+		AgencyId        *int   `json:"agency_id"`
+		AgencyName      string `json:"agency_name"`
+		Invoices        int    `json:"invoices"`
+		AverageDaysLate int    `json:"average_days_late"`
+	}
+)
@@ -0,0 +1,93 @@
+package dtos
+
+import (
+	"mtii-backend/money"
+	"mtii-backend/taxid"
+	"time"
+)
+
+type (
+	// TaxReportQuery selects a tax month, given as YYYY-MM. ReceiverId
+	// narrows the report to the incomes of one of our companies, which
+	// files its own returns. Format picks a file export instead of JSON.
+	TaxReportQuery struct {
+		Month      time.Time `json:"month" form:"month" time_format:"2006-01" binding:"required" doc:"Tax month, YYYY-MM"`
+		ReceiverId int       `json:"receiver_id" form:"receiver_id" doc:"Only the incomes billed by this receiver"`
+		DateBasis  string    `json:"date_basis" form:"date_basis" binding:"omitempty,oneof=invoice receipt" doc:"Tax point of the sales VAT report: invoice, the default, or receipt"`
+		Format     string    `json:"format" form:"format" binding:"omitempty,oneof=json csv xlsx pdf" doc:"json (default), or csv, xlsx or pdf to download the report"`
+	}
+
+	// SalesVatReport is the sales tax report (รายงานภาษีขาย) of a month:
+	// every tax invoice dated in it, in the base currency. Incomes in a
+	// currency with no rate on their tax point are listed in
+	// UnconvertedInvoices and left out.
+	SalesVatReport struct {
+		Month               string         `json:"month"`
+		DateBasis           string         `json:"date_basis"`
+		Receiver            *Receiver      `json:"receiver"`
+		Currency            string         `json:"currency"`
+		Lines               []SalesVatLine `json:"lines"`
+		Total               SalesVatTotals `json:"total"`
+		UnconvertedInvoices []int          `json:"unconverted_invoices"`
+	}
+
+	// SalesVatLine is one tax invoice. VatableAmount and VatExemptAmount
+	// are after discounts and before VAT.
+	SalesVatLine struct {
+		Sequence        int        `json:"sequence"`
+		Date            time.Time  `json:"date"`
+		InvoiceIdNumber int        `json:"invoice_id_number"`
+		ReceiptIdNumber int        `json:"receipt_id_number"`
+		CustomerName    string     `json:"customer_name"`
+		CustomerTaxId   taxid.ID   `json:"customer_tax_id"`
+		CustomerBranch  string     `json:"customer_branch"`
+		Currency        string     `json:"currency"`
+		Rate            money.Rate `json:"rate"`
+		SalesVatTotals
+	}
+
+	SalesVatTotals struct {
+		VatableAmount   money.Amount `json:"vatable_amount"`
+		VatExemptAmount money.Amount `json:"vat_exempt_amount"`
+		VatAmount       money.Amount `json:"vat_amount"`
+		Total           money.Amount `json:"total"`
+	}
+
+	// WithholdingTaxRegister lists the 50 Tawi certificates for tax our
+	// clients withheld, registered in the month of the certificate date or,
+	// until the certificate is received, of the receipt date. The total of
+	// the received certificates is the tax prepaid in the month.
+	WithholdingTaxRegister struct {
+		Month               string               `json:"month"`
+		Receiver            *Receiver            `json:"receiver"`
+		Currency            string               `json:"currency"`
+		Lines               []WithholdingTaxLine `json:"lines"`
+		Total               WithholdingTaxTotals `json:"total"`
+		UnconvertedInvoices []int                `json:"unconverted_invoices"`
+	}
+
+	WithholdingTaxLine struct {
+		Sequence           int          `json:"sequence"`
+		CertificateNumber  string       `json:"certificate_number"`
+		CertificateDate    time.Time    `json:"certificate_date"`
+		Received           bool         `json:"received"`
+		PayerName          string       `json:"payer_name"`
+		PayerTaxId         taxid.ID     `json:"payer_tax_id"`
+		PayerBranch        string       `json:"payer_branch"`
+		InvoiceIdNumber    int          `json:"invoice_id_number"`
+		ReceiptIdNumber    int          `json:"receipt_id_number"`
+		ReceiptIssueDate   time.Time    `json:"receipt_issue_date"`
+		WithholdingTaxRate money.Amount `json:"withholding_tax_rate"`
+		BaseAmount         money.Amount `json:"base_amount"`
+		TaxAmount          money.Amount `json:"tax_amount"`
+	}
+
+	// WithholdingTaxTotals sums the register. Received is the tax covered
+	// by certificates in hand and Outstanding the tax still waiting for one.
+	WithholdingTaxTotals struct {
+		BaseAmount  money.Amount `json:"base_amount"`
+		TaxAmount   money.Amount `json:"tax_amount"`
+		Received    money.Amount `json:"received"`
+		Outstanding money.Amount `json:"outstanding"`
+	}
+)
@@ -0,0 +1,52 @@
+package dtos
+
+import "mtii-backend/taxid"
+
+type (
+	Vendor struct {
+		Id                int      `json:"id"`
+		Name              string   `json:"name"`
+		Address           string   `json:"address"`
+		PhoneNumber       string   `json:"phone_number"`
+		Email             string   `json:"email"`
+		TaxPayerId        taxid.ID `json:"tax_payer_id"`
+		BranchNumber      string   `json:"branch_number"`
+		BankId            *int     `json:"bank_id"`
+		BankAccountName   string   `json:"bank_account_name"`
+		BankAccountNumber string   `json:"bank_account_number"`
+	}
+
+	CreateVendorRequest struct {
+		Name              string   `json:"name" binding:"required"`
+		Address           string   `json:"address"`
+		PhoneNumber       string   `json:"phone_number"`
+		Email             string   `json:"email" binding:"omitempty,email"`
+		TaxPayerId        taxid.ID `json:"tax_payer_id" binding:"omitempty,taxid"`
+		BranchNumber      string   `json:"branch_number" binding:"omitempty,branch" doc:"00000 for the head office, the default"`
+		BankId            *int     `json:"bank_id"`
+		BankAccountName   string   `json:"bank_account_name"`
+		BankAccountNumber string   `json:"bank_account_number" binding:"omitempty,numeric"`
+	}
+
+	UpdateVendorRequest struct {
+		Name              string   `json:"name"`
+		Address           string   `json:"address"`
+		PhoneNumber       string   `json:"phone_number"`
+		Email             string   `json:"email" binding:"omitempty,email"`
+		TaxPayerId        taxid.ID `json:"tax_payer_id" binding:"omitempty,taxid"`
+		BranchNumber      string   `json:"branch_number" binding:"omitempty,branch"`
+		BankId            *int     `json:"bank_id"`
+		BankAccountName   string   `json:"bank_account_name"`
+		BankAccountNumber string   `json:"bank_account_number" binding:"omitempty,numeric"`
+	}
+
+	// VendorQuery filters the vendor list. TaxId matches a whole tax ID or,
+	// with fewer than 13 digits, its prefix.
+	VendorQuery struct {
+		TaxId string `json:"tax_id" form:"tax_id" binding:"omitempty,numeric,max=13" doc:"Tax ID, or the leading digits of one"`
+	}
+
+	VendorResponse struct {
+		Id int `json:"id"`
+	}
+)
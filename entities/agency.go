@@ -0,0 +1,15 @@
+package entities
+
+import "mtii-backend/taxid"
+
+type Agency struct {
+	Id           int      `gorm:"primary_key;auto_increment" json:"id"`
+	Name         string   `gorm:"type:varchar(255);not null" json:"name"`
+	Address      string   `gorm:"type:varchar(255)" json:"address"`
+	PhoneNumber  string   `gorm:"type:varchar(255)" json:"phone_number"`
+	TaxPayerId   taxid.ID `gorm:"type:varchar(13);index" json:"tax_payer_id"`
+	BranchNumber string   `gorm:"type:varchar(5);not null;default:'00000'" json:"branch_number"`
+
+	Contacts []Contact `gorm:"foreignKey:AgencyId" json:"-"`
+	Brands   []Brand   `gorm:"foreignKey:AgencyId" json:"-"`
+}
@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"mtii-backend/money"
+	"time"
+)
+
+// BankStatementFormat describes the CSV statements a bank's online banking
+// exports. Columns are named by their header, which follows SkipRows lines
+// of preamble. When DebitColumn is empty, CreditColumn holds signed amounts
+// and only the positive ones are credits.
+type BankStatementFormat struct {
+	BankId            int    `gorm:"primary_key;autoIncrement:false" json:"bank_id"`
+	Bank              Bank   `gorm:"foreignKey:BankId;constraint:OnDelete:CASCADE" json:"-"`
+	Encoding          string `gorm:"type:varchar(16);not null;default:'utf-8'" json:"encoding"`
+	SkipRows          int    `gorm:"not null;default:0" json:"skip_rows"`
+	DateColumn        string `gorm:"type:varchar(100);not null" json:"date_column"`
+	DateLayout        string `gorm:"type:varchar(50);not null" json:"date_layout"`
+	DescriptionColumn string `gorm:"type:varchar(100);not null" json:"description_column"`
+	ReferenceColumn   string `gorm:"type:varchar(100);not null;default:''" json:"reference_column"`
+	CreditColumn      string `gorm:"type:varchar(100);not null" json:"credit_column"`
+	DebitColumn       string `gorm:"type:varchar(100);not null;default:''" json:"debit_column"`
+	Currency          string `gorm:"type:varchar(3);not null;default:'THB'" json:"currency"`
+}
+
+// BankStatement is an uploaded statement file. Only its credits are kept,
+// as BankTransactions.
+type BankStatement struct {
+	Id         int       `gorm:"primary_key;auto_increment" json:"id"`
+	BankId     int       `gorm:"not null;index" json:"bank_id"`
+	Bank       Bank      `gorm:"foreignKey:BankId" json:"-"`
+	FileName   string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	Credits    int       `gorm:"not null;default:0" json:"credits"`
+	Duplicates int       `gorm:"not null;default:0" json:"duplicates"`
+	CreatedAt  time.Time `gorm:"type:timestamp with time zone" json:"created_at"`
+}
+
+// BankTransaction is money received into one of our bank accounts. It is
+// unmatched until the matcher suggests incomes it may pay, pending while
+// those suggestions await review, and confirmed once one is accepted and
+// recorded as a payment of IncomeInvoiceIdNumber. Fingerprint identifies
+// the same statement line across uploads.
+type BankTransaction struct {
+	Id                    int                    `gorm:"primary_key;auto_increment" json:"id"`
+	BankStatementId       int                    `gorm:"not null;index" json:"bank_statement_id"`
+	BankStatement         BankStatement          `gorm:"foreignKey:BankStatementId;constraint:OnDelete:CASCADE" json:"-"`
+	BankId                int                    `gorm:"not null;index" json:"bank_id"`
+	Date                  time.Time              `gorm:"type:timestamp with time zone;not null" json:"date"`
+	Description           string                 `gorm:"type:varchar(255);not null;default:''" json:"description"`
+	Reference             string                 `gorm:"type:varchar(100);not null;default:''" json:"reference"`
+	Amount                money.Amount           `gorm:"type:numeric(18,2);not null" json:"amount"`
+	Currency              string                 `gorm:"type:varchar(3);not null" json:"currency"`
+	Fingerprint           string                 `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Status                string                 `gorm:"type:varchar(16);not null;index" json:"status"`
+	IncomeInvoiceIdNumber *int                   `gorm:"index" json:"income_invoice_id_number"`
+	Income                *Income                `gorm:"foreignKey:IncomeInvoiceIdNumber;constraint:OnDelete:SET NULL" json:"-"`
+	ConfirmedAt           time.Time              `gorm:"type:timestamp with time zone" json:"confirmed_at"`
+	Matches               []BankTransactionMatch `gorm:"foreignKey:BankTransactionId;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BankTransactionMatch suggests that a transaction pays an income. Score,
+// from 0 to 100, is the matcher's confidence and Reasons the comma-separated
+// signals behind it.
+type BankTransactionMatch struct {
+	Id                    int       `gorm:"primary_key;auto_increment" json:"id"`
+	BankTransactionId     int       `gorm:"not null;index" json:"bank_transaction_id"`
+	IncomeInvoiceIdNumber int       `gorm:"not null;index" json:"income_invoice_id_number"`
+	Income                Income    `gorm:"foreignKey:IncomeInvoiceIdNumber;constraint:OnDelete:CASCADE" json:"-"`
+	Score                 int       `gorm:"not null" json:"score"`
+	Reasons               string    `gorm:"type:varchar(255);not null;default:''" json:"reasons"`
+	Status                string    `gorm:"type:varchar(16);not null;index" json:"status"`
+	ReviewedAt            time.Time `gorm:"type:timestamp with time zone" json:"reviewed_at"`
+}
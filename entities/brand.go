@@ -0,0 +1,9 @@
+package entities
+
+type Brand struct {
+	Id   int    `gorm:"primary_key;auto_increment" json:"id"`
+	Name string `gorm:"type:varchar(255);not null" json:"name"`
+
+	AgencyId int    `gorm:"not null;index" json:"agency_id"`
+	Agency   Agency `gorm:"foreignKey:AgencyId" json:"-"`
+}
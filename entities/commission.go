@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"mtii-backend/money"
+	"time"
+)
+
+// CommissionPlan sets how much a sale person earns on the incomes it
+// covers. A flat plan pays Rate percent of revenue; a tiered plan pays the
+// rate of the highest tier reached by the month's revenue under the plan.
+// PlatformId and ChannelId, when set, restrict the plan to those incomes.
+type CommissionPlan struct {
+	Id         int              `gorm:"primary_key;auto_increment" json:"id"`
+	Name       string           `gorm:"type:varchar(255);not null" json:"name"`
+	Type       string           `gorm:"type:varchar(16);not null" json:"type"`
+	Rate       money.Amount     `gorm:"type:numeric(5,2);not null;default:0" json:"rate"`
+	PlatformId *int             `json:"platform_id"`
+	Platform   *Platform        `gorm:"foreignKey:PlatformId" json:"-"`
+	ChannelId  *int             `json:"channel_id"`
+	Channel    *Channel         `gorm:"foreignKey:ChannelId" json:"-"`
+	Tiers      []CommissionTier `gorm:"foreignKey:CommissionPlanId;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+type CommissionTier struct {
+	Id               int          `gorm:"primary_key;auto_increment" json:"id"`
+	CommissionPlanId int          `gorm:"not null;index" json:"commission_plan_id"`
+	MinRevenue       money.Amount `gorm:"type:numeric(18,2);not null" json:"min_revenue"`
+	Rate             money.Amount `gorm:"type:numeric(5,2);not null" json:"rate"`
+}
+
+// SalePersonCommissionPlan puts a sale person on a plan from EffectiveFrom
+// until EffectiveTo, both inclusive; a zero EffectiveTo is open-ended.
+type SalePersonCommissionPlan struct {
+	Id               int            `gorm:"primary_key;auto_increment" json:"id"`
+	SalePersonId     int            `gorm:"not null;index" json:"sale_person_id"`
+	SalePerson       SalePerson     `gorm:"foreignKey:SalePersonId" json:"-"`
+	CommissionPlanId int            `gorm:"not null;index" json:"commission_plan_id"`
+	CommissionPlan   CommissionPlan `gorm:"foreignKey:CommissionPlanId" json:"-"`
+	EffectiveFrom    time.Time      `gorm:"type:timestamp with time zone;not null" json:"effective_from"`
+	EffectiveTo      time.Time      `gorm:"type:timestamp with time zone" json:"effective_to"`
+}
+
+// Commission is what a sale person earned on one paid income. Commissions
+// are derived data: the engine rebuilds a sale person's month whenever one
+// of its incomes changes, so they carry no foreign key to the income.
+type Commission struct {
+	Id                    int            `gorm:"primary_key;auto_increment" json:"id"`
+	SalePersonId          int            `gorm:"not null;index:idx_commission_period" json:"sale_person_id"`
+	PaidDate              time.Time      `gorm:"type:timestamp with time zone;not null;index:idx_commission_period" json:"paid_date"`
+	IncomeInvoiceIdNumber int            `gorm:"not null;index" json:"income_invoice_id_number"`
+	CommissionPlanId      int            `gorm:"not null" json:"commission_plan_id"`
+	CommissionPlan        CommissionPlan `gorm:"foreignKey:CommissionPlanId" json:"-"`
+	Revenue               money.Amount   `gorm:"type:numeric(18,2);not null" json:"revenue"`
+	Rate                  money.Amount   `gorm:"type:numeric(5,2);not null" json:"rate"`
+	Amount                money.Amount   `gorm:"type:numeric(18,2);not null" json:"amount"`
+	CalculatedAt          time.Time      `gorm:"type:timestamp with time zone" json:"calculated_at"`
+}
@@ -0,0 +1,12 @@
+package entities
+
+type Contact struct {
+	Id          int    `gorm:"primary_key;auto_increment" json:"id"`
+	Name        string `gorm:"type:varchar(255);not null" json:"name"`
+	PhoneNumber string `gorm:"type:varchar(255)" json:"phone_number"`
+	Line        string `gorm:"type:varchar(255)" json:"line"`
+	Email       string `gorm:"type:varchar(255)" json:"email"`
+
+	AgencyId int    `gorm:"not null;index" json:"agency_id"`
+	Agency   Agency `gorm:"foreignKey:AgencyId" json:"-"`
+}
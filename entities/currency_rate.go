@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"mtii-backend/money"
+	"time"
+)
+
+// CurrencyRate is the rate to money.BaseCurrency of one currency from Date
+// until the next rate for that currency.
+type CurrencyRate struct {
+	Id       int        `gorm:"primary_key;auto_increment" json:"id"`
+	Date     time.Time  `gorm:"type:date;not null;uniqueIndex:idx_currency_rates_currency_date" json:"date"`
+	Currency string     `gorm:"type:varchar(3);not null;uniqueIndex:idx_currency_rates_currency_date" json:"currency"`
+	Rate     money.Rate `gorm:"type:numeric(18,6);not null" json:"rate"`
+}
@@ -1,11 +1,19 @@
 package entities
 
+import "mtii-backend/money"
+
 type Detail struct {
-	Id                    int    `gorm:"primary_key;auto_increment" json:"id"`
-	Description           string `gorm:"type:varchar(255)" json:"description"`
-	Notes                 string `gorm:"type:varchar(255)" json:"notes"`
-	Quantity              int    `json:"quantity"`
-	UnitPrice             int    `json:"unit_price"`
-	IncomeInvoiceIdNumber int    `json:"income_invoice_id_number"`
-	Income                Income `gorm:"foreignKey:IncomeInvoiceIdNumber" json:"-"`
+	Id                    int          `gorm:"primary_key;auto_increment" json:"id"`
+	Description           string       `gorm:"type:varchar(255)" json:"description"`
+	Notes                 string       `gorm:"type:varchar(255)" json:"notes"`
+	Quantity              int          `json:"quantity"`
+	UnitPrice             money.Amount `gorm:"type:numeric(18,2)" json:"unit_price"`
+	Unit                  string       `gorm:"type:varchar(32);not null;default:''" json:"unit"`
+	Category              string       `gorm:"type:varchar(32);not null;default:''" json:"category"`
+	DiscountType          string       `gorm:"type:varchar(16);not null;default:''" json:"discount_type"`
+	DiscountValue         money.Amount `gorm:"type:numeric(18,2);not null;default:0" json:"discount_value"`
+	VatExempt             bool         `gorm:"not null;default:false" json:"vat_exempt"`
+	Position              int          `gorm:"not null;default:0" json:"position"`
+	IncomeInvoiceIdNumber int          `json:"income_invoice_id_number"`
+	Income                Income       `gorm:"foreignKey:IncomeInvoiceIdNumber" json:"-"`
 }
@@ -0,0 +1,9 @@
+package entities
+
+// DocumentNumber is the last number issued in one numbering series of
+// income documents: quotations, invoices or receipts. Its row is locked
+// while the next number is taken, so no two documents share a number.
+type DocumentNumber struct {
+	Series string `gorm:"primary_key;type:varchar(16)" json:"series"`
+	Last   int    `gorm:"not null;default:0" json:"last"`
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"mtii-backend/money"
+	"time"
+)
+
+// Expense is money paid out of a job to an influencer or a vendor. Amounts
+// are in the currency of the income and exclude VAT; the withholding tax
+// is deducted from Amount when paying.
+type Expense struct {
+	Id                    int          `gorm:"primary_key;auto_increment" json:"id"`
+	IncomeInvoiceIdNumber int          `gorm:"not null;index" json:"income_invoice_id_number"`
+	Income                Income       `gorm:"foreignKey:IncomeInvoiceIdNumber" json:"-"`
+	InfluencerId          *int         `gorm:"index" json:"influencer_id"`
+	Influencer            *Influencer  `gorm:"foreignKey:InfluencerId" json:"-"`
+	VendorId              *int         `gorm:"index" json:"vendor_id"`
+	Vendor                *Vendor      `gorm:"foreignKey:VendorId" json:"-"`
+	Category              string       `gorm:"type:varchar(32);not null;default:''" json:"category"`
+	Description           string       `gorm:"type:varchar(255)" json:"description"`
+	Amount                money.Amount `gorm:"type:numeric(18,2);not null" json:"amount"`
+	WithholdingTaxRate    money.Amount `gorm:"type:numeric(5,2);not null;default:0" json:"withholding_tax_rate"`
+	WithholdingTaxAmount  money.Amount `gorm:"type:numeric(18,2);not null;default:0" json:"withholding_tax_amount"`
+	DueDate               time.Time    `gorm:"type:timestamp with time zone" json:"due_date"`
+	PaidDate              time.Time    `gorm:"type:timestamp with time zone" json:"paid_date"`
+
+	Attachments []ExpenseAttachment `gorm:"foreignKey:ExpenseId;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ExpenseAttachment is a receipt, invoice or other file kept with an
+// expense.
+type ExpenseAttachment struct {
+	Id          int       `gorm:"primary_key;auto_increment" json:"id"`
+	ExpenseId   int       `gorm:"not null;index" json:"expense_id"`
+	FileName    string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType string    `gorm:"type:varchar(255);not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	Data        []byte    `gorm:"type:bytea;not null" json:"-"`
+	CreatedAt   time.Time `gorm:"type:timestamp with time zone" json:"created_at"`
+}
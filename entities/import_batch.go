@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// ImportBatch groups the incomes brought in by one bulk import, so they
+// can be rolled back together. RolledBackAt stays zero until they are.
+type ImportBatch struct {
+	Id           int                 `gorm:"primary_key;auto_increment" json:"id"`
+	FileName     string              `gorm:"type:varchar(255);not null" json:"file_name"`
+	Source       string              `gorm:"type:varchar(16);not null" json:"source"`
+	Incomes      int                 `gorm:"not null;default:0" json:"incomes"`
+	CreatedAt    time.Time           `gorm:"type:timestamp with time zone" json:"created_at"`
+	RolledBackAt time.Time           `gorm:"type:timestamp with time zone" json:"rolled_back_at"`
+	Records      []ImportBatchRecord `gorm:"foreignKey:ImportBatchId;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ImportBatchRecord is a platform or status the import created because no
+// record had its name. Rolling the batch back removes it again unless
+// other incomes have started using it.
+type ImportBatchRecord struct {
+	Id            int    `gorm:"primary_key;auto_increment" json:"id"`
+	ImportBatchId int    `gorm:"not null;index" json:"import_batch_id"`
+	Kind          string `gorm:"type:varchar(16);not null" json:"kind"`
+	RecordId      int    `gorm:"not null" json:"record_id"`
+}
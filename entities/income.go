@@ -1,39 +1,59 @@
 package entities
 
 import (
+	"mtii-backend/money"
+	"mtii-backend/taxid"
 	"time"
 )
 
 type Income struct {
-	QuotationIdNumber          int       `json:"quotation_id_number"`
-	QuotationIssueDate         time.Time `gorm:"type:timestamp with time zone" json:"quotation_issue_date"`
-	QuotationDueDate           time.Time `gorm:"type:timestamp with time zone" json:"quotation_due_date"`
-	InvoiceIdNumber            int       `gorm:"primary_key;unique" json:"invoice_id_number"`
-	InvoiceIssueDate           time.Time `gorm:"type:timestamp with time zone" json:"invoice_issue_date"`
-	InvoiceDueDate             time.Time `gorm:"type:timestamp with time zone" json:"invoice_due_date"`
-	ReceiptIssueDate           time.Time `gorm:"type:timestamp with time zone" json:"receipt_issue_date"`
-	ReceiptIdNumber            int       `json:"receipt_id_number"`
-	AgencyTaxPayerIdNumber     int       `json:"agency_tax_payer_id_number"`
-	InfluencerPostingDate      time.Time `gorm:"type:timestamp with time zone" json:"influencer_posting_date"`
-	AgencyAgencyName           string    `gorm:"type:varchar(255)" json:"agency_agency_name"`
-	AgencyAddress              string    `gorm:"type:varchar(255)" json:"agency_address"`
-	AgencyPhoneNumber          string    `gorm:"type:varchar(255)" json:"agency_phone_number"`
-	ContactorContactorName     string    `gorm:"type:varchar(255)" json:"contactor_contactor_name"`
-	ContactorPhoneNumber       string    `gorm:"type:varchar(255)" json:"contactor_phone_number"`
-	ContactorLine              string    `gorm:"type:varchar(255)" json:"contactor_line"`
-	ContactorEmail             string    `gorm:"type:varchar(255)" json:"contactor_email"`
-	BrandBrandName             string    `gorm:"type:varchar(255)" json:"brand_brand_name"`
-	BrandProduct               string    `gorm:"type:varchar(255)" json:"brand_product"`
-	TransactionReferenceNumber int       `json:"transaction_reference_number"`
-	TermsAndConditions         string    `gorm:"type:varchar(255)" json:"terms_and_conditions"`
-	TotalPaymentAmount         int       `json:"total_payment_amount"`
-	NotesForTheTotalPayment    string    `gorm:"type:varchar(255)" json:"notes_for_the_total_payment"`
-	FirstPayment               int       `json:"first_payment"`
-	NotesForTheFirstPayment    string    `gorm:"type:varchar(255)" json:"notes_for_the_first_payment"`
-	SecondPayment              int       `json:"second_payment"`
-	NotesForTheSecondPayment   string    `gorm:"type:varchar(255)" json:"notes_for_the_second_payment"`
-	UnpaidPaymentAmount        int       `json:"unpaid_payment_amount"`
-	NotesForTheUnpaidPayment   string    `gorm:"type:varchar(255)" json:"notes_for_the_unpaid_payment"`
+	QuotationIdNumber  int       `gorm:"index:idx_incomes_quotation_id_number,unique,where:quotation_id_number <> 0" json:"quotation_id_number"`
+	QuotationIssueDate time.Time `gorm:"type:timestamp with time zone" json:"quotation_issue_date"`
+	QuotationDueDate   time.Time `gorm:"type:timestamp with time zone" json:"quotation_due_date"`
+	InvoiceIdNumber    int       `gorm:"primary_key;unique" json:"invoice_id_number"`
+	InvoiceIssueDate   time.Time `gorm:"type:timestamp with time zone" json:"invoice_issue_date"`
+	InvoiceDueDate     time.Time `gorm:"type:timestamp with time zone" json:"invoice_due_date"`
+	ReceiptIssueDate   time.Time `gorm:"type:timestamp with time zone" json:"receipt_issue_date"`
+	ReceiptIdNumber    int       `gorm:"index:idx_incomes_receipt_id_number,unique,where:receipt_id_number <> 0" json:"receipt_id_number"`
+
+	// Stage is how far the income has come: quotation, invoice or receipt.
+	// The invoice and receipt fields are filled in as it reaches them; until
+	// then a quotation is stored under the negative of its quotation number.
+	Stage string `gorm:"type:varchar(16);not null;default:'invoice';index" json:"stage"`
+
+	AgencyTaxPayerIdNumber     taxid.ID     `gorm:"type:varchar(13);index" json:"agency_tax_payer_id_number"`
+	AgencyBranchNumber         string       `gorm:"type:varchar(5);not null;default:'00000'" json:"agency_branch_number"`
+	InfluencerPostingDate      time.Time    `gorm:"type:timestamp with time zone" json:"influencer_posting_date"`
+	AgencyAgencyName           string       `gorm:"type:varchar(255)" json:"agency_agency_name"`
+	AgencyAddress              string       `gorm:"type:varchar(255)" json:"agency_address"`
+	AgencyPhoneNumber          string       `gorm:"type:varchar(255)" json:"agency_phone_number"`
+	ContactorContactorName     string       `gorm:"type:varchar(255)" json:"contactor_contactor_name"`
+	ContactorPhoneNumber       string       `gorm:"type:varchar(255)" json:"contactor_phone_number"`
+	ContactorLine              string       `gorm:"type:varchar(255)" json:"contactor_line"`
+	ContactorEmail             string       `gorm:"type:varchar(255)" json:"contactor_email"`
+	BrandBrandName             string       `gorm:"type:varchar(255)" json:"brand_brand_name"`
+	BrandProduct               string       `gorm:"type:varchar(255)" json:"brand_product"`
+	TransactionReferenceNumber int          `json:"transaction_reference_number"`
+	TermsAndConditions         string       `gorm:"type:varchar(255)" json:"terms_and_conditions"`
+	TotalPaymentAmount         money.Amount `gorm:"type:numeric(18,2)" json:"total_payment_amount"`
+	NotesForTheTotalPayment    string       `gorm:"type:varchar(255)" json:"notes_for_the_total_payment"`
+	FirstPayment               money.Amount `gorm:"type:numeric(18,2)" json:"first_payment"`
+	NotesForTheFirstPayment    string       `gorm:"type:varchar(255)" json:"notes_for_the_first_payment"`
+	SecondPayment              money.Amount `gorm:"type:numeric(18,2)" json:"second_payment"`
+	NotesForTheSecondPayment   string       `gorm:"type:varchar(255)" json:"notes_for_the_second_payment"`
+	UnpaidPaymentAmount        money.Amount `gorm:"type:numeric(18,2)" json:"unpaid_payment_amount"`
+	NotesForTheUnpaidPayment   string       `gorm:"type:varchar(255)" json:"notes_for_the_unpaid_payment"`
+	Currency                   string       `gorm:"type:varchar(3);not null;default:'THB'" json:"currency"`
+	DiscountType               string       `gorm:"type:varchar(16);not null;default:''" json:"discount_type"`
+	DiscountValue              money.Amount `gorm:"type:numeric(18,2);not null;default:0" json:"discount_value"`
+
+	// The client withholds WithholdingTaxRate percent of the amount before
+	// VAT and sends a 50 Tawi certificate for it, which is prepaid tax for
+	// us. The certificate date is zero until the certificate arrives.
+	WithholdingTaxRate           money.Amount `gorm:"type:numeric(5,2);not null;default:0" json:"withholding_tax_rate"`
+	WithholdingTaxAmount         money.Amount `gorm:"type:numeric(18,2);not null;default:0" json:"withholding_tax_amount"`
+	WithholdingCertificateNumber string       `gorm:"type:varchar(50);not null;default:''" json:"withholding_certificate_number"`
+	WithholdingCertificateDate   time.Time    `gorm:"type:timestamp with time zone" json:"withholding_certificate_date"`
 
 	PlatformId      int           `json:"platform_id"`
 	Platform        Platform      `gorm:"foreignKey:PlatformId" json:"-"`
@@ -49,4 +69,21 @@ type Income struct {
 	Channel         Channel       `gorm:"foreignKey:ChannelId" json:"-"`
 	BankId          int           `json:"bank_id"`
 	Bank            Bank          `gorm:"foreignKey:BankId" json:"-"`
+
+	// The agency, contact and brand fields above are snapshots taken when
+	// these references are set, so later edits to the master records do
+	// not change issued documents.
+	AgencyId  *int     `gorm:"index" json:"agency_id"`
+	Agency    *Agency  `gorm:"foreignKey:AgencyId" json:"-"`
+	ContactId *int     `gorm:"index" json:"contact_id"`
+	Contact   *Contact `gorm:"foreignKey:ContactId" json:"-"`
+	BrandId   *int     `gorm:"index" json:"brand_id"`
+	Brand     *Brand   `gorm:"foreignKey:BrandId" json:"-"`
+
+	// ImportBatchId is set on incomes brought in by a bulk import.
+	ImportBatchId *int         `gorm:"index" json:"import_batch_id"`
+	ImportBatch   *ImportBatch `gorm:"foreignKey:ImportBatchId" json:"-"`
+
+	Details  []Detail  `gorm:"foreignKey:IncomeInvoiceIdNumber;references:InvoiceIdNumber" json:"-"`
+	Expenses []Expense `gorm:"foreignKey:IncomeInvoiceIdNumber;references:InvoiceIdNumber" json:"-"`
 }
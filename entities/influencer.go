@@ -0,0 +1,38 @@
+package entities
+
+import "mtii-backend/money"
+
+type Influencer struct {
+	Id                int    `gorm:"primary_key;auto_increment" json:"id"`
+	Name              string `gorm:"type:varchar(255);not null" json:"name"`
+	Email             string `gorm:"type:varchar(255)" json:"email"`
+	PhoneNumber       string `gorm:"type:varchar(255)" json:"phone_number"`
+	BankId            *int   `json:"bank_id"`
+	Bank              *Bank  `gorm:"foreignKey:BankId" json:"-"`
+	BankAccountName   string `gorm:"type:varchar(255)" json:"bank_account_name"`
+	BankAccountNumber string `gorm:"type:varchar(32)" json:"bank_account_number"`
+
+	Handles  []InfluencerHandle `gorm:"foreignKey:InfluencerId;constraint:OnDelete:CASCADE" json:"-"`
+	RateCard []InfluencerRate   `gorm:"foreignKey:InfluencerId;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// InfluencerHandle is an influencer's account on one platform.
+type InfluencerHandle struct {
+	Id            int      `gorm:"primary_key;auto_increment" json:"id"`
+	InfluencerId  int      `gorm:"not null;uniqueIndex:idx_influencer_handle_platform" json:"influencer_id"`
+	PlatformId    int      `gorm:"not null;uniqueIndex:idx_influencer_handle_platform" json:"platform_id"`
+	Platform      Platform `gorm:"foreignKey:PlatformId" json:"-"`
+	Handle        string   `gorm:"type:varchar(255);not null" json:"handle"`
+	FollowerCount int64    `gorm:"not null;default:0" json:"follower_count"`
+}
+
+// InfluencerRate is an influencer's listed price for one deliverable, in
+// the base currency.
+type InfluencerRate struct {
+	Id           int          `gorm:"primary_key;auto_increment" json:"id"`
+	InfluencerId int          `gorm:"not null;index" json:"influencer_id"`
+	PlatformId   *int         `json:"platform_id"`
+	Platform     *Platform    `gorm:"foreignKey:PlatformId" json:"-"`
+	Deliverable  string       `gorm:"type:varchar(32);not null" json:"deliverable"`
+	Rate         money.Amount `gorm:"type:numeric(18,2);not null" json:"rate"`
+}
@@ -0,0 +1,18 @@
+package entities
+
+import "time"
+
+// InfluencerAssignment puts an influencer on a job, either the whole income
+// or a single line item of it.
+type InfluencerAssignment struct {
+	Id                    int        `gorm:"primary_key;auto_increment" json:"id"`
+	InfluencerId          int        `gorm:"not null;index" json:"influencer_id"`
+	Influencer            Influencer `gorm:"foreignKey:InfluencerId" json:"-"`
+	IncomeInvoiceIdNumber int        `gorm:"not null;index" json:"income_invoice_id_number"`
+	Income                Income     `gorm:"foreignKey:IncomeInvoiceIdNumber" json:"-"`
+	DetailId              *int       `gorm:"index" json:"detail_id"`
+	Detail                *Detail    `gorm:"foreignKey:DetailId;constraint:OnDelete:SET NULL" json:"-"`
+	Deliverables          string     `gorm:"type:text" json:"deliverables"`
+	PostingDate           time.Time  `gorm:"type:timestamp with time zone;index" json:"posting_date"`
+	Notes                 string     `gorm:"type:varchar(255)" json:"notes"`
+}
@@ -1,10 +1,16 @@
 package entities
 
+import "mtii-backend/taxid"
+
 type Receiver struct {
-	Id         int    `gorm:"primary_key;auto_increment" json:"id"`
-	Name       string `gorm:"type:varchar(255)" json:"name"`
-	Address    string `gorm:"type:varchar(255)" json:"address"`
-	Email      string `gorm:"type:varchar(255)" json:"email"`
-	Phone      string `gorm:"type:varchar(255)" json:"phone"`
-	TaxPayerId string `gorm:"type:varchar(255)" json:"tax_payer_id"`
+	Id           int      `gorm:"primary_key;auto_increment" json:"id"`
+	Name         string   `gorm:"type:varchar(255)" json:"name"`
+	Address      string   `gorm:"type:varchar(255)" json:"address"`
+	Email        string   `gorm:"type:varchar(255)" json:"email"`
+	Phone        string   `gorm:"type:varchar(255)" json:"phone"`
+	TaxPayerId   taxid.ID `gorm:"type:varchar(255);index" json:"tax_payer_id"`
+	BranchNumber string   `gorm:"type:varchar(5);not null;default:'00000'" json:"branch_number"`
+	// PromptPayId is the mobile number or tax ID the receiver's PromptPay
+	// account is registered under.
+	PromptPayId string `gorm:"type:varchar(13);not null;default:''" json:"promptpay_id"`
 }
@@ -0,0 +1,18 @@
+package entities
+
+import "mtii-backend/taxid"
+
+// Vendor is a production company or other supplier paid out of a job.
+type Vendor struct {
+	Id                int      `gorm:"primary_key;auto_increment" json:"id"`
+	Name              string   `gorm:"type:varchar(255);not null" json:"name"`
+	Address           string   `gorm:"type:varchar(255)" json:"address"`
+	PhoneNumber       string   `gorm:"type:varchar(255)" json:"phone_number"`
+	Email             string   `gorm:"type:varchar(255)" json:"email"`
+	TaxPayerId        taxid.ID `gorm:"type:varchar(13);index" json:"tax_payer_id"`
+	BranchNumber      string   `gorm:"type:varchar(5);not null;default:'00000'" json:"branch_number"`
+	BankId            *int     `json:"bank_id"`
+	Bank              *Bank    `gorm:"foreignKey:BankId" json:"-"`
+	BankAccountName   string   `gorm:"type:varchar(255)" json:"bank_account_name"`
+	BankAccountNumber string   `gorm:"type:varchar(32)" json:"bank_account_number"`
+}
@@ -0,0 +1,211 @@
+// Package etax writes Thai e-Tax Invoice & Receipt documents in the XML
+// format the Electronic Transactions Development Agency (ETDA) publishes
+// as standard ขมธอ. 3-2560, a profile of the UN/CEFACT Cross Industry
+// Invoice.
+package etax
+
+import (
+	"encoding/xml"
+	"mtii-backend/money"
+	"time"
+)
+
+// Namespaces of the TaxInvoice_CrossIndustryInvoice schema.
+const (
+	NamespaceRSM = "urn:etda:uncefact:data:standard:TaxInvoice_CrossIndustryInvoice:2"
+	NamespaceRAM = "urn:etda:uncefact:data:standard:TaxInvoice_ReusableAggregateBusinessInformationEntity:2"
+
+	// Guideline identifies the ETDA standard and version the document
+	// follows.
+	Guideline        = "ER3-2560"
+	GuidelineVersion = "v2.0"
+)
+
+// Document type codes of the tax invoice schema.
+const (
+	TypeTaxInvoice        = "388"
+	TypeInvoiceTaxInvoice = "T02"
+	TypeReceiptTaxInvoice = "T03"
+)
+
+// Tax ID schemes of trade parties. A Thai taxpayer's TXID is the 13-digit
+// tax ID followed by the 5-digit branch number.
+const (
+	SchemeTaxId  = "TXID"
+	SchemeOther  = "OTHR"
+	VatTypeCode  = "VAT"
+	CountryTH    = "TH"
+	UnitCodeUnit = "C62"
+)
+
+const dateTimeLayout = "2006-01-02T15:04:05"
+
+// DateTime is written as an xs:dateTime without a zone, as ETDA's samples
+// do.
+type DateTime time.Time
+
+func (t DateTime) MarshalText() ([]byte, error) {
+	return []byte(time.Time(t).Format(dateTimeLayout)), nil
+}
+
+func (t DateTime) IsZero() bool {
+	return time.Time(t).IsZero()
+}
+
+type (
+	// Invoice is the root element of a tax invoice. Element names carry
+	// their namespace prefix, declared on the root.
+	Invoice struct {
+		XMLName     xml.Name                    `xml:"rsm:TaxInvoice_CrossIndustryInvoice"`
+		XmlnsRSM    string                      `xml:"xmlns:rsm,attr"`
+		XmlnsRAM    string                      `xml:"xmlns:ram,attr"`
+		Context     ExchangedDocumentContext    `xml:"rsm:ExchangedDocumentContext"`
+		Document    ExchangedDocument           `xml:"rsm:ExchangedDocument"`
+		Transaction SupplyChainTradeTransaction `xml:"rsm:SupplyChainTradeTransaction"`
+	}
+
+	ExchangedDocumentContext struct {
+		Guideline SchemeID `xml:"ram:GuidelineSpecifiedDocumentContextParameter>ram:ID"`
+	}
+
+	ExchangedDocument struct {
+		ID               string   `xml:"ram:ID"`
+		Name             string   `xml:"ram:Name"`
+		TypeCode         string   `xml:"ram:TypeCode"`
+		IssueDateTime    DateTime `xml:"ram:IssueDateTime"`
+		CreationDateTime DateTime `xml:"ram:CreationDateTime"`
+		Notes            []Note   `xml:"ram:IncludedNote,omitempty"`
+	}
+
+	Note struct {
+		Subject string `xml:"ram:Subject,omitempty"`
+		Content string `xml:"ram:Content"`
+	}
+
+	SupplyChainTradeTransaction struct {
+		Agreement  HeaderTradeAgreement  `xml:"ram:ApplicableHeaderTradeAgreement"`
+		Delivery   struct{}              `xml:"ram:ApplicableHeaderTradeDelivery"`
+		Settlement HeaderTradeSettlement `xml:"ram:ApplicableHeaderTradeSettlement"`
+		Lines      []LineItem            `xml:"ram:IncludedSupplyChainTradeLineItem"`
+	}
+
+	HeaderTradeAgreement struct {
+		Seller TradeParty `xml:"ram:SellerTradeParty"`
+		Buyer  TradeParty `xml:"ram:BuyerTradeParty"`
+	}
+
+	TradeParty struct {
+		Name    string        `xml:"ram:Name"`
+		TaxId   SchemeID      `xml:"ram:SpecifiedTaxRegistration>ram:ID"`
+		Contact *TradeContact `xml:"ram:DefinedTradeContact,omitempty"`
+		Address PostalAddress `xml:"ram:PostalTradeAddress"`
+	}
+
+	TradeContact struct {
+		Email string `xml:"ram:EmailURIUniversalCommunication>ram:URIID"`
+	}
+
+	PostalAddress struct {
+		Postcode  string   `xml:"ram:PostcodeCode,omitempty"`
+		LineOne   string   `xml:"ram:LineOne"`
+		CountryID SchemeID `xml:"ram:CountryID"`
+	}
+
+	// SchemeID is an identifier with the scheme it is issued under.
+	SchemeID struct {
+		SchemeID        string `xml:"schemeID,attr,omitempty"`
+		SchemeAgencyID  string `xml:"schemeAgencyID,attr,omitempty"`
+		SchemeVersionID string `xml:"schemeVersionID,attr,omitempty"`
+		Value           string `xml:",chardata"`
+	}
+
+	HeaderTradeSettlement struct {
+		Currency     CurrencyCode      `xml:"ram:InvoiceCurrencyCode"`
+		Taxes        []TradeTax        `xml:"ram:ApplicableTradeTax"`
+		Allowances   []AllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge,omitempty"`
+		PaymentTerms *PaymentTerms     `xml:"ram:SpecifiedTradePaymentTerms,omitempty"`
+		Summation    MonetarySummation `xml:"ram:SpecifiedTradeSettlementHeaderMonetarySummation"`
+	}
+
+	CurrencyCode struct {
+		ListID string `xml:"listID,attr"`
+		Value  string `xml:",chardata"`
+	}
+
+	// TradeTax is the tax charged at one rate. CalculatedAmount is left
+	// out on lines, where VAT is not worked out.
+	TradeTax struct {
+		TypeCode         string        `xml:"ram:TypeCode"`
+		CalculatedRate   money.Amount  `xml:"ram:CalculatedRate"`
+		BasisAmount      *money.Amount `xml:"ram:BasisAmount,omitempty"`
+		CalculatedAmount *money.Amount `xml:"ram:CalculatedAmount,omitempty"`
+	}
+
+	// AllowanceCharge is a discount, or a charge when ChargeIndicator is
+	// set.
+	AllowanceCharge struct {
+		ChargeIndicator bool         `xml:"ram:ChargeIndicator"`
+		ActualAmount    money.Amount `xml:"ram:ActualAmount"`
+		Reason          string       `xml:"ram:Reason,omitempty"`
+	}
+
+	PaymentTerms struct {
+		DueDateDateTime DateTime `xml:"ram:DueDateDateTime"`
+	}
+
+	MonetarySummation struct {
+		LineTotalAmount      money.Amount `xml:"ram:LineTotalAmount"`
+		AllowanceTotalAmount money.Amount `xml:"ram:AllowanceTotalAmount"`
+		TaxBasisTotalAmount  money.Amount `xml:"ram:TaxBasisTotalAmount"`
+		TaxTotalAmount       money.Amount `xml:"ram:TaxTotalAmount"`
+		GrandTotalAmount     money.Amount `xml:"ram:GrandTotalAmount"`
+	}
+
+	LineItem struct {
+		LineID     string             `xml:"ram:AssociatedDocumentLineDocument>ram:LineID"`
+		Product    Product            `xml:"ram:SpecifiedTradeProduct"`
+		Agreement  LineTradeAgreement `xml:"ram:SpecifiedLineTradeAgreement"`
+		Delivery   LineTradeDelivery  `xml:"ram:SpecifiedLineTradeDelivery"`
+		Settlement LineSettlement     `xml:"ram:SpecifiedLineTradeSettlement"`
+	}
+
+	Product struct {
+		Name        string `xml:"ram:Name"`
+		Description string `xml:"ram:Description,omitempty"`
+	}
+
+	LineTradeAgreement struct {
+		GrossPrice money.Amount `xml:"ram:GrossPriceProductTradePrice>ram:ChargeAmount"`
+	}
+
+	LineTradeDelivery struct {
+		BilledQuantity Quantity `xml:"ram:BilledQuantity"`
+	}
+
+	Quantity struct {
+		UnitCode string `xml:"unitCode,attr"`
+		Value    int    `xml:",chardata"`
+	}
+
+	LineSettlement struct {
+		Tax        TradeTax          `xml:"ram:ApplicableTradeTax"`
+		Allowances []AllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge,omitempty"`
+		NetTotal   money.Amount      `xml:"ram:SpecifiedTradeSettlementLineMonetarySummation>ram:NetLineTotalAmount"`
+	}
+)
+
+// Marshal validates the invoice and writes it as an XML document.
+func Marshal(inv Invoice) ([]byte, error) {
+	inv.XmlnsRSM = NamespaceRSM
+	inv.XmlnsRAM = NamespaceRAM
+	inv.Context.Guideline = SchemeID{SchemeAgencyID: "ETDA", SchemeVersionID: GuidelineVersion, Value: Guideline}
+	if err := Validate(inv); err != nil {
+		return nil, err
+	}
+
+	body, err := xml.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
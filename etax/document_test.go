@@ -0,0 +1,190 @@
+package etax
+
+import (
+	"bytes"
+	"encoding/xml"
+	"mtii-backend/money"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sampleInvoice is a T02 invoice/tax invoice of two lines, one discounted,
+// with a document discount: 2400.00 of lines less 400.00 is taxed 140.00.
+func sampleInvoice() Invoice {
+	basis, tax := money.FromInt(2000), money.FromInt(140)
+	return Invoice{
+		Document: ExchangedDocument{
+			ID:               "1001",
+			Name:             "ใบแจ้งหนี้/ใบกำกับภาษี",
+			TypeCode:         TypeInvoiceTaxInvoice,
+			IssueDateTime:    DateTime(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)),
+			CreationDateTime: DateTime(time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC)),
+		},
+		Transaction: SupplyChainTradeTransaction{
+			Agreement: HeaderTradeAgreement{
+				Seller: TradeParty{
+					Name:    "MTII Co., Ltd.",
+					TaxId:   SchemeID{SchemeID: SchemeTaxId, Value: "123456789012100000"},
+					Contact: &TradeContact{Email: "billing@example.com"},
+					Address: PostalAddress{Postcode: "10110", LineOne: "1 Sukhumvit Road, Bangkok 10110", CountryID: SchemeID{SchemeID: "3166-1 alpha-2", Value: CountryTH}},
+				},
+				Buyer: TradeParty{
+					Name:    "Acme Agency",
+					TaxId:   SchemeID{SchemeID: SchemeTaxId, Value: "100000000005000001"},
+					Address: PostalAddress{Postcode: "10330", LineOne: "2 Rama I Road, Bangkok 10330", CountryID: SchemeID{SchemeID: "3166-1 alpha-2", Value: CountryTH}},
+				},
+			},
+			Settlement: HeaderTradeSettlement{
+				Currency:     CurrencyCode{ListID: "ISO 4217 3A", Value: "THB"},
+				Taxes:        []TradeTax{{TypeCode: VatTypeCode, CalculatedRate: money.FromInt(7), BasisAmount: &basis, CalculatedAmount: &tax}},
+				Allowances:   []AllowanceCharge{{ActualAmount: money.FromInt(400), Reason: "Discount"}},
+				PaymentTerms: &PaymentTerms{DueDateDateTime: DateTime(time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC))},
+				Summation: MonetarySummation{
+					LineTotalAmount:      money.FromInt(2400),
+					AllowanceTotalAmount: money.FromInt(400),
+					TaxBasisTotalAmount:  money.FromInt(2000),
+					TaxTotalAmount:       money.FromInt(140),
+					GrandTotalAmount:     money.FromInt(2140),
+				},
+			},
+			Lines: []LineItem{
+				{
+					LineID:    "1",
+					Product:   Product{Name: "Instagram post"},
+					Agreement: LineTradeAgreement{GrossPrice: money.FromInt(1000)},
+					Delivery:  LineTradeDelivery{BilledQuantity: Quantity{UnitCode: UnitCodeUnit, Value: 2}},
+					Settlement: LineSettlement{
+						Tax:        TradeTax{TypeCode: VatTypeCode, CalculatedRate: money.FromInt(7)},
+						Allowances: []AllowanceCharge{{ActualAmount: money.FromInt(100), Reason: "Discount"}},
+						NetTotal:   money.FromInt(1900),
+					},
+				},
+				{
+					LineID:     "2",
+					Product:    Product{Name: "Instagram story", Description: "24 hours"},
+					Agreement:  LineTradeAgreement{GrossPrice: money.FromInt(500)},
+					Delivery:   LineTradeDelivery{BilledQuantity: Quantity{UnitCode: UnitCodeUnit, Value: 1}},
+					Settlement: LineSettlement{Tax: TradeTax{TypeCode: VatTypeCode, CalculatedRate: money.FromInt(7)}, NetTotal: money.FromInt(500)},
+				},
+			},
+		},
+	}
+}
+
+// schemaSequences are the child elements, in order, of the ETDA schema's
+// types for the elements this package writes, trimmed to those it can
+// write. An element's children must appear in this order. This is not a
+// full schema check; see TestSchema, run with -tags xsd.
+var schemaSequences = map[string][]string{
+	"rsm:TaxInvoice_CrossIndustryInvoice":                 {"rsm:ExchangedDocumentContext", "rsm:ExchangedDocument", "rsm:SupplyChainTradeTransaction", "ds:Signature"},
+	"rsm:ExchangedDocumentContext":                        {"ram:GuidelineSpecifiedDocumentContextParameter"},
+	"rsm:ExchangedDocument":                               {"ram:ID", "ram:Name", "ram:TypeCode", "ram:IssueDateTime", "ram:CreationDateTime", "ram:IncludedNote"},
+	"rsm:SupplyChainTradeTransaction":                     {"ram:ApplicableHeaderTradeAgreement", "ram:ApplicableHeaderTradeDelivery", "ram:ApplicableHeaderTradeSettlement", "ram:IncludedSupplyChainTradeLineItem"},
+	"ram:ApplicableHeaderTradeAgreement":                  {"ram:SellerTradeParty", "ram:BuyerTradeParty"},
+	"ram:SellerTradeParty":                                {"ram:Name", "ram:SpecifiedTaxRegistration", "ram:DefinedTradeContact", "ram:PostalTradeAddress"},
+	"ram:BuyerTradeParty":                                 {"ram:Name", "ram:SpecifiedTaxRegistration", "ram:DefinedTradeContact", "ram:PostalTradeAddress"},
+	"ram:PostalTradeAddress":                              {"ram:PostcodeCode", "ram:LineOne", "ram:CountryID"},
+	"ram:ApplicableHeaderTradeSettlement":                 {"ram:InvoiceCurrencyCode", "ram:ApplicableTradeTax", "ram:SpecifiedTradeAllowanceCharge", "ram:SpecifiedTradePaymentTerms", "ram:SpecifiedTradeSettlementHeaderMonetarySummation"},
+	"ram:ApplicableTradeTax":                              {"ram:TypeCode", "ram:CalculatedRate", "ram:BasisAmount", "ram:CalculatedAmount"},
+	"ram:SpecifiedTradeAllowanceCharge":                   {"ram:ChargeIndicator", "ram:ActualAmount", "ram:Reason"},
+	"ram:SpecifiedTradeSettlementHeaderMonetarySummation": {"ram:LineTotalAmount", "ram:AllowanceTotalAmount", "ram:TaxBasisTotalAmount", "ram:TaxTotalAmount", "ram:GrandTotalAmount"},
+	"ram:IncludedSupplyChainTradeLineItem":                {"ram:AssociatedDocumentLineDocument", "ram:SpecifiedTradeProduct", "ram:SpecifiedLineTradeAgreement", "ram:SpecifiedLineTradeDelivery", "ram:SpecifiedLineTradeSettlement"},
+	"ram:SpecifiedTradeProduct":                           {"ram:Name", "ram:Description"},
+	"ram:SpecifiedLineTradeSettlement":                    {"ram:ApplicableTradeTax", "ram:SpecifiedTradeAllowanceCharge", "ram:SpecifiedTradeSettlementLineMonetarySummation"},
+}
+
+// checkSequences walks the document and reports children written out of
+// their schema order, or that the schema does not allow, under the
+// elements schemaSequences lists.
+func checkSequences(t *testing.T, document []byte) {
+	t.Helper()
+	type open struct {
+		name string
+		next int
+	}
+	var stack []*open
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			name := token.Name.Space + ":" + token.Name.Local
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				if sequence, ok := schemaSequences[parent.name]; ok {
+					i := parent.next
+					for i < len(sequence) && sequence[i] != name {
+						i++
+					}
+					if i == len(sequence) {
+						t.Errorf("%s: %s is out of order or not in the schema", parent.name, name)
+					} else {
+						parent.next = i
+					}
+				}
+			}
+			stack = append(stack, &open{name: name})
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	document, err := Marshal(sampleInvoice())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSequences(t, document)
+
+	for _, want := range []string{
+		`<rsm:TaxInvoice_CrossIndustryInvoice xmlns:rsm="` + NamespaceRSM + `" xmlns:ram="` + NamespaceRAM + `">`,
+		`<ram:ID schemeAgencyID="ETDA" schemeVersionID="v2.0">ER3-2560</ram:ID>`,
+		`<ram:TypeCode>T02</ram:TypeCode>`,
+		`<ram:IssueDateTime>2024-03-01T00:00:00</ram:IssueDateTime>`,
+		`<ram:ID schemeID="TXID">123456789012100000</ram:ID>`,
+		`<ram:InvoiceCurrencyCode listID="ISO 4217 3A">THB</ram:InvoiceCurrencyCode>`,
+		`<ram:BilledQuantity unitCode="C62">2</ram:BilledQuantity>`,
+		`<ram:GrandTotalAmount>2140.00</ram:GrandTotalAmount>`,
+		`<ram:DueDateDateTime>2024-03-31T00:00:00</ram:DueDateDateTime>`,
+	} {
+		if !strings.Contains(string(document), want) {
+			t.Errorf("document lacks %s", want)
+		}
+	}
+	if !strings.HasPrefix(string(document), xml.Header) {
+		t.Error("document has no XML declaration")
+	}
+
+	// Lines do not work out their VAT.
+	var lines struct {
+		Lines []struct {
+			Taxes []struct {
+				Calculated *string `xml:"CalculatedAmount"`
+			} `xml:"SpecifiedLineTradeSettlement>ApplicableTradeTax"`
+		} `xml:"SupplyChainTradeTransaction>IncludedSupplyChainTradeLineItem"`
+	}
+	if err := xml.Unmarshal(document, &lines); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines.Lines) != 2 {
+		t.Fatalf("document has %d lines, want 2", len(lines.Lines))
+	}
+	for i, line := range lines.Lines {
+		if len(line.Taxes) != 1 || line.Taxes[0].Calculated != nil {
+			t.Errorf("line %d: want one tax without a calculated amount", i+1)
+		}
+	}
+}
+
+func TestMarshalInvalid(t *testing.T) {
+	inv := sampleInvoice()
+	inv.Transaction.Settlement.Summation.GrandTotalAmount = money.FromInt(2000)
+	if _, err := Marshal(inv); err == nil {
+		t.Fatal("Marshal wrote an invoice whose totals do not add up")
+	}
+}
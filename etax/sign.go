@@ -0,0 +1,221 @@
+package etax
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/google/uuid"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"golang.org/x/crypto/pkcs12"
+)
+
+const (
+	namespaceXAdES     = "http://uri.etsi.org/01903/v1.3.2#"
+	signedPropertiesTy = "http://uri.etsi.org/01903#SignedProperties"
+	digestSHA256       = "http://www.w3.org/2001/04/xmlenc#sha256"
+)
+
+// ErrNoSigningKey is returned by LoadSigner for a certificate file without
+// the private key of its certificate.
+var ErrNoSigningKey = errors.New("etax: the certificate file has no private key for its certificate")
+
+// Signer signs documents with XAdES-BES enveloped signatures, the form the
+// Revenue Department accepts e-Tax invoices in.
+type Signer struct {
+	key   crypto.Signer
+	cert  *x509.Certificate
+	chain [][]byte
+}
+
+// LoadSigner reads the signing certificate and its RSA private key from a
+// PKCS#12 file, as certificate authorities issue them, or from a PEM file
+// holding both.
+func LoadSigner(path, password string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("etax: read certificate file: %w", err)
+	}
+
+	var blocks []*pem.Block
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			blocks = append(blocks, block)
+		}
+	} else {
+		blocks, err = pkcs12.ToPEM(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("etax: read certificate file: %w", err)
+		}
+	}
+
+	s := &Signer{}
+	var certs []*x509.Certificate
+	for _, block := range blocks {
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("etax: parse certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		case "PRIVATE KEY", "RSA PRIVATE KEY":
+			if s.key, err = parsePrivateKey(block); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.key == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	// The signing certificate is the one for the key; the others are its
+	// chain.
+	for _, cert := range certs {
+		if publicKeyEqual(cert.PublicKey, s.key.Public()) {
+			s.cert = cert
+			s.chain = append([][]byte{cert.Raw}, s.chain...)
+		} else {
+			s.chain = append(s.chain, cert.Raw)
+		}
+	}
+	if s.cert == nil {
+		return nil, ErrNoSigningKey
+	}
+	return s, nil
+}
+
+// parsePrivateKey reads an RSA key. pkcs12.ToPEM labels keys PRIVATE KEY
+// though it writes them as PKCS#1, so every encoding is tried.
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("etax: parse private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("etax: unsupported private key type %T, the key must be RSA", key)
+	}
+	return rsaKey, nil
+}
+
+func publicKeyEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
+
+// Sign adds an enveloped XAdES-BES signature to the document: it signs the
+// whole document and, in a second reference, the signing time and the
+// digest of the signing certificate.
+func (s *Signer) Sign(document []byte) ([]byte, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(document); err != nil {
+		return nil, fmt.Errorf("etax: parse document: %w", err)
+	}
+	root := doc.Root()
+	canonicalizer := dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+
+	// The enveloped-signature transform leaves the signature out of the
+	// digest, so the document is digested before it is added.
+	documentDigest, err := digest(canonicalizer, root)
+	if err != nil {
+		return nil, err
+	}
+
+	id := "xmldsig-" + uuid.NewString()
+	signature := root.CreateElement("ds:Signature")
+	signature.CreateAttr("xmlns:ds", dsig.Namespace)
+	signature.CreateAttr("Id", id)
+	signedInfo := signature.CreateElement("ds:SignedInfo")
+	signatureValue := signature.CreateElement("ds:SignatureValue")
+
+	keyInfo := signature.CreateElement("ds:KeyInfo").CreateElement("ds:X509Data")
+	for _, cert := range s.chain {
+		keyInfo.CreateElement("ds:X509Certificate").SetText(base64.StdEncoding.EncodeToString(cert))
+	}
+
+	qualifying := signature.CreateElement("ds:Object").CreateElement("xades:QualifyingProperties")
+	qualifying.CreateAttr("xmlns:xades", namespaceXAdES)
+	qualifying.CreateAttr("Target", "#"+id)
+	signedProperties := qualifying.CreateElement("xades:SignedProperties")
+	signedProperties.CreateAttr("Id", id+"-signedprops")
+	signatureProperties := signedProperties.CreateElement("xades:SignedSignatureProperties")
+	signatureProperties.CreateElement("xades:SigningTime").SetText(time.Now().Format(time.RFC3339))
+	certDigest := sha256.Sum256(s.cert.Raw)
+	cert := signatureProperties.CreateElement("xades:SigningCertificate").CreateElement("xades:Cert")
+	digestElement(cert.CreateElement("xades:CertDigest"), certDigest[:])
+	issuerSerial := cert.CreateElement("xades:IssuerSerial")
+	issuerSerial.CreateElement("ds:X509IssuerName").SetText(s.cert.Issuer.String())
+	issuerSerial.CreateElement("ds:X509SerialNumber").SetText(s.cert.SerialNumber.String())
+
+	propertiesDigest, err := digest(canonicalizer, signedProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	signedInfo.CreateElement("ds:CanonicalizationMethod").CreateAttr("Algorithm", string(canonicalizer.Algorithm()))
+	signedInfo.CreateElement("ds:SignatureMethod").CreateAttr("Algorithm", dsig.RSASHA256SignatureMethod)
+
+	documentReference := signedInfo.CreateElement("ds:Reference")
+	documentReference.CreateAttr("URI", "")
+	transforms := documentReference.CreateElement("ds:Transforms")
+	transforms.CreateElement("ds:Transform").CreateAttr("Algorithm", dsig.EnvelopedSignatureAltorithmId.String())
+	transforms.CreateElement("ds:Transform").CreateAttr("Algorithm", string(canonicalizer.Algorithm()))
+	digestElement(documentReference, documentDigest)
+
+	propertiesReference := signedInfo.CreateElement("ds:Reference")
+	propertiesReference.CreateAttr("Type", signedPropertiesTy)
+	propertiesReference.CreateAttr("URI", "#"+id+"-signedprops")
+	propertiesReference.CreateElement("ds:Transforms").CreateElement("ds:Transform").CreateAttr("Algorithm", string(canonicalizer.Algorithm()))
+	digestElement(propertiesReference, propertiesDigest)
+
+	signedInfoDigest, err := digest(canonicalizer, signedInfo)
+	if err != nil {
+		return nil, err
+	}
+	value, err := s.key.Sign(rand.Reader, signedInfoDigest, crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("etax: sign document: %w", err)
+	}
+	signatureValue.SetText(base64.StdEncoding.EncodeToString(value))
+
+	return doc.WriteToBytes()
+}
+
+// digest canonicalizes an element of the document, with the namespace
+// declarations in scope where it stands, and returns its SHA-256 digest.
+func digest(canonicalizer dsig.Canonicalizer, el *etree.Element) ([]byte, error) {
+	ctx, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, err
+	}
+	detached, err := etreeutils.NSDetatch(ctx, el)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := canonicalizer.Canonicalize(detached)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(canonical)
+	return sum[:], nil
+}
+
+func digestElement(parent *etree.Element, value []byte) {
+	parent.CreateElement("ds:DigestMethod").CreateAttr("Algorithm", digestSHA256)
+	parent.CreateElement("ds:DigestValue").SetText(base64.StdEncoding.EncodeToString(value))
+}
@@ -0,0 +1,172 @@
+package etax
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// writeTestCertificate writes a self-signed certificate and, unless
+// withoutKey is set, its RSA key to a PEM file.
+func writeTestCertificate(t *testing.T, withoutKey bool) (string, *x509.Certificate) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "MTII e-Tax test", Organization: []string{"MTII"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file bytes.Buffer
+	pem.Encode(&file, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if !withoutKey {
+		pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pem.Encode(&file, &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})
+	}
+	path := filepath.Join(t.TempDir(), "signer.pem")
+	if err := os.WriteFile(path, file.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, cert
+}
+
+// verifySignature checks an enveloped XAdES-BES signature the way a
+// verifier does: both reference digests, the signing certificate's digest
+// and the signature over SignedInfo with the key of the certificate in
+// KeyInfo, which must be cert.
+func verifySignature(signed []byte, cert *x509.Certificate) error {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(signed); err != nil {
+		return err
+	}
+	root := doc.Root()
+	signature := root.SelectElement("Signature")
+	if signature == nil {
+		return errors.New("no signature")
+	}
+	signedInfo := signature.SelectElement("SignedInfo")
+	references := signedInfo.SelectElements("Reference")
+	if len(references) != 2 {
+		return fmt.Errorf("%d references, want 2", len(references))
+	}
+	canonicalizer := dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+
+	// The enveloped-signature transform: the document without its
+	// signature.
+	unsigned := doc.Copy()
+	unsigned.Root().RemoveChild(unsigned.Root().SelectElement("Signature"))
+	if err := checkDigest(canonicalizer, unsigned.Root(), references[0]); err != nil {
+		return fmt.Errorf("document: %w", err)
+	}
+
+	uri := references[1].SelectAttrValue("URI", "")
+	signedProperties := signature.FindElement(fmt.Sprintf("//SignedProperties[@Id='%s']", uri[1:]))
+	if signedProperties == nil {
+		return fmt.Errorf("no signed properties %s", uri)
+	}
+	if err := checkDigest(canonicalizer, signedProperties, references[1]); err != nil {
+		return fmt.Errorf("signed properties: %w", err)
+	}
+
+	keyInfoCert := signature.FindElement("KeyInfo/X509Data/X509Certificate")
+	der, err := base64.StdEncoding.DecodeString(keyInfoCert.Text())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(der, cert.Raw) {
+		return errors.New("KeyInfo does not hold the signing certificate")
+	}
+	certDigest := sha256.Sum256(cert.Raw)
+	if signedProperties.FindElement("SignedSignatureProperties/SigningCertificate/Cert/CertDigest/DigestValue").Text() != base64.StdEncoding.EncodeToString(certDigest[:]) {
+		return errors.New("signing certificate digest does not match")
+	}
+
+	signedInfoDigest, err := digest(canonicalizer, signedInfo)
+	if err != nil {
+		return err
+	}
+	value, err := base64.StdEncoding.DecodeString(signature.SelectElement("SignatureValue").Text())
+	if err != nil {
+		return err
+	}
+	return rsa.VerifyPKCS1v15(cert.PublicKey.(*rsa.PublicKey), crypto.SHA256, signedInfoDigest, value)
+}
+
+func checkDigest(canonicalizer dsig.Canonicalizer, el, reference *etree.Element) error {
+	sum, err := digest(canonicalizer, el)
+	if err != nil {
+		return err
+	}
+	if got := reference.SelectElement("DigestValue").Text(); got != base64.StdEncoding.EncodeToString(sum) {
+		return errors.New("digest does not match")
+	}
+	return nil
+}
+
+func TestSign(t *testing.T) {
+	path, cert := writeTestCertificate(t, false)
+	signer, err := LoadSigner(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	document, err := Marshal(sampleInvoice())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := signer.Sign(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySignature(signed, cert); err != nil {
+		t.Fatalf("signature does not verify: %v", err)
+	}
+	checkSequences(t, signed)
+
+	tampered := bytes.Replace(signed, []byte("<ram:GrandTotalAmount>2140.00<"), []byte("<ram:GrandTotalAmount>1.00<"), 1)
+	if bytes.Equal(tampered, signed) {
+		t.Fatal("the grand total was not found to tamper with")
+	}
+	if err := verifySignature(tampered, cert); err == nil {
+		t.Error("signature of a tampered document verifies")
+	}
+}
+
+func TestLoadSignerWithoutKey(t *testing.T) {
+	path, _ := writeTestCertificate(t, true)
+	if _, err := LoadSigner(path, ""); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("LoadSigner = %v, want ErrNoSigningKey", err)
+	}
+}
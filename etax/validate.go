@@ -0,0 +1,196 @@
+package etax
+
+import (
+	"fmt"
+	"mtii-backend/money"
+	"mtii-backend/taxid"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Problem is one way a document breaks the schema, at the path of the
+// element in question.
+type Problem struct {
+	Path    string
+	Message string
+}
+
+// ValidationError lists every problem found in a document.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		messages[i] = p.Path + " " + p.Message
+	}
+	return "etax: invalid document: " + strings.Join(messages, "; ")
+}
+
+// Limits and code lists of the ETDA schema for the elements we write.
+var (
+	typeCodes = map[string]bool{TypeTaxInvoice: true, TypeInvoiceTaxInvoice: true, TypeReceiptTaxInvoice: true}
+	schemes   = map[string]bool{SchemeTaxId: true, "NIDN": true, "CCPT": true, SchemeOther: true}
+)
+
+const (
+	maxIdLength      = 35
+	maxNameLength    = 256
+	maxLineOneLength = 256
+)
+
+// Validate checks an invoice against the cardinality, length, code list
+// and format rules of the ETDA schema, and that its totals add up the way
+// the standard defines them. It does not load the XSD itself; the xsd
+// tests check marshalled documents against it.
+func Validate(inv Invoice) error {
+	v := &validator{}
+
+	if inv.XmlnsRSM != NamespaceRSM || inv.XmlnsRAM != NamespaceRAM {
+		v.add("TaxInvoice_CrossIndustryInvoice", "must declare the ETDA tax invoice namespaces")
+	}
+	if inv.Context.Guideline.Value != Guideline {
+		v.add("ExchangedDocumentContext.GuidelineSpecifiedDocumentContextParameter.ID", "must be "+Guideline)
+	}
+
+	doc := inv.Document
+	v.text("ExchangedDocument.ID", doc.ID, maxIdLength)
+	v.text("ExchangedDocument.Name", doc.Name, maxNameLength)
+	if !typeCodes[doc.TypeCode] {
+		v.add("ExchangedDocument.TypeCode", "must be one of 388, T02 or T03")
+	}
+	if doc.IssueDateTime.IsZero() {
+		v.add("ExchangedDocument.IssueDateTime", "is required")
+	}
+	if doc.CreationDateTime.IsZero() {
+		v.add("ExchangedDocument.CreationDateTime", "is required")
+	}
+
+	v.party("SellerTradeParty", inv.Transaction.Agreement.Seller)
+	if inv.Transaction.Agreement.Seller.TaxId.SchemeID != SchemeTaxId {
+		v.add("SellerTradeParty.SpecifiedTaxRegistration.ID", "must be the seller's TXID, as the seller is VAT registered")
+	}
+	v.party("BuyerTradeParty", inv.Transaction.Agreement.Buyer)
+
+	settlement := inv.Transaction.Settlement
+	if settlement.Currency.ListID != "ISO 4217 3A" || len(settlement.Currency.Value) != 3 {
+		v.add("ApplicableHeaderTradeSettlement.InvoiceCurrencyCode", "must be an ISO 4217 alphabetic code")
+	}
+	if len(settlement.Taxes) == 0 {
+		v.add("ApplicableHeaderTradeSettlement.ApplicableTradeTax", "is required")
+	}
+
+	lines := inv.Transaction.Lines
+	if len(lines) == 0 {
+		v.add("SupplyChainTradeTransaction.IncludedSupplyChainTradeLineItem", "must have at least one line")
+	}
+	var lineTotal money.Amount
+	for i, line := range lines {
+		path := fmt.Sprintf("IncludedSupplyChainTradeLineItem[%d]", i)
+		if line.LineID != strconv.Itoa(i+1) {
+			v.add(path+".AssociatedDocumentLineDocument.LineID", "must number the lines from 1")
+		}
+		v.text(path+".SpecifiedTradeProduct.Name", line.Product.Name, maxNameLength)
+		if line.Delivery.BilledQuantity.Value <= 0 {
+			v.add(path+".SpecifiedLineTradeDelivery.BilledQuantity", "must be positive")
+		}
+		if line.Settlement.Tax.TypeCode != VatTypeCode {
+			v.add(path+".SpecifiedLineTradeSettlement.ApplicableTradeTax.TypeCode", "must be VAT")
+		}
+		var allowances money.Amount
+		for _, a := range line.Settlement.Allowances {
+			allowances += a.ActualAmount
+		}
+		if line.Settlement.NetTotal != line.Agreement.GrossPrice.Mul(line.Delivery.BilledQuantity.Value)-allowances {
+			v.add(path+".SpecifiedTradeSettlementLineMonetarySummation.NetLineTotalAmount", "must be the price times the quantity less the line's allowances")
+		}
+		lineTotal += line.Settlement.NetTotal
+	}
+
+	sum := settlement.Summation
+	const summation = "SpecifiedTradeSettlementHeaderMonetarySummation"
+	if sum.LineTotalAmount != lineTotal {
+		v.add(summation+".LineTotalAmount", "must be the sum of the lines' net totals")
+	}
+	var allowances, basis, tax money.Amount
+	for _, a := range settlement.Allowances {
+		allowances += a.ActualAmount
+	}
+	for i, t := range settlement.Taxes {
+		if t.TypeCode != VatTypeCode || t.BasisAmount == nil || t.CalculatedAmount == nil {
+			v.add(fmt.Sprintf("ApplicableHeaderTradeSettlement.ApplicableTradeTax[%d]", i), "must be VAT with its basis and calculated amounts")
+			continue
+		}
+		basis += *t.BasisAmount
+		tax += *t.CalculatedAmount
+	}
+	if sum.AllowanceTotalAmount != allowances {
+		v.add(summation+".AllowanceTotalAmount", "must be the sum of the document allowances")
+	}
+	if sum.TaxBasisTotalAmount != sum.LineTotalAmount-sum.AllowanceTotalAmount || sum.TaxBasisTotalAmount != basis {
+		v.add(summation+".TaxBasisTotalAmount", "must be the line total less allowances, split between the tax rates")
+	}
+	if sum.TaxTotalAmount != tax {
+		v.add(summation+".TaxTotalAmount", "must be the sum of the calculated taxes")
+	}
+	if sum.GrandTotalAmount != sum.TaxBasisTotalAmount+sum.TaxTotalAmount {
+		v.add(summation+".GrandTotalAmount", "must be the tax basis plus the tax")
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(path, message string) {
+	v.problems = append(v.problems, Problem{Path: path, Message: message})
+}
+
+func (v *validator) text(path, value string, max int) {
+	switch {
+	case strings.TrimSpace(value) == "":
+		v.add(path, "is required")
+	case utf8.RuneCountInString(value) > max:
+		v.add(path, fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+// party checks a trade party. A TXID is the 13-digit tax ID followed by
+// the 5-digit branch number; Thai addresses need their postcode.
+func (v *validator) party(path string, p TradeParty) {
+	v.text(path+".Name", p.Name, maxNameLength)
+
+	id := p.TaxId
+	switch {
+	case !schemes[id.SchemeID]:
+		v.add(path+".SpecifiedTaxRegistration.ID", "must have a schemeID of TXID, NIDN, CCPT or OTHR")
+	case id.SchemeID == SchemeTaxId && (len(id.Value) != 18 || !taxid.Valid(id.Value[:13]) || !taxid.ValidBranch(id.Value[13:])):
+		v.add(path+".SpecifiedTaxRegistration.ID", "must be a valid 13-digit tax ID followed by a 5-digit branch number")
+	case id.Value == "":
+		v.add(path+".SpecifiedTaxRegistration.ID", "is required")
+	}
+
+	v.text(path+".PostalTradeAddress.LineOne", p.Address.LineOne, maxLineOneLength)
+	if p.Address.CountryID.Value == "" {
+		v.add(path+".PostalTradeAddress.CountryID", "is required")
+	}
+	if p.Address.CountryID.Value == CountryTH && (len(p.Address.Postcode) != 5 || !isDigits(p.Address.Postcode)) {
+		v.add(path+".PostalTradeAddress.PostcodeCode", "must be the 5-digit postcode of a Thai address")
+	}
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
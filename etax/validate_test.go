@@ -0,0 +1,135 @@
+package etax
+
+import (
+	"errors"
+	"mtii-backend/money"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate(withNamespaces(sampleInvoice())); err != nil {
+		t.Fatalf("sample invoice: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		change   func(*Invoice)
+		wantPath string
+	}{
+		{
+			name:     "line net is not price times quantity less allowances",
+			change:   func(inv *Invoice) { inv.Transaction.Lines[0].Settlement.NetTotal = money.FromInt(2000) },
+			wantPath: "IncludedSupplyChainTradeLineItem[0].SpecifiedTradeSettlementLineMonetarySummation.NetLineTotalAmount",
+		},
+		{
+			name:     "line total is not the sum of the lines",
+			change:   func(inv *Invoice) { inv.Transaction.Settlement.Summation.LineTotalAmount = money.FromInt(2500) },
+			wantPath: "SpecifiedTradeSettlementHeaderMonetarySummation.LineTotalAmount",
+		},
+		{
+			name:     "allowance total is not the sum of the allowances",
+			change:   func(inv *Invoice) { inv.Transaction.Settlement.Allowances = nil },
+			wantPath: "SpecifiedTradeSettlementHeaderMonetarySummation.AllowanceTotalAmount",
+		},
+		{
+			name: "tax basis differs from the tax's basis",
+			change: func(inv *Invoice) {
+				basis := money.FromInt(1900)
+				inv.Transaction.Settlement.Taxes[0].BasisAmount = &basis
+			},
+			wantPath: "SpecifiedTradeSettlementHeaderMonetarySummation.TaxBasisTotalAmount",
+		},
+		{
+			name:     "tax total is not the calculated tax",
+			change:   func(inv *Invoice) { inv.Transaction.Settlement.Summation.TaxTotalAmount = money.FromInt(150) },
+			wantPath: "SpecifiedTradeSettlementHeaderMonetarySummation.TaxTotalAmount",
+		},
+		{
+			name:     "grand total is not basis plus tax",
+			change:   func(inv *Invoice) { inv.Transaction.Settlement.Summation.GrandTotalAmount = money.FromInt(2400) },
+			wantPath: "SpecifiedTradeSettlementHeaderMonetarySummation.GrandTotalAmount",
+		},
+		{
+			name:     "tax without its amounts",
+			change:   func(inv *Invoice) { inv.Transaction.Settlement.Taxes[0].CalculatedAmount = nil },
+			wantPath: "ApplicableHeaderTradeSettlement.ApplicableTradeTax[0]",
+		},
+		{
+			name:     "TXID with a wrong check digit",
+			change:   func(inv *Invoice) { inv.Transaction.Agreement.Seller.TaxId.Value = "123456789012200000" },
+			wantPath: "SellerTradeParty.SpecifiedTaxRegistration.ID",
+		},
+		{
+			name:     "TXID without its branch",
+			change:   func(inv *Invoice) { inv.Transaction.Agreement.Buyer.TaxId.Value = "1000000000050" },
+			wantPath: "BuyerTradeParty.SpecifiedTaxRegistration.ID",
+		},
+		{
+			name:     "TXID with a malformed branch",
+			change:   func(inv *Invoice) { inv.Transaction.Agreement.Buyer.TaxId.Value = "10000000000500000A" },
+			wantPath: "BuyerTradeParty.SpecifiedTaxRegistration.ID",
+		},
+		{
+			name: "seller without a TXID",
+			change: func(inv *Invoice) {
+				inv.Transaction.Agreement.Seller.TaxId = SchemeID{SchemeID: SchemeOther, Value: "N/A"}
+			},
+			wantPath: "SellerTradeParty.SpecifiedTaxRegistration.ID",
+		},
+		{
+			name:     "unknown tax ID scheme",
+			change:   func(inv *Invoice) { inv.Transaction.Agreement.Buyer.TaxId.SchemeID = "VAT" },
+			wantPath: "BuyerTradeParty.SpecifiedTaxRegistration.ID",
+		},
+		{
+			name:     "Thai address without a postcode",
+			change:   func(inv *Invoice) { inv.Transaction.Agreement.Buyer.Address.Postcode = "" },
+			wantPath: "BuyerTradeParty.PostalTradeAddress.PostcodeCode",
+		},
+		{
+			name:     "lines numbered from 0",
+			change:   func(inv *Invoice) { inv.Transaction.Lines[0].LineID = "0" },
+			wantPath: "IncludedSupplyChainTradeLineItem[0].AssociatedDocumentLineDocument.LineID",
+		},
+		{
+			name:     "no lines",
+			change:   func(inv *Invoice) { inv.Transaction.Lines = nil },
+			wantPath: "SupplyChainTradeTransaction.IncludedSupplyChainTradeLineItem",
+		},
+		{
+			name:     "unknown document type",
+			change:   func(inv *Invoice) { inv.Document.TypeCode = "380" },
+			wantPath: "ExchangedDocument.TypeCode",
+		},
+		{
+			name:     "missing issue date",
+			change:   func(inv *Invoice) { inv.Document.IssueDateTime = DateTime{} },
+			wantPath: "ExchangedDocument.IssueDateTime",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := withNamespaces(sampleInvoice())
+			tt.change(&inv)
+			err := Validate(inv)
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Validate = %v, want a ValidationError", err)
+			}
+			for _, p := range invalid.Problems {
+				if p.Path == tt.wantPath {
+					return
+				}
+			}
+			t.Errorf("problems = %v, want one at %s", invalid.Problems, tt.wantPath)
+		})
+	}
+}
+
+// withNamespaces sets the root attributes Marshal fills in.
+func withNamespaces(inv Invoice) Invoice {
+	inv.XmlnsRSM = NamespaceRSM
+	inv.XmlnsRAM = NamespaceRAM
+	inv.Context.Guideline = SchemeID{SchemeAgencyID: "ETDA", SchemeVersionID: GuidelineVersion, Value: Guideline}
+	return inv
+}
@@ -0,0 +1,43 @@
+//go:build xsd
+
+package etax
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestSchema validates a marshalled invoice against the ETDA XSD with
+// xmllint. The schema is not vendored: download the ขมธอ. 3-2560 schema
+// package from ETDA and run
+//
+//	ETAX_XSD=/path/to/TaxInvoice_CrossIndustryInvoice_2p0.xsd go test -tags xsd ./etax
+//
+// The default test run only checks element order (checkSequences) and the
+// rules Validate implements.
+func TestSchema(t *testing.T) {
+	schema := os.Getenv("ETAX_XSD")
+	if schema == "" {
+		t.Fatal("ETAX_XSD must name the ETDA TaxInvoice_CrossIndustryInvoice schema")
+	}
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Fatal("the xsd tests need xmllint: ", err)
+	}
+
+	document, err := Marshal(sampleInvoice())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "invoice.xml")
+	if err := os.WriteFile(path, document, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command(xmllint, "--noout", "--schema", schema, path).CombinedOutput()
+	if err != nil {
+		t.Errorf("document does not validate against %s: %v\n%s", schema, err, out)
+	}
+}
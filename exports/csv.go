@@ -0,0 +1,33 @@
+package exports
+
+import "io"
+
+// utf8BOM lets spreadsheet programs detect that the file is UTF-8, so Thai
+// names open correctly.
+const utf8BOM = "\ufeff"
+
+func writeCSV(w io.Writer, t Table) error {
+	rw, err := newCSVRowWriter(w, t.Columns)
+	if err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		if err := rw.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	if t.Footer != nil {
+		if err := rw.WriteRow(t.Footer); err != nil {
+			return err
+		}
+	}
+	return rw.Close()
+}
+
+func textRow(row []any) []string {
+	cells := make([]string, len(row))
+	for i, v := range row {
+		cells[i] = text(v)
+	}
+	return cells
+}
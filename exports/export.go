@@ -0,0 +1,102 @@
+// Package exports renders report tables as CSV, XLSX or PDF files.
+package exports
+
+import (
+	"fmt"
+	"io"
+	"mtii-backend/money"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+// contentTypes are the media types of the formats a table can be written in.
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+}
+
+// Column is a table heading. Width is in characters and sets the column's
+// width in spreadsheets and its share of the page in PDFs.
+type Column struct {
+	Title string
+	Width float64
+}
+
+// Table is a report laid out for export. Cells hold strings, ints,
+// money.Amount, money.Rate, time.Time or bool values; amounts stay numbers
+// in spreadsheets and dates are written as YYYY-MM-DD, or left blank when
+// zero. Header lines describe the report above the table in XLSX and PDF
+// files, while CSV files hold only the table so they import cleanly. Image,
+// a PNG such as a payment QR code, is drawn under the table of PDF files
+// with Caption below it and left out of the other formats.
+type Table struct {
+	Title   string
+	Header  []string
+	Columns []Column
+	Rows    [][]any
+	Footer  []any
+	Image   []byte
+	Caption string
+}
+
+// ContentType returns the media type of format and whether it is known.
+func ContentType(format string) (string, bool) {
+	contentType, ok := contentTypes[format]
+	return contentType, ok
+}
+
+// Write renders the table to w in format.
+func Write(w io.Writer, format string, t Table) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, t)
+	case FormatXLSX:
+		return writeXLSX(w, t)
+	case FormatPDF:
+		return writePDF(w, t)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// text formats a cell for CSV and PDF files.
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case money.Amount:
+		return v.String()
+	case money.Rate:
+		return v.String()
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02")
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	}
+	return fmt.Sprint(v)
+}
+
+// numeric reports whether a cell holds a number, which is aligned right.
+func numeric(v any) bool {
+	switch v.(type) {
+	case int, money.Amount, money.Rate:
+		return true
+	}
+	return false
+}
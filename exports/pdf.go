@@ -0,0 +1,146 @@
+package exports
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pdfFont      = "report"
+	pdfMargin    = 10.0
+	pdfRowHeight = 6.0
+	pdfFontSize  = 8.0
+	pdfImageSize = 40.0
+)
+
+// writePDF lays the table out on landscape A4 pages, repeating the column
+// titles on every page. Thai text needs a Unicode TrueType font, such as
+// TH Sarabun New, whose path is read from PDF_FONT_PATH; without one the
+// built-in Helvetica is used and characters outside Windows-1252 print as
+// dots.
+func writePDF(w io.Writer, t Table) error {
+	// gofpdf reads fonts relative to its font directory.
+	fontPath := os.Getenv("PDF_FONT_PATH")
+	fontDir := ""
+	if fontPath != "" {
+		fontDir = filepath.Dir(fontPath)
+	}
+	pdf := gofpdf.New("L", "mm", "A4", fontDir)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AliasNbPages("")
+
+	family, translate := "Helvetica", pdf.UnicodeTranslatorFromDescriptor("")
+	if fontPath != "" {
+		pdf.AddUTF8Font(pdfFont, "", filepath.Base(fontPath))
+		pdf.AddUTF8Font(pdfFont, "B", filepath.Base(fontPath))
+		family, translate = pdfFont, func(s string) string { return s }
+	}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont(family, "", pdfFontSize)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	widths := columnWidths(t.Columns, pageWidth-2*pdfMargin)
+
+	if t.Title != "" {
+		pdf.SetFont(family, "B", 14)
+		pdf.CellFormat(0, 8, translate(t.Title), "", 1, "L", false, 0, "")
+	}
+	pdf.SetFont(family, "", 10)
+	for _, line := range t.Header {
+		pdf.CellFormat(0, 5, translate(line), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	headings := func() {
+		pdf.SetFont(family, "B", pdfFontSize)
+		pdf.SetFillColor(230, 230, 230)
+		for i, c := range t.Columns {
+			pdf.CellFormat(widths[i], pdfRowHeight, fit(pdf, translate(c.Title), widths[i]), "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont(family, "", pdfFontSize)
+	}
+	row := func(cells []any, border string) {
+		if pdf.GetY()+pdfRowHeight > pageHeight-2*pdfMargin {
+			pdf.AddPage()
+			headings()
+		}
+		for i := range t.Columns {
+			var v any
+			if i < len(cells) {
+				v = cells[i]
+			}
+			align := "L"
+			if numeric(v) {
+				align = "R"
+			}
+			pdf.CellFormat(widths[i], pdfRowHeight, fit(pdf, translate(text(v)), widths[i]), border, 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	headings()
+	for _, cells := range t.Rows {
+		row(cells, "LR")
+	}
+	if t.Footer != nil {
+		pdf.SetFont(family, "B", pdfFontSize)
+		row(t.Footer, "1")
+	} else {
+		pdf.CellFormat(pageWidth-2*pdfMargin, 0, "", "T", 1, "", false, 0, "")
+	}
+
+	if len(t.Image) > 0 {
+		if pdf.GetY()+pdfImageSize+pdfRowHeight+5 > pageHeight-2*pdfMargin {
+			pdf.AddPage()
+		}
+		pdf.Ln(5)
+		options := gofpdf.ImageOptions{ImageType: "PNG", ReadDpi: false}
+		pdf.RegisterImageOptionsReader("image", options, bytes.NewReader(t.Image))
+		pdf.ImageOptions("image", pdfMargin, pdf.GetY(), pdfImageSize, pdfImageSize, true, options, 0, "")
+		if t.Caption != "" {
+			pdf.SetFont(family, "", pdfFontSize)
+			pdf.CellFormat(0, pdfRowHeight, translate(t.Caption), "", 1, "L", false, 0, "")
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+// columnWidths shares the page width between the columns in proportion to
+// their widths in characters.
+func columnWidths(columns []Column, pageWidth float64) []float64 {
+	total := 0.0
+	for _, c := range columns {
+		total += max(c.Width, 1)
+	}
+	widths := make([]float64, len(columns))
+	for i, c := range columns {
+		widths[i] = pageWidth * max(c.Width, 1) / total
+	}
+	return widths
+}
+
+// fit shortens s until it fits in a cell of the given width.
+func fit(pdf *gofpdf.Fpdf, s string, width float64) string {
+	const padding = 2.0
+	if pdf.GetStringWidth(s) <= width-padding {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"..") > width-padding {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ".."
+}
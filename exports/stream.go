@@ -0,0 +1,151 @@
+package exports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mtii-backend/money"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// RowWriter writes a table one row at a time, so an export of any size
+// holds little in memory. Close finishes the file and must be called once
+// every row is written.
+type RowWriter interface {
+	WriteRow(cells []any) error
+	Close() error
+}
+
+// NewRowWriter starts a CSV or XLSX file with the column titles. PDFs are
+// laid out a page at a time and cannot be streamed.
+func NewRowWriter(w io.Writer, format string, columns []Column) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRowWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXRowWriter(w, columns)
+	}
+	return nil, fmt.Errorf("format %q cannot be streamed", format)
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func newCSVRowWriter(w io.Writer, columns []Column) (*csvRowWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	rw := &csvRowWriter{w: csv.NewWriter(w)}
+	titles := make([]any, len(columns))
+	for i, c := range columns {
+		titles[i] = c.Title
+	}
+	if err := rw.WriteRow(titles); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+func (rw *csvRowWriter) WriteRow(cells []any) error {
+	return rw.w.Write(textRow(cells))
+}
+
+func (rw *csvRowWriter) Close() error {
+	rw.w.Flush()
+	return rw.w.Error()
+}
+
+// xlsxRowWriter keeps the rows in excelize's temporary file until Close
+// assembles the workbook and writes it out.
+type xlsxRowWriter struct {
+	w      io.Writer
+	f      *excelize.File
+	sw     *excelize.StreamWriter
+	styles xlsxStyles
+	row    int
+}
+
+func newXLSXRowWriter(w io.Writer, columns []Column) (*xlsxRowWriter, error) {
+	f := excelize.NewFile()
+	sw, styles, err := newXLSXStream(f, columns)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxRowWriter{w: w, f: f, sw: sw, styles: styles, row: 1}, nil
+}
+
+// newXLSXStream opens a stream on the report sheet with the column titles
+// as a frozen first row.
+func newXLSXStream(f *excelize.File, columns []Column) (*excelize.StreamWriter, xlsxStyles, error) {
+	if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+		return nil, xlsxStyles{}, err
+	}
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return nil, xlsxStyles{}, err
+	}
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return nil, xlsxStyles{}, err
+	}
+	for i, c := range columns {
+		if c.Width > 0 {
+			if err := sw.SetColWidth(i+1, i+1, c.Width); err != nil {
+				return nil, xlsxStyles{}, err
+			}
+		}
+	}
+	err = sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	if err != nil {
+		return nil, xlsxStyles{}, err
+	}
+
+	titles := make([]any, len(columns))
+	for i, c := range columns {
+		titles[i] = excelize.Cell{StyleID: styles.heading, Value: c.Title}
+	}
+	if err := sw.SetRow("A1", titles); err != nil {
+		return nil, xlsxStyles{}, err
+	}
+	return sw, styles, nil
+}
+
+func (rw *xlsxRowWriter) WriteRow(cells []any) error {
+	values := make([]any, len(cells))
+	for i, v := range cells {
+		switch c := v.(type) {
+		case money.Amount:
+			values[i] = excelize.Cell{StyleID: rw.styles.amount, Value: c.Float64()}
+		case money.Rate:
+			values[i], _ = strconv.ParseFloat(c.String(), 64)
+		case time.Time:
+			if !c.IsZero() {
+				values[i] = excelize.Cell{StyleID: rw.styles.date, Value: c}
+			}
+		case bool:
+			values[i] = text(c)
+		default:
+			values[i] = v
+		}
+	}
+
+	rw.row++
+	cell, err := excelize.CoordinatesToCellName(1, rw.row)
+	if err != nil {
+		return err
+	}
+	return rw.sw.SetRow(cell, values)
+}
+
+func (rw *xlsxRowWriter) Close() error {
+	defer rw.f.Close()
+	if err := rw.sw.Flush(); err != nil {
+		return err
+	}
+	return rw.f.Write(rw.w)
+}
@@ -0,0 +1,160 @@
+package exports
+
+import (
+	"io"
+	"mtii-backend/money"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const sheetName = "Report"
+
+// xlsxStyles are the cell styles of a sheet, plain and bold.
+type xlsxStyles struct {
+	text, amount, date          int
+	boldText, boldAmount, title int
+	heading                     int
+}
+
+func writeXLSX(w io.Writer, t Table) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+		return err
+	}
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+
+	row := 1
+	if t.Title != "" {
+		if err := setCell(f, 1, row, t.Title, styles.title); err != nil {
+			return err
+		}
+		row++
+	}
+	for _, line := range t.Header {
+		if err := setCell(f, 1, row, line, styles.text); err != nil {
+			return err
+		}
+		row++
+	}
+	if row > 1 {
+		row++
+	}
+
+	headingRow := row
+	for i, c := range t.Columns {
+		if err := setCell(f, i+1, row, c.Title, styles.heading); err != nil {
+			return err
+		}
+		name, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if c.Width > 0 {
+			if err := f.SetColWidth(sheetName, name, name, c.Width); err != nil {
+				return err
+			}
+		}
+	}
+	row++
+
+	for _, cells := range t.Rows {
+		if err := setRow(f, row, cells, styles.text, styles.amount, styles.date); err != nil {
+			return err
+		}
+		row++
+	}
+	if t.Footer != nil {
+		if err := setRow(f, row, t.Footer, styles.boldText, styles.boldAmount, styles.date); err != nil {
+			return err
+		}
+	}
+
+	err = f.SetPanes(sheetName, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      headingRow,
+		TopLeftCell: "A" + strconv.Itoa(headingRow+1),
+		ActivePane:  "bottomLeft",
+	})
+	if err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+func newXLSXStyles(f *excelize.File) (xlsxStyles, error) {
+	amountFormat := "#,##0.00"
+	dateFormat := "yyyy-mm-dd"
+	var s xlsxStyles
+	defs := []struct {
+		style *int
+		def   excelize.Style
+	}{
+		{&s.text, excelize.Style{}},
+		{&s.amount, excelize.Style{CustomNumFmt: &amountFormat}},
+		{&s.date, excelize.Style{CustomNumFmt: &dateFormat}},
+		{&s.boldText, excelize.Style{Font: &excelize.Font{Bold: true}, Border: topBorder}},
+		{&s.boldAmount, excelize.Style{Font: &excelize.Font{Bold: true}, Border: topBorder, CustomNumFmt: &amountFormat}},
+		{&s.title, excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}}},
+		{&s.heading, excelize.Style{
+			Font:      &excelize.Font{Bold: true},
+			Border:    []excelize.Border{{Type: "bottom", Color: "000000", Style: 1}},
+			Alignment: &excelize.Alignment{WrapText: true, Vertical: "center"},
+		}},
+	}
+	for _, d := range defs {
+		id, err := f.NewStyle(&d.def)
+		if err != nil {
+			return xlsxStyles{}, err
+		}
+		*d.style = id
+	}
+	return s, nil
+}
+
+var topBorder = []excelize.Border{{Type: "top", Color: "000000", Style: 1}}
+
+// setRow writes a row of cells, keeping amounts and dates as numbers so the
+// sheet can total and sort them.
+func setRow(f *excelize.File, row int, cells []any, textStyle, amountStyle, dateStyle int) error {
+	for i, v := range cells {
+		style := textStyle
+		switch c := v.(type) {
+		case money.Amount:
+			v, style = c.Float64(), amountStyle
+		case money.Rate:
+			v, _ = strconv.ParseFloat(c.String(), 64)
+		case time.Time:
+			if c.IsZero() {
+				v = nil
+			} else {
+				style = dateStyle
+			}
+		case bool:
+			v = text(c)
+		}
+		if err := setCell(f, i+1, row, v, style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setCell(f *excelize.File, col, row int, v any, style int) error {
+	cell, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		if err := f.SetCellValue(sheetName, cell, v); err != nil {
+			return err
+		}
+	}
+	return f.SetCellStyle(sheetName, cell, cell, style)
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import "context"
+
+type contextKey string
+
+const (
+	requestIdKey        contextKey = "requestId"
+	userIdKey           contextKey = "userId"
+	repositoryMethodKey contextKey = "repositoryMethod"
+)
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+func RequestIdFromContext(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIdKey).(string)
+	return requestId, ok
+}
+
+func WithUserId(ctx context.Context, userId int) context.Context {
+	return context.WithValue(ctx, userIdKey, userId)
+}
+
+func UserIdFromContext(ctx context.Context) (int, bool) {
+	userId, ok := ctx.Value(userIdKey).(int)
+	return userId, ok
+}
+
+func WithRepositoryMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, repositoryMethodKey, method)
+}
+
+func RepositoryMethodFromContext(ctx context.Context) (string, bool) {
+	method, ok := ctx.Value(repositoryMethodKey).(string)
+	return method, ok
+}
@@ -0,0 +1,42 @@
+package helpers
+
+import "strings"
+
+const Redacted = "[REDACTED]"
+
+var secretKeys = []string{"password", "token", "secret", "authorization"}
+
+// IsSecretKey reports whether a field or header name holds a credential.
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactSecrets replaces the values of credential fields in a decoded JSON body.
+func RedactSecrets(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, val := range v {
+			if IsSecretKey(key) {
+				redacted[key] = Redacted
+				continue
+			}
+			redacted[key] = RedactSecrets(val)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, val := range v {
+			redacted[i] = RedactSecrets(val)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
@@ -27,5 +27,9 @@ func DefaultIfEmpty[T any](value, defaultValue T) T {
 		return defaultValue
 	}
 
+	if zeroer, ok := any(value).(interface{ IsZero() bool }); ok && zeroer.IsZero() {
+		return defaultValue
+	}
+
 	return value
 }
@@ -112,20 +112,31 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"mtii-backend/config"
 	"mtii-backend/controllers"
+	"mtii-backend/helpers"
+	"mtii-backend/metrics"
+	"mtii-backend/middlewares"
 	"mtii-backend/migrations"
 	"mtii-backend/repositories"
 	"mtii-backend/routes"
 	"mtii-backend/services"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
+	// 0. Set up structured logging and tracing
+	config.SetUpLogger()
+	shutdownTracing := config.SetUpTracing(context.Background())
+	defer shutdownTracing(context.Background())
+
 	// 1. Set up the database connection
 	db := config.SetUpDatabaseConnection()
 
@@ -140,6 +151,19 @@ func main() {
 	recvRepo := repositories.NewReceiverRepository(db)
 	incRepo := repositories.NewIncomeRepository(db)
 	detRepo := repositories.NewDetailRepository(db)
+	rateRepo := repositories.NewCurrencyRateRepository(db)
+	agencyRepo := repositories.NewAgencyRepository(db)
+	contactRepo := repositories.NewContactRepository(db)
+	brandRepo := repositories.NewBrandRepository(db)
+	influencerRepo := repositories.NewInfluencerRepository(db)
+	assignmentRepo := repositories.NewInfluencerAssignmentRepository(db)
+	vendorRepo := repositories.NewVendorRepository(db)
+	expenseRepo := repositories.NewExpenseRepository(db)
+	commissionPlanRepo := repositories.NewCommissionPlanRepository(db)
+	commissionRepo := repositories.NewCommissionRepository(db)
+	reportRepo := repositories.NewReportRepository(db)
+	importBatchRepo := repositories.NewImportBatchRepository(db)
+	bankStatementRepo := repositories.NewBankStatementRepository(db)
 
 	// 3. Initialize services
 	tokenSvc := services.NewTokenService()
@@ -151,8 +175,20 @@ func main() {
 	chanSvc := services.NewChannelService(chanRepo)
 	bankSvc := services.NewBankService(bankRepo)
 	recvSvc := services.NewReceiverService(recvRepo)
-	incSvc := services.NewIncomeService(incRepo)
-	detSvc := services.NewDetailService(detRepo)
+	commissionPlanSvc := services.NewCommissionPlanService(commissionPlanRepo)
+	commissionSvc := services.NewCommissionService(commissionRepo, commissionPlanRepo, saleRepo, incRepo, rateRepo)
+	incSvc := services.NewIncomeService(incRepo, rateRepo, agencyRepo, contactRepo, brandRepo, importBatchRepo, commissionSvc)
+	detSvc := services.NewDetailService(detRepo, incRepo, commissionSvc)
+	rateSvc := services.NewCurrencyRateService(rateRepo)
+	agencySvc := services.NewAgencyService(agencyRepo)
+	contactSvc := services.NewContactService(contactRepo)
+	brandSvc := services.NewBrandService(brandRepo)
+	influencerSvc := services.NewInfluencerService(influencerRepo)
+	assignmentSvc := services.NewInfluencerAssignmentService(assignmentRepo, influencerRepo, incRepo)
+	vendorSvc := services.NewVendorService(vendorRepo)
+	expenseSvc := services.NewExpenseService(expenseRepo, incRepo)
+	bankStatementSvc := services.NewBankStatementService(bankStatementRepo, bankRepo, incRepo, commissionSvc)
+	reportSvc := services.NewReportService(incRepo, rateRepo, reportRepo, recvRepo)
 
 	// 4. Initialize controllers
 	userCtrl := controllers.NewUserController(tokenSvc, userSvc)
@@ -162,17 +198,31 @@ func main() {
 	saleCtrl := controllers.NewSalePersonController(tokenSvc, saleSvc)
 	chanCtrl := controllers.NewChannelController(tokenSvc, chanSvc)
 	bankCtrl := controllers.NewBankController(tokenSvc, bankSvc)
+	bankStatementCtrl := controllers.NewBankStatementController(tokenSvc, bankStatementSvc)
 	recvCtrl := controllers.NewReceiverController(tokenSvc, recvSvc)
 	incCtrl := controllers.NewIncomeController(tokenSvc, incSvc)
 	detCtrl := controllers.NewDetailController(tokenSvc, detSvc)
+	rateCtrl := controllers.NewCurrencyRateController(tokenSvc, rateSvc)
+	agencyCtrl := controllers.NewAgencyController(tokenSvc, agencySvc)
+	contactCtrl := controllers.NewContactController(tokenSvc, contactSvc)
+	brandCtrl := controllers.NewBrandController(tokenSvc, brandSvc)
+	influencerCtrl := controllers.NewInfluencerController(tokenSvc, influencerSvc)
+	assignmentCtrl := controllers.NewInfluencerAssignmentController(tokenSvc, assignmentSvc)
+	vendorCtrl := controllers.NewVendorController(tokenSvc, vendorSvc)
+	expenseCtrl := controllers.NewExpenseController(tokenSvc, expenseSvc)
+	reportCtrl := controllers.NewReportController(tokenSvc, reportSvc)
+	commissionPlanCtrl := controllers.NewCommissionPlanController(tokenSvc, commissionPlanSvc)
+	commissionCtrl := controllers.NewCommissionController(tokenSvc, commissionSvc)
 
-	// 5. Set up Gin server with CORS
-	server := gin.Default()
+	// 5. Set up Gin server with request logging and CORS
+	server := gin.New()
+	server.Use(otelgin.Middleware("mtii-backend"), middlewares.RequestLogger(), middlewares.Metrics(), gin.Recovery(), middlewares.ErrorHandler())
 	// server.Use(middlewares.CORSMiddleware())
 	server.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"https://mtii-production.up.railway.app", "http://localhost:5173"},
 		AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE", "PUT", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization", "Origin"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", "Origin", middlewares.RequestIdHeader},
+		ExposeHeaders:    []string{middlewares.RequestIdHeader},
 		AllowCredentials: true,
 	}))
 
@@ -186,25 +236,43 @@ func main() {
 		saleCtrl,
 		chanCtrl,
 		bankCtrl,
+		bankStatementCtrl,
 		recvCtrl,
 		incCtrl,
 		detCtrl,
+		rateCtrl,
+		agencyCtrl,
+		contactCtrl,
+		brandCtrl,
+		influencerCtrl,
+		assignmentCtrl,
+		vendorCtrl,
+		expenseCtrl,
+		reportCtrl,
+		commissionPlanCtrl,
+		commissionCtrl,
 		tokenSvc,
 	)
 
 	// 7. Run migrations (always)
 	if err := migrations.Migrate(db); err != nil {
-		log.Fatalf("Migration error: %v", err)
+		slog.Error("migration failed", "error", err)
+		os.Exit(1)
 	}
 
 	// 8. Optionally run seeder
 	if os.Getenv("SKIP_SEEDER") != "true" {
 		if err := migrations.Seeder(db); err != nil {
-			log.Fatalf("Seeder error: %v", err)
+			slog.Error("seeder failed", "error", err)
+			os.Exit(1)
 		}
 	}
 
-	// 9. Start the server on the given PORT
+	// 9. Refresh business metrics and serve them on the internal listener
+	go metrics.RunBusinessGauges(context.Background(), time.Minute, incRepo)
+	go metrics.Serve(helpers.DefaultIfEmpty(os.Getenv("METRICS_ADDR"), metrics.DefaultAddr))
+
+	// 10. Start the server on the given PORT
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8888"
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"mtii-backend/money"
+	"time"
+)
+
+type OutstandingIncomeCounter interface {
+	CountOutstandingIncome(ctx context.Context) (int64, money.Amount, error)
+}
+
+// RunBusinessGauges refreshes the receivable gauges every interval until ctx
+// is cancelled.
+func RunBusinessGauges(ctx context.Context, interval time.Duration, counter OutstandingIncomeCounter) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, amount, err := counter.CountOutstandingIncome(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to refresh business gauges", "error", err)
+		} else {
+			OpenInvoices.Set(float64(count))
+			OutstandingReceivable.Set(amount.Float64())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
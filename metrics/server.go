@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultAddr is where metrics are served when METRICS_ADDR is not set. It
+// is only reachable from the host, since the gauges expose receivable
+// balances and traffic.
+const DefaultAddr = "localhost:9090"
+
+// Serve exposes /metrics on its own listener, kept apart from the public
+// API. It returns once the listener fails.
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	slog.Info("serving metrics", "addr", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("metrics listener stopped", "addr", addr, "error", err)
+	}
+}
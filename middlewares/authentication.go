@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"mtii-backend/helpers"
 	"mtii-backend/services"
 	"mtii-backend/utils"
 	"net/http"
@@ -42,6 +43,7 @@ func Authenticate(tokenService services.TokenService) gin.HandlerFunc {
 		}
 		ctx.Set("token", authHeader)
 		ctx.Set("userId", userId)
+		ctx.Request = ctx.Request.WithContext(helpers.WithUserId(ctx.Request.Context(), userId))
 		ctx.Next()
 	}
 }
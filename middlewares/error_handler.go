@@ -0,0 +1,101 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mtii-backend/money"
+	"mtii-backend/promptpay"
+	"mtii-backend/services"
+	"mtii-backend/taxid"
+	"mtii-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+var statusByKind = map[services.ErrorKind]int{
+	services.KindNotFound:   http.StatusNotFound,
+	services.KindConflict:   http.StatusConflict,
+	services.KindValidation: http.StatusUnprocessableEntity,
+	services.KindForbidden:  http.StatusForbidden,
+}
+
+// ErrorHandler turns the last error a handler attached with ctx.Error into
+// the response. The error's meta, when it is a string, is used as the
+// response message.
+func ErrorHandler() gin.HandlerFunc {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(utils.JSONFieldName)
+		if err := taxid.RegisterValidations(v); err != nil {
+			panic(err)
+		}
+		if err := promptpay.RegisterValidations(v); err != nil {
+			panic(err)
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		last := ctx.Errors.Last()
+		message, ok := last.Meta.(string)
+		if !ok {
+			message = "Failed to process the request"
+		}
+
+		status, detail := classifyError(last.Err)
+		ctx.JSON(status, utils.BuildResponseFailed(message, detail, utils.EmptyObj{}))
+	}
+}
+
+func classifyError(err error) (int, utils.ErrorDetail) {
+	var (
+		domainErr      *services.DomainError
+		validationErrs validator.ValidationErrors
+		syntaxErr      *json.SyntaxError
+		typeErr        *json.UnmarshalTypeError
+		amountErr      *money.ParseError
+	)
+
+	switch {
+	case errors.As(err, &domainErr):
+		return statusByKind[domainErr.Kind], utils.ErrorDetail{
+			Code:    domainErr.Code,
+			Message: domainErr.Error(),
+			Fields:  domainErr.Fields,
+		}
+	case errors.As(err, &validationErrs):
+		return http.StatusUnprocessableEntity, utils.ErrorDetail{
+			Code:    "validation_failed",
+			Message: "request validation failed",
+			Fields:  utils.ValidationFieldErrors(validationErrs),
+		}
+	case errors.As(err, &typeErr):
+		return http.StatusBadRequest, utils.ErrorDetail{
+			Code:    "bad_request",
+			Message: err.Error(),
+			Fields: []utils.FieldError{{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+			}},
+		}
+	case errors.As(err, &amountErr):
+		return http.StatusBadRequest, utils.ErrorDetail{Code: "bad_request", Message: err.Error()}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest, utils.ErrorDetail{Code: "bad_request", Message: err.Error()}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, utils.ErrorDetail{Code: "not_found", Message: err.Error()}
+	}
+
+	return http.StatusInternalServerError, utils.ErrorDetail{Code: "internal_error", Message: err.Error()}
+}
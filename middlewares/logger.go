@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mtii-backend/helpers"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+// requestIdPattern is the shape of request ids taken from the client. Any
+// other value is replaced so that it cannot forge log lines or grow
+// without bound in the logs and headers it is echoed into.
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,64}$`)
+
+// RequestLogger assigns every request an id, echoes it in the X-Request-ID
+// header and writes one structured access log line once the request is done.
+// A well-formed X-Request-ID sent by the client is kept.
+func RequestLogger() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		requestId := ctx.GetHeader(RequestIdHeader)
+		if !requestIdPattern.MatchString(requestId) {
+			requestId = uuid.NewString()
+		}
+		ctx.Set("requestId", requestId)
+		ctx.Header(RequestIdHeader, requestId)
+		ctx.Request = ctx.Request.WithContext(helpers.WithRequestId(ctx.Request.Context(), requestId))
+
+		if slog.Default().Enabled(ctx.Request.Context(), slog.LevelDebug) {
+			logRequestBody(ctx)
+		}
+
+		ctx.Next()
+
+		attrs := []any{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", ctx.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", ctx.ClientIP()),
+		}
+		for _, err := range ctx.Errors {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		// The user id is only known after Authenticate has run further down
+		// the chain, so it is taken from the request context it updated.
+		reqCtx := ctx.Request.Context()
+		switch {
+		case ctx.Writer.Status() >= 500:
+			slog.ErrorContext(reqCtx, "request completed", attrs...)
+		case ctx.Writer.Status() >= 400:
+			slog.WarnContext(reqCtx, "request completed", attrs...)
+		default:
+			slog.InfoContext(reqCtx, "request completed", attrs...)
+		}
+	}
+}
+
+func logRequestBody(ctx *gin.Context) {
+	if ctx.Request.Body == nil || ctx.ContentType() != gin.MIMEJSON {
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 {
+		return
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return
+	}
+	slog.DebugContext(ctx.Request.Context(), "request body", slog.Any("body", helpers.RedactSecrets(decoded)))
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"mtii-backend/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics counts requests and observes their latency by route template, so
+// /api/income/1 and /api/income/2 share one series.
+func Metrics() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+
+		metrics.HTTPRequestsTotal.WithLabelValues(route, ctx.Request.Method, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, ctx.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,141 @@
+package migrations
+
+import (
+	"fmt"
+	"mtii-backend/entities"
+	"mtii-backend/taxid"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// addCustomerMasterData links incomes to the new agency, contact and brand
+// tables. Agencies, contacts and brands are created from the snapshot text
+// of incomes that are not linked yet, newest income first so the master
+// records carry the latest details. Agencies are matched by tax ID, or by
+// name when an income has none; contacts by email, or name, and brands by
+// name within their agency.
+func addCustomerMasterData(tx *gorm.DB) error {
+	for _, field := range []string{"AgencyId", "ContactId", "BrandId"} {
+		if !tx.Migrator().HasColumn(&entities.Income{}, field) {
+			if err := tx.Migrator().AddColumn(&entities.Income{}, field); err != nil {
+				return err
+			}
+		}
+		if !tx.Migrator().HasIndex(&entities.Income{}, field) {
+			if err := tx.Migrator().CreateIndex(&entities.Income{}, field); err != nil {
+				return err
+			}
+		}
+	}
+	for _, relation := range []string{"Agency", "Contact", "Brand"} {
+		if !tx.Migrator().HasConstraint(&entities.Income{}, relation) {
+			if err := tx.Migrator().CreateConstraint(&entities.Income{}, relation); err != nil {
+				return err
+			}
+		}
+	}
+
+	var incomes []struct {
+		InvoiceIdNumber        int
+		AgencyTaxPayerIdNumber taxid.ID
+		AgencyBranchNumber     string
+		AgencyAgencyName       string
+		AgencyAddress          string
+		AgencyPhoneNumber      string
+		ContactorContactorName string
+		ContactorPhoneNumber   string
+		ContactorLine          string
+		ContactorEmail         string
+		BrandBrandName         string
+	}
+	err := tx.Table("incomes").
+		Where("agency_id IS NULL").
+		Order("invoice_issue_date DESC, invoice_id_number DESC").
+		Find(&incomes).Error
+	if err != nil {
+		return err
+	}
+
+	agencies := map[string]int{}
+	contacts := map[string]int{}
+	brands := map[string]int{}
+	for _, income := range incomes {
+		agencyKey := string(income.AgencyTaxPayerIdNumber)
+		if agencyKey == "" {
+			agencyKey = "name:" + matchKey(income.AgencyAgencyName)
+		}
+		if agencyKey == "name:" {
+			continue
+		}
+
+		agencyId, ok := agencies[agencyKey]
+		if !ok {
+			agency := entities.Agency{
+				Name:         strings.TrimSpace(income.AgencyAgencyName),
+				Address:      income.AgencyAddress,
+				PhoneNumber:  income.AgencyPhoneNumber,
+				TaxPayerId:   income.AgencyTaxPayerIdNumber,
+				BranchNumber: income.AgencyBranchNumber,
+			}
+			if agency.BranchNumber == "" {
+				agency.BranchNumber = taxid.HeadOffice
+			}
+			if err := tx.Create(&agency).Error; err != nil {
+				return err
+			}
+			agencyId = agency.Id
+			agencies[agencyKey] = agencyId
+		}
+		links := map[string]any{"agency_id": agencyId}
+
+		contactKey := matchKey(income.ContactorEmail)
+		if contactKey == "" {
+			contactKey = matchKey(income.ContactorContactorName)
+		}
+		if contactKey != "" {
+			contactKey = fmt.Sprintf("%d/%s", agencyId, contactKey)
+			contactId, ok := contacts[contactKey]
+			if !ok {
+				contact := entities.Contact{
+					AgencyId:    agencyId,
+					Name:        strings.TrimSpace(income.ContactorContactorName),
+					PhoneNumber: income.ContactorPhoneNumber,
+					Line:        income.ContactorLine,
+					Email:       strings.TrimSpace(income.ContactorEmail),
+				}
+				if err := tx.Create(&contact).Error; err != nil {
+					return err
+				}
+				contactId = contact.Id
+				contacts[contactKey] = contactId
+			}
+			links["contact_id"] = contactId
+		}
+
+		if brandKey := matchKey(income.BrandBrandName); brandKey != "" {
+			brandKey = fmt.Sprintf("%d/%s", agencyId, brandKey)
+			brandId, ok := brands[brandKey]
+			if !ok {
+				brand := entities.Brand{AgencyId: agencyId, Name: strings.TrimSpace(income.BrandBrandName)}
+				if err := tx.Create(&brand).Error; err != nil {
+					return err
+				}
+				brandId = brand.Id
+				brands[brandKey] = brandId
+			}
+			links["brand_id"] = brandId
+		}
+
+		err := tx.Table("incomes").Where("invoice_id_number = ?", income.InvoiceIdNumber).Updates(links).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchKey folds case and whitespace so "ACME  Co" and "acme co" match.
+func matchKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
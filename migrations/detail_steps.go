@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"mtii-backend/entities"
+
+	"gorm.io/gorm"
+)
+
+// addDetailPosition adds detail.position and numbers the existing lines of
+// every income in id order.
+func addDetailPosition(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&entities.Detail{}, "Position") {
+		if err := tx.Migrator().AddColumn(&entities.Detail{}, "Position"); err != nil {
+			return err
+		}
+	}
+
+	return tx.Exec(`
+		UPDATE details d
+		SET position = numbered.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY income_invoice_id_number ORDER BY id) AS position
+			FROM details
+		) AS numbered
+		WHERE d.id = numbered.id AND d.position = 0`).Error
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"mtii-backend/entities"
+
+	"gorm.io/gorm"
+)
+
+// addIncomeImportBatch links incomes to the bulk import that brought them
+// in. Incomes entered before imports existed belong to no batch.
+func addIncomeImportBatch(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&entities.Income{}, "ImportBatchId") {
+		if err := tx.Migrator().AddColumn(&entities.Income{}, "ImportBatchId"); err != nil {
+			return err
+		}
+	}
+	if !tx.Migrator().HasIndex(&entities.Income{}, "ImportBatchId") {
+		if err := tx.Migrator().CreateIndex(&entities.Income{}, "ImportBatchId"); err != nil {
+			return err
+		}
+	}
+	if !tx.Migrator().HasConstraint(&entities.Income{}, "ImportBatch") {
+		if err := tx.Migrator().CreateConstraint(&entities.Income{}, "ImportBatch"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -16,8 +16,30 @@ func Migrate(db *gorm.DB) error {
 		entities.Channel{},
 		entities.Bank{},
 		entities.Receiver{},
+		entities.Agency{},
+		entities.Contact{},
+		entities.Brand{},
+		entities.Influencer{},
+		entities.InfluencerHandle{},
+		entities.InfluencerRate{},
+		entities.Vendor{},
+		entities.CommissionPlan{},
+		entities.CommissionTier{},
+		entities.SalePersonCommissionPlan{},
+		entities.ImportBatch{},
+		entities.ImportBatchRecord{},
 		entities.Income{},
 		entities.Detail{},
+		entities.InfluencerAssignment{},
+		entities.Expense{},
+		entities.ExpenseAttachment{},
+		entities.Commission{},
+		entities.CurrencyRate{},
+		entities.BankStatementFormat{},
+		entities.BankStatement{},
+		entities.BankTransaction{},
+		entities.BankTransactionMatch{},
+		entities.DocumentNumber{},
 	}
 
 	for _, table := range tables {
@@ -28,5 +50,5 @@ func Migrate(db *gorm.DB) error {
 		}
 	}
 
-	return nil
+	return runSteps(db)
 }
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"fmt"
+	"mtii-backend/entities"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// convertMoneyToNumeric turns the integer amount columns into numeric(18,2)
+// and adds incomes.currency. Every existing value was a whole baht amount,
+// so the cast only appends ".00"; a value too large for numeric(18,2) makes
+// the ALTER fail and the step roll back instead of losing digits.
+func convertMoneyToNumeric(tx *gorm.DB) error {
+	columns := []struct {
+		model  any
+		table  string
+		column string
+	}{
+		{&entities.Income{}, "incomes", "total_payment_amount"},
+		{&entities.Income{}, "incomes", "first_payment"},
+		{&entities.Income{}, "incomes", "second_payment"},
+		{&entities.Income{}, "incomes", "unpaid_payment_amount"},
+		{&entities.Income{}, "incomes", "discount_value"},
+		{&entities.Detail{}, "details", "unit_price"},
+		{&entities.Detail{}, "details", "discount_value"},
+	}
+
+	for _, c := range columns {
+		types, err := tx.Migrator().ColumnTypes(c.model)
+		if err != nil {
+			return err
+		}
+		for _, t := range types {
+			if t.Name() != c.column || strings.EqualFold(t.DatabaseTypeName(), "numeric") {
+				continue
+			}
+			err := tx.Exec(fmt.Sprintf(
+				"ALTER TABLE %s ALTER COLUMN %s TYPE numeric(18,2) USING %s::numeric(18,2)",
+				c.table, c.column, c.column,
+			)).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if !tx.Migrator().HasColumn(&entities.Income{}, "Currency") {
+		return tx.Migrator().AddColumn(&entities.Income{}, "Currency")
+	}
+	return nil
+}
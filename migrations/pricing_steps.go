@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"mtii-backend/entities"
+
+	"gorm.io/gorm"
+)
+
+// addLinePricing adds units, categories, discounts and VAT exemption to
+// details and the document-level discount to incomes. Existing rows get no
+// discount and stay subject to VAT, so their totals do not change.
+func addLinePricing(tx *gorm.DB) error {
+	columns := []struct {
+		model  any
+		fields []string
+	}{
+		{&entities.Detail{}, []string{"Unit", "Category", "DiscountType", "DiscountValue", "VatExempt"}},
+		{&entities.Income{}, []string{"DiscountType", "DiscountValue"}},
+	}
+	for _, c := range columns {
+		for _, field := range c.fields {
+			if tx.Migrator().HasColumn(c.model, field) {
+				continue
+			}
+			if err := tx.Migrator().AddColumn(c.model, field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package migrations
+
+import (
+	"mtii-backend/entities"
+
+	"gorm.io/gorm"
+)
+
+// addReceiverPromptPay adds the PromptPay account of receivers. Existing
+// receivers have none until it is set.
+func addReceiverPromptPay(tx *gorm.DB) error {
+	if tx.Migrator().HasColumn(&entities.Receiver{}, "PromptPayId") {
+		return nil
+	}
+	return tx.Migrator().AddColumn(&entities.Receiver{}, "PromptPayId")
+}
@@ -0,0 +1,126 @@
+package migrations
+
+import (
+	"log/slog"
+	"mtii-backend/entities"
+
+	"gorm.io/gorm"
+)
+
+// addIncomeStage adds the stage of incomes. Incomes used to be created as
+// invoices; those with a receipt number have had their receipt issued.
+func addIncomeStage(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&entities.Income{}, "Stage") {
+		if err := tx.Migrator().AddColumn(&entities.Income{}, "Stage"); err != nil {
+			return err
+		}
+		err := tx.Model(&entities.Income{}).
+			Where("receipt_id_number <> 0").
+			Update("stage", "receipt").Error
+		if err != nil {
+			return err
+		}
+	}
+	if !tx.Migrator().HasIndex(&entities.Income{}, "Stage") {
+		if err := tx.Migrator().CreateIndex(&entities.Income{}, "Stage"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDocumentNumbers adds the numbering series of quotations, invoices and
+// receipts, starting each after the largest number already issued, and
+// makes quotation and receipt numbers unique. Numbers entered twice by hand
+// are renumbered first; see renumberDuplicates.
+func addDocumentNumbers(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&entities.DocumentNumber{}) {
+		if err := tx.Migrator().CreateTable(&entities.DocumentNumber{}); err != nil {
+			return err
+		}
+	}
+
+	series := map[string]string{
+		"quotation": "quotation_id_number",
+		"invoice":   "invoice_id_number",
+		"receipt":   "receipt_id_number",
+	}
+	for name, column := range series {
+		err := tx.Exec(`INSERT INTO document_numbers (series, last)
+			SELECT ?, COALESCE(MAX(`+column+`), 0) FROM incomes WHERE `+column+` > 0
+			ON CONFLICT (series) DO NOTHING`, name).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range []string{"quotation", "receipt"} {
+		column := series[name]
+		index := "idx_incomes_" + column
+		if tx.Migrator().HasIndex(&entities.Income{}, index) {
+			continue
+		}
+		if err := renumberDuplicates(tx, name, column); err != nil {
+			return err
+		}
+		if err := tx.Migrator().CreateIndex(&entities.Income{}, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renumberDuplicates gives every income sharing its number in column with
+// another the next number of the series, so the column can be made unique.
+// Of the incomes sharing a number, a quotation, which is stored under the
+// negative of its number, keeps it, then the lowest invoice number does.
+// Each change is logged so the documents can be reissued.
+func renumberDuplicates(tx *gorm.DB, series, column string) error {
+	var duplicates []int
+	err := tx.Model(&entities.Income{}).
+		Where(column+" <> 0").
+		Group(column).
+		Having("COUNT(*) > 1").
+		Pluck(column, &duplicates).Error
+	if err != nil || len(duplicates) == 0 {
+		return err
+	}
+
+	var incomes []struct {
+		InvoiceIdNumber int
+		Number          int
+	}
+	err = tx.Model(&entities.Income{}).
+		Select("invoice_id_number", column+" AS number").
+		Where(column+" IN ?", duplicates).
+		Order(column).
+		Order("stage = 'quotation' DESC").
+		Order("invoice_id_number").
+		Scan(&incomes).Error
+	if err != nil {
+		return err
+	}
+
+	var counter entities.DocumentNumber
+	if err := tx.Where("series = ?", series).First(&counter).Error; err != nil {
+		return err
+	}
+	for i, income := range incomes {
+		if i == 0 || incomes[i-1].Number != income.Number {
+			continue
+		}
+		counter.Last++
+		err := tx.Model(&entities.Income{}).
+			Where("invoice_id_number = ?", income.InvoiceIdNumber).
+			Update(column, counter.Last).Error
+		if err != nil {
+			return err
+		}
+		slog.Warn("renumbered a duplicate document number",
+			slog.String("series", series),
+			slog.Int("invoice_id_number", income.InvoiceIdNumber),
+			slog.Int("old_number", income.Number),
+			slog.Int("new_number", counter.Last))
+	}
+	return tx.Save(&counter).Error
+}
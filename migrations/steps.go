@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// schemaMigration records a step that has been applied, so every step runs
+// exactly once per database.
+type schemaMigration struct {
+	Id        string    `gorm:"primary_key;type:varchar(255)"`
+	AppliedAt time.Time `gorm:"type:timestamp with time zone"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// step changes the schema or data of tables that already exist. Steps must
+// also succeed on a fresh database where Migrate has just created the tables
+// from the current entities.
+type step struct {
+	Id  string
+	Run func(tx *gorm.DB) error
+}
+
+// steps run in order; append new ones at the end and never reorder them.
+var steps = []step{
+	{Id: "0001_detail_position", Run: addDetailPosition},
+	{Id: "0002_line_pricing", Run: addLinePricing},
+	{Id: "0003_money_numeric", Run: convertMoneyToNumeric},
+	{Id: "0004_tax_id_strings", Run: convertTaxIdsToStrings},
+	{Id: "0005_customer_master_data", Run: addCustomerMasterData},
+	{Id: "0006_income_withholding_tax", Run: addIncomeWithholdingTax},
+	{Id: "0007_income_import_batch", Run: addIncomeImportBatch},
+	{Id: "0008_receiver_promptpay", Run: addReceiverPromptPay},
+	{Id: "0009_income_stage", Run: addIncomeStage},
+	{Id: "0010_document_numbers", Run: addDocumentNumbers},
+}
+
+func runSteps(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, s := range steps {
+		var applied int64
+		if err := db.Model(&schemaMigration{}).Where("id = ?", s.Id).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := s.Run(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Id: s.Id, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
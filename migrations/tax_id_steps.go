@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"mtii-backend/entities"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// convertTaxIdsToStrings turns incomes.agency_tax_payer_id_number into a
+// 13-character string, restoring the leading zeros the integer dropped, and
+// adds branch numbers defaulting to the head office. Separators are removed
+// from receivers' tax IDs so searches match. Invalid IDs are kept as they
+// are; they are only rejected when next written through the API.
+func convertTaxIdsToStrings(tx *gorm.DB) error {
+	types, err := tx.Migrator().ColumnTypes(&entities.Income{})
+	if err != nil {
+		return err
+	}
+	for _, t := range types {
+		if t.Name() != "agency_tax_payer_id_number" || strings.Contains(strings.ToLower(t.DatabaseTypeName()), "char") {
+			continue
+		}
+		err := tx.Exec(`
+			ALTER TABLE incomes ALTER COLUMN agency_tax_payer_id_number TYPE varchar(13)
+			USING CASE WHEN agency_tax_payer_id_number = 0 THEN ''
+				ELSE LPAD(agency_tax_payer_id_number::text, 13, '0') END`).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Exec(`UPDATE receivers SET tax_payer_id = regexp_replace(tax_payer_id, '[-\s]', '', 'g') WHERE tax_payer_id ~ '[-\s]'`).Error
+	if err != nil {
+		return err
+	}
+
+	columns := []struct {
+		model any
+		field string
+	}{
+		{&entities.Income{}, "AgencyBranchNumber"},
+		{&entities.Receiver{}, "BranchNumber"},
+	}
+	for _, c := range columns {
+		if tx.Migrator().HasColumn(c.model, c.field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(c.model, c.field); err != nil {
+			return err
+		}
+	}
+
+	indexes := []struct {
+		model any
+		field string
+	}{
+		{&entities.Income{}, "AgencyTaxPayerIdNumber"},
+		{&entities.Receiver{}, "TaxPayerId"},
+	}
+	for _, i := range indexes {
+		if tx.Migrator().HasIndex(i.model, i.field) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(i.model, i.field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"mtii-backend/entities"
+
+	"gorm.io/gorm"
+)
+
+// addIncomeWithholdingTax adds the tax clients withhold from incomes and the
+// certificates they send for it. Existing incomes have nothing withheld.
+func addIncomeWithholdingTax(tx *gorm.DB) error {
+	fields := []string{"WithholdingTaxRate", "WithholdingTaxAmount", "WithholdingCertificateNumber", "WithholdingCertificateDate"}
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(&entities.Income{}, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(&entities.Income{}, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the ISO 4217 code of amounts that do not name one.
+const DefaultCurrency = BaseCurrency
+
+// scale is the number of minor units in a major unit (satang per baht).
+const scale = 100
+
+// Amount is a fixed-point money value with two decimal places, held as
+// minor units so addition, subtraction and multiplication by a quantity are
+// exact. It is stored as numeric(18,2) and encoded in JSON as a string such
+// as "1250.50".
+type Amount int64
+
+// FromMinor returns the amount of the given number of minor units.
+func FromMinor(minor int64) Amount {
+	return Amount(minor)
+}
+
+// FromInt returns a whole amount, e.g. FromInt(7) is 7.00.
+func FromInt(units int64) Amount {
+	return Amount(units * scale)
+}
+
+// Parse reads a decimal such as "12", "-3.5" or "1250.50". Values with more
+// than two decimal places are rejected rather than rounded.
+func Parse(s string) (Amount, error) {
+	input := s
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, &ParseError{Input: input, Reason: "not a number"}
+	}
+	if len(fraction) > 2 {
+		return 0, &ParseError{Input: input, Reason: "more than two decimal places"}
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, &ParseError{Input: input, Reason: "not a number"}
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", 2-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, &ParseError{Input: input, Reason: "out of range"}
+	}
+	if negative {
+		units = -units
+	}
+	return Amount(units), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseError reports a value that is not a valid amount.
+type ParseError struct {
+	Input  string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid amount %q: %s", e.Input, e.Reason)
+}
+
+// Minor returns the amount in minor units.
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+// Float64 is for metrics and other approximate uses only.
+func (a Amount) Float64() float64 {
+	return float64(a) / scale
+}
+
+func (a Amount) String() string {
+	sign := ""
+	minor := int64(a)
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/scale, minor%scale)
+}
+
+// Mul multiplies the amount by a quantity.
+func (a Amount) Mul(quantity int) Amount {
+	return a * Amount(quantity)
+}
+
+// MulDiv returns a*num/den rounded half-up, for splitting an amount in
+// proportion to others. It returns 0 when den is 0.
+func (a Amount) MulDiv(num, den Amount) Amount {
+	if den == 0 {
+		return 0
+	}
+	return Amount(divRound(int64(a)*int64(num), int64(den)))
+}
+
+// Percent returns percent% of the amount rounded half-up to the satang, the
+// rounding used for VAT and percentage discounts. percent is itself an
+// Amount so that rates such as 2.5% are exact.
+func (a Amount) Percent(percent Amount) Amount {
+	return Amount(divRound(int64(a)*int64(percent), 100*scale))
+}
+
+// divRound divides rounding halves away from zero.
+func divRound(n, d int64) int64 {
+	if d < 0 {
+		n, d = -n, -d
+	}
+	if n < 0 {
+		return -((-n + d/2) / d)
+	}
+	return (n + d/2) / d
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// MarshalText writes the amount as a decimal, as XML documents carry it.
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts both "12.50" and 12.50 so existing clients that send
+// numbers keep working.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case int64:
+		*a = FromInt(v)
+	case float64:
+		*a = Amount(math.Round(v * scale))
+	case []byte:
+		return a.Scan(string(v))
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into money.Amount", src)
+	}
+	return nil
+}
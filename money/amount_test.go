@@ -0,0 +1,231 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Amount
+	}{
+		{"0", 0},
+		{"12", 1200},
+		{"12.5", 1250},
+		{"1250.50", 125050},
+		{" 7.05 ", 705},
+		{"+3", 300},
+		{"-3.5", -350},
+		{"-0.01", -1},
+		{".5", 50},
+		{"1.", 100},
+		{"92233720368547758.07", 9223372036854775807},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+	}{
+		{"", "not a number"},
+		{".", "not a number"},
+		{"-", "not a number"},
+		{"1.005", "more than two decimal places"},
+		{"0.125", "more than two decimal places"},
+		{"1,000", "not a number"},
+		{"1e3", "not a number"},
+		{"--1", "not a number"},
+		{"1.2.", "not a number"},
+		{"92233720368547758.08", "out of range"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tt.input, err)
+			continue
+		}
+		if parseErr.Reason != tt.reason {
+			t.Errorf("Parse(%q) reason = %q, want %q", tt.input, parseErr.Reason, tt.reason)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{125050, "1250.50"},
+		{-1, "-0.01"},
+		{-350, "-3.50"},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		amount   Amount
+		quantity int
+		want     Amount
+	}{
+		{1250, 3, 3750},
+		{-1250, 3, -3750},
+		{1250, 0, 0},
+		{333, -2, -666},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.Mul(tt.quantity); got != tt.want {
+			t.Errorf("%s.Mul(%d) = %s, want %s", tt.amount, tt.quantity, got, tt.want)
+		}
+	}
+}
+
+// Halves round away from zero so that a negative amount rounds to the
+// negation of its positive counterpart.
+func TestPercentRoundsHalfAwayFromZero(t *testing.T) {
+	tests := []struct {
+		amount  Amount
+		percent Amount
+		want    Amount
+	}{
+		{10000, 700, 700},  // 7% of 100.00
+		{50, 100, 1},       // 1% of 0.50 is 0.005
+		{49, 100, 0},       // 1% of 0.49 is 0.0049
+		{-50, 100, -1},     // -0.005
+		{-49, 100, 0},      // -0.0049
+		{1450, 700, 102},   // 7% of 14.50 is 1.015
+		{-1450, 700, -102}, // -1.015
+		{1000, 250, 25},    // 2.5% of 10.00
+		{10000, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.Percent(tt.percent); got != tt.want {
+			t.Errorf("%s.Percent(%s) = %s, want %s", tt.amount, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct {
+		amount, num, den Amount
+		want             Amount
+	}{
+		{10000, 1, 3, 3333},
+		{10000, 2, 3, 6667},
+		{-10000, 2, 3, -6667},
+		{10000, 2, -3, -6667},
+		{1, 1, 2, 1},
+		{-1, 1, 2, -1},
+		{10000, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.MulDiv(tt.num, tt.den); got != tt.want {
+			t.Errorf("%d.MulDiv(%d, %d) = %d, want %d", tt.amount, tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Total Amount `json:"total"`
+	}{Total: -125050})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"total":"-1250.50"}` {
+		t.Errorf("json.Marshal = %s", data)
+	}
+
+	tests := []struct {
+		input string
+		want  Amount
+	}{
+		{`"-1250.50"`, -125050},
+		{`1250.5`, 125050},
+		{`12`, 1200},
+		{`null`, 99},
+	}
+	for _, tt := range tests {
+		got := Amount(99)
+		if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+			t.Errorf("json.Unmarshal(%s) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("json.Unmarshal(%s) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{`"1.005"`, `1.005`, `"abc"`, `true`} {
+		var got Amount
+		if err := json.Unmarshal([]byte(input), &got); err == nil {
+			t.Errorf("json.Unmarshal(%s) = %s, want an error", input, got)
+		}
+	}
+}
+
+func TestAmountSQL(t *testing.T) {
+	for _, amount := range []Amount{0, 1, -1, 125050, -125050} {
+		value, err := amount.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var scanned Amount
+		if err := scanned.Scan(value); err != nil {
+			t.Errorf("Scan(%v) failed: %v", value, err)
+		}
+		if scanned != amount {
+			t.Errorf("Scan(Value(%d)) = %d", amount, scanned)
+		}
+	}
+
+	tests := []struct {
+		src  any
+		want Amount
+	}{
+		{nil, 0},
+		{int64(12), 1200},
+		{float64(0.125), 13},
+		{float64(-0.125), -13},
+		{[]byte("1250.50"), 125050},
+		{"-3.50", -350},
+	}
+	for _, tt := range tests {
+		got := Amount(99)
+		if err := got.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%#v) failed: %v", tt.src, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Scan(%#v) = %d, want %d", tt.src, got, tt.want)
+		}
+	}
+
+	var got Amount
+	if err := got.Scan(true); err == nil {
+		t.Error("Scan(true) succeeded")
+	}
+	if err := got.Scan("1.005"); err == nil {
+		t.Error(`Scan("1.005") succeeded`)
+	}
+}
@@ -0,0 +1,118 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// BaseCurrency is the currency reports are totalled in.
+const BaseCurrency = "THB"
+
+// rateScale is the number of decimal places kept for exchange rates.
+const rateScale = 1_000_000
+
+// Rate is an exchange rate to BaseCurrency with six decimal places, e.g.
+// 35.123456 baht per dollar. It is stored as numeric(18,6) and encoded in
+// JSON as a string.
+type Rate int64
+
+// RateOne converts an amount to itself.
+const RateOne Rate = rateScale
+
+// ParseRate reads a decimal with at most six decimal places.
+func ParseRate(s string) (Rate, error) {
+	input := s
+	s = strings.TrimSpace(s)
+	whole, fraction, _ := strings.Cut(s, ".")
+	if (whole == "" && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return 0, &ParseError{Input: input, Reason: "not a rate"}
+	}
+	if len(fraction) > 6 {
+		return 0, &ParseError{Input: input, Reason: "more than six decimal places"}
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	units, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", 6-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, &ParseError{Input: input, Reason: "out of range"}
+	}
+	return Rate(units), nil
+}
+
+func (r Rate) IsZero() bool {
+	return r == 0
+}
+
+func (r Rate) String() string {
+	return fmt.Sprintf("%d.%06d", int64(r)/rateScale, int64(r)%rateScale)
+}
+
+// Convert returns amount*r rounded half-up to the satang. The product is
+// computed with big integers so large amounts cannot overflow.
+func (r Rate) Convert(amount Amount) Amount {
+	n := new(big.Int).Mul(big.NewInt(int64(amount)), big.NewInt(int64(r)))
+	negative := n.Sign() < 0
+	n.Abs(n)
+	n.Add(n, big.NewInt(rateScale/2))
+	n.Quo(n, big.NewInt(rateScale))
+	if negative {
+		n.Neg(n)
+	}
+	return Amount(n.Int64())
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseRate(text)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func (r *Rate) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*r = 0
+	case int64:
+		*r = Rate(v * rateScale)
+	case float64:
+		*r = Rate(math.Round(v * rateScale))
+	case []byte:
+		return r.Scan(string(v))
+	case string:
+		parsed, err := ParseRate(v)
+		if err != nil {
+			return err
+		}
+		*r = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into money.Rate", src)
+	}
+	return nil
+}
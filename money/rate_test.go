@@ -0,0 +1,86 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Rate
+	}{
+		{"1", RateOne},
+		{"35.123456", 35123456},
+		{"0.5", 500000},
+		{".000001", 1},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.input)
+		if err != nil {
+			t.Errorf("ParseRate(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", ".", "-1", "1.0000001", "abc"} {
+		if got, err := ParseRate(input); err == nil {
+			t.Errorf("ParseRate(%q) = %s, want an error", input, got)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		rate   Rate
+		amount Amount
+		want   Amount
+	}{
+		{RateOne, 125050, 125050},
+		{35123456, 10000, 351235},   // 100.00 USD is 3512.3456 THB
+		{35123450, 10000, 351235},   // 3512.345 rounds up
+		{35123450, -10000, -351235}, // and a refund rounds down
+		{500000, 1, 1},              // 0.005 rounds up
+		{500000, -1, -1},
+		{0, 10000, 0},
+		{40000000, 9000000000000000, 360000000000000000},
+	}
+	for _, tt := range tests {
+		if got := tt.rate.Convert(tt.amount); got != tt.want {
+			t.Errorf("%s.Convert(%s) = %s, want %s", tt.rate, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestRateRoundTrip(t *testing.T) {
+	for _, rate := range []Rate{0, 1, RateOne, 35123456} {
+		data, err := json.Marshal(rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Rate
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded != rate {
+			t.Errorf("JSON round trip of %s = %s (%v)", rate, decoded, err)
+		}
+
+		value, err := rate.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var scanned Rate
+		if err := scanned.Scan(value); err != nil || scanned != rate {
+			t.Errorf("SQL round trip of %s = %s (%v)", rate, scanned, err)
+		}
+	}
+
+	var scanned Rate
+	if err := scanned.Scan(float64(35.1234567)); err != nil || scanned != 35123457 {
+		t.Errorf("Scan(35.1234567) = %s (%v)", scanned, err)
+	}
+	if err := scanned.Scan([]byte("1.5")); err != nil || scanned != 1500000 {
+		t.Errorf(`Scan([]byte("1.5")) = %s (%v)`, scanned, err)
+	}
+}
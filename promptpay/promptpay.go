@@ -0,0 +1,140 @@
+// Package promptpay builds the EMVCo QR payloads Thai banking apps scan to
+// pay by PromptPay.
+package promptpay
+
+import (
+	"errors"
+	"fmt"
+	"mtii-backend/money"
+	"mtii-backend/taxid"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/skip2/go-qrcode"
+)
+
+// Currency is the only currency PromptPay transfers are made in.
+const Currency = "THB"
+
+// EMVCo tags of the payload and the PromptPay application identifier.
+const (
+	tagFormat        = "00"
+	tagInitiation    = "01"
+	tagMerchant      = "29"
+	tagCurrency      = "53"
+	tagAmount        = "54"
+	tagCountry       = "58"
+	tagCRC           = "63"
+	tagApplicationId = "00"
+	tagPhone         = "01"
+	tagTaxId         = "02"
+
+	applicationId = "A000000677010111"
+	// staticQR marks a payload the payer enters the amount of, dynamicQR
+	// one for a single payment of a set amount.
+	staticQR  = "11"
+	dynamicQR = "12"
+	// currencyTHB is the ISO 4217 numeric code of the baht.
+	currencyTHB = "764"
+)
+
+// ErrInvalidProxy is returned for a proxy that is neither a mobile number
+// nor a tax ID.
+var ErrInvalidProxy = errors.New("promptpay: the proxy must be a 10-digit mobile number or a 13-digit tax ID")
+
+// Normalize removes the dashes and spaces people type between digit groups
+// and writes +66 numbers in their local form.
+func Normalize(s string) string {
+	s = taxid.Normalize(s)
+	if strings.HasPrefix(s, "+66") {
+		s = "0" + s[3:]
+	}
+	return s
+}
+
+// Valid reports whether s, once normalized, is a proxy PromptPay accounts
+// are registered under: a mobile number such as 0812345678 or a tax ID.
+func Valid(s string) bool {
+	s = Normalize(s)
+	return isPhone(s) || taxid.Valid(s)
+}
+
+func isPhone(s string) bool {
+	if len(s) != 10 || s[0] != '0' {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Payload returns the payload of a QR code paying amount to the PromptPay
+// account registered under proxy. A zero amount leaves it to the payer.
+// The fields are written in the order of the payloads Thai banks issue.
+func Payload(proxy string, amount money.Amount) (string, error) {
+	proxy = Normalize(proxy)
+	var account string
+	switch {
+	case isPhone(proxy):
+		// Mobile numbers are written with the country code, zero padded to
+		// 13 digits.
+		account = field(tagPhone, "0066"+proxy[1:])
+	case taxid.Valid(proxy):
+		account = field(tagTaxId, proxy)
+	default:
+		return "", ErrInvalidProxy
+	}
+	if amount < 0 {
+		return "", fmt.Errorf("promptpay: the amount cannot be negative, got %s", amount)
+	}
+
+	initiation, amountField := staticQR, ""
+	if amount > 0 {
+		initiation, amountField = dynamicQR, field(tagAmount, amount.String())
+	}
+	payload := field(tagFormat, "01") +
+		field(tagInitiation, initiation) +
+		field(tagMerchant, field(tagApplicationId, applicationId)+account) +
+		field(tagCountry, "TH") +
+		field(tagCurrency, currencyTHB) +
+		amountField +
+		tagCRC + "04"
+	return payload + fmt.Sprintf("%04X", CRC16([]byte(payload))), nil
+}
+
+// field writes an EMVCo data object: its tag, two-digit length and value.
+func field(tag, value string) string {
+	return fmt.Sprintf("%s%02d%s", tag, len(value), value)
+}
+
+// CRC16 is the CRC-16/CCITT-FALSE checksum EMVCo payloads end with:
+// polynomial 0x1021, starting from 0xFFFF.
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// PNG draws the payload as a QR code size pixels wide.
+func PNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+// RegisterValidations adds the "promptpay" binding rule.
+func RegisterValidations(v *validator.Validate) error {
+	return v.RegisterValidation("promptpay", func(fl validator.FieldLevel) bool {
+		return Valid(fl.Field().String())
+	})
+}
@@ -0,0 +1,110 @@
+package promptpay
+
+import (
+	"errors"
+	"mtii-backend/money"
+	"testing"
+)
+
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint16
+	}{
+		// The check value of CRC-16/CCITT-FALSE.
+		{data: "123456789", want: 0x29B1},
+		{data: "", want: 0xFFFF},
+		{data: "00020101021129370016A000000677010111011300668012345675802TH53037646304", want: 0x6197},
+	}
+	for _, tt := range tests {
+		if got := CRC16([]byte(tt.data)); got != tt.want {
+			t.Errorf("CRC16(%q) = %04X, want %04X", tt.data, got, tt.want)
+		}
+	}
+}
+
+// The phone payloads are the examples PromptPay generators are checked
+// against; the tax ID ones follow their layout with tag 02.
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		name   string
+		proxy  string
+		amount string
+		want   string
+	}{
+		{
+			name:  "phone without amount",
+			proxy: "0801234567", amount: "0",
+			want: "00020101021129370016A000000677010111011300668012345675802TH530376463046197",
+		},
+		{
+			name:  "phone with amount",
+			proxy: "000-000-0000", amount: "4.22",
+			want: "00020101021229370016A000000677010111011300660000000005802TH530376454044.226304E469",
+		},
+		{
+			name:  "international phone",
+			proxy: "+66 80 123 4567", amount: "0",
+			want: "00020101021129370016A000000677010111011300668012345675802TH530376463046197",
+		},
+		{
+			name:  "tax ID without amount",
+			proxy: "1-2345-67890-12-1", amount: "0",
+			want: "00020101021129370016A000000677010111021312345678901215802TH530376463041C03",
+		},
+		{
+			name:  "tax ID with amount",
+			proxy: "1234567890121", amount: "1070.00",
+			want: "00020101021229370016A000000677010111021312345678901215802TH530376454071070.0063040D56",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := money.Parse(tt.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Payload(tt.proxy, amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Payload(%q, %s) =\n%s, want\n%s", tt.proxy, tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadRejects(t *testing.T) {
+	if _, err := Payload("12345", money.FromInt(1)); !errors.Is(err, ErrInvalidProxy) {
+		t.Errorf("short proxy: err = %v, want ErrInvalidProxy", err)
+	}
+	// The check digit of a valid tax ID changed.
+	if _, err := Payload("1234567890122", money.FromInt(1)); !errors.Is(err, ErrInvalidProxy) {
+		t.Errorf("bad tax ID checksum: err = %v, want ErrInvalidProxy", err)
+	}
+	if _, err := Payload("0801234567", -money.FromInt(1)); err == nil {
+		t.Error("negative amount: err = nil")
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"0812345678", true},
+		{"081-234-5678", true},
+		{"+66812345678", true},
+		{"1234567890121", true},
+		{"1234567890122", false},
+		{"812345678", false},
+		{"08123456789", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.s); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
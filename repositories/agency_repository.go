@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+
+	"gorm.io/gorm"
+)
+
+type AgencyRepository interface {
+	GetAllAgency(ctx context.Context, taxId string) ([]entities.Agency, error)
+	GetAgencyById(ctx context.Context, agencyId int) (entities.Agency, error)
+	CreateAgency(ctx context.Context, agency entities.Agency) (entities.Agency, error)
+	UpdateAgency(ctx context.Context, agency entities.Agency) (entities.Agency, error)
+	DeleteAgency(ctx context.Context, agencyId int) error
+}
+
+type agencyRepository struct {
+	db *gorm.DB
+}
+
+func NewAgencyRepository(db *gorm.DB) AgencyRepository {
+	return &agencyRepository{
+		db: db,
+	}
+}
+
+// GetAllAgency lists agencies with their contacts and brands, keeping only
+// those whose tax ID starts with taxId when it is set.
+func (r *agencyRepository) GetAllAgency(ctx context.Context, taxId string) ([]entities.Agency, error) {
+	ctx, span := telemetry.Start(ctx, "AgencyRepository.GetAllAgency")
+	defer span.End()
+
+	var agencies []entities.Agency
+	query := session(ctx, r.db, "AgencyRepository.GetAllAgency").
+		Preload("Contacts", orderById).
+		Preload("Brands", orderById).
+		Order("id")
+	if taxId != "" {
+		query = query.Where("tax_payer_id LIKE ?", taxId+"%")
+	}
+	err := query.Find(&agencies).Error
+	if err != nil {
+		return []entities.Agency{}, err
+	}
+	return agencies, err
+}
+
+func (r *agencyRepository) GetAgencyById(ctx context.Context, agencyId int) (entities.Agency, error) {
+	ctx, span := telemetry.Start(ctx, "AgencyRepository.GetAgencyById")
+	defer span.End()
+
+	var agency entities.Agency
+	err := session(ctx, r.db, "AgencyRepository.GetAgencyById").
+		Preload("Contacts", orderById).
+		Preload("Brands", orderById).
+		Where("id = ?", agencyId).First(&agency).Error
+	if err != nil {
+		return entities.Agency{}, err
+	}
+	return agency, err
+}
+
+func (r *agencyRepository) CreateAgency(ctx context.Context, agency entities.Agency) (entities.Agency, error) {
+	ctx, span := telemetry.Start(ctx, "AgencyRepository.CreateAgency")
+	defer span.End()
+
+	err := session(ctx, r.db, "AgencyRepository.CreateAgency").Create(&agency).Error
+	if err != nil {
+		return entities.Agency{}, err
+	}
+	return agency, err
+}
+
+func (r *agencyRepository) UpdateAgency(ctx context.Context, agency entities.Agency) (entities.Agency, error) {
+	ctx, span := telemetry.Start(ctx, "AgencyRepository.UpdateAgency")
+	defer span.End()
+
+	err := session(ctx, r.db, "AgencyRepository.UpdateAgency").Save(&agency).Error
+	if err != nil {
+		return entities.Agency{}, err
+	}
+	return agency, err
+}
+
+func (r *agencyRepository) DeleteAgency(ctx context.Context, agencyId int) error {
+	ctx, span := telemetry.Start(ctx, "AgencyRepository.DeleteAgency")
+	defer span.End()
+
+	err := session(ctx, r.db, "AgencyRepository.DeleteAgency").Delete(&entities.Agency{}, "id = ?", agencyId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func orderById(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
 )
@@ -26,8 +27,11 @@ func NewBankRepository(db *gorm.DB) BankRepository {
 }
 
 func (r *bankRepository) GetAllBank(ctx context.Context) ([]entities.Bank, error) {
+	ctx, span := telemetry.Start(ctx, "BankRepository.GetAllBank")
+	defer span.End()
+
 	var banks []entities.Bank
-	err := r.db.Find(&banks).Error
+	err := session(ctx, r.db, "BankRepository.GetAllBank").Find(&banks).Error
 	if err != nil {
 		return []entities.Bank{}, err
 	}
@@ -35,8 +39,11 @@ func (r *bankRepository) GetAllBank(ctx context.Context) ([]entities.Bank, error
 }
 
 func (r *bankRepository) GetBankById(ctx context.Context, bankId int) (entities.Bank, error) {
+	ctx, span := telemetry.Start(ctx, "BankRepository.GetBankById")
+	defer span.End()
+
 	var bank entities.Bank
-	err := r.db.Where("id = ?", bankId).First(&bank).Error
+	err := session(ctx, r.db, "BankRepository.GetBankById").Where("id = ?", bankId).First(&bank).Error
 	if err != nil {
 		return entities.Bank{}, err
 	}
@@ -44,7 +51,10 @@ func (r *bankRepository) GetBankById(ctx context.Context, bankId int) (entities.
 }
 
 func (r *bankRepository) CreateBank(ctx context.Context, bank entities.Bank) (entities.Bank, error) {
-	err := r.db.Create(&bank).Error
+	ctx, span := telemetry.Start(ctx, "BankRepository.CreateBank")
+	defer span.End()
+
+	err := session(ctx, r.db, "BankRepository.CreateBank").Create(&bank).Error
 	if err != nil {
 		return entities.Bank{}, err
 	}
@@ -52,7 +62,10 @@ func (r *bankRepository) CreateBank(ctx context.Context, bank entities.Bank) (en
 }
 
 func (r *bankRepository) UpdateBank(ctx context.Context, bank entities.Bank) (entities.Bank, error) {
-	err := r.db.Save(&bank).Error
+	ctx, span := telemetry.Start(ctx, "BankRepository.UpdateBank")
+	defer span.End()
+
+	err := session(ctx, r.db, "BankRepository.UpdateBank").Save(&bank).Error
 	if err != nil {
 		return entities.Bank{}, err
 	}
@@ -60,7 +73,10 @@ func (r *bankRepository) UpdateBank(ctx context.Context, bank entities.Bank) (en
 }
 
 func (r *bankRepository) DeleteBank(ctx context.Context, bankId int) error {
-	err := r.db.Delete(&entities.Bank{}, "id = ?", bankId).Error
+	ctx, span := telemetry.Start(ctx, "BankRepository.DeleteBank")
+	defer span.End()
+
+	err := session(ctx, r.db, "BankRepository.DeleteBank").Delete(&entities.Bank{}, "id = ?", bankId).Error
 	if err != nil {
 		return err
 	}
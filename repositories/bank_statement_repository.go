@@ -0,0 +1,364 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Statuses of a BankTransaction.
+const (
+	BankTransactionUnmatched = "unmatched"
+	BankTransactionPending   = "pending"
+	BankTransactionConfirmed = "confirmed"
+)
+
+// Statuses of a BankTransactionMatch.
+const (
+	BankMatchPending   = "pending"
+	BankMatchConfirmed = "confirmed"
+	BankMatchRejected  = "rejected"
+)
+
+var (
+	// ErrMatchReviewed is returned when a match was confirmed or rejected
+	// by someone else since it was read.
+	ErrMatchReviewed = errors.New("bank transaction match is already reviewed")
+	// ErrIncomeBalanceChanged is returned when a payment was recorded
+	// against the income since its balance was read.
+	ErrIncomeBalanceChanged = errors.New("income balance changed")
+)
+
+type BankStatementRepository interface {
+	GetStatementFormat(ctx context.Context, bankId int) (entities.BankStatementFormat, error)
+	SaveStatementFormat(ctx context.Context, format entities.BankStatementFormat) (entities.BankStatementFormat, error)
+	CreateStatement(ctx context.Context, statement entities.BankStatement, transactions []entities.BankTransaction) (entities.BankStatement, []entities.BankTransaction, error)
+	GetAllBankTransaction(ctx context.Context, status string, bankId int) ([]entities.BankTransaction, error)
+	GetBankTransactionById(ctx context.Context, transactionId int) (entities.BankTransaction, error)
+	SaveMatches(ctx context.Context, transactionId int, matches []entities.BankTransactionMatch) error
+	GetMatchById(ctx context.Context, matchId int) (entities.BankTransactionMatch, entities.BankTransaction, error)
+	ConfirmMatch(ctx context.Context, match entities.BankTransactionMatch, income entities.Income) error
+	RejectMatch(ctx context.Context, match entities.BankTransactionMatch) error
+}
+
+type bankStatementRepository struct {
+	db *gorm.DB
+}
+
+func NewBankStatementRepository(db *gorm.DB) BankStatementRepository {
+	return &bankStatementRepository{
+		db: db,
+	}
+}
+
+func (r *bankStatementRepository) GetStatementFormat(ctx context.Context, bankId int) (entities.BankStatementFormat, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.GetStatementFormat")
+	defer span.End()
+
+	var format entities.BankStatementFormat
+	err := session(ctx, r.db, "BankStatementRepository.GetStatementFormat").Where("bank_id = ?", bankId).First(&format).Error
+	if err != nil {
+		return entities.BankStatementFormat{}, err
+	}
+	return format, err
+}
+
+// SaveStatementFormat creates or replaces the statement format of a bank.
+func (r *bankStatementRepository) SaveStatementFormat(ctx context.Context, format entities.BankStatementFormat) (entities.BankStatementFormat, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.SaveStatementFormat")
+	defer span.End()
+
+	err := session(ctx, r.db, "BankStatementRepository.SaveStatementFormat").
+		Omit("Bank").
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "bank_id"}}, UpdateAll: true}).
+		Create(&format).Error
+	if err != nil {
+		return entities.BankStatementFormat{}, err
+	}
+	return format, err
+}
+
+// CreateStatement saves a statement with its transactions, skipping those
+// already saved from an earlier upload. It returns the statement and the
+// transactions that are new.
+func (r *bankStatementRepository) CreateStatement(ctx context.Context, statement entities.BankStatement, transactions []entities.BankTransaction) (entities.BankStatement, []entities.BankTransaction, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.CreateStatement")
+	defer span.End()
+
+	tx := session(ctx, r.db, "BankStatementRepository.CreateStatement").Begin()
+	if tx.Error != nil {
+		return entities.BankStatement{}, nil, tx.Error
+	}
+
+	if err := tx.Create(&statement).Error; err != nil {
+		tx.Rollback()
+		return entities.BankStatement{}, nil, err
+	}
+
+	created := []entities.BankTransaction{}
+	for _, t := range transactions {
+		t.BankStatementId = statement.Id
+		result := tx.Omit("Income").
+			Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "fingerprint"}}, DoNothing: true}).
+			Create(&t)
+		if result.Error != nil {
+			tx.Rollback()
+			return entities.BankStatement{}, nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			created = append(created, t)
+		}
+	}
+
+	statement.Credits = len(created)
+	statement.Duplicates = len(transactions) - len(created)
+	err := tx.Model(&statement).
+		Select("credits", "duplicates").
+		Updates(&statement).Error
+	if err != nil {
+		tx.Rollback()
+		return entities.BankStatement{}, nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return entities.BankStatement{}, nil, err
+	}
+
+	return statement, created, nil
+}
+
+// GetAllBankTransaction lists transactions, newest first, with their
+// matches by descending score and the incomes matched.
+func (r *bankStatementRepository) GetAllBankTransaction(ctx context.Context, status string, bankId int) ([]entities.BankTransaction, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.GetAllBankTransaction")
+	defer span.End()
+
+	var transactions []entities.BankTransaction
+	query := session(ctx, r.db, "BankStatementRepository.GetAllBankTransaction")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if bankId != 0 {
+		query = query.Where("bank_id = ?", bankId)
+	}
+	err := query.
+		Preload("Matches", func(db *gorm.DB) *gorm.DB {
+			return db.Order("score DESC, id")
+		}).
+		Preload("Matches.Income").
+		Order("date DESC, id DESC").
+		Find(&transactions).Error
+	if err != nil {
+		return []entities.BankTransaction{}, err
+	}
+	return transactions, err
+}
+
+func (r *bankStatementRepository) GetBankTransactionById(ctx context.Context, transactionId int) (entities.BankTransaction, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.GetBankTransactionById")
+	defer span.End()
+
+	var transaction entities.BankTransaction
+	err := session(ctx, r.db, "BankStatementRepository.GetBankTransactionById").
+		Preload("Matches", func(db *gorm.DB) *gorm.DB {
+			return db.Order("score DESC, id")
+		}).
+		Preload("Matches.Income").
+		Where("id = ?", transactionId).
+		First(&transaction).Error
+	if err != nil {
+		return entities.BankTransaction{}, err
+	}
+	return transaction, err
+}
+
+// SaveMatches replaces the pending matches of a transaction, keeping those
+// already reviewed, and marks it pending when any match is left to review.
+func (r *bankStatementRepository) SaveMatches(ctx context.Context, transactionId int, matches []entities.BankTransactionMatch) error {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.SaveMatches")
+	defer span.End()
+
+	tx := session(ctx, r.db, "BankStatementRepository.SaveMatches").Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	err := tx.Where("bank_transaction_id = ? AND status = ?", transactionId, BankMatchPending).
+		Delete(&entities.BankTransactionMatch{}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(matches) > 0 {
+		for i := range matches {
+			matches[i].BankTransactionId = transactionId
+			matches[i].Status = BankMatchPending
+		}
+		if err := tx.Omit("Income").Create(&matches).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	status := BankTransactionUnmatched
+	if len(matches) > 0 {
+		status = BankTransactionPending
+	}
+	err = tx.Model(&entities.BankTransaction{}).
+		Where("id = ? AND status <> ?", transactionId, BankTransactionConfirmed).
+		Update("status", status).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetMatchById returns a match with the income it suggests, and its
+// transaction.
+func (r *bankStatementRepository) GetMatchById(ctx context.Context, matchId int) (entities.BankTransactionMatch, entities.BankTransaction, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.GetMatchById")
+	defer span.End()
+
+	var match entities.BankTransactionMatch
+	err := session(ctx, r.db, "BankStatementRepository.GetMatchById").
+		Preload("Income").
+		Where("id = ?", matchId).
+		First(&match).Error
+	if err != nil {
+		return entities.BankTransactionMatch{}, entities.BankTransaction{}, err
+	}
+
+	var transaction entities.BankTransaction
+	err = session(ctx, r.db, "BankStatementRepository.GetMatchById").
+		Where("id = ?", match.BankTransactionId).
+		First(&transaction).Error
+	if err != nil {
+		return entities.BankTransactionMatch{}, entities.BankTransaction{}, err
+	}
+	return match, transaction, nil
+}
+
+// ConfirmMatch records that the match's transaction pays its income: it
+// saves the income's payment columns, confirms the transaction and rejects
+// its other matches. Once the income is paid in full, matches of other
+// transactions to it are rejected too, and those transactions go back to
+// unmatched when nothing is left to review.
+//
+// The match must still be pending and the income's balance must still be
+// the one read with the match, match.Income; otherwise nothing is saved and
+// ErrMatchReviewed or ErrIncomeBalanceChanged is returned.
+func (r *bankStatementRepository) ConfirmMatch(ctx context.Context, match entities.BankTransactionMatch, income entities.Income) error {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.ConfirmMatch")
+	defer span.End()
+
+	now := time.Now()
+	tx := session(ctx, r.db, "BankStatementRepository.ConfirmMatch").Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	confirmed := tx.Model(&entities.BankTransactionMatch{}).
+		Where("id = ? AND status = ?", match.Id, BankMatchPending).
+		Updates(map[string]any{"status": BankMatchConfirmed, "reviewed_at": now})
+	if confirmed.Error != nil {
+		tx.Rollback()
+		return confirmed.Error
+	}
+	if confirmed.RowsAffected == 0 {
+		tx.Rollback()
+		return ErrMatchReviewed
+	}
+
+	paid := tx.Model(&entities.Income{}).
+		Where("invoice_id_number = ? AND unpaid_payment_amount = ?", income.InvoiceIdNumber, match.Income.UnpaidPaymentAmount).
+		Select("first_payment", "notes_for_the_first_payment", "second_payment", "notes_for_the_second_payment",
+			"unpaid_payment_amount", "receipt_issue_date").
+		Updates(&income)
+	if paid.Error != nil {
+		tx.Rollback()
+		return paid.Error
+	}
+	if paid.RowsAffected == 0 {
+		tx.Rollback()
+		return ErrIncomeBalanceChanged
+	}
+
+	rejected := tx.Model(&entities.BankTransactionMatch{}).Where("status = ?", BankMatchPending)
+	if income.UnpaidPaymentAmount.IsZero() {
+		rejected = rejected.Where("(bank_transaction_id = ? OR income_invoice_id_number = ?)", match.BankTransactionId, income.InvoiceIdNumber)
+	} else {
+		rejected = rejected.Where("bank_transaction_id = ?", match.BankTransactionId)
+	}
+	if err := rejected.Updates(map[string]any{"status": BankMatchRejected, "reviewed_at": now}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err := tx.Model(&entities.BankTransaction{}).
+		Where("id = ?", match.BankTransactionId).
+		Updates(map[string]any{
+			"status":                   BankTransactionConfirmed,
+			"income_invoice_id_number": income.InvoiceIdNumber,
+			"confirmed_at":             now,
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := unmatchReviewed(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RejectMatch rejects a match, leaving its transaction unmatched when it
+// was the last one to review. It returns ErrMatchReviewed when the match is
+// no longer pending.
+func (r *bankStatementRepository) RejectMatch(ctx context.Context, match entities.BankTransactionMatch) error {
+	ctx, span := telemetry.Start(ctx, "BankStatementRepository.RejectMatch")
+	defer span.End()
+
+	tx := session(ctx, r.db, "BankStatementRepository.RejectMatch").Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	rejected := tx.Model(&entities.BankTransactionMatch{}).
+		Where("id = ? AND status = ?", match.Id, BankMatchPending).
+		Updates(map[string]any{"status": BankMatchRejected, "reviewed_at": time.Now()})
+	if rejected.Error != nil {
+		tx.Rollback()
+		return rejected.Error
+	}
+	if rejected.RowsAffected == 0 {
+		tx.Rollback()
+		return ErrMatchReviewed
+	}
+
+	if err := unmatchReviewed(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// unmatchReviewed sets pending transactions with no match left to review
+// back to unmatched.
+func unmatchReviewed(tx *gorm.DB) error {
+	return tx.Model(&entities.BankTransaction{}).
+		Where("status = ?", BankTransactionPending).
+		Where("NOT EXISTS (SELECT 1 FROM bank_transaction_matches m WHERE m.bank_transaction_id = bank_transactions.id AND m.status = ?)", BankMatchPending).
+		Update("status", BankTransactionUnmatched).Error
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"testing"
+	"time"
+)
+
+// newMatchTestDB stores an income of 100 with one pending match from a
+// transaction of 100 and returns the match as GetMatchById would.
+func newMatchTestDB(t *testing.T) (BankStatementRepository, entities.BankTransactionMatch) {
+	t.Helper()
+	db := newTestDB(t, &entities.Income{}, &entities.Detail{}, &entities.BankTransaction{}, &entities.BankTransactionMatch{})
+
+	income := testIncome(100)
+	income.UnpaidPaymentAmount = money.FromInt(100)
+	if err := db.Create(&income).Error; err != nil {
+		t.Fatal(err)
+	}
+	transaction := entities.BankTransaction{
+		BankStatementId: 1,
+		BankId:          1,
+		Date:            time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Amount:          money.FromInt(100),
+		Currency:        "THB",
+		Fingerprint:     "fingerprint",
+		Status:          BankTransactionPending,
+	}
+	if err := db.Create(&transaction).Error; err != nil {
+		t.Fatal(err)
+	}
+	match := entities.BankTransactionMatch{
+		BankTransactionId:     transaction.Id,
+		IncomeInvoiceIdNumber: income.InvoiceIdNumber,
+		Score:                 90,
+		Status:                BankMatchPending,
+	}
+	if err := db.Omit("Income").Create(&match).Error; err != nil {
+		t.Fatal(err)
+	}
+	match.Income = income
+	return NewBankStatementRepository(db), match
+}
+
+// paid is the income of match once the transaction pays it in full.
+func paid(match entities.BankTransactionMatch) entities.Income {
+	income := match.Income
+	income.FirstPayment = money.FromInt(100)
+	income.UnpaidPaymentAmount = 0
+	return income
+}
+
+func TestConfirmMatchTwice(t *testing.T) {
+	ctx := context.Background()
+	repo, match := newMatchTestDB(t)
+
+	if err := repo.ConfirmMatch(ctx, match, paid(match)); err != nil {
+		t.Fatal(err)
+	}
+	// A second reviewer loaded the match before the first one confirmed it.
+	if err := repo.ConfirmMatch(ctx, match, paid(match)); !errors.Is(err, ErrMatchReviewed) {
+		t.Errorf("second ConfirmMatch = %v, want ErrMatchReviewed", err)
+	}
+	if err := repo.RejectMatch(ctx, match); !errors.Is(err, ErrMatchReviewed) {
+		t.Errorf("RejectMatch after confirming = %v, want ErrMatchReviewed", err)
+	}
+}
+
+func TestConfirmMatchStaleBalance(t *testing.T) {
+	ctx := context.Background()
+	repo, match := newMatchTestDB(t)
+
+	// Another payment was recorded after the match was loaded.
+	stale := match
+	stale.Income.UnpaidPaymentAmount = money.FromInt(150)
+	if err := repo.ConfirmMatch(ctx, stale, paid(stale)); !errors.Is(err, ErrIncomeBalanceChanged) {
+		t.Fatalf("ConfirmMatch = %v, want ErrIncomeBalanceChanged", err)
+	}
+
+	reloaded, transaction, err := repo.GetMatchById(ctx, match.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Status != BankMatchPending {
+		t.Errorf("match status = %q, want it rolled back to %q", reloaded.Status, BankMatchPending)
+	}
+	if transaction.Status != BankTransactionPending {
+		t.Errorf("transaction status = %q, want %q", transaction.Status, BankTransactionPending)
+	}
+	if reloaded.Income.UnpaidPaymentAmount != money.FromInt(100) {
+		t.Errorf("unpaid amount = %s, want 100.00", reloaded.Income.UnpaidPaymentAmount)
+	}
+
+	// The match can still be confirmed against the current balance.
+	if err := repo.ConfirmMatch(ctx, reloaded, paid(reloaded)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+
+	"gorm.io/gorm"
+)
+
+type BrandRepository interface {
+	GetAllBrand(ctx context.Context, agencyId int) ([]entities.Brand, error)
+	GetBrandById(ctx context.Context, brandId int) (entities.Brand, error)
+	CreateBrand(ctx context.Context, brand entities.Brand) (entities.Brand, error)
+	UpdateBrand(ctx context.Context, brand entities.Brand) (entities.Brand, error)
+	DeleteBrand(ctx context.Context, brandId int) error
+}
+
+type brandRepository struct {
+	db *gorm.DB
+}
+
+func NewBrandRepository(db *gorm.DB) BrandRepository {
+	return &brandRepository{
+		db: db,
+	}
+}
+
+// GetAllBrand lists brands, keeping only those of agencyId when it is set.
+func (r *brandRepository) GetAllBrand(ctx context.Context, agencyId int) ([]entities.Brand, error) {
+	ctx, span := telemetry.Start(ctx, "BrandRepository.GetAllBrand")
+	defer span.End()
+
+	var brands []entities.Brand
+	query := session(ctx, r.db, "BrandRepository.GetAllBrand").Order("id")
+	if agencyId != 0 {
+		query = query.Where("agency_id = ?", agencyId)
+	}
+	err := query.Find(&brands).Error
+	if err != nil {
+		return []entities.Brand{}, err
+	}
+	return brands, err
+}
+
+func (r *brandRepository) GetBrandById(ctx context.Context, brandId int) (entities.Brand, error) {
+	ctx, span := telemetry.Start(ctx, "BrandRepository.GetBrandById")
+	defer span.End()
+
+	var brand entities.Brand
+	err := session(ctx, r.db, "BrandRepository.GetBrandById").Where("id = ?", brandId).First(&brand).Error
+	if err != nil {
+		return entities.Brand{}, err
+	}
+	return brand, err
+}
+
+func (r *brandRepository) CreateBrand(ctx context.Context, brand entities.Brand) (entities.Brand, error) {
+	ctx, span := telemetry.Start(ctx, "BrandRepository.CreateBrand")
+	defer span.End()
+
+	err := session(ctx, r.db, "BrandRepository.CreateBrand").Create(&brand).Error
+	if err != nil {
+		return entities.Brand{}, err
+	}
+	return brand, err
+}
+
+func (r *brandRepository) UpdateBrand(ctx context.Context, brand entities.Brand) (entities.Brand, error) {
+	ctx, span := telemetry.Start(ctx, "BrandRepository.UpdateBrand")
+	defer span.End()
+
+	err := session(ctx, r.db, "BrandRepository.UpdateBrand").Save(&brand).Error
+	if err != nil {
+		return entities.Brand{}, err
+	}
+	return brand, err
+}
+
+func (r *brandRepository) DeleteBrand(ctx context.Context, brandId int) error {
+	ctx, span := telemetry.Start(ctx, "BrandRepository.DeleteBrand")
+	defer span.End()
+
+	err := session(ctx, r.db, "BrandRepository.DeleteBrand").Delete(&entities.Brand{}, "id = ?", brandId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
 )
@@ -26,8 +27,11 @@ func NewChannelRepository(db *gorm.DB) ChannelRepository {
 }
 
 func (r *channelRepository) GetAllChannel(ctx context.Context) ([]entities.Channel, error) {
+	ctx, span := telemetry.Start(ctx, "ChannelRepository.GetAllChannel")
+	defer span.End()
+
 	var channels []entities.Channel
-	err := r.db.Find(&channels).Error
+	err := session(ctx, r.db, "ChannelRepository.GetAllChannel").Find(&channels).Error
 	if err != nil {
 		return []entities.Channel{}, err
 	}
@@ -35,8 +39,11 @@ func (r *channelRepository) GetAllChannel(ctx context.Context) ([]entities.Chann
 }
 
 func (r *channelRepository) GetChannelById(ctx context.Context, channelId int) (entities.Channel, error) {
+	ctx, span := telemetry.Start(ctx, "ChannelRepository.GetChannelById")
+	defer span.End()
+
 	var channel entities.Channel
-	err := r.db.Where("id = ?", channelId).First(&channel).Error
+	err := session(ctx, r.db, "ChannelRepository.GetChannelById").Where("id = ?", channelId).First(&channel).Error
 	if err != nil {
 		return entities.Channel{}, err
 	}
@@ -44,7 +51,10 @@ func (r *channelRepository) GetChannelById(ctx context.Context, channelId int) (
 }
 
 func (r *channelRepository) CreateChannel(ctx context.Context, channel entities.Channel) (entities.Channel, error) {
-	err := r.db.Create(&channel).Error
+	ctx, span := telemetry.Start(ctx, "ChannelRepository.CreateChannel")
+	defer span.End()
+
+	err := session(ctx, r.db, "ChannelRepository.CreateChannel").Create(&channel).Error
 	if err != nil {
 		return entities.Channel{}, err
 	}
@@ -52,7 +62,10 @@ func (r *channelRepository) CreateChannel(ctx context.Context, channel entities.
 }
 
 func (r *channelRepository) UpdateChannel(ctx context.Context, channel entities.Channel) (entities.Channel, error) {
-	err := r.db.Save(&channel).Error
+	ctx, span := telemetry.Start(ctx, "ChannelRepository.UpdateChannel")
+	defer span.End()
+
+	err := session(ctx, r.db, "ChannelRepository.UpdateChannel").Save(&channel).Error
 	if err != nil {
 		return entities.Channel{}, err
 	}
@@ -60,7 +73,10 @@ func (r *channelRepository) UpdateChannel(ctx context.Context, channel entities.
 }
 
 func (r *channelRepository) DeleteChannel(ctx context.Context, channelId int) error {
-	err := r.db.Delete(&entities.Channel{}, "id = ?", channelId).Error
+	ctx, span := telemetry.Start(ctx, "ChannelRepository.DeleteChannel")
+	defer span.End()
+
+	err := session(ctx, r.db, "ChannelRepository.DeleteChannel").Delete(&entities.Channel{}, "id = ?", channelId).Error
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+
+	"gorm.io/gorm"
+)
+
+type CommissionPlanRepository interface {
+	GetAllCommissionPlan(ctx context.Context) ([]entities.CommissionPlan, error)
+	GetCommissionPlanById(ctx context.Context, commissionPlanId int) (entities.CommissionPlan, error)
+	CreateCommissionPlan(ctx context.Context, commissionPlan entities.CommissionPlan) (entities.CommissionPlan, error)
+	UpdateCommissionPlan(ctx context.Context, commissionPlan entities.CommissionPlan, replaceTiers bool) (entities.CommissionPlan, error)
+	DeleteCommissionPlan(ctx context.Context, commissionPlanId int) error
+}
+
+type commissionPlanRepository struct {
+	db *gorm.DB
+}
+
+func NewCommissionPlanRepository(db *gorm.DB) CommissionPlanRepository {
+	return &commissionPlanRepository{
+		db: db,
+	}
+}
+
+// orderByMinRevenue lists tiers from the lowest threshold up.
+func orderByMinRevenue(db *gorm.DB) *gorm.DB {
+	return db.Order("min_revenue, id")
+}
+
+func (r *commissionPlanRepository) GetAllCommissionPlan(ctx context.Context) ([]entities.CommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanRepository.GetAllCommissionPlan")
+	defer span.End()
+
+	var commissionPlans []entities.CommissionPlan
+	err := session(ctx, r.db, "CommissionPlanRepository.GetAllCommissionPlan").
+		Preload("Tiers", orderByMinRevenue).
+		Order("id").
+		Find(&commissionPlans).Error
+	if err != nil {
+		return []entities.CommissionPlan{}, err
+	}
+	return commissionPlans, err
+}
+
+func (r *commissionPlanRepository) GetCommissionPlanById(ctx context.Context, commissionPlanId int) (entities.CommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanRepository.GetCommissionPlanById")
+	defer span.End()
+
+	var commissionPlan entities.CommissionPlan
+	err := session(ctx, r.db, "CommissionPlanRepository.GetCommissionPlanById").
+		Preload("Tiers", orderByMinRevenue).
+		Where("id = ?", commissionPlanId).First(&commissionPlan).Error
+	if err != nil {
+		return entities.CommissionPlan{}, err
+	}
+	return commissionPlan, err
+}
+
+func (r *commissionPlanRepository) CreateCommissionPlan(ctx context.Context, commissionPlan entities.CommissionPlan) (entities.CommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanRepository.CreateCommissionPlan")
+	defer span.End()
+
+	err := session(ctx, r.db, "CommissionPlanRepository.CreateCommissionPlan").Create(&commissionPlan).Error
+	if err != nil {
+		return entities.CommissionPlan{}, err
+	}
+	return commissionPlan, err
+}
+
+// UpdateCommissionPlan saves the plan and, when asked, replaces its tiers
+// with the ones given, in one transaction.
+func (r *commissionPlanRepository) UpdateCommissionPlan(ctx context.Context, commissionPlan entities.CommissionPlan, replaceTiers bool) (entities.CommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanRepository.UpdateCommissionPlan")
+	defer span.End()
+
+	tiers := commissionPlan.Tiers
+	commissionPlan.Tiers = nil
+
+	tx := session(ctx, r.db, "CommissionPlanRepository.UpdateCommissionPlan").Begin()
+	if tx.Error != nil {
+		return entities.CommissionPlan{}, tx.Error
+	}
+
+	if err := tx.Omit("Platform", "Channel").Save(&commissionPlan).Error; err != nil {
+		tx.Rollback()
+		return entities.CommissionPlan{}, err
+	}
+
+	if replaceTiers {
+		if err := tx.Where("commission_plan_id = ?", commissionPlan.Id).Delete(&entities.CommissionTier{}).Error; err != nil {
+			tx.Rollback()
+			return entities.CommissionPlan{}, err
+		}
+		for i := range tiers {
+			tiers[i].Id = 0
+			tiers[i].CommissionPlanId = commissionPlan.Id
+			if err := tx.Create(&tiers[i]).Error; err != nil {
+				tx.Rollback()
+				return entities.CommissionPlan{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return entities.CommissionPlan{}, err
+	}
+
+	return commissionPlan, nil
+}
+
+func (r *commissionPlanRepository) DeleteCommissionPlan(ctx context.Context, commissionPlanId int) error {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanRepository.DeleteCommissionPlan")
+	defer span.End()
+
+	err := session(ctx, r.db, "CommissionPlanRepository.DeleteCommissionPlan").Delete(&entities.CommissionPlan{}, "id = ?", commissionPlanId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CommissionRepository interface {
+	GetSalePersonCommissionPlans(ctx context.Context, salePersonId int) ([]entities.SalePersonCommissionPlan, error)
+	CreateSalePersonCommissionPlan(ctx context.Context, assignment entities.SalePersonCommissionPlan) (entities.SalePersonCommissionPlan, error)
+	DeleteSalePersonCommissionPlan(ctx context.Context, salePersonId, assignmentId int) error
+	GetCommissions(ctx context.Context, salePersonId int, from, to time.Time) ([]entities.Commission, error)
+	ReplaceCommissions(ctx context.Context, salePersonId int, from, to time.Time, commissions []entities.Commission) error
+}
+
+type commissionRepository struct {
+	db *gorm.DB
+}
+
+func NewCommissionRepository(db *gorm.DB) CommissionRepository {
+	return &commissionRepository{
+		db: db,
+	}
+}
+
+// GetSalePersonCommissionPlans lists the plans a sale person has been on,
+// earliest first, with each plan's tiers.
+func (r *commissionRepository) GetSalePersonCommissionPlans(ctx context.Context, salePersonId int) ([]entities.SalePersonCommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionRepository.GetSalePersonCommissionPlans")
+	defer span.End()
+
+	var assignments []entities.SalePersonCommissionPlan
+	err := session(ctx, r.db, "CommissionRepository.GetSalePersonCommissionPlans").
+		Preload("CommissionPlan.Tiers", orderByMinRevenue).
+		Where("sale_person_id = ?", salePersonId).
+		Order("effective_from, id").
+		Find(&assignments).Error
+	if err != nil {
+		return []entities.SalePersonCommissionPlan{}, err
+	}
+	return assignments, err
+}
+
+func (r *commissionRepository) CreateSalePersonCommissionPlan(ctx context.Context, assignment entities.SalePersonCommissionPlan) (entities.SalePersonCommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionRepository.CreateSalePersonCommissionPlan")
+	defer span.End()
+
+	err := session(ctx, r.db, "CommissionRepository.CreateSalePersonCommissionPlan").
+		Omit("SalePerson", "CommissionPlan").
+		Create(&assignment).Error
+	if err != nil {
+		return entities.SalePersonCommissionPlan{}, err
+	}
+	return assignment, err
+}
+
+// DeleteSalePersonCommissionPlan removes an assignment of the sale person,
+// reporting gorm.ErrRecordNotFound when it has no such assignment.
+func (r *commissionRepository) DeleteSalePersonCommissionPlan(ctx context.Context, salePersonId, assignmentId int) error {
+	ctx, span := telemetry.Start(ctx, "CommissionRepository.DeleteSalePersonCommissionPlan")
+	defer span.End()
+
+	result := session(ctx, r.db, "CommissionRepository.DeleteSalePersonCommissionPlan").
+		Delete(&entities.SalePersonCommissionPlan{}, "id = ? AND sale_person_id = ?", assignmentId, salePersonId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetCommissions lists the commissions of a sale person on incomes paid
+// between from and to, both inclusive.
+func (r *commissionRepository) GetCommissions(ctx context.Context, salePersonId int, from, to time.Time) ([]entities.Commission, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionRepository.GetCommissions")
+	defer span.End()
+
+	var commissions []entities.Commission
+	err := session(ctx, r.db, "CommissionRepository.GetCommissions").
+		Preload("CommissionPlan").
+		Where("sale_person_id = ? AND paid_date >= ? AND paid_date < ?", salePersonId, from, to.AddDate(0, 0, 1)).
+		Order("paid_date, income_invoice_id_number").
+		Find(&commissions).Error
+	if err != nil {
+		return []entities.Commission{}, err
+	}
+	return commissions, err
+}
+
+// ReplaceCommissions swaps the sale person's commissions between from and
+// to, both inclusive, for the ones given, in one transaction.
+func (r *commissionRepository) ReplaceCommissions(ctx context.Context, salePersonId int, from, to time.Time, commissions []entities.Commission) error {
+	ctx, span := telemetry.Start(ctx, "CommissionRepository.ReplaceCommissions")
+	defer span.End()
+
+	tx := session(ctx, r.db, "CommissionRepository.ReplaceCommissions").Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	err := tx.Where("sale_person_id = ? AND paid_date >= ? AND paid_date < ?", salePersonId, from, to.AddDate(0, 0, 1)).
+		Delete(&entities.Commission{}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(commissions) > 0 {
+		if err := tx.Omit("CommissionPlan").Create(&commissions).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+
+	"gorm.io/gorm"
+)
+
+type ContactRepository interface {
+	GetAllContact(ctx context.Context, agencyId int) ([]entities.Contact, error)
+	GetContactById(ctx context.Context, contactId int) (entities.Contact, error)
+	CreateContact(ctx context.Context, contact entities.Contact) (entities.Contact, error)
+	UpdateContact(ctx context.Context, contact entities.Contact) (entities.Contact, error)
+	DeleteContact(ctx context.Context, contactId int) error
+}
+
+type contactRepository struct {
+	db *gorm.DB
+}
+
+func NewContactRepository(db *gorm.DB) ContactRepository {
+	return &contactRepository{
+		db: db,
+	}
+}
+
+// GetAllContact lists contacts, keeping only those of agencyId when it is set.
+func (r *contactRepository) GetAllContact(ctx context.Context, agencyId int) ([]entities.Contact, error) {
+	ctx, span := telemetry.Start(ctx, "ContactRepository.GetAllContact")
+	defer span.End()
+
+	var contacts []entities.Contact
+	query := session(ctx, r.db, "ContactRepository.GetAllContact").Order("id")
+	if agencyId != 0 {
+		query = query.Where("agency_id = ?", agencyId)
+	}
+	err := query.Find(&contacts).Error
+	if err != nil {
+		return []entities.Contact{}, err
+	}
+	return contacts, err
+}
+
+func (r *contactRepository) GetContactById(ctx context.Context, contactId int) (entities.Contact, error) {
+	ctx, span := telemetry.Start(ctx, "ContactRepository.GetContactById")
+	defer span.End()
+
+	var contact entities.Contact
+	err := session(ctx, r.db, "ContactRepository.GetContactById").Where("id = ?", contactId).First(&contact).Error
+	if err != nil {
+		return entities.Contact{}, err
+	}
+	return contact, err
+}
+
+func (r *contactRepository) CreateContact(ctx context.Context, contact entities.Contact) (entities.Contact, error) {
+	ctx, span := telemetry.Start(ctx, "ContactRepository.CreateContact")
+	defer span.End()
+
+	err := session(ctx, r.db, "ContactRepository.CreateContact").Create(&contact).Error
+	if err != nil {
+		return entities.Contact{}, err
+	}
+	return contact, err
+}
+
+func (r *contactRepository) UpdateContact(ctx context.Context, contact entities.Contact) (entities.Contact, error) {
+	ctx, span := telemetry.Start(ctx, "ContactRepository.UpdateContact")
+	defer span.End()
+
+	err := session(ctx, r.db, "ContactRepository.UpdateContact").Save(&contact).Error
+	if err != nil {
+		return entities.Contact{}, err
+	}
+	return contact, err
+}
+
+func (r *contactRepository) DeleteContact(ctx context.Context, contactId int) error {
+	ctx, span := telemetry.Start(ctx, "ContactRepository.DeleteContact")
+	defer span.End()
+
+	err := session(ctx, r.db, "ContactRepository.DeleteContact").Delete(&entities.Contact{}, "id = ?", contactId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
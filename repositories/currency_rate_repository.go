@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+
+	"gorm.io/gorm"
+)
+
+type CurrencyRateRepository interface {
+	GetAllCurrencyRate(ctx context.Context, currency string) ([]entities.CurrencyRate, error)
+	GetCurrencyRateById(ctx context.Context, currencyRateId int) (entities.CurrencyRate, error)
+	GetCurrencyRatesByCurrencies(ctx context.Context, currencies []string) ([]entities.CurrencyRate, error)
+	CreateCurrencyRate(ctx context.Context, currencyRate entities.CurrencyRate) (entities.CurrencyRate, error)
+	UpdateCurrencyRate(ctx context.Context, currencyRate entities.CurrencyRate) (entities.CurrencyRate, error)
+	DeleteCurrencyRate(ctx context.Context, currencyRateId int) error
+}
+
+type currencyRateRepository struct {
+	db *gorm.DB
+}
+
+func NewCurrencyRateRepository(db *gorm.DB) CurrencyRateRepository {
+	return &currencyRateRepository{
+		db: db,
+	}
+}
+
+func (r *currencyRateRepository) GetAllCurrencyRate(ctx context.Context, currency string) ([]entities.CurrencyRate, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateRepository.GetAllCurrencyRate")
+	defer span.End()
+
+	var currencyRates []entities.CurrencyRate
+	query := session(ctx, r.db, "CurrencyRateRepository.GetAllCurrencyRate")
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+	err := query.Order("currency, date DESC").Find(&currencyRates).Error
+	if err != nil {
+		return []entities.CurrencyRate{}, err
+	}
+	return currencyRates, err
+}
+
+func (r *currencyRateRepository) GetCurrencyRateById(ctx context.Context, currencyRateId int) (entities.CurrencyRate, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateRepository.GetCurrencyRateById")
+	defer span.End()
+
+	var currencyRate entities.CurrencyRate
+	err := session(ctx, r.db, "CurrencyRateRepository.GetCurrencyRateById").Where("id = ?", currencyRateId).First(&currencyRate).Error
+	if err != nil {
+		return entities.CurrencyRate{}, err
+	}
+	return currencyRate, err
+}
+
+// GetCurrencyRatesByCurrencies returns every rate of the given currencies
+// ordered by currency and date, for converting many incomes at once.
+func (r *currencyRateRepository) GetCurrencyRatesByCurrencies(ctx context.Context, currencies []string) ([]entities.CurrencyRate, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateRepository.GetCurrencyRatesByCurrencies")
+	defer span.End()
+
+	var currencyRates []entities.CurrencyRate
+	if len(currencies) == 0 {
+		return currencyRates, nil
+	}
+	err := session(ctx, r.db, "CurrencyRateRepository.GetCurrencyRatesByCurrencies").
+		Where("currency IN ?", currencies).
+		Order("currency, date").
+		Find(&currencyRates).Error
+	if err != nil {
+		return []entities.CurrencyRate{}, err
+	}
+	return currencyRates, err
+}
+
+func (r *currencyRateRepository) CreateCurrencyRate(ctx context.Context, currencyRate entities.CurrencyRate) (entities.CurrencyRate, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateRepository.CreateCurrencyRate")
+	defer span.End()
+
+	err := session(ctx, r.db, "CurrencyRateRepository.CreateCurrencyRate").Create(&currencyRate).Error
+	if err != nil {
+		return entities.CurrencyRate{}, err
+	}
+	return currencyRate, err
+}
+
+func (r *currencyRateRepository) UpdateCurrencyRate(ctx context.Context, currencyRate entities.CurrencyRate) (entities.CurrencyRate, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateRepository.UpdateCurrencyRate")
+	defer span.End()
+
+	err := session(ctx, r.db, "CurrencyRateRepository.UpdateCurrencyRate").Save(&currencyRate).Error
+	if err != nil {
+		return entities.CurrencyRate{}, err
+	}
+	return currencyRate, err
+}
+
+func (r *currencyRateRepository) DeleteCurrencyRate(ctx context.Context, currencyRateId int) error {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateRepository.DeleteCurrencyRate")
+	defer span.End()
+
+	err := session(ctx, r.db, "CurrencyRateRepository.DeleteCurrencyRate").Delete(&entities.CurrencyRate{}, "id = ?", currencyRateId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -3,18 +3,29 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DetailRepository interface {
-	GetAllDetail(ctx context.Context) ([]entities.Detail, error)
+	GetAllDetail(ctx context.Context, includeIncome bool) ([]entities.Detail, error)
 	GetDetailById(ctx context.Context, detailId int) (entities.Detail, error)
-	CreateDetail(ctx context.Context, detail entities.Detail) (entities.Detail, error)
-	UpdateDetail(ctx context.Context, detail entities.Detail) (entities.Detail, error)
-	DeleteDetail(ctx context.Context, detailId int) error
+	GetDetailsByIncomeInvoiceIdNumber(ctx context.Context, incomeInvoiceIdNumber int) ([]entities.Detail, error)
+	GetMaxDetailPosition(ctx context.Context, incomeInvoiceIdNumber int) (int, error)
+	CreateDetail(ctx context.Context, detail entities.Detail, withholdingTax WithholdingTaxFunc) (entities.Detail, error)
+	UpdateDetail(ctx context.Context, detail entities.Detail, withholdingTax WithholdingTaxFunc) (entities.Detail, error)
+	ReorderDetails(ctx context.Context, incomeInvoiceIdNumber int, detailIds []int) error
+	DeleteDetail(ctx context.Context, detailId int, withholdingTax WithholdingTaxFunc) error
 }
 
+// WithholdingTaxFunc computes the tax the client of an income withholds
+// from the income and its lines. Changing a line stores the result on the
+// income in the same transaction.
+type WithholdingTaxFunc func(income entities.Income, details []entities.Detail) money.Amount
+
 type detailRepository struct {
 	db *gorm.DB
 }
@@ -25,18 +36,24 @@ func NewDetailRepository(db *gorm.DB) DetailRepository {
 	}
 }
 
-func (r *detailRepository) GetAllDetail(ctx context.Context) ([]entities.Detail, error) {
+func (r *detailRepository) GetAllDetail(ctx context.Context, includeIncome bool) ([]entities.Detail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailRepository.GetAllDetail")
+	defer span.End()
+
 	var details []entities.Detail
-	err := r.db.
-		Preload("Income").
-		Preload("Income.Platform").
-		Preload("Income.Status").
-		Preload("Income.PaymentMethod").
-		Preload("Income.Receiver").
-		Preload("Income.SalePerson").
-		Preload("Income.Channel").
-		Preload("Income.Bank").
-		Find(&details).Error
+	query := session(ctx, r.db, "DetailRepository.GetAllDetail")
+	if includeIncome {
+		query = query.
+			Preload("Income").
+			Preload("Income.Platform").
+			Preload("Income.Status").
+			Preload("Income.PaymentMethod").
+			Preload("Income.Receiver").
+			Preload("Income.SalePerson").
+			Preload("Income.Channel").
+			Preload("Income.Bank")
+	}
+	err := query.Order("income_invoice_id_number, position, id").Find(&details).Error
 	if err != nil {
 		return []entities.Detail{}, err
 	}
@@ -44,8 +61,11 @@ func (r *detailRepository) GetAllDetail(ctx context.Context) ([]entities.Detail,
 }
 
 func (r *detailRepository) GetDetailById(ctx context.Context, detailId int) (entities.Detail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailRepository.GetDetailById")
+	defer span.End()
+
 	var detail entities.Detail
-	err := r.db.
+	err := session(ctx, r.db, "DetailRepository.GetDetailById").
 		Preload("Income").
 		Preload("Income.Platform").
 		Preload("Income.Status").
@@ -62,26 +82,164 @@ func (r *detailRepository) GetDetailById(ctx context.Context, detailId int) (ent
 	return detail, err
 }
 
-func (r *detailRepository) CreateDetail(ctx context.Context, detail entities.Detail) (entities.Detail, error) {
-	err := r.db.Create(&detail).Error
+func (r *detailRepository) GetDetailsByIncomeInvoiceIdNumber(ctx context.Context, incomeInvoiceIdNumber int) ([]entities.Detail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailRepository.GetDetailsByIncomeInvoiceIdNumber")
+	defer span.End()
+
+	var details []entities.Detail
+	err := session(ctx, r.db, "DetailRepository.GetDetailsByIncomeInvoiceIdNumber").
+		Where("income_invoice_id_number = ?", incomeInvoiceIdNumber).
+		Order("position, id").
+		Find(&details).Error
 	if err != nil {
-		return entities.Detail{}, err
+		return []entities.Detail{}, err
 	}
-	return detail, err
+	return details, err
 }
 
-func (r *detailRepository) UpdateDetail(ctx context.Context, detail entities.Detail) (entities.Detail, error) {
-	err := r.db.Save(&detail).Error
+func (r *detailRepository) GetMaxDetailPosition(ctx context.Context, incomeInvoiceIdNumber int) (int, error) {
+	ctx, span := telemetry.Start(ctx, "DetailRepository.GetMaxDetailPosition")
+	defer span.End()
+
+	var position int
+	err := session(ctx, r.db, "DetailRepository.GetMaxDetailPosition").
+		Model(&entities.Detail{}).
+		Where("income_invoice_id_number = ?", incomeInvoiceIdNumber).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&position).Error
 	if err != nil {
+		return 0, err
+	}
+	return position, nil
+}
+
+func (r *detailRepository) CreateDetail(ctx context.Context, detail entities.Detail, withholdingTax WithholdingTaxFunc) (entities.Detail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailRepository.CreateDetail")
+	defer span.End()
+
+	tx := session(ctx, r.db, "DetailRepository.CreateDetail").Begin()
+	if tx.Error != nil {
+		return entities.Detail{}, tx.Error
+	}
+
+	if err := tx.Create(&detail).Error; err != nil {
+		tx.Rollback()
 		return entities.Detail{}, err
 	}
-	return detail, err
+	if err := saveWithholdingTax(tx, detail.IncomeInvoiceIdNumber, withholdingTax); err != nil {
+		tx.Rollback()
+		return entities.Detail{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return entities.Detail{}, err
+	}
+	return detail, nil
+}
+
+// UpdateDetail saves a line, refreshing the withholding tax of its income
+// and, when the line moved, of the income it left.
+func (r *detailRepository) UpdateDetail(ctx context.Context, detail entities.Detail, withholdingTax WithholdingTaxFunc) (entities.Detail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailRepository.UpdateDetail")
+	defer span.End()
+
+	tx := session(ctx, r.db, "DetailRepository.UpdateDetail").Begin()
+	if tx.Error != nil {
+		return entities.Detail{}, tx.Error
+	}
+
+	var old entities.Detail
+	if err := tx.Select("income_invoice_id_number").Where("id = ?", detail.Id).First(&old).Error; err != nil {
+		tx.Rollback()
+		return entities.Detail{}, err
+	}
+	if err := tx.Save(&detail).Error; err != nil {
+		tx.Rollback()
+		return entities.Detail{}, err
+	}
+	incomes := []int{detail.IncomeInvoiceIdNumber}
+	if old.IncomeInvoiceIdNumber != detail.IncomeInvoiceIdNumber {
+		incomes = append(incomes, old.IncomeInvoiceIdNumber)
+	}
+	for _, number := range incomes {
+		if err := saveWithholdingTax(tx, number, withholdingTax); err != nil {
+			tx.Rollback()
+			return entities.Detail{}, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return entities.Detail{}, err
+	}
+	return detail, nil
+}
+
+// ReorderDetails numbers the given lines of an income 1..n in slice order.
+func (r *detailRepository) ReorderDetails(ctx context.Context, incomeInvoiceIdNumber int, detailIds []int) error {
+	ctx, span := telemetry.Start(ctx, "DetailRepository.ReorderDetails")
+	defer span.End()
+
+	tx := session(ctx, r.db, "DetailRepository.ReorderDetails").Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	for i, detailId := range detailIds {
+		if err := tx.Model(&entities.Detail{}).
+			Where("id = ? AND income_invoice_id_number = ?", detailId, incomeInvoiceIdNumber).
+			Update("position", i+1).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
 }
 
-func (r *detailRepository) DeleteDetail(ctx context.Context, detailId int) error {
-	err := r.db.Delete(&entities.Detail{}, "id = ?", detailId).Error
+func (r *detailRepository) DeleteDetail(ctx context.Context, detailId int, withholdingTax WithholdingTaxFunc) error {
+	ctx, span := telemetry.Start(ctx, "DetailRepository.DeleteDetail")
+	defer span.End()
+
+	tx := session(ctx, r.db, "DetailRepository.DeleteDetail").Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	var detail entities.Detail
+	if err := tx.Select("id", "income_invoice_id_number").Where("id = ?", detailId).First(&detail).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(&entities.Detail{}, "id = ?", detailId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := saveWithholdingTax(tx, detail.IncomeInvoiceIdNumber, withholdingTax); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// saveWithholdingTax stores the withholding tax of an income computed from
+// its lines as they stand in tx. The income row is locked first, so the
+// line changes of concurrent transactions are counted one after the other.
+func saveWithholdingTax(tx *gorm.DB, incomeInvoiceIdNumber int, withholdingTax WithholdingTaxFunc) error {
+	var income entities.Income
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("invoice_id_number = ?", incomeInvoiceIdNumber).
+		First(&income).Error
 	if err != nil {
 		return err
 	}
-	return nil
+
+	var details []entities.Detail
+	if err := tx.Where("income_invoice_id_number = ?", incomeInvoiceIdNumber).Order("position, id").Find(&details).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&entities.Income{}).
+		Where("invoice_id_number = ?", incomeInvoiceIdNumber).
+		Update("withholding_tax_amount", withholdingTax(income, details)).Error
 }
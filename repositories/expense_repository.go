@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExpenseFilter narrows an expense list; zero fields match everything.
+type ExpenseFilter struct {
+	IncomeInvoiceIdNumber int
+	InfluencerId          int
+	VendorId              int
+	Unpaid                bool
+}
+
+type ExpenseRepository interface {
+	GetAllExpense(ctx context.Context, filter ExpenseFilter) ([]entities.Expense, error)
+	GetExpenseById(ctx context.Context, expenseId int) (entities.Expense, error)
+	CreateExpense(ctx context.Context, expense entities.Expense) (entities.Expense, error)
+	UpdateExpense(ctx context.Context, expense entities.Expense) (entities.Expense, error)
+	DeleteExpense(ctx context.Context, expenseId int) error
+	GetExpenseAttachment(ctx context.Context, expenseId, attachmentId int) (entities.ExpenseAttachment, error)
+	CreateExpenseAttachment(ctx context.Context, attachment entities.ExpenseAttachment) (entities.ExpenseAttachment, error)
+	DeleteExpenseAttachment(ctx context.Context, expenseId, attachmentId int) error
+}
+
+type expenseRepository struct {
+	db *gorm.DB
+}
+
+func NewExpenseRepository(db *gorm.DB) ExpenseRepository {
+	return &expenseRepository{
+		db: db,
+	}
+}
+
+// withExpenseRelations loads what an expense response shows: the income
+// for its currency, the payee's name and the attachments without their
+// contents.
+func withExpenseRelations(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Income", func(db *gorm.DB) *gorm.DB {
+			return db.Select("invoice_id_number", "currency")
+		}).
+		Preload("Influencer").
+		Preload("Vendor").
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+			return db.Omit("data").Order("id")
+		})
+}
+
+func (r *expenseRepository) GetAllExpense(ctx context.Context, filter ExpenseFilter) ([]entities.Expense, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseRepository.GetAllExpense")
+	defer span.End()
+
+	var expenses []entities.Expense
+	query := withExpenseRelations(session(ctx, r.db, "ExpenseRepository.GetAllExpense")).Order("id")
+	if filter.IncomeInvoiceIdNumber != 0 {
+		query = query.Where("income_invoice_id_number = ?", filter.IncomeInvoiceIdNumber)
+	}
+	if filter.InfluencerId != 0 {
+		query = query.Where("influencer_id = ?", filter.InfluencerId)
+	}
+	if filter.VendorId != 0 {
+		query = query.Where("vendor_id = ?", filter.VendorId)
+	}
+	if filter.Unpaid {
+		query = query.Where("paid_date IS NULL OR paid_date = ?", time.Time{})
+	}
+	err := query.Find(&expenses).Error
+	if err != nil {
+		return []entities.Expense{}, err
+	}
+	return expenses, err
+}
+
+func (r *expenseRepository) GetExpenseById(ctx context.Context, expenseId int) (entities.Expense, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseRepository.GetExpenseById")
+	defer span.End()
+
+	var expense entities.Expense
+	err := withExpenseRelations(session(ctx, r.db, "ExpenseRepository.GetExpenseById")).
+		Where("id = ?", expenseId).First(&expense).Error
+	if err != nil {
+		return entities.Expense{}, err
+	}
+	return expense, err
+}
+
+func (r *expenseRepository) CreateExpense(ctx context.Context, expense entities.Expense) (entities.Expense, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseRepository.CreateExpense")
+	defer span.End()
+
+	err := session(ctx, r.db, "ExpenseRepository.CreateExpense").
+		Omit("Income", "Influencer", "Vendor", "Attachments").Create(&expense).Error
+	if err != nil {
+		return entities.Expense{}, err
+	}
+	return expense, err
+}
+
+func (r *expenseRepository) UpdateExpense(ctx context.Context, expense entities.Expense) (entities.Expense, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseRepository.UpdateExpense")
+	defer span.End()
+
+	err := session(ctx, r.db, "ExpenseRepository.UpdateExpense").
+		Omit("Income", "Influencer", "Vendor", "Attachments").Save(&expense).Error
+	if err != nil {
+		return entities.Expense{}, err
+	}
+	return expense, err
+}
+
+func (r *expenseRepository) DeleteExpense(ctx context.Context, expenseId int) error {
+	ctx, span := telemetry.Start(ctx, "ExpenseRepository.DeleteExpense")
+	defer span.End()
+
+	err := session(ctx, r.db, "ExpenseRepository.DeleteExpense").Delete(&entities.Expense{}, "id = ?", expenseId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *expenseRepository) GetExpenseAttachment(ctx context.Context, expenseId, attachmentId int) (entities.ExpenseAttachment, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseRepository.GetExpenseAttachment")
+	defer span.End()
+
+	var attachment entities.ExpenseAttachment
+	err := session(ctx, r.db, "ExpenseRepository.GetExpenseAttachment").
+		Where("id = ? AND expense_id = ?", attachmentId, expenseId).First(&attachment).Error
+	if err != nil {
+		return entities.ExpenseAttachment{}, err
+	}
+	return attachment, err
+}
+
+func (r *expenseRepository) CreateExpenseAttachment(ctx context.Context, attachment entities.ExpenseAttachment) (entities.ExpenseAttachment, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseRepository.CreateExpenseAttachment")
+	defer span.End()
+
+	err := session(ctx, r.db, "ExpenseRepository.CreateExpenseAttachment").Create(&attachment).Error
+	if err != nil {
+		return entities.ExpenseAttachment{}, err
+	}
+	return attachment, err
+}
+
+func (r *expenseRepository) DeleteExpenseAttachment(ctx context.Context, expenseId, attachmentId int) error {
+	ctx, span := telemetry.Start(ctx, "ExpenseRepository.DeleteExpenseAttachment")
+	defer span.End()
+
+	result := session(ctx, r.db, "ExpenseRepository.DeleteExpenseAttachment").
+		Delete(&entities.ExpenseAttachment{}, "id = ? AND expense_id = ?", attachmentId, expenseId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
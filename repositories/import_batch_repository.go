@@ -0,0 +1,296 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of ImportBatchRecord.
+const (
+	ImportRecordPlatform = "platform"
+	ImportRecordStatus   = "status"
+)
+
+type ImportBatchRepository interface {
+	GetAllImportBatch(ctx context.Context) ([]entities.ImportBatch, error)
+	GetImportBatchById(ctx context.Context, importBatchId int) (entities.ImportBatch, error)
+	GetLookupIds(ctx context.Context, model any) (map[string]int, error)
+	CreateImportBatch(ctx context.Context, batch entities.ImportBatch, incomes []ImportedIncome) (entities.ImportBatch, error)
+	CountImportBatchDependents(ctx context.Context, importBatchId int) (int64, error)
+	RollbackImportBatch(ctx context.Context, importBatchId int) ([]entities.Income, error)
+}
+
+// ImportedIncome is an income to import. NewPlatform and NewStatus name a
+// platform or status to create for it; the income then gets its id. Incomes
+// naming the same new record share it.
+type ImportedIncome struct {
+	Income      entities.Income
+	NewPlatform string
+	NewStatus   string
+}
+
+type importBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewImportBatchRepository(db *gorm.DB) ImportBatchRepository {
+	return &importBatchRepository{
+		db: db,
+	}
+}
+
+func (r *importBatchRepository) GetAllImportBatch(ctx context.Context) ([]entities.ImportBatch, error) {
+	ctx, span := telemetry.Start(ctx, "ImportBatchRepository.GetAllImportBatch")
+	defer span.End()
+
+	var batches []entities.ImportBatch
+	err := session(ctx, r.db, "ImportBatchRepository.GetAllImportBatch").Order("id DESC").Find(&batches).Error
+	if err != nil {
+		return []entities.ImportBatch{}, err
+	}
+	return batches, err
+}
+
+func (r *importBatchRepository) GetImportBatchById(ctx context.Context, importBatchId int) (entities.ImportBatch, error) {
+	ctx, span := telemetry.Start(ctx, "ImportBatchRepository.GetImportBatchById")
+	defer span.End()
+
+	var batch entities.ImportBatch
+	err := session(ctx, r.db, "ImportBatchRepository.GetImportBatchById").Where("id = ?", importBatchId).First(&batch).Error
+	if err != nil {
+		return entities.ImportBatch{}, err
+	}
+	return batch, err
+}
+
+// GetLookupIds maps the lowercased names of a lookup table such as
+// entities.Platform to their ids. When names repeat, the oldest record
+// wins.
+func (r *importBatchRepository) GetLookupIds(ctx context.Context, model any) (map[string]int, error) {
+	ctx, span := telemetry.Start(ctx, "ImportBatchRepository.GetLookupIds")
+	defer span.End()
+
+	var records []struct {
+		Id   int
+		Name string
+	}
+	err := session(ctx, r.db, "ImportBatchRepository.GetLookupIds").
+		Model(model).
+		Select("id, name").
+		Order("id").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int, len(records))
+	for _, record := range records {
+		key := strings.ToLower(strings.TrimSpace(record.Name))
+		if _, ok := ids[key]; !ok {
+			ids[key] = record.Id
+		}
+	}
+	return ids, nil
+}
+
+// CreateImportBatch saves batch together with the incomes, their lines and
+// the platforms and statuses they need in one transaction, so an import
+// either lands completely or not at all.
+func (r *importBatchRepository) CreateImportBatch(ctx context.Context, batch entities.ImportBatch, incomes []ImportedIncome) (entities.ImportBatch, error) {
+	ctx, span := telemetry.Start(ctx, "ImportBatchRepository.CreateImportBatch")
+	defer span.End()
+
+	tx := session(ctx, r.db, "ImportBatchRepository.CreateImportBatch").Begin()
+	if tx.Error != nil {
+		return entities.ImportBatch{}, tx.Error
+	}
+
+	batch.Incomes = len(incomes)
+	if err := tx.Create(&batch).Error; err != nil {
+		tx.Rollback()
+		return entities.ImportBatch{}, err
+	}
+
+	created := map[string]map[string]int{ImportRecordPlatform: {}, ImportRecordStatus: {}}
+	lookup := func(kind, name string) (int, error) {
+		if id, ok := created[kind][name]; ok {
+			return id, nil
+		}
+		id, err := createImportRecord(tx, batch.Id, kind, name)
+		if err != nil {
+			return 0, err
+		}
+		created[kind][name] = id
+		return id, nil
+	}
+
+	for i := range incomes {
+		income := incomes[i].Income
+		if name := incomes[i].NewPlatform; name != "" {
+			id, err := lookup(ImportRecordPlatform, name)
+			if err != nil {
+				tx.Rollback()
+				return entities.ImportBatch{}, err
+			}
+			income.PlatformId = id
+		}
+		if name := incomes[i].NewStatus; name != "" {
+			id, err := lookup(ImportRecordStatus, name)
+			if err != nil {
+				tx.Rollback()
+				return entities.ImportBatch{}, err
+			}
+			income.StatusId = id
+		}
+
+		// The income's lines are inserted along with it.
+		income.ImportBatchId = &batch.Id
+		if err := tx.Create(&income).Error; err != nil {
+			tx.Rollback()
+			return entities.ImportBatch{}, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return entities.ImportBatch{}, err
+	}
+
+	return batch, nil
+}
+
+// createImportRecord creates the platform or status called name and
+// records that batch created it.
+func createImportRecord(tx *gorm.DB, batchId int, kind, name string) (int, error) {
+	var id int
+	switch kind {
+	case ImportRecordPlatform:
+		platform := entities.Platform{Name: name}
+		if err := tx.Create(&platform).Error; err != nil {
+			return 0, err
+		}
+		id = platform.Id
+	case ImportRecordStatus:
+		status := entities.Status{Name: name}
+		if err := tx.Create(&status).Error; err != nil {
+			return 0, err
+		}
+		id = status.Id
+	}
+
+	record := entities.ImportBatchRecord{ImportBatchId: batchId, Kind: kind, RecordId: id}
+	if err := tx.Create(&record).Error; err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// CountImportBatchDependents counts the expenses, influencer assignments
+// and confirmed bank payments recorded against the batch's incomes since
+// the import, which a rollback would orphan.
+func (r *importBatchRepository) CountImportBatchDependents(ctx context.Context, importBatchId int) (int64, error) {
+	ctx, span := telemetry.Start(ctx, "ImportBatchRepository.CountImportBatchDependents")
+	defer span.End()
+
+	const batchIncomes = "income_invoice_id_number IN (SELECT invoice_id_number FROM incomes WHERE import_batch_id = ?)"
+
+	var expenses, assignments, payments int64
+	err := session(ctx, r.db, "ImportBatchRepository.CountImportBatchDependents").
+		Model(&entities.Expense{}).
+		Where(batchIncomes, importBatchId).
+		Count(&expenses).Error
+	if err != nil {
+		return 0, err
+	}
+	err = session(ctx, r.db, "ImportBatchRepository.CountImportBatchDependents").
+		Model(&entities.InfluencerAssignment{}).
+		Where(batchIncomes, importBatchId).
+		Count(&assignments).Error
+	if err != nil {
+		return 0, err
+	}
+	err = session(ctx, r.db, "ImportBatchRepository.CountImportBatchDependents").
+		Model(&entities.BankTransaction{}).
+		Where(batchIncomes, importBatchId).
+		Count(&payments).Error
+	if err != nil {
+		return 0, err
+	}
+	return expenses + assignments + payments, nil
+}
+
+// RollbackImportBatch deletes the batch's incomes with their lines, then
+// the platforms and statuses it created that no income uses any more, and
+// marks the batch rolled back. It returns the deleted incomes.
+func (r *importBatchRepository) RollbackImportBatch(ctx context.Context, importBatchId int) ([]entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "ImportBatchRepository.RollbackImportBatch")
+	defer span.End()
+
+	tx := session(ctx, r.db, "ImportBatchRepository.RollbackImportBatch").Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	var incomes []entities.Income
+	if err := tx.Where("import_batch_id = ?", importBatchId).Find(&incomes).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	invoiceIds := make([]int, len(incomes))
+	for i, income := range incomes {
+		invoiceIds[i] = income.InvoiceIdNumber
+	}
+	if len(invoiceIds) > 0 {
+		if err := tx.Where("income_invoice_id_number IN ?", invoiceIds).Delete(&entities.Detail{}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Where("import_batch_id = ?", importBatchId).Delete(&entities.Income{}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	var records []entities.ImportBatchRecord
+	if err := tx.Where("import_batch_id = ?", importBatchId).Find(&records).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, record := range records {
+		var model any
+		var column string
+		switch record.Kind {
+		case ImportRecordPlatform:
+			model, column = &entities.Platform{}, "platform_id"
+		case ImportRecordStatus:
+			model, column = &entities.Status{}, "status_id"
+		default:
+			continue
+		}
+		err := tx.Where("id = ? AND NOT EXISTS (SELECT 1 FROM incomes WHERE "+column+" = ?)", record.RecordId, record.RecordId).
+			Delete(model).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err := tx.Model(&entities.ImportBatch{}).
+		Where("id = ?", importBatchId).
+		Update("rolled_back_at", time.Now()).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return incomes, nil
+}
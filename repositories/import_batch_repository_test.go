@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"testing"
+)
+
+func testIncome(invoice int) entities.Income {
+	return entities.Income{
+		InvoiceIdNumber:    invoice,
+		PlatformId:         1,
+		StatusId:           1,
+		TotalPaymentAmount: money.FromInt(100),
+		Details:            []entities.Detail{{Description: "Post", Quantity: 1, UnitPrice: money.FromInt(100)}},
+	}
+}
+
+func TestRollbackImportBatchRemovesOnlyItsRows(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &entities.Platform{}, &entities.Status{}, &entities.Income{}, &entities.Detail{},
+		&entities.ImportBatch{}, &entities.ImportBatchRecord{})
+	repo := NewImportBatchRepository(db)
+
+	if err := db.Create(&entities.Platform{Id: 1, Name: "TikTok"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&entities.Status{Id: 1, Name: "Open"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	manual := testIncome(100)
+	if err := db.Create(&manual).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := repo.CreateImportBatch(ctx, entities.ImportBatch{FileName: "first.csv", Source: "api"}, []ImportedIncome{
+		{Income: testIncome(101), NewPlatform: "Instagram"},
+		{Income: testIncome(102), NewPlatform: "Instagram", NewStatus: "Imported"},
+		{Income: testIncome(103), NewPlatform: "YouTube"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := repo.CreateImportBatch(ctx, entities.ImportBatch{FileName: "second.csv", Source: "api"}, []ImportedIncome{
+		{Income: testIncome(201)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An income of the second batch starts using the YouTube platform the
+	// first one created.
+	var youtube entities.Platform
+	if err := db.Where("name = ?", "YouTube").First(&youtube).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&entities.Income{}).Where("invoice_id_number = ?", 201).Update("platform_id", youtube.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := repo.RollbackImportBatch(ctx, first.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("removed %d incomes, want the 3 of the batch", len(removed))
+	}
+
+	var invoices []int
+	db.Model(&entities.Income{}).Order("invoice_id_number").Pluck("invoice_id_number", &invoices)
+	if len(invoices) != 2 || invoices[0] != 100 || invoices[1] != 201 {
+		t.Errorf("incomes left = %v, want 100 and 201", invoices)
+	}
+	var detailIncomes []int
+	db.Model(&entities.Detail{}).Order("income_invoice_id_number").Pluck("income_invoice_id_number", &detailIncomes)
+	if len(detailIncomes) != 2 || detailIncomes[0] != 100 || detailIncomes[1] != 201 {
+		t.Errorf("lines left belong to %v, want 100 and 201", detailIncomes)
+	}
+
+	var platforms, statuses []string
+	db.Model(&entities.Platform{}).Order("name").Pluck("name", &platforms)
+	db.Model(&entities.Status{}).Order("name").Pluck("name", &statuses)
+	if len(platforms) != 2 || platforms[0] != "TikTok" || platforms[1] != "YouTube" {
+		t.Errorf("platforms left = %v, want TikTok and the YouTube still in use", platforms)
+	}
+	if len(statuses) != 1 || statuses[0] != "Open" {
+		t.Errorf("statuses left = %v, want Open", statuses)
+	}
+
+	batch, err := repo.GetImportBatchById(ctx, first.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.RolledBackAt.IsZero() {
+		t.Error("the batch is not marked rolled back")
+	}
+	batch, err = repo.GetImportBatchById(ctx, second.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !batch.RolledBackAt.IsZero() {
+		t.Error("the other batch is marked rolled back")
+	}
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/money"
+	"mtii-backend/taxid"
+	"mtii-backend/telemetry"
+	"time"
+)
+
+// IncomeExportRow is an income with the names of what it references and
+// one of its lines. An income without lines has a single row whose detail
+// fields are zero.
+type IncomeExportRow struct {
+	InvoiceIdNumber            int
+	InvoiceIssueDate           time.Time
+	InvoiceDueDate             time.Time
+	QuotationIdNumber          int
+	QuotationIssueDate         time.Time
+	ReceiptIdNumber            int
+	ReceiptIssueDate           time.Time
+	InfluencerPostingDate      time.Time
+	AgencyAgencyName           string
+	AgencyTaxPayerIdNumber     taxid.ID
+	AgencyBranchNumber         string
+	ContactorContactorName     string
+	ContactorEmail             string
+	BrandBrandName             string
+	BrandProduct               string
+	TransactionReferenceNumber int
+	Currency                   string
+	DiscountType               string
+	DiscountValue              money.Amount
+	TotalPaymentAmount         money.Amount
+	UnpaidPaymentAmount        money.Amount
+	WithholdingTaxAmount       money.Amount
+	PlatformName               string
+	StatusName                 string
+	PaymentMethodName          string
+	ReceiverName               string
+	SalePersonName             string
+	ChannelName                string
+	BankName                   string
+
+	DetailId            int
+	DetailDescription   string
+	DetailCategory      string
+	DetailQuantity      int
+	DetailUnit          string
+	DetailUnitPrice     money.Amount
+	DetailDiscountType  string
+	DetailDiscountValue money.Amount
+	DetailVatExempt     bool
+}
+
+// StreamIncomeExport passes fn the export rows of the incomes the list
+// endpoint would return for taxId, in invoice order with each income's
+// lines in their position order. Rows are read from the database cursor one
+// at a time, so the export never holds more than a row in memory. An error
+// from fn stops the stream and is returned.
+func (r *incomeRepository) StreamIncomeExport(ctx context.Context, taxId string, fn func(IncomeExportRow) error) error {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.StreamIncomeExport")
+	defer span.End()
+
+	db := session(ctx, r.db, "IncomeRepository.StreamIncomeExport")
+	query := db.Table("incomes").
+		Select(`incomes.invoice_id_number, incomes.invoice_issue_date, incomes.invoice_due_date,
+			incomes.quotation_id_number, incomes.quotation_issue_date,
+			incomes.receipt_id_number, incomes.receipt_issue_date, incomes.influencer_posting_date,
+			incomes.agency_agency_name, incomes.agency_tax_payer_id_number, incomes.agency_branch_number,
+			incomes.contactor_contactor_name, incomes.contactor_email,
+			incomes.brand_brand_name, incomes.brand_product, incomes.transaction_reference_number,
+			incomes.currency, incomes.discount_type, incomes.discount_value,
+			incomes.total_payment_amount, incomes.unpaid_payment_amount, incomes.withholding_tax_amount,
+			COALESCE(platforms.name, '') AS platform_name,
+			COALESCE(statuses.name, '') AS status_name,
+			COALESCE(payment_methods.name, '') AS payment_method_name,
+			COALESCE(receivers.name, '') AS receiver_name,
+			COALESCE(sale_people.name, '') AS sale_person_name,
+			COALESCE(channels.name, '') AS channel_name,
+			COALESCE(banks.name, '') AS bank_name,
+			COALESCE(details.id, 0) AS detail_id,
+			COALESCE(details.description, '') AS detail_description,
+			COALESCE(details.category, '') AS detail_category,
+			COALESCE(details.quantity, 0) AS detail_quantity,
+			COALESCE(details.unit, '') AS detail_unit,
+			COALESCE(details.unit_price, 0) AS detail_unit_price,
+			COALESCE(details.discount_type, '') AS detail_discount_type,
+			COALESCE(details.discount_value, 0) AS detail_discount_value,
+			COALESCE(details.vat_exempt, false) AS detail_vat_exempt`).
+		Joins("LEFT JOIN platforms ON platforms.id = incomes.platform_id").
+		Joins("LEFT JOIN statuses ON statuses.id = incomes.status_id").
+		Joins("LEFT JOIN payment_methods ON payment_methods.id = incomes.payment_method_id").
+		Joins("LEFT JOIN receivers ON receivers.id = incomes.receiver_id").
+		Joins("LEFT JOIN sale_people ON sale_people.id = incomes.sale_person_id").
+		Joins("LEFT JOIN channels ON channels.id = incomes.channel_id").
+		Joins("LEFT JOIN banks ON banks.id = incomes.bank_id").
+		Joins("LEFT JOIN details ON details.income_invoice_id_number = incomes.invoice_id_number")
+	if taxId != "" {
+		query = query.Where("incomes.agency_tax_payer_id_number LIKE ?", taxId+"%")
+	}
+
+	rows, err := query.Order("incomes.invoice_id_number, details.position, details.id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row IncomeExportRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
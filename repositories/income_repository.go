@@ -2,18 +2,64 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/telemetry"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IncomeRepository interface {
-	GetAllIncome(ctx context.Context) ([]entities.Income, error)
+	GetAllIncome(ctx context.Context, taxId string) ([]entities.Income, error)
 	GetIncomeByInvoiceIdNumber(ctx context.Context, incomeInvoiceIdNumber int) (entities.Income, error)
 	CreateIncome(ctx context.Context, income entities.Income) (entities.Income, error)
 	UpdateIncome(ctx context.Context, income entities.Income) (entities.Income, error)
 	UpdateIncomeWithNewInvoiceIdNumber(ctx context.Context, income entities.Income, oldInvoiceIdNumber int) (entities.Income, error)
+	SaveIncomeWithDetails(ctx context.Context, income entities.Income, oldInvoiceIdNumber int) (entities.Income, error)
 	DeleteIncome(ctx context.Context, incomeInvoiceIdNumber int) error
+	CountOutstandingIncome(ctx context.Context) (int64, money.Amount, error)
+	GetIncomesByInvoiceDate(ctx context.Context, from, to time.Time) ([]entities.Income, error)
+	GetPaidIncomesBySalePerson(ctx context.Context, salePersonId int, from, to time.Time) ([]entities.Income, error)
+	GetIncomesForTaxMonth(ctx context.Context, filter TaxMonthFilter) ([]entities.Income, error)
+	GetWithheldIncomes(ctx context.Context, filter TaxMonthFilter) ([]entities.Income, error)
+	StreamIncomeExport(ctx context.Context, taxId string, fn func(IncomeExportRow) error) error
+	GetOpenIncomes(ctx context.Context, currency string) ([]entities.Income, error)
+	GetIncomeByReceiptIdNumber(ctx context.Context, receiptIdNumber int) (entities.Income, error)
+	SaveStage(ctx context.Context, income entities.Income, from string, oldInvoiceIdNumber int) (entities.Income, error)
+}
+
+// Stages of an Income: a quotation becomes an invoice, and the invoice a
+// receipt once it is paid.
+const (
+	IncomeStageQuotation = "quotation"
+	IncomeStageInvoice   = "invoice"
+	IncomeStageReceipt   = "receipt"
+)
+
+// ErrStageChanged is returned when an income moved on to another stage
+// after it was loaded.
+var ErrStageChanged = errors.New("income stage changed")
+
+// seriesColumns are the income columns each numbering series issues, by
+// series name.
+var seriesColumns = map[string]string{
+	IncomeStageQuotation: "quotation_id_number",
+	IncomeStageInvoice:   "invoice_id_number",
+	IncomeStageReceipt:   "receipt_id_number",
+}
+
+// TaxMonthFilter selects the incomes whose DateColumn falls between From and
+// To, both inclusive. ReceiverId, when set, keeps only the incomes billed by
+// that receiver.
+type TaxMonthFilter struct {
+	DateColumn string
+	From       time.Time
+	To         time.Time
+	ReceiverId int
 }
 
 type incomeRepository struct {
@@ -26,9 +72,18 @@ func NewIncomeRepository(db *gorm.DB) IncomeRepository {
 	}
 }
 
-func (r *incomeRepository) GetAllIncome(ctx context.Context) ([]entities.Income, error) {
+// GetAllIncome lists incomes, keeping only those whose agency tax ID starts
+// with taxId when it is set.
+func (r *incomeRepository) GetAllIncome(ctx context.Context, taxId string) ([]entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.GetAllIncome")
+	defer span.End()
+
 	var incomes []entities.Income
-	err := r.db.
+	query := session(ctx, r.db, "IncomeRepository.GetAllIncome")
+	if taxId != "" {
+		query = query.Where("agency_tax_payer_id_number LIKE ?", taxId+"%")
+	}
+	err := query.
 		Preload("Platform").
 		Preload("Status").
 		Preload("PaymentMethod").
@@ -36,6 +91,10 @@ func (r *incomeRepository) GetAllIncome(ctx context.Context) ([]entities.Income,
 		Preload("SalePerson").
 		Preload("Channel").
 		Preload("Bank").
+		Preload("Details", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		Preload("Expenses").
 		Find(&incomes).Error
 	if err != nil {
 		return []entities.Income{}, err
@@ -44,8 +103,11 @@ func (r *incomeRepository) GetAllIncome(ctx context.Context) ([]entities.Income,
 }
 
 func (r *incomeRepository) GetIncomeByInvoiceIdNumber(ctx context.Context, incomeInvoiceIdNumber int) (entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.GetIncomeByInvoiceIdNumber")
+	defer span.End()
+
 	var income entities.Income
-	err := r.db.
+	err := session(ctx, r.db, "IncomeRepository.GetIncomeByInvoiceIdNumber").
 		Preload("Platform").
 		Preload("Status").
 		Preload("PaymentMethod").
@@ -53,6 +115,10 @@ func (r *incomeRepository) GetIncomeByInvoiceIdNumber(ctx context.Context, incom
 		Preload("SalePerson").
 		Preload("Channel").
 		Preload("Bank").
+		Preload("Details", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		Preload("Expenses").
 		Where("invoice_id_number = ?", incomeInvoiceIdNumber).
 		First(&income).Error
 	if err != nil {
@@ -61,16 +127,51 @@ func (r *incomeRepository) GetIncomeByInvoiceIdNumber(ctx context.Context, incom
 	return income, err
 }
 
+// CreateIncome inserts an income with its lines. An income without its
+// stage's number takes the next one of the stage's series in the same
+// transaction; a quotation is stored under the negative of its quotation
+// number until it is invoiced.
 func (r *incomeRepository) CreateIncome(ctx context.Context, income entities.Income) (entities.Income, error) {
-	err := r.db.Create(&income).Error
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.CreateIncome")
+	defer span.End()
+
+	tx := session(ctx, r.db, "IncomeRepository.CreateIncome").Begin()
+	if tx.Error != nil {
+		return entities.Income{}, tx.Error
+	}
+
+	var err error
+	if income.Stage == IncomeStageQuotation {
+		if income.QuotationIdNumber == 0 {
+			income.QuotationIdNumber, err = nextNumber(tx, IncomeStageQuotation)
+		}
+		if income.InvoiceIdNumber == 0 {
+			income.InvoiceIdNumber = -income.QuotationIdNumber
+		}
+	} else if income.InvoiceIdNumber == 0 {
+		income.InvoiceIdNumber, err = nextNumber(tx, IncomeStageInvoice)
+	}
 	if err != nil {
+		tx.Rollback()
 		return entities.Income{}, err
 	}
-	return income, err
+
+	if err := tx.Omit(unsetLinks(income)...).Create(&income).Error; err != nil {
+		tx.Rollback()
+		return entities.Income{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return entities.Income{}, err
+	}
+	return income, nil
 }
 
 func (r *incomeRepository) UpdateIncome(ctx context.Context, income entities.Income) (entities.Income, error) {
-	err := r.db.Save(&income).Error
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.UpdateIncome")
+	defer span.End()
+
+	err := session(ctx, r.db, "IncomeRepository.UpdateIncome").Omit(unsetLinks(income)...).Save(&income).Error
 	if err != nil {
 		return entities.Income{}, err
 	}
@@ -78,23 +179,66 @@ func (r *incomeRepository) UpdateIncome(ctx context.Context, income entities.Inc
 }
 
 func (r *incomeRepository) UpdateIncomeWithNewInvoiceIdNumber(ctx context.Context, income entities.Income, oldInvoiceIdNumber int) (entities.Income, error) {
-	tx := r.db.Begin()
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.UpdateIncomeWithNewInvoiceIdNumber")
+	defer span.End()
+
+	tx := session(ctx, r.db, "IncomeRepository.UpdateIncomeWithNewInvoiceIdNumber").Begin()
 	if tx.Error != nil {
 		return entities.Income{}, tx.Error
 	}
 
-	if err := tx.Create(&income).Error; err != nil {
+	if err := moveIncome(tx, &income, oldInvoiceIdNumber); err != nil {
 		tx.Rollback()
 		return entities.Income{}, err
 	}
 
-	if err := tx.Model(&entities.Detail{}).Where("income_invoice_id_number = ?", oldInvoiceIdNumber).
-		Update("income_invoice_id_number", income.InvoiceIdNumber).Error; err != nil {
+	if err := tx.Commit().Error; err != nil {
+		return entities.Income{}, err
+	}
+
+	return income, nil
+}
+
+// SaveIncomeWithDetails updates the income stored under oldInvoiceIdNumber
+// and replaces its lines with income.Details in one transaction: lines with
+// an id are updated, new lines are inserted and all other lines are deleted.
+func (r *incomeRepository) SaveIncomeWithDetails(ctx context.Context, income entities.Income, oldInvoiceIdNumber int) (entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.SaveIncomeWithDetails")
+	defer span.End()
+
+	details := income.Details
+	income.Details = nil
+
+	tx := session(ctx, r.db, "IncomeRepository.SaveIncomeWithDetails").Begin()
+	if tx.Error != nil {
+		return entities.Income{}, tx.Error
+	}
+
+	if income.InvoiceIdNumber != oldInvoiceIdNumber {
+		if err := moveIncome(tx, &income, oldInvoiceIdNumber); err != nil {
+			tx.Rollback()
+			return entities.Income{}, err
+		}
+	} else if err := tx.Omit(unsetLinks(income)...).Save(&income).Error; err != nil {
 		tx.Rollback()
 		return entities.Income{}, err
 	}
 
-	if err := tx.Where("invoice_id_number = ?", oldInvoiceIdNumber).Delete(&entities.Income{}).Error; err != nil {
+	keepIds := []int{}
+	for i := range details {
+		details[i].IncomeInvoiceIdNumber = income.InvoiceIdNumber
+		if err := tx.Omit("Income").Save(&details[i]).Error; err != nil {
+			tx.Rollback()
+			return entities.Income{}, err
+		}
+		keepIds = append(keepIds, details[i].Id)
+	}
+
+	removed := tx.Where("income_invoice_id_number = ?", income.InvoiceIdNumber)
+	if len(keepIds) > 0 {
+		removed = removed.Where("id NOT IN ?", keepIds)
+	}
+	if err := removed.Delete(&entities.Detail{}).Error; err != nil {
 		tx.Rollback()
 		return entities.Income{}, err
 	}
@@ -103,13 +247,322 @@ func (r *incomeRepository) UpdateIncomeWithNewInvoiceIdNumber(ctx context.Contex
 		return entities.Income{}, err
 	}
 
+	income.Details = details
 	return income, nil
 }
 
+// moveIncome stores income under its new invoice number, points every
+// record of the income stored under oldInvoiceIdNumber at it and deletes the
+// old row. It runs inside the caller's transaction.
+func moveIncome(tx *gorm.DB, income *entities.Income, oldInvoiceIdNumber int) error {
+	// The old row gives up its quotation and receipt numbers first, so the
+	// new one can take them without breaking their unique indexes.
+	if err := tx.Model(&entities.Income{}).Where("invoice_id_number = ?", oldInvoiceIdNumber).
+		Updates(map[string]any{"quotation_id_number": 0, "receipt_id_number": 0}).Error; err != nil {
+		return err
+	}
+	if err := tx.Omit(unsetLinks(*income)...).Create(income).Error; err != nil {
+		return err
+	}
+
+	children := []any{
+		&entities.Detail{},
+		&entities.InfluencerAssignment{},
+		&entities.Expense{},
+		&entities.BankTransaction{},
+		&entities.BankTransactionMatch{},
+	}
+	for _, child := range children {
+		if err := tx.Model(child).Where("income_invoice_id_number = ?", oldInvoiceIdNumber).
+			Update("income_invoice_id_number", income.InvoiceIdNumber).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("invoice_id_number = ?", oldInvoiceIdNumber).Delete(&entities.Income{}).Error
+}
+
 func (r *incomeRepository) DeleteIncome(ctx context.Context, incomeInvoiceIdNumber int) error {
-	err := r.db.Delete(&entities.Income{}, "invoice_id_number = ?", incomeInvoiceIdNumber).Error
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.DeleteIncome")
+	defer span.End()
+
+	err := session(ctx, r.db, "IncomeRepository.DeleteIncome").Delete(&entities.Income{}, "invoice_id_number = ?", incomeInvoiceIdNumber).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (r *incomeRepository) CountOutstandingIncome(ctx context.Context) (int64, money.Amount, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.CountOutstandingIncome")
+	defer span.End()
+
+	var result struct {
+		Count  int64
+		Amount money.Amount
+	}
+	err := session(ctx, r.db, "IncomeRepository.CountOutstandingIncome").
+		Table("incomes").
+		Joins(baseRateJoin("incomes", "invoice_issue_date", "base_rate")).
+		Select("COUNT(*) AS count, COALESCE(ROUND(SUM(incomes.unpaid_payment_amount * base_rate.rate), 2), 0) AS amount").
+		Where("incomes.unpaid_payment_amount > 0 AND incomes.stage <> ?", IncomeStageQuotation).
+		Scan(&result).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return result.Count, result.Amount, nil
+}
+
+// GetIncomesByInvoiceDate lists the incomes invoiced between from and to,
+// both inclusive and either open-ended when zero, with what reports need to
+// total and group them. Quotations are left out, not being invoiced yet.
+func (r *incomeRepository) GetIncomesByInvoiceDate(ctx context.Context, from, to time.Time) ([]entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.GetIncomesByInvoiceDate")
+	defer span.End()
+
+	var incomes []entities.Income
+	query := session(ctx, r.db, "IncomeRepository.GetIncomesByInvoiceDate").
+		Where("stage <> ?", IncomeStageQuotation)
+	if !from.IsZero() {
+		query = query.Where("invoice_issue_date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("invoice_issue_date < ?", to.AddDate(0, 0, 1))
+	}
+	err := query.
+		Preload("Platform").
+		Preload("Channel").
+		Preload("SalePerson").
+		Preload("Details").
+		Preload("Expenses").
+		Order("invoice_issue_date, invoice_id_number").
+		Find(&incomes).Error
+	if err != nil {
+		return []entities.Income{}, err
+	}
+	return incomes, err
+}
+
+// GetPaidIncomesBySalePerson lists the fully paid incomes of a sale person
+// whose receipt was issued between from and to, both inclusive, with the
+// lines their revenue is computed from. Quotations are left out.
+func (r *incomeRepository) GetPaidIncomesBySalePerson(ctx context.Context, salePersonId int, from, to time.Time) ([]entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.GetPaidIncomesBySalePerson")
+	defer span.End()
+
+	var incomes []entities.Income
+	err := session(ctx, r.db, "IncomeRepository.GetPaidIncomesBySalePerson").
+		Preload("Details").
+		Where("sale_person_id = ? AND unpaid_payment_amount = 0 AND stage <> ?", salePersonId, IncomeStageQuotation).
+		Where("receipt_issue_date >= ? AND receipt_issue_date < ?", from, to.AddDate(0, 0, 1)).
+		Order("receipt_issue_date, invoice_id_number").
+		Find(&incomes).Error
+	if err != nil {
+		return []entities.Income{}, err
+	}
+	return incomes, err
+}
+
+// GetIncomesForTaxMonth lists the incomes dated in the tax month with the
+// lines their VAT is computed from. Quotations carry no tax and are left
+// out.
+func (r *incomeRepository) GetIncomesForTaxMonth(ctx context.Context, filter TaxMonthFilter) ([]entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.GetIncomesForTaxMonth")
+	defer span.End()
+
+	if !revenueDateColumns[filter.DateColumn] {
+		return []entities.Income{}, fmt.Errorf("unknown tax month date column %q", filter.DateColumn)
+	}
+
+	var incomes []entities.Income
+	query := session(ctx, r.db, "IncomeRepository.GetIncomesForTaxMonth").
+		Where(fmt.Sprintf("%s >= ? AND %s < ?", filter.DateColumn, filter.DateColumn), filter.From, filter.To.AddDate(0, 0, 1)).
+		Where("stage <> ?", IncomeStageQuotation)
+	if filter.ReceiverId != 0 {
+		query = query.Where("receiver_id = ?", filter.ReceiverId)
+	}
+	err := query.
+		Preload("Details").
+		Order(filter.DateColumn + ", invoice_id_number").
+		Find(&incomes).Error
+	if err != nil {
+		return []entities.Income{}, err
+	}
+	return incomes, err
+}
+
+// withholdingDate is the SQL for the date tax withheld from an income is
+// registered on: the date of its certificate, or the receipt date while the
+// certificate has not arrived.
+const withholdingDate = `CASE WHEN withholding_certificate_date > ? THEN withholding_certificate_date ELSE receipt_issue_date END`
+
+// GetWithheldIncomes lists the incomes a client withheld tax from whose
+// withholding date, the certificate date or else the receipt date, falls in
+// the filter's range. DateColumn is ignored and quotations are left out.
+func (r *incomeRepository) GetWithheldIncomes(ctx context.Context, filter TaxMonthFilter) ([]entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.GetWithheldIncomes")
+	defer span.End()
+
+	var incomes []entities.Income
+	query := session(ctx, r.db, "IncomeRepository.GetWithheldIncomes").
+		Where("withholding_tax_amount > 0 AND stage <> ?", IncomeStageQuotation).
+		Where("("+withholdingDate+") >= ? AND ("+withholdingDate+") < ?", time.Time{}, filter.From, time.Time{}, filter.To.AddDate(0, 0, 1))
+	if filter.ReceiverId != 0 {
+		query = query.Where("receiver_id = ?", filter.ReceiverId)
+	}
+	err := query.
+		Preload("Details").
+		Order("invoice_id_number").
+		Find(&incomes).Error
+	if err != nil {
+		return []entities.Income{}, err
+	}
+	return incomes, err
+}
+
+// GetOpenIncomes lists the invoices in currency that still have an unpaid
+// balance, oldest first.
+func (r *incomeRepository) GetOpenIncomes(ctx context.Context, currency string) ([]entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.GetOpenIncomes")
+	defer span.End()
+
+	var incomes []entities.Income
+	err := session(ctx, r.db, "IncomeRepository.GetOpenIncomes").
+		Where("unpaid_payment_amount > 0 AND currency = ? AND stage <> ?", currency, IncomeStageQuotation).
+		Order("invoice_issue_date, invoice_id_number").
+		Find(&incomes).Error
+	if err != nil {
+		return []entities.Income{}, err
+	}
+	return incomes, err
+}
+
+func (r *incomeRepository) GetIncomeByReceiptIdNumber(ctx context.Context, receiptIdNumber int) (entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.GetIncomeByReceiptIdNumber")
+	defer span.End()
+
+	var income entities.Income
+	err := session(ctx, r.db, "IncomeRepository.GetIncomeByReceiptIdNumber").
+		Where("receipt_id_number = ?", receiptIdNumber).
+		First(&income).Error
+	if err != nil {
+		return entities.Income{}, err
+	}
+	return income, err
+}
+
+// SaveStage saves an income that has moved on from the stage from to
+// income.Stage, stored under oldInvoiceIdNumber. An invoice without an
+// invoice number, or a receipt without a receipt number, takes the next
+// one of its series in the same transaction, and the income moves to a new
+// invoice number with its records. It returns ErrStageChanged when the
+// stored income is no longer at the stage from.
+func (r *incomeRepository) SaveStage(ctx context.Context, income entities.Income, from string, oldInvoiceIdNumber int) (entities.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeRepository.SaveStage")
+	defer span.End()
+
+	tx := session(ctx, r.db, "IncomeRepository.SaveStage").Begin()
+	if tx.Error != nil {
+		return entities.Income{}, tx.Error
+	}
+
+	moved := tx.Model(&entities.Income{}).
+		Where("invoice_id_number = ? AND stage = ?", oldInvoiceIdNumber, from).
+		Update("stage", income.Stage)
+	if moved.Error != nil {
+		tx.Rollback()
+		return entities.Income{}, moved.Error
+	}
+	if moved.RowsAffected == 0 {
+		tx.Rollback()
+		return entities.Income{}, ErrStageChanged
+	}
+
+	var err error
+	switch {
+	case income.Stage == IncomeStageInvoice && income.InvoiceIdNumber <= 0:
+		income.InvoiceIdNumber, err = nextNumber(tx, IncomeStageInvoice)
+	case income.Stage == IncomeStageReceipt && income.ReceiptIdNumber == 0:
+		income.ReceiptIdNumber, err = nextNumber(tx, IncomeStageReceipt)
+	}
+	if err != nil {
+		tx.Rollback()
+		return entities.Income{}, err
+	}
+
+	if income.InvoiceIdNumber != oldInvoiceIdNumber {
+		err = moveIncome(tx, &income, oldInvoiceIdNumber)
+	} else {
+		err = tx.Omit(unsetLinks(income)...).Save(&income).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return entities.Income{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return entities.Income{}, err
+	}
+	return income, nil
+}
+
+// unsetLinks lists the lookup fields an income leaves at zero. A quotation
+// may be saved before its platform, bank and the rest are known; leaving
+// them out of the statement stores them as NULL, which their foreign keys
+// accept.
+func unsetLinks(income entities.Income) []string {
+	links := map[string]int{
+		"PlatformId":      income.PlatformId,
+		"StatusId":        income.StatusId,
+		"PaymentMethodId": income.PaymentMethodId,
+		"ReceiverId":      income.ReceiverId,
+		"SalePersonId":    income.SalePersonId,
+		"ChannelId":       income.ChannelId,
+		"BankId":          income.BankId,
+	}
+	var unset []string
+	for field, id := range links {
+		if id == 0 {
+			unset = append(unset, field)
+		}
+	}
+	return unset
+}
+
+// nextNumber issues the next number of a series inside tx. The series' row
+// stays locked until tx ends, so concurrent transactions wait for it, and a
+// rolled back transaction gives its number back. Numbers entered by hand
+// beyond the series are skipped.
+func nextNumber(tx *gorm.DB, series string) (int, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entities.DocumentNumber{Series: series}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var counter entities.DocumentNumber
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("series = ?", series).
+		First(&counter).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var used int
+	column := seriesColumns[series]
+	err = tx.Model(&entities.Income{}).
+		Select("COALESCE(MAX(" + column + "), 0)").
+		Scan(&used).Error
+	if err != nil {
+		return 0, err
+	}
+
+	next := max(counter.Last, used) + 1
+	err = tx.Model(&entities.DocumentNumber{}).
+		Where("series = ?", series).
+		Update("last", next).Error
+	if err != nil {
+		return 0, err
+	}
+	return next, nil
+}
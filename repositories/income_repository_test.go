@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"testing"
+	"time"
+)
+
+// TestReportsLeaveOutQuotations saves a paid invoice and a quotation
+// carrying the same dates and amounts; only the invoice may be counted.
+func TestReportsLeaveOutQuotations(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &entities.Income{}, &entities.Detail{}, &entities.Platform{}, &entities.Channel{}, &entities.SalePerson{}, &entities.Expense{})
+	repo := NewIncomeRepository(db)
+
+	march := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	for number, stage := range map[int]string{1: IncomeStageReceipt, 2: IncomeStageQuotation} {
+		income := testIncome(number)
+		income.Stage = stage
+		income.SalePersonId = 7
+		income.InvoiceIssueDate = march
+		income.ReceiptIssueDate = march
+		income.WithholdingTaxRate = money.FromInt(3)
+		income.WithholdingTaxAmount = money.FromInt(3)
+		if err := db.Create(&income).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from, to := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+	check := func(name string, incomes []entities.Income, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(incomes) != 1 || incomes[0].InvoiceIdNumber != 1 {
+			numbers := make([]int, len(incomes))
+			for i, income := range incomes {
+				numbers[i] = income.InvoiceIdNumber
+			}
+			t.Errorf("%s = incomes %v, want only the invoice 1", name, numbers)
+		}
+	}
+
+	incomes, err := repo.GetIncomesForTaxMonth(ctx, TaxMonthFilter{DateColumn: "invoice_issue_date", From: from, To: to})
+	check("GetIncomesForTaxMonth", incomes, err)
+	incomes, err = repo.GetWithheldIncomes(ctx, TaxMonthFilter{From: from, To: to})
+	check("GetWithheldIncomes", incomes, err)
+	incomes, err = repo.GetPaidIncomesBySalePerson(ctx, 7, from, to)
+	check("GetPaidIncomesBySalePerson", incomes, err)
+	incomes, err = repo.GetIncomesByInvoiceDate(ctx, from, to)
+	check("GetIncomesByInvoiceDate", incomes, err)
+}
+
+// TestIncomeNumbering walks a quotation through to its receipt next to
+// invoices numbered by hand and by the series.
+func TestIncomeNumbering(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &entities.Income{}, &entities.Detail{}, &entities.DocumentNumber{},
+		&entities.InfluencerAssignment{}, &entities.Expense{}, &entities.BankTransaction{}, &entities.BankTransactionMatch{})
+	repo := NewIncomeRepository(db)
+
+	create := func(income entities.Income) entities.Income {
+		t.Helper()
+		created, err := repo.CreateIncome(ctx, income)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created
+	}
+
+	quotation := testIncome(0)
+	quotation.Stage = IncomeStageQuotation
+	quotation = create(quotation)
+	if quotation.QuotationIdNumber != 1 || quotation.InvoiceIdNumber != -1 {
+		t.Errorf("quotation numbered %d under key %d, want 1 under -1", quotation.QuotationIdNumber, quotation.InvoiceIdNumber)
+	}
+
+	// An invoice entered by hand is skipped by the series.
+	manual := testIncome(41)
+	manual.Stage = IncomeStageInvoice
+	create(manual)
+	invoice := testIncome(0)
+	invoice.Stage = IncomeStageInvoice
+	if invoice = create(invoice); invoice.InvoiceIdNumber != 42 {
+		t.Errorf("invoice numbered %d, want 42", invoice.InvoiceIdNumber)
+	}
+
+	// Deleting the last invoice does not give its number back.
+	if err := repo.DeleteIncome(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+
+	// Converting the quotation numbers it as the next invoice and moves
+	// its lines.
+	converted := quotation
+	converted.Details = nil
+	converted.InvoiceIdNumber = 0
+	converted.Stage = IncomeStageInvoice
+	converted, err := repo.SaveStage(ctx, converted, IncomeStageQuotation, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.InvoiceIdNumber != 43 {
+		t.Errorf("converted quotation numbered %d, want 43", converted.InvoiceIdNumber)
+	}
+	stored, err := repo.GetIncomeByInvoiceIdNumber(ctx, 43)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.QuotationIdNumber != 1 || len(stored.Details) != 1 {
+		t.Errorf("invoice 43 has quotation number %d and %d lines, want 1 and 1", stored.QuotationIdNumber, len(stored.Details))
+	}
+
+	// A second conversion of the same quotation finds it gone.
+	if _, err := repo.SaveStage(ctx, converted, IncomeStageQuotation, -1); !errors.Is(err, ErrStageChanged) {
+		t.Errorf("second conversion = %v, want ErrStageChanged", err)
+	}
+
+	receipt := stored
+	receipt.Details = nil
+	receipt.Stage = IncomeStageReceipt
+	receipt, err = repo.SaveStage(ctx, receipt, IncomeStageInvoice, 43)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.ReceiptIdNumber != 1 {
+		t.Errorf("receipt numbered %d, want 1", receipt.ReceiptIdNumber)
+	}
+	if _, err := repo.SaveStage(ctx, receipt, IncomeStageInvoice, 43); !errors.Is(err, ErrStageChanged) {
+		t.Errorf("second receipt = %v, want ErrStageChanged", err)
+	}
+
+	// Receipt numbers are unique.
+	duplicate := manual
+	duplicate.Details = nil
+	duplicate.Stage = IncomeStageReceipt
+	duplicate.ReceiptIdNumber = 1
+	if _, err := repo.SaveStage(ctx, duplicate, IncomeStageInvoice, 41); err == nil {
+		t.Error("a second receipt 1 was saved")
+	}
+}
+
+// TestQuotationLeavesLinksNull saves a quotation without its lookups; they
+// must be stored as NULL, which their foreign keys accept, and be filled in
+// when it is invoiced.
+func TestQuotationLeavesLinksNull(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &entities.Income{}, &entities.Detail{}, &entities.DocumentNumber{},
+		&entities.InfluencerAssignment{}, &entities.Expense{}, &entities.BankTransaction{}, &entities.BankTransactionMatch{})
+	repo := NewIncomeRepository(db)
+
+	quotation, err := repo.CreateIncome(ctx, entities.Income{Stage: IncomeStageQuotation, Currency: "THB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unset int64
+	db.Model(&entities.Income{}).Where("platform_id IS NULL AND bank_id IS NULL").Count(&unset)
+	if unset != 1 {
+		t.Fatalf("%d incomes have no platform and bank, want the quotation", unset)
+	}
+
+	quotation.Stage, quotation.PlatformId, quotation.BankId = IncomeStageInvoice, 2, 3
+	invoice, err := repo.SaveStage(ctx, quotation, IncomeStageQuotation, quotation.InvoiceIdNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.GetIncomeByInvoiceIdNumber(ctx, invoice.InvoiceIdNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.PlatformId != 2 || stored.BankId != 3 || stored.ChannelId != 0 {
+		t.Errorf("invoice links platform %d, bank %d and channel %d, want 2, 3 and none", stored.PlatformId, stored.BankId, stored.ChannelId)
+	}
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InfluencerAssignmentFilter narrows an assignment list. Zero fields match
+// everything; From and To bound the posting date, both inclusive.
+type InfluencerAssignmentFilter struct {
+	InfluencerId          int
+	IncomeInvoiceIdNumber int
+	PlatformId            int
+	From                  time.Time
+	To                    time.Time
+}
+
+type InfluencerAssignmentRepository interface {
+	GetAllInfluencerAssignment(ctx context.Context, filter InfluencerAssignmentFilter) ([]entities.InfluencerAssignment, error)
+	GetInfluencerAssignmentById(ctx context.Context, assignmentId int) (entities.InfluencerAssignment, error)
+	CreateInfluencerAssignment(ctx context.Context, assignment entities.InfluencerAssignment) (entities.InfluencerAssignment, error)
+	UpdateInfluencerAssignment(ctx context.Context, assignment entities.InfluencerAssignment) (entities.InfluencerAssignment, error)
+	DeleteInfluencerAssignment(ctx context.Context, assignmentId int) error
+}
+
+type influencerAssignmentRepository struct {
+	db *gorm.DB
+}
+
+func NewInfluencerAssignmentRepository(db *gorm.DB) InfluencerAssignmentRepository {
+	return &influencerAssignmentRepository{
+		db: db,
+	}
+}
+
+// GetAllInfluencerAssignment lists matching assignments by posting date,
+// with their influencer, line item and income.
+func (r *influencerAssignmentRepository) GetAllInfluencerAssignment(ctx context.Context, filter InfluencerAssignmentFilter) ([]entities.InfluencerAssignment, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentRepository.GetAllInfluencerAssignment")
+	defer span.End()
+
+	var assignments []entities.InfluencerAssignment
+	query := session(ctx, r.db, "InfluencerAssignmentRepository.GetAllInfluencerAssignment").
+		Preload("Influencer").
+		Preload("Detail").
+		Preload("Income.Platform").
+		Preload("Income.Status").
+		Order("influencer_assignments.posting_date, influencer_assignments.id")
+	if filter.InfluencerId != 0 {
+		query = query.Where("influencer_assignments.influencer_id = ?", filter.InfluencerId)
+	}
+	if filter.IncomeInvoiceIdNumber != 0 {
+		query = query.Where("influencer_assignments.income_invoice_id_number = ?", filter.IncomeInvoiceIdNumber)
+	}
+	if filter.PlatformId != 0 {
+		query = query.
+			Joins("JOIN incomes ON incomes.invoice_id_number = influencer_assignments.income_invoice_id_number").
+			Where("incomes.platform_id = ?", filter.PlatformId)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("influencer_assignments.posting_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("influencer_assignments.posting_date < ?", filter.To.AddDate(0, 0, 1))
+	}
+	err := query.Find(&assignments).Error
+	if err != nil {
+		return []entities.InfluencerAssignment{}, err
+	}
+	return assignments, err
+}
+
+func (r *influencerAssignmentRepository) GetInfluencerAssignmentById(ctx context.Context, assignmentId int) (entities.InfluencerAssignment, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentRepository.GetInfluencerAssignmentById")
+	defer span.End()
+
+	var assignment entities.InfluencerAssignment
+	err := session(ctx, r.db, "InfluencerAssignmentRepository.GetInfluencerAssignmentById").
+		Preload("Influencer").
+		Where("id = ?", assignmentId).First(&assignment).Error
+	if err != nil {
+		return entities.InfluencerAssignment{}, err
+	}
+	return assignment, err
+}
+
+func (r *influencerAssignmentRepository) CreateInfluencerAssignment(ctx context.Context, assignment entities.InfluencerAssignment) (entities.InfluencerAssignment, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentRepository.CreateInfluencerAssignment")
+	defer span.End()
+
+	err := session(ctx, r.db, "InfluencerAssignmentRepository.CreateInfluencerAssignment").
+		Omit("Influencer", "Income", "Detail").Create(&assignment).Error
+	if err != nil {
+		return entities.InfluencerAssignment{}, err
+	}
+	return assignment, err
+}
+
+func (r *influencerAssignmentRepository) UpdateInfluencerAssignment(ctx context.Context, assignment entities.InfluencerAssignment) (entities.InfluencerAssignment, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentRepository.UpdateInfluencerAssignment")
+	defer span.End()
+
+	err := session(ctx, r.db, "InfluencerAssignmentRepository.UpdateInfluencerAssignment").
+		Omit("Influencer", "Income", "Detail").Save(&assignment).Error
+	if err != nil {
+		return entities.InfluencerAssignment{}, err
+	}
+	return assignment, err
+}
+
+func (r *influencerAssignmentRepository) DeleteInfluencerAssignment(ctx context.Context, assignmentId int) error {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentRepository.DeleteInfluencerAssignment")
+	defer span.End()
+
+	err := session(ctx, r.db, "InfluencerAssignmentRepository.DeleteInfluencerAssignment").Delete(&entities.InfluencerAssignment{}, "id = ?", assignmentId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
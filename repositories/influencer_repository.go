@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+
+	"gorm.io/gorm"
+)
+
+type InfluencerRepository interface {
+	GetAllInfluencer(ctx context.Context, platformId int) ([]entities.Influencer, error)
+	GetInfluencerById(ctx context.Context, influencerId int) (entities.Influencer, error)
+	CreateInfluencer(ctx context.Context, influencer entities.Influencer) (entities.Influencer, error)
+	UpdateInfluencer(ctx context.Context, influencer entities.Influencer, replaceHandles, replaceRateCard bool) (entities.Influencer, error)
+	DeleteInfluencer(ctx context.Context, influencerId int) error
+}
+
+type influencerRepository struct {
+	db *gorm.DB
+}
+
+func NewInfluencerRepository(db *gorm.DB) InfluencerRepository {
+	return &influencerRepository{
+		db: db,
+	}
+}
+
+// GetAllInfluencer lists influencers with their handles and rate cards,
+// keeping only those with a handle on platformId when it is set.
+func (r *influencerRepository) GetAllInfluencer(ctx context.Context, platformId int) ([]entities.Influencer, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerRepository.GetAllInfluencer")
+	defer span.End()
+
+	var influencers []entities.Influencer
+	query := session(ctx, r.db, "InfluencerRepository.GetAllInfluencer").
+		Preload("Handles", orderById).
+		Preload("RateCard", orderById).
+		Order("id")
+	if platformId != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM influencer_handles h WHERE h.influencer_id = influencers.id AND h.platform_id = ?)", platformId)
+	}
+	err := query.Find(&influencers).Error
+	if err != nil {
+		return []entities.Influencer{}, err
+	}
+	return influencers, err
+}
+
+func (r *influencerRepository) GetInfluencerById(ctx context.Context, influencerId int) (entities.Influencer, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerRepository.GetInfluencerById")
+	defer span.End()
+
+	var influencer entities.Influencer
+	err := session(ctx, r.db, "InfluencerRepository.GetInfluencerById").
+		Preload("Handles", orderById).
+		Preload("RateCard", orderById).
+		Where("id = ?", influencerId).First(&influencer).Error
+	if err != nil {
+		return entities.Influencer{}, err
+	}
+	return influencer, err
+}
+
+func (r *influencerRepository) CreateInfluencer(ctx context.Context, influencer entities.Influencer) (entities.Influencer, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerRepository.CreateInfluencer")
+	defer span.End()
+
+	err := session(ctx, r.db, "InfluencerRepository.CreateInfluencer").Create(&influencer).Error
+	if err != nil {
+		return entities.Influencer{}, err
+	}
+	return influencer, err
+}
+
+// UpdateInfluencer saves the influencer and, when asked, replaces its
+// handles or rate card with the ones given, in one transaction.
+func (r *influencerRepository) UpdateInfluencer(ctx context.Context, influencer entities.Influencer, replaceHandles, replaceRateCard bool) (entities.Influencer, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerRepository.UpdateInfluencer")
+	defer span.End()
+
+	handles, rateCard := influencer.Handles, influencer.RateCard
+	influencer.Handles, influencer.RateCard = nil, nil
+
+	tx := session(ctx, r.db, "InfluencerRepository.UpdateInfluencer").Begin()
+	if tx.Error != nil {
+		return entities.Influencer{}, tx.Error
+	}
+
+	if err := tx.Omit("Bank").Save(&influencer).Error; err != nil {
+		tx.Rollback()
+		return entities.Influencer{}, err
+	}
+
+	if replaceHandles {
+		if err := tx.Where("influencer_id = ?", influencer.Id).Delete(&entities.InfluencerHandle{}).Error; err != nil {
+			tx.Rollback()
+			return entities.Influencer{}, err
+		}
+		for i := range handles {
+			handles[i].InfluencerId = influencer.Id
+			if err := tx.Create(&handles[i]).Error; err != nil {
+				tx.Rollback()
+				return entities.Influencer{}, err
+			}
+		}
+	}
+
+	if replaceRateCard {
+		if err := tx.Where("influencer_id = ?", influencer.Id).Delete(&entities.InfluencerRate{}).Error; err != nil {
+			tx.Rollback()
+			return entities.Influencer{}, err
+		}
+		for i := range rateCard {
+			rateCard[i].InfluencerId = influencer.Id
+			if err := tx.Create(&rateCard[i]).Error; err != nil {
+				tx.Rollback()
+				return entities.Influencer{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return entities.Influencer{}, err
+	}
+
+	return influencer, nil
+}
+
+func (r *influencerRepository) DeleteInfluencer(ctx context.Context, influencerId int) error {
+	ctx, span := telemetry.Start(ctx, "InfluencerRepository.DeleteInfluencer")
+	defer span.End()
+
+	err := session(ctx, r.db, "InfluencerRepository.DeleteInfluencer").Delete(&entities.Influencer{}, "id = ?", influencerId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
 )
@@ -26,8 +27,11 @@ func NewPaymentMethodRepository(db *gorm.DB) PaymentMethodRepository {
 }
 
 func (r *paymentMethodRepository) GetAllPaymentMethod(ctx context.Context) ([]entities.PaymentMethod, error) {
+	ctx, span := telemetry.Start(ctx, "PaymentMethodRepository.GetAllPaymentMethod")
+	defer span.End()
+
 	var paymentMethods []entities.PaymentMethod
-	err := r.db.Find(&paymentMethods).Error
+	err := session(ctx, r.db, "PaymentMethodRepository.GetAllPaymentMethod").Find(&paymentMethods).Error
 	if err != nil {
 		return []entities.PaymentMethod{}, err
 	}
@@ -35,8 +39,11 @@ func (r *paymentMethodRepository) GetAllPaymentMethod(ctx context.Context) ([]en
 }
 
 func (r *paymentMethodRepository) GetPaymentMethodById(ctx context.Context, paymentMethodId int) (entities.PaymentMethod, error) {
+	ctx, span := telemetry.Start(ctx, "PaymentMethodRepository.GetPaymentMethodById")
+	defer span.End()
+
 	var paymentMethod entities.PaymentMethod
-	err := r.db.Where("id = ?", paymentMethodId).First(&paymentMethod).Error
+	err := session(ctx, r.db, "PaymentMethodRepository.GetPaymentMethodById").Where("id = ?", paymentMethodId).First(&paymentMethod).Error
 	if err != nil {
 		return entities.PaymentMethod{}, err
 	}
@@ -44,7 +51,10 @@ func (r *paymentMethodRepository) GetPaymentMethodById(ctx context.Context, paym
 }
 
 func (r *paymentMethodRepository) CreatePaymentMethod(ctx context.Context, paymentMethod entities.PaymentMethod) (entities.PaymentMethod, error) {
-	err := r.db.Create(&paymentMethod).Error
+	ctx, span := telemetry.Start(ctx, "PaymentMethodRepository.CreatePaymentMethod")
+	defer span.End()
+
+	err := session(ctx, r.db, "PaymentMethodRepository.CreatePaymentMethod").Create(&paymentMethod).Error
 	if err != nil {
 		return entities.PaymentMethod{}, err
 	}
@@ -52,7 +62,10 @@ func (r *paymentMethodRepository) CreatePaymentMethod(ctx context.Context, payme
 }
 
 func (r *paymentMethodRepository) UpdatePaymentMethod(ctx context.Context, paymentMethod entities.PaymentMethod) (entities.PaymentMethod, error) {
-	err := r.db.Save(&paymentMethod).Error
+	ctx, span := telemetry.Start(ctx, "PaymentMethodRepository.UpdatePaymentMethod")
+	defer span.End()
+
+	err := session(ctx, r.db, "PaymentMethodRepository.UpdatePaymentMethod").Save(&paymentMethod).Error
 	if err != nil {
 		return entities.PaymentMethod{}, err
 	}
@@ -60,7 +73,10 @@ func (r *paymentMethodRepository) UpdatePaymentMethod(ctx context.Context, payme
 }
 
 func (r *paymentMethodRepository) DeletePaymentMethod(ctx context.Context, paymentMethodId int) error {
-	err := r.db.Delete(&entities.PaymentMethod{}, "id = ?", paymentMethodId).Error
+	ctx, span := telemetry.Start(ctx, "PaymentMethodRepository.DeletePaymentMethod")
+	defer span.End()
+
+	err := session(ctx, r.db, "PaymentMethodRepository.DeletePaymentMethod").Delete(&entities.PaymentMethod{}, "id = ?", paymentMethodId).Error
 	if err != nil {
 		return err
 	}
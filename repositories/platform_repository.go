@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
 )
@@ -26,8 +27,11 @@ func NewPlatformRepository(db *gorm.DB) PlatformRepository {
 }
 
 func (r *platformRepository) GetAllPlatform(ctx context.Context) ([]entities.Platform, error) {
+	ctx, span := telemetry.Start(ctx, "PlatformRepository.GetAllPlatform")
+	defer span.End()
+
 	var platforms []entities.Platform
-	err := r.db.Find(&platforms).Error
+	err := session(ctx, r.db, "PlatformRepository.GetAllPlatform").Find(&platforms).Error
 	if err != nil {
 		return []entities.Platform{}, err
 	}
@@ -35,8 +39,11 @@ func (r *platformRepository) GetAllPlatform(ctx context.Context) ([]entities.Pla
 }
 
 func (r *platformRepository) GetPlatformById(ctx context.Context, platformId int) (entities.Platform, error) {
+	ctx, span := telemetry.Start(ctx, "PlatformRepository.GetPlatformById")
+	defer span.End()
+
 	var platform entities.Platform
-	err := r.db.Where("id = ?", platformId).First(&platform).Error
+	err := session(ctx, r.db, "PlatformRepository.GetPlatformById").Where("id = ?", platformId).First(&platform).Error
 	if err != nil {
 		return entities.Platform{}, err
 	}
@@ -44,7 +51,10 @@ func (r *platformRepository) GetPlatformById(ctx context.Context, platformId int
 }
 
 func (r *platformRepository) CreatePlatform(ctx context.Context, platform entities.Platform) (entities.Platform, error) {
-	err := r.db.Create(&platform).Error
+	ctx, span := telemetry.Start(ctx, "PlatformRepository.CreatePlatform")
+	defer span.End()
+
+	err := session(ctx, r.db, "PlatformRepository.CreatePlatform").Create(&platform).Error
 	if err != nil {
 		return entities.Platform{}, err
 	}
@@ -52,7 +62,10 @@ func (r *platformRepository) CreatePlatform(ctx context.Context, platform entiti
 }
 
 func (r *platformRepository) UpdatePlatform(ctx context.Context, platform entities.Platform) (entities.Platform, error) {
-	err := r.db.Save(&platform).Error
+	ctx, span := telemetry.Start(ctx, "PlatformRepository.UpdatePlatform")
+	defer span.End()
+
+	err := session(ctx, r.db, "PlatformRepository.UpdatePlatform").Save(&platform).Error
 	if err != nil {
 		return entities.Platform{}, err
 	}
@@ -60,7 +73,10 @@ func (r *platformRepository) UpdatePlatform(ctx context.Context, platform entiti
 }
 
 func (r *platformRepository) DeletePlatform(ctx context.Context, platformId int) error {
-	err := r.db.Delete(&entities.Platform{}, "id = ?", platformId).Error
+	ctx, span := telemetry.Start(ctx, "PlatformRepository.DeletePlatform")
+	defer span.End()
+
+	err := session(ctx, r.db, "PlatformRepository.DeletePlatform").Delete(&entities.Platform{}, "id = ?", platformId).Error
 	if err != nil {
 		return err
 	}
@@ -3,12 +3,13 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
 )
 
 type ReceiverRepository interface {
-	GetAllReceiver(ctx context.Context) ([]entities.Receiver, error)
+	GetAllReceiver(ctx context.Context, taxId string) ([]entities.Receiver, error)
 	GetReceiverById(ctx context.Context, receiverId int) (entities.Receiver, error)
 	CreateReceiver(ctx context.Context, receiver entities.Receiver) (entities.Receiver, error)
 	UpdateReceiver(ctx context.Context, receiver entities.Receiver) (entities.Receiver, error)
@@ -25,9 +26,18 @@ func NewReceiverRepository(db *gorm.DB) ReceiverRepository {
 	}
 }
 
-func (r *receiverRepository) GetAllReceiver(ctx context.Context) ([]entities.Receiver, error) {
+// GetAllReceiver lists receivers, keeping only those whose tax ID starts
+// with taxId when it is set.
+func (r *receiverRepository) GetAllReceiver(ctx context.Context, taxId string) ([]entities.Receiver, error) {
+	ctx, span := telemetry.Start(ctx, "ReceiverRepository.GetAllReceiver")
+	defer span.End()
+
 	var receivers []entities.Receiver
-	err := r.db.Find(&receivers).Error
+	query := session(ctx, r.db, "ReceiverRepository.GetAllReceiver")
+	if taxId != "" {
+		query = query.Where("tax_payer_id LIKE ?", taxId+"%")
+	}
+	err := query.Find(&receivers).Error
 	if err != nil {
 		return []entities.Receiver{}, err
 	}
@@ -35,8 +45,11 @@ func (r *receiverRepository) GetAllReceiver(ctx context.Context) ([]entities.Rec
 }
 
 func (r *receiverRepository) GetReceiverById(ctx context.Context, receiverId int) (entities.Receiver, error) {
+	ctx, span := telemetry.Start(ctx, "ReceiverRepository.GetReceiverById")
+	defer span.End()
+
 	var receiver entities.Receiver
-	err := r.db.Where("id = ?", receiverId).First(&receiver).Error
+	err := session(ctx, r.db, "ReceiverRepository.GetReceiverById").Where("id = ?", receiverId).First(&receiver).Error
 	if err != nil {
 		return entities.Receiver{}, err
 	}
@@ -44,7 +57,10 @@ func (r *receiverRepository) GetReceiverById(ctx context.Context, receiverId int
 }
 
 func (r *receiverRepository) CreateReceiver(ctx context.Context, receiver entities.Receiver) (entities.Receiver, error) {
-	err := r.db.Create(&receiver).Error
+	ctx, span := telemetry.Start(ctx, "ReceiverRepository.CreateReceiver")
+	defer span.End()
+
+	err := session(ctx, r.db, "ReceiverRepository.CreateReceiver").Create(&receiver).Error
 	if err != nil {
 		return entities.Receiver{}, err
 	}
@@ -52,7 +68,10 @@ func (r *receiverRepository) CreateReceiver(ctx context.Context, receiver entiti
 }
 
 func (r *receiverRepository) UpdateReceiver(ctx context.Context, receiver entities.Receiver) (entities.Receiver, error) {
-	err := r.db.Save(&receiver).Error
+	ctx, span := telemetry.Start(ctx, "ReceiverRepository.UpdateReceiver")
+	defer span.End()
+
+	err := session(ctx, r.db, "ReceiverRepository.UpdateReceiver").Save(&receiver).Error
 	if err != nil {
 		return entities.Receiver{}, err
 	}
@@ -60,7 +79,10 @@ func (r *receiverRepository) UpdateReceiver(ctx context.Context, receiver entiti
 }
 
 func (r *receiverRepository) DeleteReceiver(ctx context.Context, receiverId int) error {
-	err := r.db.Delete(&entities.Receiver{}, "id = ?", receiverId).Error
+	ctx, span := telemetry.Start(ctx, "ReceiverRepository.DeleteReceiver")
+	defer span.End()
+
+	err := session(ctx, r.db, "ReceiverRepository.DeleteReceiver").Delete(&entities.Receiver{}, "id = ?", receiverId).Error
 	if err != nil {
 		return err
 	}
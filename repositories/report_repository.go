@@ -0,0 +1,323 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"mtii-backend/money"
+	"mtii-backend/telemetry"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RevenueFilter selects the incomes whose DateColumn falls between From and
+// To, both inclusive and either open-ended when zero, and the dimension or
+// period to total them by.
+type RevenueFilter struct {
+	GroupBy    string
+	DateColumn string
+	From       time.Time
+	To         time.Time
+}
+
+// RevenueTotal is one group of a revenue report, in the base currency.
+// Unconverted counts the incomes of the group that have no rate on their
+// invoice date and are left out of the amounts.
+type RevenueTotal struct {
+	GroupKey    string
+	GroupName   string
+	Invoices    int
+	Invoiced    money.Amount
+	Received    money.Amount
+	Outstanding money.Amount
+	Unconverted int
+}
+
+// Receivable is an income with a balance due on a date. BaseBalance is
+// nil when no rate converts the income's currency on its invoice date.
+type Receivable struct {
+	InvoiceIdNumber  int
+	AgencyId         *int
+	AgencyName       string
+	SalePersonId     int
+	SalePersonName   string
+	InvoiceIssueDate time.Time
+	InvoiceDueDate   time.Time
+	Currency         string
+	Balance          money.Amount
+	BaseBalance      *money.Amount
+}
+
+// MonthlyReceivables is what was invoiced in a month and what was still
+// owed at its end, both in the base currency.
+type MonthlyReceivables struct {
+	Month       time.Time
+	Sales       money.Amount
+	Receivables money.Amount
+}
+
+// CashItem is an income as cash planning sees it, in the base currency:
+// its total and unpaid balance at the invoice date's rate and what was
+// received at the receipt date's. Converted is false when no rate converts
+// it, leaving the amounts zero. DueDate falls back to the invoice date.
+type CashItem struct {
+	InvoiceIdNumber  int
+	AgencyId         *int
+	AgencyName       string
+	DueDate          time.Time
+	ReceiptIssueDate time.Time
+	Total            money.Amount
+	Unpaid           money.Amount
+	Received         money.Amount
+	Converted        bool
+}
+
+// AgencyLateness is how many days after their due date an agency's paid
+// incomes were receipted on average, early payments counting as negative.
+type AgencyLateness struct {
+	AgencyId   *int
+	AgencyName string
+	Invoices   int
+	DaysLate   float64
+}
+
+type ReportRepository interface {
+	GetRevenueTotals(ctx context.Context, filter RevenueFilter) ([]RevenueTotal, error)
+	GetReceivables(ctx context.Context, asOf time.Time) ([]Receivable, error)
+	GetMonthlyReceivables(ctx context.Context, from, to time.Time) ([]MonthlyReceivables, error)
+	GetCashItems(ctx context.Context, from, to time.Time) ([]CashItem, error)
+	GetAgencyLateness(ctx context.Context) ([]AgencyLateness, error)
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{
+		db: db,
+	}
+}
+
+// revenueDateColumns are the income dates a revenue report can be based on.
+var revenueDateColumns = map[string]bool{
+	"invoice_issue_date":      true,
+	"receipt_issue_date":      true,
+	"influencer_posting_date": true,
+}
+
+// revenueGroup is how a revenue report groups incomes: the key and name of
+// each group, the join the name needs and the order of the groups. Periods
+// are named by their key and run in date order; other groups run from the
+// largest invoiced amount down.
+type revenueGroup struct {
+	key, name, join, order string
+}
+
+func revenueGroupFor(groupBy, dateColumn string) (revenueGroup, bool) {
+	period := func(layout string) revenueGroup {
+		expr := fmt.Sprintf("to_char(incomes.%s AT TIME ZONE 'UTC', '%s')", dateColumn, layout)
+		return revenueGroup{key: expr, name: expr, order: "group_key"}
+	}
+	dimension := func(column, table string) revenueGroup {
+		return revenueGroup{
+			key:   fmt.Sprintf("CAST(incomes.%s AS text)", column),
+			name:  "COALESCE(g.name, '')",
+			join:  fmt.Sprintf("LEFT JOIN %s g ON g.id = incomes.%s", table, column),
+			order: "invoiced DESC, group_key",
+		}
+	}
+
+	switch groupBy {
+	case "month":
+		return period("YYYY-MM"), true
+	case "quarter":
+		return period(`YYYY-"Q"Q`), true
+	case "year":
+		return period("YYYY"), true
+	case "platform":
+		return dimension("platform_id", "platforms"), true
+	case "channel":
+		return dimension("channel_id", "channels"), true
+	case "sale_person":
+		return dimension("sale_person_id", "sale_people"), true
+	case "status":
+		return dimension("status_id", "statuses"), true
+	case "payment_method":
+		return dimension("payment_method_id", "payment_methods"), true
+	case "receiver":
+		return dimension("receiver_id", "receivers"), true
+	case "bank":
+		return dimension("bank_id", "banks"), true
+	}
+	return revenueGroup{}, false
+}
+
+// GetRevenueTotals totals incomes per group in the database. Invoiced and
+// outstanding amounts convert at the invoice date's rate and received
+// amounts at the receipt date's, falling back to the invoice rate like the
+// income response does. Quotations, not being sales yet, and incomes
+// without the date in DateColumn are left out.
+func (r *reportRepository) GetRevenueTotals(ctx context.Context, filter RevenueFilter) ([]RevenueTotal, error) {
+	ctx, span := telemetry.Start(ctx, "ReportRepository.GetRevenueTotals")
+	defer span.End()
+
+	if !revenueDateColumns[filter.DateColumn] {
+		return []RevenueTotal{}, fmt.Errorf("unknown revenue date column %q", filter.DateColumn)
+	}
+	group, ok := revenueGroupFor(filter.GroupBy, filter.DateColumn)
+	if !ok {
+		return []RevenueTotal{}, fmt.Errorf("unknown revenue grouping %q", filter.GroupBy)
+	}
+
+	converted := "FILTER (WHERE invoice_rate.rate IS NOT NULL)"
+	query := session(ctx, r.db, "ReportRepository.GetRevenueTotals").
+		Table("incomes").
+		Joins(baseRateJoin("incomes", "invoice_issue_date", "invoice_rate")).
+		Joins(baseRateJoin("incomes", "receipt_issue_date", "payment_rate")).
+		Select(fmt.Sprintf(`%s AS group_key, %s AS group_name, COUNT(*) AS invoices,
+			COALESCE(SUM(ROUND(incomes.total_payment_amount * invoice_rate.rate, 2)) %[3]s, 0) AS invoiced,
+			COALESCE(SUM(ROUND((incomes.total_payment_amount - incomes.unpaid_payment_amount) * COALESCE(payment_rate.rate, invoice_rate.rate), 2)) %[3]s, 0) AS received,
+			COALESCE(SUM(ROUND(incomes.unpaid_payment_amount * invoice_rate.rate, 2)) %[3]s, 0) AS outstanding,
+			COUNT(*) FILTER (WHERE invoice_rate.rate IS NULL) AS unconverted`, group.key, group.name, converted)).
+		Where(fmt.Sprintf("incomes.%s > ? AND incomes.stage <> ?", filter.DateColumn), time.Time{}, IncomeStageQuotation)
+	if group.join != "" {
+		query = query.Joins(group.join)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where(fmt.Sprintf("incomes.%s >= ?", filter.DateColumn), filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where(fmt.Sprintf("incomes.%s < ?", filter.DateColumn), filter.To.AddDate(0, 0, 1))
+	}
+
+	var totals []RevenueTotal
+	err := query.Group("group_key, group_name").Order(group.order).Scan(&totals).Error
+	if err != nil {
+		return []RevenueTotal{}, err
+	}
+	return totals, err
+}
+
+// balanceBefore is the SQL for what an income still owed just before the
+// instant in the SQL expression given. Payments carry no dates of their
+// own, so the receipt date stands in for them: before its receipt an
+// income owed its whole total, and from then on its current unpaid amount.
+// Incomes without a receipt owe their current unpaid amount.
+func balanceBefore(instant string) string {
+	return fmt.Sprintf(`(CASE WHEN incomes.receipt_issue_date >= %s
+		THEN incomes.total_payment_amount ELSE incomes.unpaid_payment_amount END)`, instant)
+}
+
+// GetReceivables lists the incomes invoiced on or before asOf that still
+// owed money at the end of that day, oldest due date first.
+func (r *reportRepository) GetReceivables(ctx context.Context, asOf time.Time) ([]Receivable, error) {
+	ctx, span := telemetry.Start(ctx, "ReportRepository.GetReceivables")
+	defer span.End()
+
+	before := asOf.AddDate(0, 0, 1)
+	balance := balanceBefore("?")
+	var receivables []Receivable
+	err := session(ctx, r.db, "ReportRepository.GetReceivables").
+		Table("incomes").
+		Joins(baseRateJoin("incomes", "invoice_issue_date", "invoice_rate")).
+		Joins("LEFT JOIN agencies a ON a.id = incomes.agency_id").
+		Joins("LEFT JOIN sale_people sp ON sp.id = incomes.sale_person_id").
+		Select(`incomes.invoice_id_number, incomes.agency_id,
+			COALESCE(a.name, incomes.agency_agency_name) AS agency_name,
+			incomes.sale_person_id, COALESCE(sp.name, '') AS sale_person_name,
+			incomes.invoice_issue_date, incomes.invoice_due_date, incomes.currency,
+			`+balance+` AS balance,
+			ROUND(`+balance+` * invoice_rate.rate, 2) AS base_balance`, before, before).
+		Where("incomes.invoice_issue_date < ? AND "+balance+" > 0 AND incomes.stage <> ?", before, before, IncomeStageQuotation).
+		Order("incomes.invoice_due_date, incomes.invoice_id_number").
+		Scan(&receivables).Error
+	if err != nil {
+		return []Receivable{}, err
+	}
+	return receivables, err
+}
+
+// GetMonthlyReceivables returns, for every month from the one starting at
+// from to the one starting at to, the incomes invoiced in it and the balance owed at its
+// end, converted at each income's invoice date rate. Incomes with no rate
+// are left out of both.
+func (r *reportRepository) GetMonthlyReceivables(ctx context.Context, from, to time.Time) ([]MonthlyReceivables, error) {
+	ctx, span := telemetry.Start(ctx, "ReportRepository.GetMonthlyReceivables")
+	defer span.End()
+
+	monthEnd := "(m.month + interval '1 month')"
+	var months []MonthlyReceivables
+	err := session(ctx, r.db, "ReportRepository.GetMonthlyReceivables").
+		Table("generate_series(?::timestamptz, ?::timestamptz, interval '1 month') AS m(month)", from, to).
+		Joins("LEFT JOIN incomes ON incomes.invoice_issue_date < "+monthEnd+" AND incomes.stage <> ?", IncomeStageQuotation).
+		Joins(baseRateJoin("incomes", "invoice_issue_date", "invoice_rate")).
+		Select(`m.month,
+			COALESCE(SUM(ROUND(incomes.total_payment_amount * invoice_rate.rate, 2))
+				FILTER (WHERE incomes.invoice_issue_date >= m.month), 0) AS sales,
+			COALESCE(SUM(ROUND(` + balanceBefore(monthEnd) + ` * invoice_rate.rate, 2)), 0) AS receivables`).
+		Group("m.month").
+		Order("m.month").
+		Scan(&months).Error
+	if err != nil {
+		return []MonthlyReceivables{}, err
+	}
+	return months, err
+}
+
+// dueDate is the SQL for an income's due date, its invoice date when it
+// has none.
+const dueDate = `CASE WHEN incomes.invoice_due_date > ? THEN incomes.invoice_due_date ELSE incomes.invoice_issue_date END`
+
+// GetCashItems lists the invoices that still owe money, together with those
+// due or receipted between from and to, both inclusive.
+func (r *reportRepository) GetCashItems(ctx context.Context, from, to time.Time) ([]CashItem, error) {
+	ctx, span := telemetry.Start(ctx, "ReportRepository.GetCashItems")
+	defer span.End()
+
+	end := to.AddDate(0, 0, 1)
+	var items []CashItem
+	err := session(ctx, r.db, "ReportRepository.GetCashItems").
+		Table("incomes").
+		Joins(baseRateJoin("incomes", "invoice_issue_date", "invoice_rate")).
+		Joins(baseRateJoin("incomes", "receipt_issue_date", "payment_rate")).
+		Joins("LEFT JOIN agencies a ON a.id = incomes.agency_id").
+		Select(`incomes.invoice_id_number, incomes.agency_id,
+			COALESCE(a.name, incomes.agency_agency_name) AS agency_name,
+			`+dueDate+` AS due_date, incomes.receipt_issue_date,
+			COALESCE(ROUND(incomes.total_payment_amount * invoice_rate.rate, 2), 0) AS total,
+			COALESCE(ROUND(incomes.unpaid_payment_amount * invoice_rate.rate, 2), 0) AS unpaid,
+			COALESCE(ROUND((incomes.total_payment_amount - incomes.unpaid_payment_amount) * COALESCE(payment_rate.rate, invoice_rate.rate), 2), 0) AS received,
+			invoice_rate.rate IS NOT NULL AS converted`, time.Time{}).
+		Where("incomes.stage <> ? AND (incomes.unpaid_payment_amount > 0 OR (("+dueDate+") >= ? AND ("+dueDate+") < ?) OR (incomes.receipt_issue_date >= ? AND incomes.receipt_issue_date < ?))",
+			IncomeStageQuotation, time.Time{}, from, time.Time{}, end, from, end).
+		Order("due_date, incomes.invoice_id_number").
+		Scan(&items).Error
+	if err != nil {
+		return []CashItem{}, err
+	}
+	return items, err
+}
+
+// GetAgencyLateness measures each agency's payment habits from its fully
+// paid incomes. Incomes not linked to an agency are grouped by agency name.
+func (r *reportRepository) GetAgencyLateness(ctx context.Context) ([]AgencyLateness, error) {
+	ctx, span := telemetry.Start(ctx, "ReportRepository.GetAgencyLateness")
+	defer span.End()
+
+	var lateness []AgencyLateness
+	err := session(ctx, r.db, "ReportRepository.GetAgencyLateness").
+		Table("incomes").
+		Joins("LEFT JOIN agencies a ON a.id = incomes.agency_id").
+		Select(`incomes.agency_id, COALESCE(a.name, incomes.agency_agency_name) AS agency_name,
+			COUNT(*) AS invoices,
+			AVG(EXTRACT(EPOCH FROM incomes.receipt_issue_date - (`+dueDate+`)) / 86400) AS days_late`, time.Time{}).
+		Where("incomes.unpaid_payment_amount = 0 AND incomes.receipt_issue_date > ? AND incomes.stage <> ?", time.Time{}, IncomeStageQuotation).
+		Group("incomes.agency_id, COALESCE(a.name, incomes.agency_agency_name)").
+		Order("agency_name").
+		Scan(&lateness).Error
+	if err != nil {
+		return []AgencyLateness{}, err
+	}
+	return lateness, err
+}
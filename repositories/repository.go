@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// session binds db to the request context and tags it with the calling
+// repository method, so query metrics can be attributed per method.
+func session(ctx context.Context, db *gorm.DB, method string) *gorm.DB {
+	return db.WithContext(helpers.WithRepositoryMethod(ctx, method))
+}
+
+// baseRateJoin joins alias.rate, the rate converting table's amounts to
+// money.BaseCurrency on the UTC date in dateColumn. Rows in the base
+// currency get a rate of 1; rows with no rate on file get NULL, so SUMs
+// leave them out rather than counting foreign amounts as baht.
+func baseRateJoin(table, dateColumn, alias string) string {
+	return fmt.Sprintf(`LEFT JOIN LATERAL (
+		SELECT CASE WHEN %[1]s.currency IN ('', '%[3]s') THEN 1 ELSE (
+			SELECT cr.rate FROM currency_rates cr
+			WHERE cr.currency = %[1]s.currency AND cr.date <= (%[1]s.%[2]s AT TIME ZONE 'UTC')::date
+			ORDER BY cr.date DESC LIMIT 1
+		) END AS rate
+	) AS %[4]s ON true`, table, dateColumn, money.BaseCurrency, alias)
+}
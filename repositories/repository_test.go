@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// testDialector is SQLite with the Postgres timestamp types declared as
+// datetime, the declared type the SQLite driver reads back as time.Time.
+type testDialector struct {
+	sqlite.Dialector
+}
+
+func (d testDialector) DataTypeOf(field *schema.Field) string {
+	if strings.HasPrefix(string(field.DataType), "timestamp") {
+		return "datetime"
+	}
+	return d.Dialector.DataTypeOf(field)
+}
+
+func (d testDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqlite.Migrator{Migrator: migrator.Migrator{Config: migrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
+
+// newTestDB opens an empty in-memory database with the tables of models.
+// Only queries written in SQL that SQLite shares with Postgres can be
+// tested against it.
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(testDialector{sqlite.Dialector{DSN: "file::memory:"}}, &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a database of its own.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
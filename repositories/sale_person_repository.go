@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
 )
@@ -26,8 +27,11 @@ func NewSalePersonRepository(db *gorm.DB) SalePersonRepository {
 }
 
 func (r *salePersonRepository) GetAllSalePerson(ctx context.Context) ([]entities.SalePerson, error) {
+	ctx, span := telemetry.Start(ctx, "SalePersonRepository.GetAllSalePerson")
+	defer span.End()
+
 	var salePeople []entities.SalePerson
-	err := r.db.Find(&salePeople).Error
+	err := session(ctx, r.db, "SalePersonRepository.GetAllSalePerson").Find(&salePeople).Error
 	if err != nil {
 		return []entities.SalePerson{}, err
 	}
@@ -35,8 +39,11 @@ func (r *salePersonRepository) GetAllSalePerson(ctx context.Context) ([]entities
 }
 
 func (r *salePersonRepository) GetSalePersonById(ctx context.Context, salePersonId int) (entities.SalePerson, error) {
+	ctx, span := telemetry.Start(ctx, "SalePersonRepository.GetSalePersonById")
+	defer span.End()
+
 	var salePerson entities.SalePerson
-	err := r.db.Where("id = ?", salePersonId).First(&salePerson).Error
+	err := session(ctx, r.db, "SalePersonRepository.GetSalePersonById").Where("id = ?", salePersonId).First(&salePerson).Error
 	if err != nil {
 		return entities.SalePerson{}, err
 	}
@@ -44,7 +51,10 @@ func (r *salePersonRepository) GetSalePersonById(ctx context.Context, salePerson
 }
 
 func (r *salePersonRepository) CreateSalePerson(ctx context.Context, salePerson entities.SalePerson) (entities.SalePerson, error) {
-	err := r.db.Create(&salePerson).Error
+	ctx, span := telemetry.Start(ctx, "SalePersonRepository.CreateSalePerson")
+	defer span.End()
+
+	err := session(ctx, r.db, "SalePersonRepository.CreateSalePerson").Create(&salePerson).Error
 	if err != nil {
 		return entities.SalePerson{}, err
 	}
@@ -52,7 +62,10 @@ func (r *salePersonRepository) CreateSalePerson(ctx context.Context, salePerson
 }
 
 func (r *salePersonRepository) UpdateSalePerson(ctx context.Context, salePerson entities.SalePerson) (entities.SalePerson, error) {
-	err := r.db.Save(&salePerson).Error
+	ctx, span := telemetry.Start(ctx, "SalePersonRepository.UpdateSalePerson")
+	defer span.End()
+
+	err := session(ctx, r.db, "SalePersonRepository.UpdateSalePerson").Save(&salePerson).Error
 	if err != nil {
 		return entities.SalePerson{}, err
 	}
@@ -60,7 +73,10 @@ func (r *salePersonRepository) UpdateSalePerson(ctx context.Context, salePerson
 }
 
 func (r *salePersonRepository) DeleteSalePerson(ctx context.Context, salePersonId int) error {
-	err := r.db.Delete(&entities.SalePerson{}, "id = ?", salePersonId).Error
+	ctx, span := telemetry.Start(ctx, "SalePersonRepository.DeleteSalePerson")
+	defer span.End()
+
+	err := session(ctx, r.db, "SalePersonRepository.DeleteSalePerson").Delete(&entities.SalePerson{}, "id = ?", salePersonId).Error
 	if err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
 )
@@ -26,8 +27,11 @@ func NewStatusRepository(db *gorm.DB) StatusRepository {
 }
 
 func (r *statusRepository) GetAllStatus(ctx context.Context) ([]entities.Status, error) {
+	ctx, span := telemetry.Start(ctx, "StatusRepository.GetAllStatus")
+	defer span.End()
+
 	var statuses []entities.Status
-	err := r.db.Find(&statuses).Error
+	err := session(ctx, r.db, "StatusRepository.GetAllStatus").Find(&statuses).Error
 	if err != nil {
 		return []entities.Status{}, err
 	}
@@ -35,8 +39,11 @@ func (r *statusRepository) GetAllStatus(ctx context.Context) ([]entities.Status,
 }
 
 func (r *statusRepository) GetStatusById(ctx context.Context, statusId int) (entities.Status, error) {
+	ctx, span := telemetry.Start(ctx, "StatusRepository.GetStatusById")
+	defer span.End()
+
 	var status entities.Status
-	err := r.db.Where("id = ?", statusId).First(&status).Error
+	err := session(ctx, r.db, "StatusRepository.GetStatusById").Where("id = ?", statusId).First(&status).Error
 	if err != nil {
 		return entities.Status{}, err
 	}
@@ -44,7 +51,10 @@ func (r *statusRepository) GetStatusById(ctx context.Context, statusId int) (ent
 }
 
 func (r *statusRepository) CreateStatus(ctx context.Context, status entities.Status) (entities.Status, error) {
-	err := r.db.Create(&status).Error
+	ctx, span := telemetry.Start(ctx, "StatusRepository.CreateStatus")
+	defer span.End()
+
+	err := session(ctx, r.db, "StatusRepository.CreateStatus").Create(&status).Error
 	if err != nil {
 		return entities.Status{}, err
 	}
@@ -52,7 +62,10 @@ func (r *statusRepository) CreateStatus(ctx context.Context, status entities.Sta
 }
 
 func (r *statusRepository) UpdateStatus(ctx context.Context, status entities.Status) (entities.Status, error) {
-	err := r.db.Save(&status).Error
+	ctx, span := telemetry.Start(ctx, "StatusRepository.UpdateStatus")
+	defer span.End()
+
+	err := session(ctx, r.db, "StatusRepository.UpdateStatus").Save(&status).Error
 	if err != nil {
 		return entities.Status{}, err
 	}
@@ -60,7 +73,10 @@ func (r *statusRepository) UpdateStatus(ctx context.Context, status entities.Sta
 }
 
 func (r *statusRepository) DeleteStatus(ctx context.Context, statusId int) error {
-	err := r.db.Delete(&entities.Status{}, "id = ?", statusId).Error
+	ctx, span := telemetry.Start(ctx, "StatusRepository.DeleteStatus")
+	defer span.End()
+
+	err := session(ctx, r.db, "StatusRepository.DeleteStatus").Delete(&entities.Status{}, "id = ?", statusId).Error
 	if err != nil {
 		return err
 	}
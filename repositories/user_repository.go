@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"mtii-backend/entities"
+	"mtii-backend/telemetry"
 
 	"gorm.io/gorm"
 )
@@ -23,8 +24,11 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 }
 
 func (r *userRepository) GetUserById(ctx context.Context, userId int) (entities.User, error) {
+	ctx, span := telemetry.Start(ctx, "UserRepository.GetUserById")
+	defer span.End()
+
 	var user entities.User
-	err := r.db.Where("id = ?", userId).Take(&user).Error
+	err := session(ctx, r.db, "UserRepository.GetUserById").Where("id = ?", userId).Take(&user).Error
 	if err != nil {
 		return entities.User{}, err
 	}
@@ -32,8 +36,11 @@ func (r *userRepository) GetUserById(ctx context.Context, userId int) (entities.
 }
 
 func (r *userRepository) GetUserByUsername(ctx context.Context, username string) (entities.User, error) {
+	ctx, span := telemetry.Start(ctx, "UserRepository.GetUserByUsername")
+	defer span.End()
+
 	var user entities.User
-	err := r.db.Where("username = ?", username).Take(&user).Error
+	err := session(ctx, r.db, "UserRepository.GetUserByUsername").Where("username = ?", username).Take(&user).Error
 	if err != nil {
 		return entities.User{}, err
 	}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/telemetry"
+
+	"gorm.io/gorm"
+)
+
+type VendorRepository interface {
+	GetAllVendor(ctx context.Context, taxId string) ([]entities.Vendor, error)
+	GetVendorById(ctx context.Context, vendorId int) (entities.Vendor, error)
+	CreateVendor(ctx context.Context, vendor entities.Vendor) (entities.Vendor, error)
+	UpdateVendor(ctx context.Context, vendor entities.Vendor) (entities.Vendor, error)
+	DeleteVendor(ctx context.Context, vendorId int) error
+}
+
+type vendorRepository struct {
+	db *gorm.DB
+}
+
+func NewVendorRepository(db *gorm.DB) VendorRepository {
+	return &vendorRepository{
+		db: db,
+	}
+}
+
+// GetAllVendor lists vendors, keeping only those whose tax ID starts
+// with taxId when it is set.
+func (r *vendorRepository) GetAllVendor(ctx context.Context, taxId string) ([]entities.Vendor, error) {
+	ctx, span := telemetry.Start(ctx, "VendorRepository.GetAllVendor")
+	defer span.End()
+
+	var vendors []entities.Vendor
+	query := session(ctx, r.db, "VendorRepository.GetAllVendor")
+	if taxId != "" {
+		query = query.Where("tax_payer_id LIKE ?", taxId+"%")
+	}
+	err := query.Find(&vendors).Error
+	if err != nil {
+		return []entities.Vendor{}, err
+	}
+	return vendors, err
+}
+
+func (r *vendorRepository) GetVendorById(ctx context.Context, vendorId int) (entities.Vendor, error) {
+	ctx, span := telemetry.Start(ctx, "VendorRepository.GetVendorById")
+	defer span.End()
+
+	var vendor entities.Vendor
+	err := session(ctx, r.db, "VendorRepository.GetVendorById").Where("id = ?", vendorId).First(&vendor).Error
+	if err != nil {
+		return entities.Vendor{}, err
+	}
+	return vendor, err
+}
+
+func (r *vendorRepository) CreateVendor(ctx context.Context, vendor entities.Vendor) (entities.Vendor, error) {
+	ctx, span := telemetry.Start(ctx, "VendorRepository.CreateVendor")
+	defer span.End()
+
+	err := session(ctx, r.db, "VendorRepository.CreateVendor").Create(&vendor).Error
+	if err != nil {
+		return entities.Vendor{}, err
+	}
+	return vendor, err
+}
+
+func (r *vendorRepository) UpdateVendor(ctx context.Context, vendor entities.Vendor) (entities.Vendor, error) {
+	ctx, span := telemetry.Start(ctx, "VendorRepository.UpdateVendor")
+	defer span.End()
+
+	err := session(ctx, r.db, "VendorRepository.UpdateVendor").Save(&vendor).Error
+	if err != nil {
+		return entities.Vendor{}, err
+	}
+	return vendor, err
+}
+
+func (r *vendorRepository) DeleteVendor(ctx context.Context, vendorId int) error {
+	ctx, span := telemetry.Start(ctx, "VendorRepository.DeleteVendor")
+	defer span.End()
+
+	err := session(ctx, r.db, "VendorRepository.DeleteVendor").Delete(&entities.Vendor{}, "id = ?", vendorId).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
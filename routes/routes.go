@@ -2,6 +2,7 @@ package routes
 
 import (
 	"mtii-backend/controllers"
+	"mtii-backend/docs"
 	"mtii-backend/middlewares"
 	"mtii-backend/services"
 
@@ -17,9 +18,21 @@ func Router(
 	SalePersonController controllers.SalePersonController,
 	ChannelController controllers.ChannelController,
 	BankController controllers.BankController,
+	BankStatementController controllers.BankStatementController,
 	ReceiverController controllers.ReceiverController,
 	IncomeController controllers.IncomeController,
 	DetailController controllers.DetailController,
+	CurrencyRateController controllers.CurrencyRateController,
+	AgencyController controllers.AgencyController,
+	ContactController controllers.ContactController,
+	BrandController controllers.BrandController,
+	InfluencerController controllers.InfluencerController,
+	InfluencerAssignmentController controllers.InfluencerAssignmentController,
+	VendorController controllers.VendorController,
+	ExpenseController controllers.ExpenseController,
+	ReportController controllers.ReportController,
+	CommissionPlanController controllers.CommissionPlanController,
+	CommissionController controllers.CommissionController,
 	tokenService services.TokenService,
 ) {
 
@@ -32,6 +45,13 @@ func Router(
 	// 	AllowCredentials: true,
 	// }))
 
+	docsRoutes := route.Group("/api")
+	{
+		docsRoutes.GET("/openapi.json", docs.SpecHandler(route))
+		docsRoutes.GET("/docs", docs.UIHandler)
+		docsRoutes.GET("/docs/swagger-ui/*file", docs.AssetHandler)
+	}
+
 	userRoutes := route.Group("/api/user")
 	{
 		userRoutes.POST("/login", UserController.LoginUser)
@@ -72,6 +92,20 @@ func Router(
 		salePersonRoutes.POST("/", middlewares.Authenticate(tokenService), SalePersonController.CreateSalePerson)
 		salePersonRoutes.PATCH("/:sale_person_id", middlewares.Authenticate(tokenService), SalePersonController.UpdateSalePerson)
 		salePersonRoutes.DELETE("/:sale_person_id", middlewares.Authenticate(tokenService), SalePersonController.DeleteSalePerson)
+		salePersonRoutes.GET("/:sale_person_id/commission_plans", middlewares.Authenticate(tokenService), CommissionController.GetSalePersonCommissionPlans)
+		salePersonRoutes.POST("/:sale_person_id/commission_plans", middlewares.Authenticate(tokenService), CommissionController.AssignCommissionPlan)
+		salePersonRoutes.DELETE("/:sale_person_id/commission_plans/:assignment_id", middlewares.Authenticate(tokenService), CommissionController.UnassignCommissionPlan)
+		salePersonRoutes.GET("/:sale_person_id/commissions", middlewares.Authenticate(tokenService), CommissionController.GetCommissionStatement)
+		salePersonRoutes.POST("/:sale_person_id/commissions/recalculate", middlewares.Authenticate(tokenService), CommissionController.RecalculateCommissions)
+	}
+
+	commissionPlanRoutes := route.Group("/api/commission_plan")
+	{
+		commissionPlanRoutes.GET("/", middlewares.Authenticate(tokenService), CommissionPlanController.GetAllCommissionPlan)
+		commissionPlanRoutes.GET("/:commission_plan_id", middlewares.Authenticate(tokenService), CommissionPlanController.GetCommissionPlanById)
+		commissionPlanRoutes.POST("/", middlewares.Authenticate(tokenService), CommissionPlanController.CreateCommissionPlan)
+		commissionPlanRoutes.PATCH("/:commission_plan_id", middlewares.Authenticate(tokenService), CommissionPlanController.UpdateCommissionPlan)
+		commissionPlanRoutes.DELETE("/:commission_plan_id", middlewares.Authenticate(tokenService), CommissionPlanController.DeleteCommissionPlan)
 	}
 
 	channelRoutes := route.Group("/api/channel")
@@ -90,6 +124,17 @@ func Router(
 		bankRoutes.POST("/", middlewares.Authenticate(tokenService), BankController.CreateBank)
 		bankRoutes.PATCH("/:bank_id", middlewares.Authenticate(tokenService), BankController.UpdateBank)
 		bankRoutes.DELETE("/:bank_id", middlewares.Authenticate(tokenService), BankController.DeleteBank)
+		bankRoutes.GET("/:bank_id/statement_format", middlewares.Authenticate(tokenService), BankStatementController.GetStatementFormat)
+		bankRoutes.PUT("/:bank_id/statement_format", middlewares.Authenticate(tokenService), BankStatementController.SaveStatementFormat)
+		bankRoutes.POST("/:bank_id/statements", middlewares.Authenticate(tokenService), BankStatementController.UploadStatement)
+	}
+
+	bankTransactionRoutes := route.Group("/api/bank_transaction")
+	{
+		bankTransactionRoutes.GET("/", middlewares.Authenticate(tokenService), BankStatementController.GetAllBankTransaction)
+		bankTransactionRoutes.POST("/rematch", middlewares.Authenticate(tokenService), BankStatementController.RematchBankTransactions)
+		bankTransactionRoutes.POST("/match/:match_id/confirm", middlewares.Authenticate(tokenService), BankStatementController.ConfirmMatch)
+		bankTransactionRoutes.POST("/match/:match_id/reject", middlewares.Authenticate(tokenService), BankStatementController.RejectMatch)
 	}
 
 	receiverRoutes := route.Group("/api/receiver")
@@ -101,13 +146,116 @@ func Router(
 		receiverRoutes.DELETE("/:receiver_id", middlewares.Authenticate(tokenService), ReceiverController.DeleteReceiver)
 	}
 
+	currencyRateRoutes := route.Group("/api/currency_rate")
+	{
+		currencyRateRoutes.GET("/", middlewares.Authenticate(tokenService), CurrencyRateController.GetAllCurrencyRate)
+		currencyRateRoutes.GET("/:currency_rate_id", middlewares.Authenticate(tokenService), CurrencyRateController.GetCurrencyRateById)
+		currencyRateRoutes.POST("/", middlewares.Authenticate(tokenService), CurrencyRateController.CreateCurrencyRate)
+		currencyRateRoutes.PATCH("/:currency_rate_id", middlewares.Authenticate(tokenService), CurrencyRateController.UpdateCurrencyRate)
+		currencyRateRoutes.DELETE("/:currency_rate_id", middlewares.Authenticate(tokenService), CurrencyRateController.DeleteCurrencyRate)
+	}
+
+	agencyRoutes := route.Group("/api/agency")
+	{
+		agencyRoutes.GET("/", middlewares.Authenticate(tokenService), AgencyController.GetAllAgency)
+		agencyRoutes.GET("/:agency_id", middlewares.Authenticate(tokenService), AgencyController.GetAgencyById)
+		agencyRoutes.POST("/", middlewares.Authenticate(tokenService), AgencyController.CreateAgency)
+		agencyRoutes.PATCH("/:agency_id", middlewares.Authenticate(tokenService), AgencyController.UpdateAgency)
+		agencyRoutes.DELETE("/:agency_id", middlewares.Authenticate(tokenService), AgencyController.DeleteAgency)
+	}
+
+	contactRoutes := route.Group("/api/contact")
+	{
+		contactRoutes.GET("/", middlewares.Authenticate(tokenService), ContactController.GetAllContact)
+		contactRoutes.GET("/:contact_id", middlewares.Authenticate(tokenService), ContactController.GetContactById)
+		contactRoutes.POST("/", middlewares.Authenticate(tokenService), ContactController.CreateContact)
+		contactRoutes.PATCH("/:contact_id", middlewares.Authenticate(tokenService), ContactController.UpdateContact)
+		contactRoutes.DELETE("/:contact_id", middlewares.Authenticate(tokenService), ContactController.DeleteContact)
+	}
+
+	brandRoutes := route.Group("/api/brand")
+	{
+		brandRoutes.GET("/", middlewares.Authenticate(tokenService), BrandController.GetAllBrand)
+		brandRoutes.GET("/:brand_id", middlewares.Authenticate(tokenService), BrandController.GetBrandById)
+		brandRoutes.POST("/", middlewares.Authenticate(tokenService), BrandController.CreateBrand)
+		brandRoutes.PATCH("/:brand_id", middlewares.Authenticate(tokenService), BrandController.UpdateBrand)
+		brandRoutes.DELETE("/:brand_id", middlewares.Authenticate(tokenService), BrandController.DeleteBrand)
+	}
+
+	influencerRoutes := route.Group("/api/influencer")
+	{
+		influencerRoutes.GET("/", middlewares.Authenticate(tokenService), InfluencerController.GetAllInfluencer)
+		influencerRoutes.GET("/:influencer_id", middlewares.Authenticate(tokenService), InfluencerController.GetInfluencerById)
+		influencerRoutes.POST("/", middlewares.Authenticate(tokenService), InfluencerController.CreateInfluencer)
+		influencerRoutes.PATCH("/:influencer_id", middlewares.Authenticate(tokenService), InfluencerController.UpdateInfluencer)
+		influencerRoutes.DELETE("/:influencer_id", middlewares.Authenticate(tokenService), InfluencerController.DeleteInfluencer)
+		influencerRoutes.GET("/:influencer_id/jobs", middlewares.Authenticate(tokenService), InfluencerAssignmentController.GetInfluencerJobs)
+	}
+
+	influencerAssignmentRoutes := route.Group("/api/influencer_assignment")
+	{
+		influencerAssignmentRoutes.GET("/", middlewares.Authenticate(tokenService), InfluencerAssignmentController.GetAllInfluencerAssignment)
+		influencerAssignmentRoutes.GET("/:influencer_assignment_id", middlewares.Authenticate(tokenService), InfluencerAssignmentController.GetInfluencerAssignmentById)
+		influencerAssignmentRoutes.POST("/", middlewares.Authenticate(tokenService), InfluencerAssignmentController.CreateInfluencerAssignment)
+		influencerAssignmentRoutes.PATCH("/:influencer_assignment_id", middlewares.Authenticate(tokenService), InfluencerAssignmentController.UpdateInfluencerAssignment)
+		influencerAssignmentRoutes.DELETE("/:influencer_assignment_id", middlewares.Authenticate(tokenService), InfluencerAssignmentController.DeleteInfluencerAssignment)
+	}
+
+	vendorRoutes := route.Group("/api/vendor")
+	{
+		vendorRoutes.GET("/", middlewares.Authenticate(tokenService), VendorController.GetAllVendor)
+		vendorRoutes.GET("/:vendor_id", middlewares.Authenticate(tokenService), VendorController.GetVendorById)
+		vendorRoutes.POST("/", middlewares.Authenticate(tokenService), VendorController.CreateVendor)
+		vendorRoutes.PATCH("/:vendor_id", middlewares.Authenticate(tokenService), VendorController.UpdateVendor)
+		vendorRoutes.DELETE("/:vendor_id", middlewares.Authenticate(tokenService), VendorController.DeleteVendor)
+	}
+
+	expenseRoutes := route.Group("/api/expense")
+	{
+		expenseRoutes.GET("/", middlewares.Authenticate(tokenService), ExpenseController.GetAllExpense)
+		expenseRoutes.GET("/:expense_id", middlewares.Authenticate(tokenService), ExpenseController.GetExpenseById)
+		expenseRoutes.POST("/", middlewares.Authenticate(tokenService), ExpenseController.CreateExpense)
+		expenseRoutes.PATCH("/:expense_id", middlewares.Authenticate(tokenService), ExpenseController.UpdateExpense)
+		expenseRoutes.DELETE("/:expense_id", middlewares.Authenticate(tokenService), ExpenseController.DeleteExpense)
+		expenseRoutes.POST("/:expense_id/attachments", middlewares.Authenticate(tokenService), ExpenseController.CreateExpenseAttachment)
+		expenseRoutes.GET("/:expense_id/attachments/:attachment_id", middlewares.Authenticate(tokenService), ExpenseController.GetExpenseAttachment)
+		expenseRoutes.DELETE("/:expense_id/attachments/:attachment_id", middlewares.Authenticate(tokenService), ExpenseController.DeleteExpenseAttachment)
+	}
+
+	reportRoutes := route.Group("/api/reports")
+	{
+		reportRoutes.GET("/profitability", middlewares.Authenticate(tokenService), ReportController.GetProfitabilityReport)
+		reportRoutes.GET("/revenue", middlewares.Authenticate(tokenService), ReportController.GetRevenueReport)
+		reportRoutes.GET("/ar_aging", middlewares.Authenticate(tokenService), ReportController.GetARAgingReport)
+		reportRoutes.GET("/ar_aging/invoices", middlewares.Authenticate(tokenService), ReportController.GetARAgingInvoices)
+		reportRoutes.GET("/dso", middlewares.Authenticate(tokenService), ReportController.GetDSOReport)
+		reportRoutes.GET("/cash_flow", middlewares.Authenticate(tokenService), ReportController.GetCashFlowReport)
+		reportRoutes.GET("/sales_vat", middlewares.Authenticate(tokenService), ReportController.GetSalesVatReport)
+		reportRoutes.GET("/withholding_tax", middlewares.Authenticate(tokenService), ReportController.GetWithholdingTaxRegister)
+	}
+
 	incomeRoutes := route.Group("/api/income")
 	{
 		incomeRoutes.GET("/", middlewares.Authenticate(tokenService), IncomeController.GetAllIncome)
+		incomeRoutes.GET("/export", middlewares.Authenticate(tokenService), IncomeController.ExportIncomes)
+		incomeRoutes.POST("/import", middlewares.Authenticate(tokenService), IncomeController.ImportIncomes)
+		incomeRoutes.GET("/import", middlewares.Authenticate(tokenService), IncomeController.GetAllImportBatch)
+		incomeRoutes.DELETE("/import/:import_batch_id", middlewares.Authenticate(tokenService), IncomeController.RollbackImportBatch)
 		incomeRoutes.GET("/:income_invoice_id_number", middlewares.Authenticate(tokenService), IncomeController.GetIncomeByInvoiceIdNumber)
 		incomeRoutes.POST("/", middlewares.Authenticate(tokenService), IncomeController.CreateIncome)
 		incomeRoutes.PATCH("/:income_invoice_id_number", middlewares.Authenticate(tokenService), IncomeController.UpdateIncome)
 		incomeRoutes.DELETE("/:income_invoice_id_number", middlewares.Authenticate(tokenService), IncomeController.DeleteIncome)
+		incomeRoutes.POST("/:income_invoice_id_number/convert-to-invoice", middlewares.Authenticate(tokenService), IncomeController.ConvertToInvoice)
+		incomeRoutes.POST("/:income_invoice_id_number/issue-receipt", middlewares.Authenticate(tokenService), IncomeController.IssueReceipt)
+		incomeRoutes.GET("/:income_invoice_id_number/promptpay", middlewares.Authenticate(tokenService), IncomeController.GetPromptPay)
+		incomeRoutes.GET("/:income_invoice_id_number/promptpay.png", middlewares.Authenticate(tokenService), IncomeController.GetPromptPayQR)
+		incomeRoutes.GET("/:income_invoice_id_number/etax.xml", middlewares.Authenticate(tokenService), IncomeController.GetETaxInvoice)
+		incomeRoutes.GET("/:income_invoice_id_number/document.pdf", middlewares.Authenticate(tokenService), IncomeController.GetInvoicePDF)
+		incomeRoutes.GET("/:income_invoice_id_number/details", middlewares.Authenticate(tokenService), DetailController.GetIncomeDetails)
+		incomeRoutes.POST("/:income_invoice_id_number/details", middlewares.Authenticate(tokenService), DetailController.CreateIncomeDetail)
+		incomeRoutes.PUT("/:income_invoice_id_number/details/order", middlewares.Authenticate(tokenService), DetailController.ReorderIncomeDetails)
+		incomeRoutes.PATCH("/:income_invoice_id_number/details/:detail_id", middlewares.Authenticate(tokenService), DetailController.UpdateIncomeDetail)
+		incomeRoutes.DELETE("/:income_invoice_id_number/details/:detail_id", middlewares.Authenticate(tokenService), DetailController.DeleteIncomeDetail)
 	}
 
 	detailRoutes := route.Group("/api/detail")
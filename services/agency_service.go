@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/repositories"
+	"mtii-backend/taxid"
+	"mtii-backend/telemetry"
+)
+
+type AgencyService interface {
+	GetAllAgency(ctx context.Context, query dtos.AgencyQuery) ([]dtos.Agency, error)
+	GetAgencyById(ctx context.Context, agencyId int) (dtos.Agency, error)
+	CreateAgency(ctx context.Context, req dtos.CreateAgencyRequest) (dtos.AgencyResponse, error)
+	UpdateAgency(ctx context.Context, agencyId int, req dtos.UpdateAgencyRequest) (dtos.AgencyResponse, error)
+	DeleteAgency(ctx context.Context, agencyId int) error
+}
+
+type agencyService struct {
+	agencyRepository repositories.AgencyRepository
+}
+
+func NewAgencyService(
+	agencyRepository repositories.AgencyRepository,
+) AgencyService {
+	return &agencyService{
+		agencyRepository: agencyRepository,
+	}
+}
+
+func (s *agencyService) GetAllAgency(ctx context.Context, query dtos.AgencyQuery) ([]dtos.Agency, error) {
+	ctx, span := telemetry.Start(ctx, "AgencyService.GetAllAgency")
+	defer span.End()
+
+	agencies, err := s.agencyRepository.GetAllAgency(ctx, query.TaxId)
+	if err != nil {
+		return []dtos.Agency{}, wrapError(err, "failed to get agency")
+	}
+
+	var agencyDTOs []dtos.Agency
+	for _, a := range agencies {
+		agencyDTOs = append(agencyDTOs, toAgencyDTO(a))
+	}
+
+	if len(agencyDTOs) == 0 {
+		return []dtos.Agency{}, nil
+	}
+
+	return agencyDTOs, nil
+}
+
+func (s *agencyService) GetAgencyById(ctx context.Context, agencyId int) (dtos.Agency, error) {
+	ctx, span := telemetry.Start(ctx, "AgencyService.GetAgencyById")
+	defer span.End()
+
+	agency, err := s.agencyRepository.GetAgencyById(ctx, agencyId)
+	if err != nil {
+		return dtos.Agency{}, wrapError(err, "failed to get agency")
+	}
+
+	return toAgencyDTO(agency), nil
+}
+
+func (s *agencyService) CreateAgency(ctx context.Context, req dtos.CreateAgencyRequest) (dtos.AgencyResponse, error) {
+	ctx, span := telemetry.Start(ctx, "AgencyService.CreateAgency")
+	defer span.End()
+
+	data := entities.Agency{
+		Name:         req.Name,
+		Address:      req.Address,
+		PhoneNumber:  req.PhoneNumber,
+		TaxPayerId:   req.TaxPayerId,
+		BranchNumber: helpers.DefaultIfEmpty(req.BranchNumber, taxid.HeadOffice),
+	}
+
+	agency, err := s.agencyRepository.CreateAgency(ctx, data)
+	if err != nil {
+		return dtos.AgencyResponse{}, wrapError(err, "failed to save agency")
+	}
+
+	return dtos.AgencyResponse{
+		Id: agency.Id,
+	}, nil
+}
+
+func (s *agencyService) UpdateAgency(ctx context.Context, agencyId int, req dtos.UpdateAgencyRequest) (dtos.AgencyResponse, error) {
+	ctx, span := telemetry.Start(ctx, "AgencyService.UpdateAgency")
+	defer span.End()
+
+	agency, err := s.agencyRepository.GetAgencyById(ctx, agencyId)
+	if err != nil {
+		return dtos.AgencyResponse{}, wrapError(err, "failed to get agency")
+	}
+
+	data := entities.Agency{
+		Id:           agencyId,
+		Name:         helpers.DefaultIfEmpty(req.Name, agency.Name),
+		Address:      helpers.DefaultIfEmpty(req.Address, agency.Address),
+		PhoneNumber:  helpers.DefaultIfEmpty(req.PhoneNumber, agency.PhoneNumber),
+		TaxPayerId:   helpers.DefaultIfEmpty(req.TaxPayerId, agency.TaxPayerId),
+		BranchNumber: helpers.DefaultIfEmpty(req.BranchNumber, agency.BranchNumber),
+	}
+
+	updatedAgency, err := s.agencyRepository.UpdateAgency(ctx, data)
+	if err != nil {
+		return dtos.AgencyResponse{}, wrapError(err, "failed to save agency")
+	}
+
+	return dtos.AgencyResponse{
+		Id: updatedAgency.Id,
+	}, nil
+}
+
+// DeleteAgency removes an agency. The database refuses while contacts,
+// brands or incomes still reference it.
+func (s *agencyService) DeleteAgency(ctx context.Context, agencyId int) error {
+	ctx, span := telemetry.Start(ctx, "AgencyService.DeleteAgency")
+	defer span.End()
+
+	agency, err := s.agencyRepository.GetAgencyById(ctx, agencyId)
+	if err != nil {
+		return wrapError(err, "failed to get agency")
+	}
+
+	err = s.agencyRepository.DeleteAgency(ctx, agency.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete agency")
+	}
+
+	return nil
+}
+
+func toAgencyDTO(a entities.Agency) dtos.Agency {
+	contacts := []dtos.Contact{}
+	for _, c := range a.Contacts {
+		contacts = append(contacts, toContactDTO(c))
+	}
+	brands := []dtos.Brand{}
+	for _, b := range a.Brands {
+		brands = append(brands, toBrandDTO(b))
+	}
+
+	return dtos.Agency{
+		Id:           a.Id,
+		Name:         a.Name,
+		Address:      a.Address,
+		PhoneNumber:  a.PhoneNumber,
+		TaxPayerId:   a.TaxPayerId,
+		BranchNumber: a.BranchNumber,
+		Contacts:     contacts,
+		Brands:       brands,
+	}
+}
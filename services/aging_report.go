@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"mtii-backend/dtos"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"sort"
+	"time"
+)
+
+const GroupByAgency = "agency"
+
+// UnassignedAgency names the aging row of invoices that are neither linked
+// to an agency nor carry an agency name.
+const UnassignedAgency = "Unassigned"
+
+const (
+	BucketCurrent = "current"
+	Bucket1To30   = "1_30"
+	Bucket31To60  = "31_60"
+	Bucket61To90  = "61_90"
+	BucketOver90  = "over_90"
+)
+
+// GetARAgingReport buckets the balances owed at the end of the as-of day by
+// days past due, per agency or per sale person. An invoice without a due
+// date is due on its invoice date. Invoices not linked to an agency are
+// grouped by the agency name they carry, or as Unassigned.
+func (s *reportService) GetARAgingReport(ctx context.Context, query dtos.ARAgingQuery) (dtos.ARAgingReport, error) {
+	ctx, span := telemetry.Start(ctx, "ReportService.GetARAgingReport")
+	defer span.End()
+
+	asOf := asOfDate(query.AsOf)
+	groupBy := query.GroupBy
+	if groupBy == "" {
+		groupBy = GroupByAgency
+	}
+
+	receivables, err := s.reportRepository.GetReceivables(ctx, asOf)
+	if err != nil {
+		return dtos.ARAgingReport{}, wrapError(err, "failed to get receivables")
+	}
+
+	report := dtos.ARAgingReport{
+		AsOf:                asOf,
+		GroupBy:             groupBy,
+		Currency:            money.BaseCurrency,
+		Rows:                []dtos.ARAgingRow{},
+		Total:               dtos.ARAgingRow{Name: "Total"},
+		UnconvertedInvoices: []int{},
+	}
+	rows := map[string]*dtos.ARAgingRow{}
+	var keys []string
+	for _, r := range receivables {
+		if r.BaseBalance == nil {
+			report.UnconvertedInvoices = append(report.UnconvertedInvoices, r.InvoiceIdNumber)
+			continue
+		}
+
+		id, name := r.SalePersonId, r.SalePersonName
+		key := fmt.Sprint(id)
+		if groupBy == GroupByAgency {
+			id, name, key = 0, agingAgencyName(r), agencyKey(r.AgencyId, agingAgencyName(r))
+			if r.AgencyId != nil {
+				id = *r.AgencyId
+			}
+		}
+		row, ok := rows[key]
+		if !ok {
+			row = &dtos.ARAgingRow{Id: id, Name: name}
+			rows[key] = row
+			keys = append(keys, key)
+		}
+
+		_, bucket := agingBucket(asOf, r)
+		for _, target := range []*dtos.ARAgingRow{row, &report.Total} {
+			addToBucket(target, bucket, *r.BaseBalance)
+		}
+	}
+
+	for _, key := range keys {
+		report.Rows = append(report.Rows, *rows[key])
+	}
+	sort.SliceStable(report.Rows, func(a, b int) bool {
+		return report.Rows[a].Total > report.Rows[b].Total
+	})
+
+	return report, nil
+}
+
+// GetARAgingInvoices lists the invoices behind an aging report, most
+// overdue first.
+func (s *reportService) GetARAgingInvoices(ctx context.Context, query dtos.ARAgingInvoiceQuery) ([]dtos.ARAgingInvoice, error) {
+	ctx, span := telemetry.Start(ctx, "ReportService.GetARAgingInvoices")
+	defer span.End()
+
+	asOf := asOfDate(query.AsOf)
+	receivables, err := s.reportRepository.GetReceivables(ctx, asOf)
+	if err != nil {
+		return []dtos.ARAgingInvoice{}, wrapError(err, "failed to get receivables")
+	}
+
+	invoices := []dtos.ARAgingInvoice{}
+	for _, r := range receivables {
+		if query.AgencyId != 0 && (r.AgencyId == nil || *r.AgencyId != query.AgencyId) {
+			continue
+		}
+		if query.AgencyName != "" && (r.AgencyId != nil || agingAgencyName(r) != query.AgencyName) {
+			continue
+		}
+		if query.SalePersonId != 0 && r.SalePersonId != query.SalePersonId {
+			continue
+		}
+		days, bucket := agingBucket(asOf, r)
+		if query.Bucket != "" && bucket != query.Bucket {
+			continue
+		}
+		invoices = append(invoices, dtos.ARAgingInvoice{
+			InvoiceIdNumber:  r.InvoiceIdNumber,
+			AgencyId:         r.AgencyId,
+			AgencyName:       r.AgencyName,
+			SalePersonId:     r.SalePersonId,
+			SalePersonName:   r.SalePersonName,
+			InvoiceIssueDate: r.InvoiceIssueDate,
+			InvoiceDueDate:   r.InvoiceDueDate,
+			DaysPastDue:      days,
+			Bucket:           bucket,
+			Currency:         r.Currency,
+			Balance:          r.Balance,
+			BaseBalance:      r.BaseBalance,
+		})
+	}
+	return invoices, nil
+}
+
+// GetDSOReport computes days sales outstanding month by month.
+func (s *reportService) GetDSOReport(ctx context.Context, query dtos.DSOQuery) (dtos.DSOReport, error) {
+	ctx, span := telemetry.Start(ctx, "ReportService.GetDSOReport")
+	defer span.End()
+
+	to := monthOf(query.To)
+	if query.To.IsZero() {
+		to = monthOf(time.Now())
+	}
+	from := monthOf(query.From)
+	if query.From.IsZero() {
+		from = to.AddDate(0, -11, 0)
+	}
+	if from.After(to) {
+		return dtos.DSOReport{}, NewValidationError("invalid_period", "from is after to", utils.FieldError{
+			Field:   "from",
+			Rule:    "ltefield",
+			Message: "from must be on or before to",
+		})
+	}
+
+	months, err := s.reportRepository.GetMonthlyReceivables(ctx, from, to)
+	if err != nil {
+		return dtos.DSOReport{}, wrapError(err, "failed to get receivables")
+	}
+
+	report := dtos.DSOReport{Currency: money.BaseCurrency, Months: []dtos.DSOMonth{}}
+	for _, m := range months {
+		start := monthOf(m.Month)
+		days := start.AddDate(0, 1, -1).Day()
+		month := dtos.DSOMonth{
+			Month:       start.Format("2006-01"),
+			Days:        days,
+			Sales:       m.Sales,
+			Receivables: m.Receivables,
+		}
+		if !m.Sales.IsZero() {
+			dso := math.Round(float64(m.Receivables)*float64(days)/float64(m.Sales)*100) / 100
+			month.DaysSalesOutstanding = &dso
+		}
+		report.Months = append(report.Months, month)
+	}
+	return report, nil
+}
+
+// agingBucket returns how many days past due a receivable is on asOf and
+// the bucket that puts it in.
+func agingBucket(asOf time.Time, r repositories.Receivable) (int, string) {
+	due := r.InvoiceDueDate
+	if due.IsZero() {
+		due = r.InvoiceIssueDate
+	}
+	days := int(asOf.Sub(dateOf(due)).Hours() / 24)
+	switch {
+	case days <= 0:
+		return days, BucketCurrent
+	case days <= 30:
+		return days, Bucket1To30
+	case days <= 60:
+		return days, Bucket31To60
+	case days <= 90:
+		return days, Bucket61To90
+	}
+	return days, BucketOver90
+}
+
+// agingAgencyName is the agency a receivable is reported under.
+func agingAgencyName(r repositories.Receivable) string {
+	if r.AgencyId == nil && r.AgencyName == "" {
+		return UnassignedAgency
+	}
+	return r.AgencyName
+}
+
+func addToBucket(row *dtos.ARAgingRow, bucket string, amount money.Amount) {
+	switch bucket {
+	case BucketCurrent:
+		row.Current += amount
+	case Bucket1To30:
+		row.Days1To30 += amount
+	case Bucket31To60:
+		row.Days31To60 += amount
+	case Bucket61To90:
+		row.Days61To90 += amount
+	default:
+		row.Over90 += amount
+	}
+	row.Invoices++
+	row.Total += amount
+}
+
+// asOfDate is the day a report is as of, today when not given.
+func asOfDate(asOf time.Time) time.Time {
+	if asOf.IsZero() {
+		return dateOf(time.Now())
+	}
+	return dateOf(asOf)
+}
+
+// monthOf returns the first day of t's month in UTC.
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
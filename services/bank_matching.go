@@ -0,0 +1,350 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// DefaultStatementDateLayout is the date layout of a statement format
+// that does not set one.
+const DefaultStatementDateLayout = "02/01/2006"
+
+// Scores of the matching signals. A match needs MinMatchScore to be
+// suggested, so an amount alone is not enough.
+const (
+	scoreReference     = 50
+	scoreInvoiceNumber = 40
+	scoreAmount        = 30
+	scoreAgency        = 20
+	scoreAgencyPartial = 10
+
+	MinMatchScore = 40
+	maxMatches    = 3
+)
+
+// companyWords are left out when comparing company names, since every
+// name has them.
+var companyWords = map[string]bool{
+	"co": true, "company": true, "ltd": true, "limited": true, "plc": true, "public": true,
+	"inc": true, "corp": true, "corporation": true, "the": true,
+	"บริษัท": true, "บจก": true, "จำกัด": true, "มหาชน": true, "บมจ": true, "ห้างหุ้นส่วน": true, "หจก": true,
+}
+
+// parseBankStatement reads the credits of a CSV statement laid out as
+// format describes. Problems are reported together, by line.
+func parseBankStatement(format entities.BankStatementFormat, r io.Reader) ([]entities.BankTransaction, error) {
+	if format.Encoding == "windows-874" {
+		r = charmap.Windows874.NewDecoder().Reader(r)
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, NewValidationError("invalid_statement", "the file is not valid CSV", utils.FieldError{
+			Field: "file", Rule: "csv", Message: err.Error(),
+		})
+	}
+	if len(records) <= format.SkipRows {
+		return nil, NewValidationError("invalid_statement", "the file has no header row")
+	}
+
+	header := map[string]int{}
+	for i, h := range records[format.SkipRows] {
+		header[normalizeStatementHeader(h)] = i
+	}
+	var fields []utils.FieldError
+	column := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		i, ok := header[normalizeStatementHeader(name)]
+		if !ok {
+			fields = append(fields, utils.FieldError{Field: "file", Rule: "column", Message: fmt.Sprintf("has no %q column", name)})
+			return -1
+		}
+		return i
+	}
+	dateCol := column(format.DateColumn)
+	descriptionCol := column(format.DescriptionColumn)
+	referenceCol := column(format.ReferenceColumn)
+	creditCol := column(format.CreditColumn)
+	column(format.DebitColumn)
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid_statement", "the file does not match the bank's statement format", fields...)
+	}
+
+	layout := format.DateLayout
+	if layout == "" {
+		layout = DefaultStatementDateLayout
+	}
+	cell := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	transactions := []entities.BankTransaction{}
+	seen := map[string]int{}
+	for n, record := range records[format.SkipRows+1:] {
+		line := format.SkipRows + n + 2
+		if isBlankRow(record) {
+			continue
+		}
+
+		amount, err := parseStatementAmount(cell(record, creditCol))
+		if err != nil {
+			fields = append(fields, utils.FieldError{Field: fmt.Sprintf("rows[%d].%s", line, format.CreditColumn), Rule: "format", Message: err.Error()})
+			continue
+		}
+		// Debits leave the credit column blank, or negative when it is
+		// the only amount column.
+		if amount <= 0 {
+			continue
+		}
+
+		date, err := time.Parse(layout, cell(record, dateCol))
+		if err != nil {
+			fields = append(fields, utils.FieldError{
+				Field:   fmt.Sprintf("rows[%d].%s", line, format.DateColumn),
+				Rule:    "format",
+				Message: fmt.Sprintf("must be a date laid out as %s", layout),
+			})
+			continue
+		}
+
+		t := entities.BankTransaction{
+			BankId:      format.BankId,
+			Date:        fromBuddhistEra(date),
+			Description: truncate(cell(record, descriptionCol), 255),
+			Reference:   truncate(cell(record, referenceCol), 100),
+			Amount:      amount,
+			Currency:    format.Currency,
+			Status:      repositories.BankTransactionUnmatched,
+		}
+		// Identical lines, such as two equal transfers from one client on
+		// the same day, are told apart by their order in the statement.
+		key := strings.Join([]string{strconv.Itoa(t.BankId), t.Date.Format("2006-01-02"), t.Amount.String(), t.Reference, t.Description}, "|")
+		seen[key]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, seen[key])))
+		t.Fingerprint = hex.EncodeToString(sum[:])
+		transactions = append(transactions, t)
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid_statement", "the file has invalid lines", fields...)
+	}
+	return transactions, nil
+}
+
+func normalizeStatementHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseStatementAmount reads an amount written with thousands separators,
+// and possibly a currency symbol. A blank cell is zero.
+func parseStatementAmount(raw string) (money.Amount, error) {
+	raw = strings.Map(func(r rune) rune {
+		if r == ',' || r == '฿' || unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, raw)
+	if raw == "" {
+		return 0, nil
+	}
+	a, err := money.Parse(raw)
+	if err != nil {
+		return 0, fmt.Errorf("must be an amount such as 1,500.00")
+	}
+	return a, nil
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// transferText is what a transfer says about its payer: the words and
+// numbers of its description and reference, and the same run together, for
+// names written without spaces as Thai often is.
+type transferText struct {
+	tokens  map[string]bool
+	compact string
+}
+
+// matchTransaction scores the open incomes against a transaction and
+// returns the best ones that reach MinMatchScore, skipping the incomes in
+// rejected.
+func matchTransaction(t entities.BankTransaction, incomes []entities.Income, rejected map[int]bool) []entities.BankTransactionMatch {
+	words := nameTokens(t.Description + " " + t.Reference)
+	text := transferText{tokens: map[string]bool{}, compact: strings.Join(words, "")}
+	for _, token := range words {
+		text.tokens[token] = true
+		// Numbers written with a prefix, such as INV1001, count as 1001.
+		if digits := strings.TrimLeftFunc(token, unicode.IsLetter); digits != token && isDigits(digits) {
+			text.tokens[digits] = true
+		}
+	}
+
+	matches := []entities.BankTransactionMatch{}
+	for _, income := range incomes {
+		if rejected[income.InvoiceIdNumber] {
+			continue
+		}
+		score, reasons := scoreMatch(t, text, income)
+		if score < MinMatchScore {
+			continue
+		}
+		matches = append(matches, entities.BankTransactionMatch{
+			IncomeInvoiceIdNumber: income.InvoiceIdNumber,
+			Score:                 score,
+			Reasons:               strings.Join(reasons, ","),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > maxMatches {
+		matches = matches[:maxMatches]
+	}
+	return matches
+}
+
+// scoreMatch adds up the signals that the transaction pays the income: its
+// reference or invoice number in the transfer's text, an amount settling
+// the balance, with or without the tax the client withholds, and the
+// agency's name. The score is capped at 100.
+func scoreMatch(t entities.BankTransaction, text transferText, income entities.Income) (int, []string) {
+	score := 0
+	var reasons []string
+
+	switch {
+	case income.TransactionReferenceNumber != 0 && text.tokens[strconv.Itoa(income.TransactionReferenceNumber)]:
+		score += scoreReference
+		reasons = append(reasons, "reference")
+	case text.tokens[strconv.Itoa(income.InvoiceIdNumber)]:
+		score += scoreInvoiceNumber
+		reasons = append(reasons, "invoice_number")
+	}
+
+	switch {
+	case t.Amount == income.UnpaidPaymentAmount:
+		score += scoreAmount
+		reasons = append(reasons, "amount")
+	case income.WithholdingTaxAmount > 0 && t.Amount == income.UnpaidPaymentAmount-income.WithholdingTaxAmount:
+		score += scoreAmount
+		reasons = append(reasons, "amount_net_of_withholding_tax")
+	}
+
+	name := nameTokens(income.AgencyAgencyName)
+	found := 0
+	for _, token := range name {
+		if text.tokens[token] {
+			found++
+		}
+	}
+	switch {
+	case len(name) > 0 && (found == len(name) || strings.Contains(text.compact, strings.Join(name, ""))):
+		score += scoreAgency
+		reasons = append(reasons, "agency")
+	case len(name) > 0 && found*2 >= len(name):
+		score += scoreAgencyPartial
+		reasons = append(reasons, "agency_partial")
+	}
+
+	return min(score, 100), reasons
+}
+
+// nameTokens splits text into lowercase words and numbers, leaving out
+// words every company name has and single letters.
+func nameTokens(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+	})
+	tokens := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) < 2 || companyWords[w] {
+			continue
+		}
+		tokens = append(tokens, w)
+	}
+	return tokens
+}
+
+// applyBankPayment records a transfer as the income's next payment and
+// dates the receipt on the transfer when it pays the balance in full. It
+// returns the amount settled.
+func applyBankPayment(income *entities.Income, t entities.BankTransaction) (money.Amount, error) {
+	note := fmt.Sprintf("Bank transfer on %s", t.Date.Format("2006-01-02"))
+	if t.Reference != "" {
+		note += ", ref " + t.Reference
+	}
+	settled, err := applyPayment(income, t.Amount, note, "amount")
+	if err != nil {
+		return 0, err
+	}
+	if income.UnpaidPaymentAmount.IsZero() {
+		income.ReceiptIssueDate = t.Date
+	}
+	return settled, nil
+}
+
+// applyPayment records amount as the income's next payment: the first
+// payment when none was recorded, otherwise added to the second. A payment
+// short of the balance by exactly the withholding tax settles the balance,
+// the tax being covered by the client's certificate. field names the
+// request field a payment larger than the balance is reported against. It
+// returns the amount settled.
+func applyPayment(income *entities.Income, amount money.Amount, note, field string) (money.Amount, error) {
+	settled := amount
+	if income.WithholdingTaxAmount > 0 && amount == income.UnpaidPaymentAmount-income.WithholdingTaxAmount {
+		settled = income.UnpaidPaymentAmount
+	}
+	if settled > income.UnpaidPaymentAmount {
+		return 0, NewValidationError("payment_exceeds_balance", "the payment is larger than the income's unpaid balance", utils.FieldError{
+			Field:   field,
+			Rule:    "max",
+			Message: fmt.Sprintf("must be at most %s", income.UnpaidPaymentAmount),
+		})
+	}
+
+	if income.FirstPayment.IsZero() {
+		income.FirstPayment = amount
+		income.NotesForTheFirstPayment = note
+	} else {
+		income.SecondPayment += amount
+		income.NotesForTheSecondPayment = strings.TrimPrefix(income.NotesForTheSecondPayment+"; "+note, "; ")
+	}
+
+	income.UnpaidPaymentAmount -= settled
+	return settled, nil
+}
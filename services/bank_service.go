@@ -2,10 +2,10 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"mtii-backend/dtos"
 	"mtii-backend/entities"
 	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
 )
 
 type BankService interface {
@@ -29,9 +29,12 @@ func NewBankService(
 }
 
 func (s *bankService) GetAllBank(ctx context.Context) ([]dtos.Bank, error) {
+	ctx, span := telemetry.Start(ctx, "BankService.GetAllBank")
+	defer span.End()
+
 	banks, err := s.bankRepository.GetAllBank(ctx)
 	if err != nil {
-		return []dtos.Bank{}, fmt.Errorf("failed to get the bank: %w", err)
+		return []dtos.Bank{}, wrapError(err, "failed to get the bank")
 	}
 
 	var bankDTOs []dtos.Bank
@@ -50,9 +53,12 @@ func (s *bankService) GetAllBank(ctx context.Context) ([]dtos.Bank, error) {
 }
 
 func (s *bankService) GetBankById(ctx context.Context, bankId int) (dtos.Bank, error) {
+	ctx, span := telemetry.Start(ctx, "BankService.GetBankById")
+	defer span.End()
+
 	bank, err := s.bankRepository.GetBankById(ctx, bankId)
 	if err != nil {
-		return dtos.Bank{}, fmt.Errorf("failed to get the bank: %w", err)
+		return dtos.Bank{}, wrapError(err, "failed to get the bank")
 	}
 
 	return dtos.Bank{
@@ -62,13 +68,16 @@ func (s *bankService) GetBankById(ctx context.Context, bankId int) (dtos.Bank, e
 }
 
 func (s *bankService) CreateBank(ctx context.Context, req dtos.BankRequest) (dtos.BankResponse, error) {
+	ctx, span := telemetry.Start(ctx, "BankService.CreateBank")
+	defer span.End()
+
 	data := entities.Bank{
 		Name: req.Name,
 	}
 
 	bank, err := s.bankRepository.CreateBank(ctx, data)
 	if err != nil {
-		return dtos.BankResponse{}, fmt.Errorf("failed to save the bank: %w", err)
+		return dtos.BankResponse{}, wrapError(err, "failed to save the bank")
 	}
 
 	return dtos.BankResponse{
@@ -77,9 +86,12 @@ func (s *bankService) CreateBank(ctx context.Context, req dtos.BankRequest) (dto
 }
 
 func (s *bankService) UpdateBank(ctx context.Context, bankId int, req dtos.BankRequest) (dtos.BankResponse, error) {
+	ctx, span := telemetry.Start(ctx, "BankService.UpdateBank")
+	defer span.End()
+
 	_, err := s.bankRepository.GetBankById(ctx, bankId)
 	if err != nil {
-		return dtos.BankResponse{}, fmt.Errorf("failed to get the bank: %w", err)
+		return dtos.BankResponse{}, wrapError(err, "failed to get the bank")
 	}
 
 	data := entities.Bank{
@@ -89,7 +101,7 @@ func (s *bankService) UpdateBank(ctx context.Context, bankId int, req dtos.BankR
 
 	bank, err := s.bankRepository.UpdateBank(ctx, data)
 	if err != nil {
-		return dtos.BankResponse{}, fmt.Errorf("failed to save the bank: %w", err)
+		return dtos.BankResponse{}, wrapError(err, "failed to save the bank")
 	}
 
 	return dtos.BankResponse{
@@ -98,14 +110,17 @@ func (s *bankService) UpdateBank(ctx context.Context, bankId int, req dtos.BankR
 }
 
 func (s *bankService) DeleteBank(ctx context.Context, bankId int) error {
+	ctx, span := telemetry.Start(ctx, "BankService.DeleteBank")
+	defer span.End()
+
 	bank, err := s.bankRepository.GetBankById(ctx, bankId)
 	if err != nil {
-		return fmt.Errorf("failed to get the bank: %w", err)
+		return wrapError(err, "failed to get the bank")
 	}
 
 	err = s.bankRepository.DeleteBank(ctx, bank.Id)
 	if err != nil {
-		return fmt.Errorf("failed to delete the bank: %w", err)
+		return wrapError(err, "failed to delete the bank")
 	}
 
 	return nil
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxStatementSize is the largest bank statement accepted, in bytes.
+const MaxStatementSize = 10 << 20
+
+type BankStatementService interface {
+	GetStatementFormat(ctx context.Context, bankId int) (dtos.BankStatementFormat, error)
+	SaveStatementFormat(ctx context.Context, bankId int, req dtos.SaveBankStatementFormatRequest) (dtos.BankStatementFormat, error)
+	UploadStatement(ctx context.Context, bankId int, fileName string, r io.Reader) (dtos.BankStatementResult, error)
+	GetAllBankTransaction(ctx context.Context, query dtos.BankTransactionQuery) ([]dtos.BankTransaction, error)
+	RematchBankTransactions(ctx context.Context) (dtos.BankRematchResult, error)
+	ConfirmMatch(ctx context.Context, matchId int) (dtos.BankTransaction, error)
+	RejectMatch(ctx context.Context, matchId int) (dtos.BankTransaction, error)
+}
+
+type bankStatementService struct {
+	bankStatementRepository repositories.BankStatementRepository
+	bankRepository          repositories.BankRepository
+	incomeRepository        repositories.IncomeRepository
+	commissionEngine        CommissionEngine
+}
+
+func NewBankStatementService(
+	bankStatementRepository repositories.BankStatementRepository,
+	bankRepository repositories.BankRepository,
+	incomeRepository repositories.IncomeRepository,
+	commissionEngine CommissionEngine,
+) BankStatementService {
+	return &bankStatementService{
+		bankStatementRepository: bankStatementRepository,
+		bankRepository:          bankRepository,
+		incomeRepository:        incomeRepository,
+		commissionEngine:        commissionEngine,
+	}
+}
+
+func (s *bankStatementService) GetStatementFormat(ctx context.Context, bankId int) (dtos.BankStatementFormat, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementService.GetStatementFormat")
+	defer span.End()
+
+	format, err := s.bankStatementRepository.GetStatementFormat(ctx, bankId)
+	if err != nil {
+		return dtos.BankStatementFormat{}, wrapError(err, "failed to get bank statement format")
+	}
+	return toBankStatementFormatDTO(format), nil
+}
+
+func (s *bankStatementService) SaveStatementFormat(ctx context.Context, bankId int, req dtos.SaveBankStatementFormatRequest) (dtos.BankStatementFormat, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementService.SaveStatementFormat")
+	defer span.End()
+
+	if _, err := s.bankRepository.GetBankById(ctx, bankId); err != nil {
+		return dtos.BankStatementFormat{}, wrapError(err, "failed to get bank")
+	}
+
+	format, err := s.bankStatementRepository.SaveStatementFormat(ctx, entities.BankStatementFormat{
+		BankId:            bankId,
+		Encoding:          helpers.DefaultIfEmpty(req.Encoding, "utf-8"),
+		SkipRows:          req.SkipRows,
+		DateColumn:        req.DateColumn,
+		DateLayout:        helpers.DefaultIfEmpty(req.DateLayout, DefaultStatementDateLayout),
+		DescriptionColumn: req.DescriptionColumn,
+		ReferenceColumn:   req.ReferenceColumn,
+		CreditColumn:      req.CreditColumn,
+		DebitColumn:       req.DebitColumn,
+		Currency:          helpers.DefaultIfEmpty(req.Currency, money.DefaultCurrency),
+	})
+	if err != nil {
+		return dtos.BankStatementFormat{}, wrapError(err, "failed to save bank statement format")
+	}
+	return toBankStatementFormatDTO(format), nil
+}
+
+// UploadStatement reads a statement in the bank's format, saves the credits
+// not uploaded before and suggests the incomes they may pay.
+func (s *bankStatementService) UploadStatement(ctx context.Context, bankId int, fileName string, r io.Reader) (dtos.BankStatementResult, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementService.UploadStatement")
+	defer span.End()
+
+	format, err := s.bankStatementRepository.GetStatementFormat(ctx, bankId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dtos.BankStatementResult{}, NewValidationError("bank_statement_format_missing", "set up the statement format of this bank first")
+	} else if err != nil {
+		return dtos.BankStatementResult{}, wrapError(err, "failed to get bank statement format")
+	}
+
+	// Read one byte past the limit to tell when the file is larger.
+	data, err := io.ReadAll(io.LimitReader(r, MaxStatementSize+1))
+	if err != nil {
+		return dtos.BankStatementResult{}, fmt.Errorf("failed to read bank statement: %w", err)
+	}
+	if len(data) > MaxStatementSize {
+		return dtos.BankStatementResult{}, NewValidationError("statement_too_large", "the file is too large", utils.FieldError{
+			Field: "file", Rule: "max", Message: fmt.Sprintf("must be at most %d MB", MaxStatementSize>>20),
+		})
+	}
+
+	transactions, err := parseBankStatement(format, strings.NewReader(string(data)))
+	if err != nil {
+		return dtos.BankStatementResult{}, err
+	}
+
+	statement, created, err := s.bankStatementRepository.CreateStatement(ctx, entities.BankStatement{
+		BankId:   bankId,
+		FileName: fileName,
+	}, transactions)
+	if err != nil {
+		return dtos.BankStatementResult{}, wrapError(err, "failed to save bank statement")
+	}
+
+	suggested, err := s.matchTransactions(ctx, created)
+	if err != nil {
+		return dtos.BankStatementResult{}, err
+	}
+
+	return dtos.BankStatementResult{
+		Id:         statement.Id,
+		BankId:     statement.BankId,
+		FileName:   statement.FileName,
+		Credits:    statement.Credits,
+		Duplicates: statement.Duplicates,
+		Suggested:  suggested,
+		CreatedAt:  statement.CreatedAt,
+	}, nil
+}
+
+func (s *bankStatementService) GetAllBankTransaction(ctx context.Context, query dtos.BankTransactionQuery) ([]dtos.BankTransaction, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementService.GetAllBankTransaction")
+	defer span.End()
+
+	transactions, err := s.bankStatementRepository.GetAllBankTransaction(ctx, query.Status, query.BankId)
+	if err != nil {
+		return []dtos.BankTransaction{}, wrapError(err, "failed to get bank transactions")
+	}
+
+	res := make([]dtos.BankTransaction, len(transactions))
+	for i, t := range transactions {
+		res[i] = toBankTransactionDTO(t)
+	}
+	return res, nil
+}
+
+// RematchBankTransactions matches the unmatched transactions again, for
+// incomes entered since they were uploaded.
+func (s *bankStatementService) RematchBankTransactions(ctx context.Context) (dtos.BankRematchResult, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementService.RematchBankTransactions")
+	defer span.End()
+
+	transactions, err := s.bankStatementRepository.GetAllBankTransaction(ctx, repositories.BankTransactionUnmatched, 0)
+	if err != nil {
+		return dtos.BankRematchResult{}, wrapError(err, "failed to get bank transactions")
+	}
+
+	suggested, err := s.matchTransactions(ctx, transactions)
+	if err != nil {
+		return dtos.BankRematchResult{}, err
+	}
+	return dtos.BankRematchResult{Transactions: len(transactions), Suggested: suggested}, nil
+}
+
+// matchTransactions saves the suggested matches of each transaction against
+// the open incomes in its currency, never suggesting an income again once
+// it was rejected for the transaction. It returns how many transactions got
+// suggestions.
+func (s *bankStatementService) matchTransactions(ctx context.Context, transactions []entities.BankTransaction) (int, error) {
+	openIncomes := map[string][]entities.Income{}
+	suggested := 0
+	for _, t := range transactions {
+		incomes, ok := openIncomes[t.Currency]
+		if !ok {
+			var err error
+			incomes, err = s.incomeRepository.GetOpenIncomes(ctx, t.Currency)
+			if err != nil {
+				return 0, wrapError(err, "failed to get open incomes")
+			}
+			openIncomes[t.Currency] = incomes
+		}
+
+		rejected := map[int]bool{}
+		for _, m := range t.Matches {
+			if m.Status == repositories.BankMatchRejected {
+				rejected[m.IncomeInvoiceIdNumber] = true
+			}
+		}
+
+		matches := matchTransaction(t, incomes, rejected)
+		if err := s.bankStatementRepository.SaveMatches(ctx, t.Id, matches); err != nil {
+			return 0, wrapError(err, "failed to save bank transaction matches")
+		}
+		if len(matches) > 0 {
+			suggested++
+		}
+	}
+	return suggested, nil
+}
+
+// ConfirmMatch accepts a suggested match: the transfer is recorded as a
+// payment of the income, reducing its unpaid balance.
+func (s *bankStatementService) ConfirmMatch(ctx context.Context, matchId int) (dtos.BankTransaction, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementService.ConfirmMatch")
+	defer span.End()
+
+	match, transaction, err := s.reviewableMatch(ctx, matchId)
+	if err != nil {
+		return dtos.BankTransaction{}, err
+	}
+
+	income := match.Income
+	before := income
+	if _, err := applyBankPayment(&income, transaction); err != nil {
+		return dtos.BankTransaction{}, err
+	}
+
+	if err := s.bankStatementRepository.ConfirmMatch(ctx, match, income); err != nil {
+		return dtos.BankTransaction{}, reviewError(err, "failed to confirm bank transaction match")
+	}
+	if err := recalculateCommissions(ctx, s.commissionEngine, before, income); err != nil {
+		return dtos.BankTransaction{}, err
+	}
+
+	return s.bankTransaction(ctx, transaction)
+}
+
+// RejectMatch turns down a suggested match, which is not suggested again.
+func (s *bankStatementService) RejectMatch(ctx context.Context, matchId int) (dtos.BankTransaction, error) {
+	ctx, span := telemetry.Start(ctx, "BankStatementService.RejectMatch")
+	defer span.End()
+
+	match, transaction, err := s.reviewableMatch(ctx, matchId)
+	if err != nil {
+		return dtos.BankTransaction{}, err
+	}
+
+	if err := s.bankStatementRepository.RejectMatch(ctx, match); err != nil {
+		return dtos.BankTransaction{}, reviewError(err, "failed to reject bank transaction match")
+	}
+
+	return s.bankTransaction(ctx, transaction)
+}
+
+// reviewableMatch returns a match still waiting for review.
+func (s *bankStatementService) reviewableMatch(ctx context.Context, matchId int) (entities.BankTransactionMatch, entities.BankTransaction, error) {
+	match, transaction, err := s.bankStatementRepository.GetMatchById(ctx, matchId)
+	if err != nil {
+		return entities.BankTransactionMatch{}, entities.BankTransaction{}, wrapError(err, "failed to get bank transaction match")
+	}
+	if match.Status != repositories.BankMatchPending || transaction.Status == repositories.BankTransactionConfirmed {
+		return entities.BankTransactionMatch{}, entities.BankTransaction{}, NewConflictError("bank_match_reviewed", "bank transaction match is already reviewed")
+	}
+	return match, transaction, nil
+}
+
+// reviewError reports a review that lost a race with another one, or with
+// a payment recorded on the income, as a conflict to retry.
+func reviewError(err error, message string) error {
+	switch {
+	case errors.Is(err, repositories.ErrMatchReviewed):
+		return NewConflictError("bank_match_reviewed", "bank transaction match is already reviewed")
+	case errors.Is(err, repositories.ErrIncomeBalanceChanged):
+		return NewConflictError("income_balance_changed", "the income's balance changed while the match was reviewed, review it again")
+	}
+	return wrapError(err, message)
+}
+
+// bankTransaction reloads a transaction with its matches after a review.
+func (s *bankStatementService) bankTransaction(ctx context.Context, transaction entities.BankTransaction) (dtos.BankTransaction, error) {
+	transaction, err := s.bankStatementRepository.GetBankTransactionById(ctx, transaction.Id)
+	if err != nil {
+		return dtos.BankTransaction{}, wrapError(err, "failed to get bank transaction")
+	}
+	return toBankTransactionDTO(transaction), nil
+}
+
+func toBankStatementFormatDTO(f entities.BankStatementFormat) dtos.BankStatementFormat {
+	return dtos.BankStatementFormat{
+		BankId:            f.BankId,
+		Encoding:          f.Encoding,
+		SkipRows:          f.SkipRows,
+		DateColumn:        f.DateColumn,
+		DateLayout:        f.DateLayout,
+		DescriptionColumn: f.DescriptionColumn,
+		ReferenceColumn:   f.ReferenceColumn,
+		CreditColumn:      f.CreditColumn,
+		DebitColumn:       f.DebitColumn,
+		Currency:          f.Currency,
+	}
+}
+
+func toBankTransactionDTO(t entities.BankTransaction) dtos.BankTransaction {
+	matches := make([]dtos.BankTransactionMatch, len(t.Matches))
+	for i, m := range t.Matches {
+		reasons := []string{}
+		if m.Reasons != "" {
+			reasons = strings.Split(m.Reasons, ",")
+		}
+		matches[i] = dtos.BankTransactionMatch{
+			Id:                    m.Id,
+			IncomeInvoiceIdNumber: m.IncomeInvoiceIdNumber,
+			AgencyName:            m.Income.AgencyAgencyName,
+			InvoiceIssueDate:      m.Income.InvoiceIssueDate,
+			UnpaidPaymentAmount:   m.Income.UnpaidPaymentAmount,
+			Score:                 m.Score,
+			Reasons:               reasons,
+			Status:                m.Status,
+			ReviewedAt:            m.ReviewedAt,
+		}
+	}
+
+	return dtos.BankTransaction{
+		Id:                    t.Id,
+		BankId:                t.BankId,
+		BankStatementId:       t.BankStatementId,
+		Date:                  t.Date,
+		Description:           t.Description,
+		Reference:             t.Reference,
+		Amount:                t.Amount,
+		Currency:              t.Currency,
+		Status:                t.Status,
+		IncomeInvoiceIdNumber: t.IncomeInvoiceIdNumber,
+		ConfirmedAt:           t.ConfirmedAt,
+		Matches:               matches,
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+)
+
+type BrandService interface {
+	GetAllBrand(ctx context.Context, query dtos.BrandQuery) ([]dtos.Brand, error)
+	GetBrandById(ctx context.Context, brandId int) (dtos.Brand, error)
+	CreateBrand(ctx context.Context, req dtos.CreateBrandRequest) (dtos.BrandResponse, error)
+	UpdateBrand(ctx context.Context, brandId int, req dtos.UpdateBrandRequest) (dtos.BrandResponse, error)
+	DeleteBrand(ctx context.Context, brandId int) error
+}
+
+type brandService struct {
+	brandRepository repositories.BrandRepository
+}
+
+func NewBrandService(
+	brandRepository repositories.BrandRepository,
+) BrandService {
+	return &brandService{
+		brandRepository: brandRepository,
+	}
+}
+
+func (s *brandService) GetAllBrand(ctx context.Context, query dtos.BrandQuery) ([]dtos.Brand, error) {
+	ctx, span := telemetry.Start(ctx, "BrandService.GetAllBrand")
+	defer span.End()
+
+	brands, err := s.brandRepository.GetAllBrand(ctx, query.AgencyId)
+	if err != nil {
+		return []dtos.Brand{}, wrapError(err, "failed to get brand")
+	}
+
+	var brandDTOs []dtos.Brand
+	for _, b := range brands {
+		brandDTOs = append(brandDTOs, toBrandDTO(b))
+	}
+
+	if len(brandDTOs) == 0 {
+		return []dtos.Brand{}, nil
+	}
+
+	return brandDTOs, nil
+}
+
+func (s *brandService) GetBrandById(ctx context.Context, brandId int) (dtos.Brand, error) {
+	ctx, span := telemetry.Start(ctx, "BrandService.GetBrandById")
+	defer span.End()
+
+	brand, err := s.brandRepository.GetBrandById(ctx, brandId)
+	if err != nil {
+		return dtos.Brand{}, wrapError(err, "failed to get brand")
+	}
+
+	return toBrandDTO(brand), nil
+}
+
+func (s *brandService) CreateBrand(ctx context.Context, req dtos.CreateBrandRequest) (dtos.BrandResponse, error) {
+	ctx, span := telemetry.Start(ctx, "BrandService.CreateBrand")
+	defer span.End()
+
+	data := entities.Brand{
+		AgencyId: req.AgencyId,
+		Name:     req.Name,
+	}
+
+	brand, err := s.brandRepository.CreateBrand(ctx, data)
+	if err != nil {
+		return dtos.BrandResponse{}, wrapError(err, "failed to save brand")
+	}
+
+	return dtos.BrandResponse{
+		Id: brand.Id,
+	}, nil
+}
+
+func (s *brandService) UpdateBrand(ctx context.Context, brandId int, req dtos.UpdateBrandRequest) (dtos.BrandResponse, error) {
+	ctx, span := telemetry.Start(ctx, "BrandService.UpdateBrand")
+	defer span.End()
+
+	brand, err := s.brandRepository.GetBrandById(ctx, brandId)
+	if err != nil {
+		return dtos.BrandResponse{}, wrapError(err, "failed to get brand")
+	}
+
+	data := entities.Brand{
+		Id:       brandId,
+		AgencyId: helpers.DefaultIfEmpty(req.AgencyId, brand.AgencyId),
+		Name:     helpers.DefaultIfEmpty(req.Name, brand.Name),
+	}
+
+	updatedBrand, err := s.brandRepository.UpdateBrand(ctx, data)
+	if err != nil {
+		return dtos.BrandResponse{}, wrapError(err, "failed to save brand")
+	}
+
+	return dtos.BrandResponse{
+		Id: updatedBrand.Id,
+	}, nil
+}
+
+func (s *brandService) DeleteBrand(ctx context.Context, brandId int) error {
+	ctx, span := telemetry.Start(ctx, "BrandService.DeleteBrand")
+	defer span.End()
+
+	brand, err := s.brandRepository.GetBrandById(ctx, brandId)
+	if err != nil {
+		return wrapError(err, "failed to get brand")
+	}
+
+	err = s.brandRepository.DeleteBrand(ctx, brand.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete brand")
+	}
+
+	return nil
+}
+
+func toBrandDTO(b entities.Brand) dtos.Brand {
+	return dtos.Brand{
+		Id:       b.Id,
+		AgencyId: b.AgencyId,
+		Name:     b.Name,
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"mtii-backend/dtos"
+	"mtii-backend/money"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"sort"
+	"time"
+)
+
+const (
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// GetCashFlowReport projects when unpaid balances will come in and sets
+// past periods' receipts against what fell due in them.
+//
+// Installments carry no dates of their own, so an income's unpaid balance
+// is expected on its due date. With ApplyLateness, that date moves by the
+// agency's average days late on its past incomes, which also moves when
+// past incomes count as scheduled.
+func (s *reportService) GetCashFlowReport(ctx context.Context, query dtos.CashFlowQuery) (dtos.CashFlowReport, error) {
+	ctx, span := telemetry.Start(ctx, "ReportService.GetCashFlowReport")
+	defer span.End()
+
+	interval := query.Interval
+	if interval == "" {
+		interval = IntervalMonth
+	}
+	today := dateOf(time.Now())
+	from, to := dateOf(query.From), dateOf(query.To)
+	if query.From.IsZero() {
+		from = monthOf(today).AddDate(0, -2, 0)
+	}
+	if query.To.IsZero() {
+		to = monthOf(today).AddDate(0, 4, -1)
+	}
+	if from.After(to) {
+		return dtos.CashFlowReport{}, NewValidationError("invalid_period", "from is after to", utils.FieldError{
+			Field:   "from",
+			Rule:    "ltefield",
+			Message: "from must be on or before to",
+		})
+	}
+
+	periods := cashFlowPeriods(interval, from, to)
+	from, to = periods[0].Start, periods[len(periods)-1].End
+
+	items, err := s.reportRepository.GetCashItems(ctx, from, to)
+	if err != nil {
+		return dtos.CashFlowReport{}, wrapError(err, "failed to get receivables")
+	}
+	lateness, err := s.reportRepository.GetAgencyLateness(ctx)
+	if err != nil {
+		return dtos.CashFlowReport{}, wrapError(err, "failed to get payment history")
+	}
+
+	report := dtos.CashFlowReport{
+		Interval:            interval,
+		From:                from,
+		To:                  to,
+		AsOf:                today,
+		ApplyLateness:       query.ApplyLateness,
+		Currency:            money.BaseCurrency,
+		AgencyLateness:      []dtos.AgencyLatenessItem{},
+		UnconvertedInvoices: []int{},
+	}
+
+	delays := map[string]int{}
+	for _, l := range lateness {
+		days := int(math.Round(l.DaysLate))
+		delays[agencyKey(l.AgencyId, l.AgencyName)] = days
+		report.AgencyLateness = append(report.AgencyLateness, dtos.AgencyLatenessItem{
+			AgencyId:        l.AgencyId,
+			AgencyName:      l.AgencyName,
+			Invoices:        l.Invoices,
+			AverageDaysLate: days,
+		})
+	}
+
+	periodOf := func(t time.Time) *dtos.CashFlowPeriod {
+		t = dateOf(t)
+		i := sort.Search(len(periods), func(i int) bool { return !periods[i].End.Before(t) })
+		if i == len(periods) || t.Before(periods[i].Start) {
+			return nil
+		}
+		return &periods[i]
+	}
+
+	for _, item := range items {
+		if !item.Converted {
+			report.UnconvertedInvoices = append(report.UnconvertedInvoices, item.InvoiceIdNumber)
+			continue
+		}
+
+		expected := dateOf(item.DueDate)
+		if query.ApplyLateness {
+			expected = expected.AddDate(0, 0, delays[agencyKey(item.AgencyId, item.AgencyName)])
+		}
+
+		if p := periodOf(expected); p != nil {
+			p.Scheduled += item.Total
+		}
+		if !item.ReceiptIssueDate.IsZero() {
+			if p := periodOf(item.ReceiptIssueDate); p != nil {
+				p.Actual += item.Received
+			}
+		}
+		if !item.Unpaid.IsZero() {
+			if expected.Before(today) {
+				expected = today
+			}
+			if p := periodOf(expected); p != nil {
+				p.Forecast += item.Unpaid
+			}
+		}
+	}
+
+	for i := range periods {
+		if periods[i].End.Before(today) {
+			variance := periods[i].Actual - periods[i].Scheduled
+			periods[i].Variance = &variance
+		}
+	}
+	report.Periods = periods
+
+	return report, nil
+}
+
+// cashFlowPeriods splits from to to into whole ISO weeks, starting on
+// Monday, or calendar months.
+func cashFlowPeriods(interval string, from, to time.Time) []dtos.CashFlowPeriod {
+	start := monthOf(from)
+	if interval == IntervalWeek {
+		start = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	}
+
+	var periods []dtos.CashFlowPeriod
+	for !start.After(to) {
+		var next time.Time
+		var label string
+		if interval == IntervalWeek {
+			next = start.AddDate(0, 0, 7)
+			year, week := start.ISOWeek()
+			label = fmt.Sprintf("%d-W%02d", year, week)
+		} else {
+			next = start.AddDate(0, 1, 0)
+			label = start.Format("2006-01")
+		}
+		periods = append(periods, dtos.CashFlowPeriod{Label: label, Start: start, End: next.AddDate(0, 0, -1)})
+		start = next
+	}
+	return periods
+}
+
+// agencyKey identifies an agency by id, or by name for incomes not linked
+// to one.
+func agencyKey(agencyId *int, agencyName string) string {
+	if agencyId != nil {
+		return fmt.Sprint(*agencyId)
+	}
+	return "name:" + agencyName
+}
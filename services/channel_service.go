@@ -2,10 +2,10 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"mtii-backend/dtos"
 	"mtii-backend/entities"
 	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
 )
 
 type ChannelService interface {
@@ -29,9 +29,12 @@ func NewChannelService(
 }
 
 func (s *channelService) GetAllChannel(ctx context.Context) ([]dtos.Channel, error) {
+	ctx, span := telemetry.Start(ctx, "ChannelService.GetAllChannel")
+	defer span.End()
+
 	channels, err := s.channelRepository.GetAllChannel(ctx)
 	if err != nil {
-		return []dtos.Channel{}, fmt.Errorf("failed to get channel: %w", err)
+		return []dtos.Channel{}, wrapError(err, "failed to get channel")
 	}
 
 	var channelDTOs []dtos.Channel
@@ -50,9 +53,12 @@ func (s *channelService) GetAllChannel(ctx context.Context) ([]dtos.Channel, err
 }
 
 func (s *channelService) GetChannelById(ctx context.Context, channelId int) (dtos.Channel, error) {
+	ctx, span := telemetry.Start(ctx, "ChannelService.GetChannelById")
+	defer span.End()
+
 	channel, err := s.channelRepository.GetChannelById(ctx, channelId)
 	if err != nil {
-		return dtos.Channel{}, fmt.Errorf("failed to get channel: %w", err)
+		return dtos.Channel{}, wrapError(err, "failed to get channel")
 	}
 
 	return dtos.Channel{
@@ -62,13 +68,16 @@ func (s *channelService) GetChannelById(ctx context.Context, channelId int) (dto
 }
 
 func (s *channelService) CreateChannel(ctx context.Context, req dtos.ChannelRequest) (dtos.ChannelResponse, error) {
+	ctx, span := telemetry.Start(ctx, "ChannelService.CreateChannel")
+	defer span.End()
+
 	data := entities.Channel{
 		Name: req.Name,
 	}
 
 	channel, err := s.channelRepository.CreateChannel(ctx, data)
 	if err != nil {
-		return dtos.ChannelResponse{}, fmt.Errorf("failed to save channel: %w", err)
+		return dtos.ChannelResponse{}, wrapError(err, "failed to save channel")
 	}
 
 	return dtos.ChannelResponse{
@@ -77,9 +86,12 @@ func (s *channelService) CreateChannel(ctx context.Context, req dtos.ChannelRequ
 }
 
 func (s *channelService) UpdateChannel(ctx context.Context, channelId int, req dtos.ChannelRequest) (dtos.ChannelResponse, error) {
+	ctx, span := telemetry.Start(ctx, "ChannelService.UpdateChannel")
+	defer span.End()
+
 	_, err := s.channelRepository.GetChannelById(ctx, channelId)
 	if err != nil {
-		return dtos.ChannelResponse{}, fmt.Errorf("failed to get channel: %w", err)
+		return dtos.ChannelResponse{}, wrapError(err, "failed to get channel")
 	}
 
 	data := entities.Channel{
@@ -89,7 +101,7 @@ func (s *channelService) UpdateChannel(ctx context.Context, channelId int, req d
 
 	channel, err := s.channelRepository.UpdateChannel(ctx, data)
 	if err != nil {
-		return dtos.ChannelResponse{}, fmt.Errorf("failed to save channel: %w", err)
+		return dtos.ChannelResponse{}, wrapError(err, "failed to save channel")
 	}
 
 	return dtos.ChannelResponse{
@@ -98,14 +110,17 @@ func (s *channelService) UpdateChannel(ctx context.Context, channelId int, req d
 }
 
 func (s *channelService) DeleteChannel(ctx context.Context, channelId int) error {
+	ctx, span := telemetry.Start(ctx, "ChannelService.DeleteChannel")
+	defer span.End()
+
 	channel, err := s.channelRepository.GetChannelById(ctx, channelId)
 	if err != nil {
-		return fmt.Errorf("failed to get channel: %w", err)
+		return wrapError(err, "failed to get channel")
 	}
 
 	err = s.channelRepository.DeleteChannel(ctx, channel.Id)
 	if err != nil {
-		return fmt.Errorf("failed to delete channel: %w", err)
+		return wrapError(err, "failed to delete channel")
 	}
 
 	return nil
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+)
+
+const (
+	CommissionFlat   = "flat"
+	CommissionTiered = "tiered"
+)
+
+type CommissionPlanService interface {
+	GetAllCommissionPlan(ctx context.Context) ([]dtos.CommissionPlan, error)
+	GetCommissionPlanById(ctx context.Context, commissionPlanId int) (dtos.CommissionPlan, error)
+	CreateCommissionPlan(ctx context.Context, req dtos.CreateCommissionPlanRequest) (dtos.CommissionPlanResponse, error)
+	UpdateCommissionPlan(ctx context.Context, commissionPlanId int, req dtos.UpdateCommissionPlanRequest) (dtos.CommissionPlanResponse, error)
+	DeleteCommissionPlan(ctx context.Context, commissionPlanId int) error
+}
+
+type commissionPlanService struct {
+	commissionPlanRepository repositories.CommissionPlanRepository
+}
+
+func NewCommissionPlanService(
+	commissionPlanRepository repositories.CommissionPlanRepository,
+) CommissionPlanService {
+	return &commissionPlanService{
+		commissionPlanRepository: commissionPlanRepository,
+	}
+}
+
+func (s *commissionPlanService) GetAllCommissionPlan(ctx context.Context) ([]dtos.CommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanService.GetAllCommissionPlan")
+	defer span.End()
+
+	commissionPlans, err := s.commissionPlanRepository.GetAllCommissionPlan(ctx)
+	if err != nil {
+		return []dtos.CommissionPlan{}, wrapError(err, "failed to get commission plan")
+	}
+
+	var commissionPlanDTOs []dtos.CommissionPlan
+	for _, p := range commissionPlans {
+		commissionPlanDTOs = append(commissionPlanDTOs, toCommissionPlanDTO(p))
+	}
+
+	if len(commissionPlanDTOs) == 0 {
+		return []dtos.CommissionPlan{}, nil
+	}
+
+	return commissionPlanDTOs, nil
+}
+
+func (s *commissionPlanService) GetCommissionPlanById(ctx context.Context, commissionPlanId int) (dtos.CommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanService.GetCommissionPlanById")
+	defer span.End()
+
+	commissionPlan, err := s.commissionPlanRepository.GetCommissionPlanById(ctx, commissionPlanId)
+	if err != nil {
+		return dtos.CommissionPlan{}, wrapError(err, "failed to get commission plan")
+	}
+
+	return toCommissionPlanDTO(commissionPlan), nil
+}
+
+func (s *commissionPlanService) CreateCommissionPlan(ctx context.Context, req dtos.CreateCommissionPlanRequest) (dtos.CommissionPlanResponse, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanService.CreateCommissionPlan")
+	defer span.End()
+
+	data := entities.CommissionPlan{
+		Name:       req.Name,
+		Type:       req.Type,
+		Rate:       req.Rate,
+		PlatformId: optionalId(req.PlatformId, nil),
+		ChannelId:  optionalId(req.ChannelId, nil),
+		Tiers:      toCommissionTierEntities(req.Tiers),
+	}
+
+	if err := validateCommissionPlan(&data); err != nil {
+		return dtos.CommissionPlanResponse{}, err
+	}
+
+	commissionPlan, err := s.commissionPlanRepository.CreateCommissionPlan(ctx, data)
+	if err != nil {
+		return dtos.CommissionPlanResponse{}, wrapError(err, "failed to save commission plan")
+	}
+
+	return dtos.CommissionPlanResponse{
+		Id: commissionPlan.Id,
+	}, nil
+}
+
+// UpdateCommissionPlan changes a plan. Commissions already calculated keep
+// the old terms until the months they fall in are recalculated.
+func (s *commissionPlanService) UpdateCommissionPlan(ctx context.Context, commissionPlanId int, req dtos.UpdateCommissionPlanRequest) (dtos.CommissionPlanResponse, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanService.UpdateCommissionPlan")
+	defer span.End()
+
+	commissionPlan, err := s.commissionPlanRepository.GetCommissionPlanById(ctx, commissionPlanId)
+	if err != nil {
+		return dtos.CommissionPlanResponse{}, wrapError(err, "failed to get commission plan")
+	}
+
+	data := entities.CommissionPlan{
+		Id:         commissionPlanId,
+		Name:       helpers.DefaultIfEmpty(req.Name, commissionPlan.Name),
+		Type:       helpers.DefaultIfEmpty(req.Type, commissionPlan.Type),
+		Rate:       commissionPlan.Rate,
+		PlatformId: optionalId(req.PlatformId, commissionPlan.PlatformId),
+		ChannelId:  optionalId(req.ChannelId, commissionPlan.ChannelId),
+		Tiers:      commissionPlan.Tiers,
+	}
+	if req.Rate != nil {
+		data.Rate = *req.Rate
+	}
+	if req.Tiers != nil {
+		data.Tiers = toCommissionTierEntities(req.Tiers)
+	}
+
+	if err := validateCommissionPlan(&data); err != nil {
+		return dtos.CommissionPlanResponse{}, err
+	}
+
+	replaceTiers := req.Tiers != nil || data.Type != commissionPlan.Type
+	updatedCommissionPlan, err := s.commissionPlanRepository.UpdateCommissionPlan(ctx, data, replaceTiers)
+	if err != nil {
+		return dtos.CommissionPlanResponse{}, wrapError(err, "failed to save commission plan")
+	}
+
+	return dtos.CommissionPlanResponse{
+		Id: updatedCommissionPlan.Id,
+	}, nil
+}
+
+// DeleteCommissionPlan removes a plan. The database refuses while a sale
+// person is assigned to it or commissions were calculated with it.
+func (s *commissionPlanService) DeleteCommissionPlan(ctx context.Context, commissionPlanId int) error {
+	ctx, span := telemetry.Start(ctx, "CommissionPlanService.DeleteCommissionPlan")
+	defer span.End()
+
+	commissionPlan, err := s.commissionPlanRepository.GetCommissionPlanById(ctx, commissionPlanId)
+	if err != nil {
+		return wrapError(err, "failed to get commission plan")
+	}
+
+	err = s.commissionPlanRepository.DeleteCommissionPlan(ctx, commissionPlan.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete commission plan")
+	}
+
+	return nil
+}
+
+// validateCommissionPlan checks that rates are percentages and that a
+// tiered plan has tiers with distinct thresholds. A flat plan keeps no
+// tiers and a tiered plan no flat rate.
+func validateCommissionPlan(plan *entities.CommissionPlan) error {
+	hundred := money.FromInt(100)
+	switch plan.Type {
+	case CommissionFlat:
+		plan.Tiers = nil
+		if plan.Rate > hundred {
+			return commissionRateError("rate", plan.Rate)
+		}
+	case CommissionTiered:
+		plan.Rate = 0
+		if len(plan.Tiers) == 0 {
+			return NewValidationError("missing_tiers", "a tiered plan needs at least one tier", utils.FieldError{
+				Field:   "tiers",
+				Rule:    "required",
+				Message: "tiers is required for a tiered plan",
+			})
+		}
+		seen := map[money.Amount]bool{}
+		for i, t := range plan.Tiers {
+			if t.Rate > hundred {
+				return commissionRateError(fmt.Sprintf("tiers[%d].rate", i), t.Rate)
+			}
+			if seen[t.MinRevenue] {
+				return NewValidationError("duplicate_tier", "tiers must have distinct thresholds", utils.FieldError{
+					Field:   fmt.Sprintf("tiers[%d].min_revenue", i),
+					Rule:    "unique",
+					Message: fmt.Sprintf("another tier already starts at %s", t.MinRevenue),
+				})
+			}
+			seen[t.MinRevenue] = true
+		}
+	}
+	return nil
+}
+
+func commissionRateError(field string, rate money.Amount) error {
+	return NewValidationError("invalid_rate", "commission rate must be between 0 and 100", utils.FieldError{
+		Field:   field,
+		Rule:    "max",
+		Message: fmt.Sprintf("%s is more than 100 percent", rate),
+	})
+}
+
+func toCommissionTierEntities(tiers []dtos.CommissionTier) []entities.CommissionTier {
+	var result []entities.CommissionTier
+	for _, t := range tiers {
+		result = append(result, entities.CommissionTier{MinRevenue: t.MinRevenue, Rate: t.Rate})
+	}
+	return result
+}
+
+func toCommissionPlanDTO(p entities.CommissionPlan) dtos.CommissionPlan {
+	tiers := []dtos.CommissionTier{}
+	for _, t := range p.Tiers {
+		tiers = append(tiers, dtos.CommissionTier{MinRevenue: t.MinRevenue, Rate: t.Rate})
+	}
+
+	return dtos.CommissionPlan{
+		Id:         p.Id,
+		Name:       p.Name,
+		Type:       p.Type,
+		Rate:       p.Rate,
+		PlatformId: p.PlatformId,
+		ChannelId:  p.ChannelId,
+		Tiers:      tiers,
+	}
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CommissionEngine recalculates what a sale person earned in a month. The
+// income service runs it whenever an income of the sale person changes.
+type CommissionEngine interface {
+	RecalculateMonth(ctx context.Context, salePersonId int, month time.Time) error
+}
+
+type CommissionService interface {
+	CommissionEngine
+	GetSalePersonCommissionPlans(ctx context.Context, salePersonId int) ([]dtos.SalePersonCommissionPlan, error)
+	AssignCommissionPlan(ctx context.Context, salePersonId int, req dtos.AssignCommissionPlanRequest) (dtos.SalePersonCommissionPlan, error)
+	UnassignCommissionPlan(ctx context.Context, salePersonId, assignmentId int) error
+	GetCommissionStatement(ctx context.Context, salePersonId int, period string) (dtos.CommissionStatement, error)
+	RecalculateCommissions(ctx context.Context, salePersonId int, period string) (dtos.CommissionStatement, error)
+}
+
+type commissionService struct {
+	commissionRepository     repositories.CommissionRepository
+	commissionPlanRepository repositories.CommissionPlanRepository
+	salePersonRepository     repositories.SalePersonRepository
+	incomeRepository         repositories.IncomeRepository
+	currencyRateRepository   repositories.CurrencyRateRepository
+}
+
+func NewCommissionService(
+	commissionRepository repositories.CommissionRepository,
+	commissionPlanRepository repositories.CommissionPlanRepository,
+	salePersonRepository repositories.SalePersonRepository,
+	incomeRepository repositories.IncomeRepository,
+	currencyRateRepository repositories.CurrencyRateRepository,
+) CommissionService {
+	return &commissionService{
+		commissionRepository:     commissionRepository,
+		commissionPlanRepository: commissionPlanRepository,
+		salePersonRepository:     salePersonRepository,
+		incomeRepository:         incomeRepository,
+		currencyRateRepository:   currencyRateRepository,
+	}
+}
+
+func (s *commissionService) GetSalePersonCommissionPlans(ctx context.Context, salePersonId int) ([]dtos.SalePersonCommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionService.GetSalePersonCommissionPlans")
+	defer span.End()
+
+	if _, err := s.salePersonRepository.GetSalePersonById(ctx, salePersonId); err != nil {
+		return []dtos.SalePersonCommissionPlan{}, wrapError(err, "failed to get sale person")
+	}
+
+	assignments, err := s.commissionRepository.GetSalePersonCommissionPlans(ctx, salePersonId)
+	if err != nil {
+		return []dtos.SalePersonCommissionPlan{}, wrapError(err, "failed to get commission plan")
+	}
+
+	assignmentDTOs := []dtos.SalePersonCommissionPlan{}
+	for _, a := range assignments {
+		assignmentDTOs = append(assignmentDTOs, toSalePersonCommissionPlanDTO(a))
+	}
+	return assignmentDTOs, nil
+}
+
+// AssignCommissionPlan puts a sale person on a plan. Commissions already
+// calculated for the dates it covers change only when those months are
+// recalculated.
+func (s *commissionService) AssignCommissionPlan(ctx context.Context, salePersonId int, req dtos.AssignCommissionPlanRequest) (dtos.SalePersonCommissionPlan, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionService.AssignCommissionPlan")
+	defer span.End()
+
+	if _, err := s.salePersonRepository.GetSalePersonById(ctx, salePersonId); err != nil {
+		return dtos.SalePersonCommissionPlan{}, wrapError(err, "failed to get sale person")
+	}
+
+	plan, err := s.commissionPlanRepository.GetCommissionPlanById(ctx, req.CommissionPlanId)
+	if err != nil {
+		return dtos.SalePersonCommissionPlan{}, referenceError(err, "commission_plan_id", "commission plan", req.CommissionPlanId)
+	}
+
+	data := entities.SalePersonCommissionPlan{
+		SalePersonId:     salePersonId,
+		CommissionPlanId: plan.Id,
+		EffectiveFrom:    dateOf(req.EffectiveFrom),
+	}
+	if !req.EffectiveTo.IsZero() {
+		data.EffectiveTo = dateOf(req.EffectiveTo)
+		if data.EffectiveTo.Before(data.EffectiveFrom) {
+			return dtos.SalePersonCommissionPlan{}, NewValidationError("invalid_period", "effective_to is before effective_from", utils.FieldError{
+				Field:   "effective_to",
+				Rule:    "gtefield",
+				Message: "effective_to must be on or after effective_from",
+			})
+		}
+	}
+
+	assignment, err := s.commissionRepository.CreateSalePersonCommissionPlan(ctx, data)
+	if err != nil {
+		return dtos.SalePersonCommissionPlan{}, wrapError(err, "failed to save commission plan")
+	}
+	assignment.CommissionPlan = plan
+
+	return toSalePersonCommissionPlanDTO(assignment), nil
+}
+
+func (s *commissionService) UnassignCommissionPlan(ctx context.Context, salePersonId, assignmentId int) error {
+	ctx, span := telemetry.Start(ctx, "CommissionService.UnassignCommissionPlan")
+	defer span.End()
+
+	err := s.commissionRepository.DeleteSalePersonCommissionPlan(ctx, salePersonId, assignmentId)
+	if err != nil {
+		return wrapError(err, "failed to delete commission plan")
+	}
+
+	return nil
+}
+
+// GetCommissionStatement lists the commissions of a sale person on incomes
+// paid in period, a month such as 2026-10 or a quarter such as 2026-Q4.
+func (s *commissionService) GetCommissionStatement(ctx context.Context, salePersonId int, period string) (dtos.CommissionStatement, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionService.GetCommissionStatement")
+	defer span.End()
+
+	from, to, err := parsePeriod(period)
+	if err != nil {
+		return dtos.CommissionStatement{}, err
+	}
+
+	salePerson, err := s.salePersonRepository.GetSalePersonById(ctx, salePersonId)
+	if err != nil {
+		return dtos.CommissionStatement{}, wrapError(err, "failed to get sale person")
+	}
+
+	commissions, err := s.commissionRepository.GetCommissions(ctx, salePersonId, from, to)
+	if err != nil {
+		return dtos.CommissionStatement{}, wrapError(err, "failed to get commission")
+	}
+
+	statement := dtos.CommissionStatement{
+		SalePerson: dtos.SalePerson{Id: salePerson.Id, Name: salePerson.Name},
+		Period:     period,
+		From:       from,
+		To:         to,
+		Currency:   money.BaseCurrency,
+		Lines:      []dtos.CommissionLine{},
+	}
+	for _, c := range commissions {
+		statement.Lines = append(statement.Lines, dtos.CommissionLine{
+			IncomeInvoiceIdNumber: c.IncomeInvoiceIdNumber,
+			PaidDate:              c.PaidDate,
+			CommissionPlanId:      c.CommissionPlanId,
+			CommissionPlanName:    c.CommissionPlan.Name,
+			Revenue:               c.Revenue,
+			Rate:                  c.Rate,
+			Amount:                c.Amount,
+		})
+		statement.TotalRevenue += c.Revenue
+		statement.TotalCommission += c.Amount
+	}
+	return statement, nil
+}
+
+// RecalculateCommissions rebuilds every month of period from the incomes
+// and plans as they are now, then returns the statement. It is how plan
+// changes reach months that were already calculated.
+func (s *commissionService) RecalculateCommissions(ctx context.Context, salePersonId int, period string) (dtos.CommissionStatement, error) {
+	ctx, span := telemetry.Start(ctx, "CommissionService.RecalculateCommissions")
+	defer span.End()
+
+	from, to, err := parsePeriod(period)
+	if err != nil {
+		return dtos.CommissionStatement{}, err
+	}
+
+	if _, err := s.salePersonRepository.GetSalePersonById(ctx, salePersonId); err != nil {
+		return dtos.CommissionStatement{}, wrapError(err, "failed to get sale person")
+	}
+
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		if err := s.RecalculateMonth(ctx, salePersonId, month); err != nil {
+			return dtos.CommissionStatement{}, err
+		}
+	}
+
+	return s.GetCommissionStatement(ctx, salePersonId, period)
+}
+
+// RecalculateMonth replaces the sale person's commissions for the month of
+// month with ones computed from the incomes fully paid in it.
+//
+// Commission is earned on revenue: the lines after discounts and before
+// VAT, converted to the base currency at the invoice date's rate. Incomes
+// without a rate or without a plan on their paid date earn nothing until
+// recalculated. A tiered plan pays every income under it at the rate of
+// the highest tier reached by the month's revenue under that plan.
+func (s *commissionService) RecalculateMonth(ctx context.Context, salePersonId int, month time.Time) error {
+	ctx, span := telemetry.Start(ctx, "CommissionService.RecalculateMonth")
+	defer span.End()
+
+	from := monthOf(month)
+	to := from.AddDate(0, 1, -1)
+
+	incomes, err := s.incomeRepository.GetPaidIncomesBySalePerson(ctx, salePersonId, from, to)
+	if err != nil {
+		return wrapError(err, "failed to get income")
+	}
+
+	assignments, err := s.commissionRepository.GetSalePersonCommissionPlans(ctx, salePersonId)
+	if err != nil {
+		return wrapError(err, "failed to get commission plan")
+	}
+
+	rates, err := incomeRateTable(ctx, s.currencyRateRepository, incomes)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var commissions []entities.Commission
+	plans := map[int]entities.CommissionPlan{}
+	planRevenue := map[int]money.Amount{}
+	for _, i := range incomes {
+		plan, ok := commissionPlanOn(assignments, i)
+		if !ok {
+			continue
+		}
+		rate, _, ok := rates.rateOn(i.Currency, i.InvoiceIssueDate)
+		if !ok {
+			continue
+		}
+		totals := incomeTotals(i.DiscountType, i.DiscountValue, i.Details)
+		revenue := rate.Convert(totals.Subtotal - totals.DiscountAmount)
+
+		plans[plan.Id] = plan
+		planRevenue[plan.Id] += revenue
+		commissions = append(commissions, entities.Commission{
+			SalePersonId:          salePersonId,
+			PaidDate:              i.ReceiptIssueDate,
+			IncomeInvoiceIdNumber: i.InvoiceIdNumber,
+			CommissionPlanId:      plan.Id,
+			Revenue:               revenue,
+			CalculatedAt:          now,
+		})
+	}
+
+	for n := range commissions {
+		c := &commissions[n]
+		c.Rate = commissionRate(plans[c.CommissionPlanId], planRevenue[c.CommissionPlanId])
+		c.Amount = c.Revenue.Percent(c.Rate)
+	}
+
+	err = s.commissionRepository.ReplaceCommissions(ctx, salePersonId, from, to, commissions)
+	if err != nil {
+		return wrapError(err, "failed to save commission")
+	}
+	return nil
+}
+
+// commissionPlanOn picks the plan covering income on its paid date. A plan
+// for the income's platform or channel wins over a general one, and among
+// equally specific plans the one that started last wins.
+func commissionPlanOn(assignments []entities.SalePersonCommissionPlan, income entities.Income) (entities.CommissionPlan, bool) {
+	paid := dateOf(income.ReceiptIssueDate)
+	best, bestScore := -1, -1
+	for n, a := range assignments {
+		if paid.Before(a.EffectiveFrom) || (!a.EffectiveTo.IsZero() && paid.After(a.EffectiveTo)) {
+			continue
+		}
+		plan := a.CommissionPlan
+		score := 0
+		if plan.PlatformId != nil {
+			if *plan.PlatformId != income.PlatformId {
+				continue
+			}
+			score++
+		}
+		if plan.ChannelId != nil {
+			if *plan.ChannelId != income.ChannelId {
+				continue
+			}
+			score++
+		}
+		// Assignments come sorted by start date, so a later one replaces an
+		// earlier one of the same score.
+		if score >= bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best < 0 {
+		return entities.CommissionPlan{}, false
+	}
+	return assignments[best].CommissionPlan, true
+}
+
+// commissionRate is the percentage a plan pays when revenue was earned
+// under it in a month.
+func commissionRate(plan entities.CommissionPlan, revenue money.Amount) money.Amount {
+	if plan.Type != CommissionTiered {
+		return plan.Rate
+	}
+	tiers := append([]entities.CommissionTier(nil), plan.Tiers...)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinRevenue < tiers[j].MinRevenue })
+	var rate money.Amount
+	for _, t := range tiers {
+		if revenue < t.MinRevenue {
+			break
+		}
+		rate = t.Rate
+	}
+	return rate
+}
+
+// isPaid reports whether an income has been paid in full, which is when it
+// starts earning commission.
+func isPaid(i entities.Income) bool {
+	return i.UnpaidPaymentAmount.IsZero() && !i.ReceiptIssueDate.IsZero()
+}
+
+// parsePeriod reads a month such as 2026-10 or a quarter such as 2026-Q4
+// and returns its first and last day.
+func parsePeriod(period string) (time.Time, time.Time, error) {
+	if strings.Contains(period, "Q") {
+		from, to, err := parseQuarter(period)
+		if err == nil {
+			return from, to, nil
+		}
+	} else if from, err := time.Parse("2006-01", period); err == nil {
+		return from, from.AddDate(0, 1, -1), nil
+	}
+	return time.Time{}, time.Time{}, NewValidationError("invalid_period", "period must look like 2026-10 or 2026-Q4", utils.FieldError{
+		Field:   "period",
+		Rule:    "period",
+		Message: fmt.Sprintf("%q is not a month such as 2026-10 or a quarter such as 2026-Q4", period),
+	})
+}
+
+func toSalePersonCommissionPlanDTO(a entities.SalePersonCommissionPlan) dtos.SalePersonCommissionPlan {
+	return dtos.SalePersonCommissionPlan{
+		Id:             a.Id,
+		SalePersonId:   a.SalePersonId,
+		CommissionPlan: toCommissionPlanDTO(a.CommissionPlan),
+		EffectiveFrom:  a.EffectiveFrom,
+		EffectiveTo:    a.EffectiveTo,
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"testing"
+	"time"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCommissionRate(t *testing.T) {
+	flat := entities.CommissionPlan{Type: CommissionFlat, Rate: money.FromInt(5)}
+	tiered := entities.CommissionPlan{Type: CommissionTiered, Tiers: []entities.CommissionTier{
+		{MinRevenue: money.FromInt(100000), Rate: money.FromInt(3)},
+		{MinRevenue: money.FromInt(10000), Rate: money.FromInt(1)},
+		{MinRevenue: money.FromInt(50000), Rate: money.FromInt(2)},
+	}}
+
+	tests := []struct {
+		name    string
+		plan    entities.CommissionPlan
+		revenue money.Amount
+		want    money.Amount
+	}{
+		{"flat plans ignore revenue", flat, 0, money.FromInt(5)},
+		{"below the first tier", tiered, money.FromInt(10000) - 1, 0},
+		{"on the first tier", tiered, money.FromInt(10000), money.FromInt(1)},
+		{"tiers are read in order of revenue", tiered, money.FromInt(60000), money.FromInt(2)},
+		{"just below the top tier", tiered, money.FromInt(100000) - 1, money.FromInt(2)},
+		{"top tier", tiered, money.FromInt(250000), money.FromInt(3)},
+		{"tiered plan without tiers", entities.CommissionPlan{Type: CommissionTiered}, money.FromInt(1000), 0},
+	}
+	for _, tt := range tests {
+		if got := commissionRate(tt.plan, tt.revenue); got != tt.want {
+			t.Errorf("%s: commissionRate() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCommissionPlanOn(t *testing.T) {
+	platform := 7
+	general := entities.SalePersonCommissionPlan{Id: 1, EffectiveFrom: day(2026, 1, 1), CommissionPlan: entities.CommissionPlan{Id: 1}}
+	later := entities.SalePersonCommissionPlan{Id: 2, EffectiveFrom: day(2026, 6, 1), CommissionPlan: entities.CommissionPlan{Id: 2}}
+	ended := entities.SalePersonCommissionPlan{Id: 3, EffectiveFrom: day(2026, 7, 1), EffectiveTo: day(2026, 8, 31), CommissionPlan: entities.CommissionPlan{Id: 3}}
+	onPlatform := entities.SalePersonCommissionPlan{Id: 4, EffectiveFrom: day(2026, 1, 1), CommissionPlan: entities.CommissionPlan{Id: 4, PlatformId: &platform}}
+	assignments := []entities.SalePersonCommissionPlan{general, onPlatform, later, ended}
+
+	tests := []struct {
+		name       string
+		paid       time.Time
+		platformId int
+		want       int
+	}{
+		{"before any plan", day(2025, 12, 31), 1, 0},
+		{"only the general plan", day(2026, 3, 1), 1, 1},
+		{"a later plan replaces an earlier one", day(2026, 6, 1), 1, 2},
+		{"inside a closed period", day(2026, 8, 31), 1, 3},
+		{"after a closed period", day(2026, 9, 1), 1, 2},
+		{"a platform plan wins over a general one", day(2026, 8, 1), platform, 4},
+	}
+	for _, tt := range tests {
+		plan, ok := commissionPlanOn(assignments, entities.Income{ReceiptIssueDate: tt.paid, PlatformId: tt.platformId})
+		if !ok {
+			plan.Id = 0
+		}
+		if plan.Id != tt.want {
+			t.Errorf("%s: commissionPlanOn() = plan %d, want plan %d", tt.name, plan.Id, tt.want)
+		}
+	}
+}
+
+// RecalculateMonth pays every income under a tiered plan at the rate of the
+// tier its month's revenue reached, counting revenue before VAT in baht.
+func TestRecalculateMonth(t *testing.T) {
+	platform := 7
+	tiered := entities.CommissionPlan{Id: 1, Type: CommissionTiered, Tiers: []entities.CommissionTier{
+		{MinRevenue: 0, Rate: money.FromInt(1)},
+		{MinRevenue: money.FromInt(50000), Rate: amount(t, "1.5")},
+		{MinRevenue: money.FromInt(100000), Rate: money.FromInt(2)},
+	}}
+	flat := entities.CommissionPlan{Id: 2, Type: CommissionFlat, Rate: money.FromInt(5), PlatformId: &platform}
+
+	income := func(invoice int, currency string, price money.Amount, invoiced, paid time.Time, platformId int) entities.Income {
+		return entities.Income{
+			InvoiceIdNumber:  invoice,
+			SalePersonId:     1,
+			PlatformId:       platformId,
+			Currency:         currency,
+			InvoiceIssueDate: invoiced,
+			ReceiptIssueDate: paid,
+			Details:          []entities.Detail{{UnitPrice: price, Quantity: 1}},
+		}
+	}
+	incomes := &fakeIncomeRepository{paid: []entities.Income{
+		income(1, "", money.FromInt(30000), day(2026, 9, 20), day(2026, 10, 2), 1),
+		income(2, "THB", money.FromInt(25000), day(2026, 10, 1), day(2026, 10, 15), 1),
+		income(3, "USD", money.FromInt(1000), day(2026, 10, 3), day(2026, 10, 31), 1),
+		income(4, "USD", money.FromInt(1000), day(2026, 9, 1), day(2026, 10, 20), 1),
+		income(5, "THB", money.FromInt(10000), day(2026, 10, 1), day(2026, 10, 10), platform),
+		income(6, "THB", money.FromInt(99999), day(2026, 10, 1), day(2026, 11, 1), 1),
+	}}
+	commissions := &fakeCommissionRepository{assignments: []entities.SalePersonCommissionPlan{
+		{SalePersonId: 1, EffectiveFrom: day(2026, 1, 1), CommissionPlanId: 1, CommissionPlan: tiered},
+		{SalePersonId: 1, EffectiveFrom: day(2026, 1, 1), CommissionPlanId: 2, CommissionPlan: flat},
+	}}
+	rates := &fakeCurrencyRateRepository{rates: []entities.CurrencyRate{
+		{Currency: "USD", Date: day(2026, 10, 1), Rate: 35000000},
+	}}
+	service := &commissionService{
+		commissionRepository:   commissions,
+		incomeRepository:       incomes,
+		currencyRateRepository: rates,
+	}
+
+	if err := service.RecalculateMonth(context.Background(), 1, day(2026, 10, 17)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Incomes 1 to 3 earn 90,000 baht under the tiered plan, reaching the
+	// 1.5% tier. Income 4 has no rate on its invoice date and income 6 was
+	// paid in November.
+	want := map[int][2]money.Amount{
+		1: {money.FromInt(30000), money.FromInt(450)},
+		2: {money.FromInt(25000), money.FromInt(375)},
+		3: {money.FromInt(35000), money.FromInt(525)},
+		5: {money.FromInt(10000), money.FromInt(500)},
+	}
+	if len(commissions.replaced) != len(want) {
+		t.Fatalf("got %d commissions, want %d: %+v", len(commissions.replaced), len(want), commissions.replaced)
+	}
+	for _, c := range commissions.replaced {
+		w, ok := want[c.IncomeInvoiceIdNumber]
+		if !ok {
+			t.Errorf("unexpected commission on income %d", c.IncomeInvoiceIdNumber)
+			continue
+		}
+		if c.Revenue != w[0] || c.Amount != w[1] {
+			t.Errorf("income %d: revenue %s commission %s, want %s and %s", c.IncomeInvoiceIdNumber, c.Revenue, c.Amount, w[0], w[1])
+		}
+	}
+}
+
+type fakeCommissionEngine struct {
+	months []string
+	fail   map[string]bool
+}
+
+func (f *fakeCommissionEngine) RecalculateMonth(ctx context.Context, salePersonId int, month time.Time) error {
+	key := month.Format("2006-01")
+	f.months = append(f.months, key)
+	if f.fail[key] {
+		return errors.New("database is down")
+	}
+	return nil
+}
+
+func TestRecalculateCommissions(t *testing.T) {
+	paid := func(month time.Month) entities.Income {
+		return entities.Income{SalePersonId: 1, ReceiptIssueDate: day(2026, month, 15)}
+	}
+	unpaid := entities.Income{SalePersonId: 1, ReceiptIssueDate: day(2026, 8, 1), UnpaidPaymentAmount: money.FromInt(1)}
+
+	engine := &fakeCommissionEngine{}
+	if err := recalculateCommissions(context.Background(), engine, paid(9), paid(9), unpaid, paid(10)); err != nil {
+		t.Fatal(err)
+	}
+	if len(engine.months) != 2 || engine.months[0] != "2026-09" || engine.months[1] != "2026-10" {
+		t.Errorf("recalculated %v, want each paid month once", engine.months)
+	}
+
+	engine = &fakeCommissionEngine{fail: map[string]bool{"2026-09": true}}
+	err := recalculateCommissions(context.Background(), engine, paid(9), paid(10))
+	if err == nil {
+		t.Fatal("a failed recalculation was not reported")
+	}
+	if len(engine.months) != 2 {
+		t.Errorf("recalculated %v, want the months after a failure too", engine.months)
+	}
+}
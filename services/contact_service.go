@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+)
+
+type ContactService interface {
+	GetAllContact(ctx context.Context, query dtos.ContactQuery) ([]dtos.Contact, error)
+	GetContactById(ctx context.Context, contactId int) (dtos.Contact, error)
+	CreateContact(ctx context.Context, req dtos.CreateContactRequest) (dtos.ContactResponse, error)
+	UpdateContact(ctx context.Context, contactId int, req dtos.UpdateContactRequest) (dtos.ContactResponse, error)
+	DeleteContact(ctx context.Context, contactId int) error
+}
+
+type contactService struct {
+	contactRepository repositories.ContactRepository
+}
+
+func NewContactService(
+	contactRepository repositories.ContactRepository,
+) ContactService {
+	return &contactService{
+		contactRepository: contactRepository,
+	}
+}
+
+func (s *contactService) GetAllContact(ctx context.Context, query dtos.ContactQuery) ([]dtos.Contact, error) {
+	ctx, span := telemetry.Start(ctx, "ContactService.GetAllContact")
+	defer span.End()
+
+	contacts, err := s.contactRepository.GetAllContact(ctx, query.AgencyId)
+	if err != nil {
+		return []dtos.Contact{}, wrapError(err, "failed to get contact")
+	}
+
+	var contactDTOs []dtos.Contact
+	for _, c := range contacts {
+		contactDTOs = append(contactDTOs, toContactDTO(c))
+	}
+
+	if len(contactDTOs) == 0 {
+		return []dtos.Contact{}, nil
+	}
+
+	return contactDTOs, nil
+}
+
+func (s *contactService) GetContactById(ctx context.Context, contactId int) (dtos.Contact, error) {
+	ctx, span := telemetry.Start(ctx, "ContactService.GetContactById")
+	defer span.End()
+
+	contact, err := s.contactRepository.GetContactById(ctx, contactId)
+	if err != nil {
+		return dtos.Contact{}, wrapError(err, "failed to get contact")
+	}
+
+	return toContactDTO(contact), nil
+}
+
+func (s *contactService) CreateContact(ctx context.Context, req dtos.CreateContactRequest) (dtos.ContactResponse, error) {
+	ctx, span := telemetry.Start(ctx, "ContactService.CreateContact")
+	defer span.End()
+
+	data := entities.Contact{
+		AgencyId:    req.AgencyId,
+		Name:        req.Name,
+		PhoneNumber: req.PhoneNumber,
+		Line:        req.Line,
+		Email:       req.Email,
+	}
+
+	contact, err := s.contactRepository.CreateContact(ctx, data)
+	if err != nil {
+		return dtos.ContactResponse{}, wrapError(err, "failed to save contact")
+	}
+
+	return dtos.ContactResponse{
+		Id: contact.Id,
+	}, nil
+}
+
+func (s *contactService) UpdateContact(ctx context.Context, contactId int, req dtos.UpdateContactRequest) (dtos.ContactResponse, error) {
+	ctx, span := telemetry.Start(ctx, "ContactService.UpdateContact")
+	defer span.End()
+
+	contact, err := s.contactRepository.GetContactById(ctx, contactId)
+	if err != nil {
+		return dtos.ContactResponse{}, wrapError(err, "failed to get contact")
+	}
+
+	data := entities.Contact{
+		Id:          contactId,
+		AgencyId:    helpers.DefaultIfEmpty(req.AgencyId, contact.AgencyId),
+		Name:        helpers.DefaultIfEmpty(req.Name, contact.Name),
+		PhoneNumber: helpers.DefaultIfEmpty(req.PhoneNumber, contact.PhoneNumber),
+		Line:        helpers.DefaultIfEmpty(req.Line, contact.Line),
+		Email:       helpers.DefaultIfEmpty(req.Email, contact.Email),
+	}
+
+	updatedContact, err := s.contactRepository.UpdateContact(ctx, data)
+	if err != nil {
+		return dtos.ContactResponse{}, wrapError(err, "failed to save contact")
+	}
+
+	return dtos.ContactResponse{
+		Id: updatedContact.Id,
+	}, nil
+}
+
+func (s *contactService) DeleteContact(ctx context.Context, contactId int) error {
+	ctx, span := telemetry.Start(ctx, "ContactService.DeleteContact")
+	defer span.End()
+
+	contact, err := s.contactRepository.GetContactById(ctx, contactId)
+	if err != nil {
+		return wrapError(err, "failed to get contact")
+	}
+
+	err = s.contactRepository.DeleteContact(ctx, contact.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete contact")
+	}
+
+	return nil
+}
+
+func toContactDTO(c entities.Contact) dtos.Contact {
+	return dtos.Contact{
+		Id:          c.Id,
+		AgencyId:    c.AgencyId,
+		Name:        c.Name,
+		PhoneNumber: c.PhoneNumber,
+		Line:        c.Line,
+		Email:       c.Email,
+	}
+}
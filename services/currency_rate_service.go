@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"sort"
+	"time"
+)
+
+type CurrencyRateService interface {
+	GetAllCurrencyRate(ctx context.Context, currency string) ([]dtos.CurrencyRate, error)
+	GetCurrencyRateById(ctx context.Context, currencyRateId int) (dtos.CurrencyRate, error)
+	CreateCurrencyRate(ctx context.Context, req dtos.CreateCurrencyRateRequest) (dtos.CurrencyRateResponse, error)
+	UpdateCurrencyRate(ctx context.Context, currencyRateId int, req dtos.UpdateCurrencyRateRequest) (dtos.CurrencyRateResponse, error)
+	DeleteCurrencyRate(ctx context.Context, currencyRateId int) error
+}
+
+type currencyRateService struct {
+	currencyRateRepository repositories.CurrencyRateRepository
+}
+
+func NewCurrencyRateService(
+	currencyRateRepository repositories.CurrencyRateRepository,
+) CurrencyRateService {
+	return &currencyRateService{
+		currencyRateRepository: currencyRateRepository,
+	}
+}
+
+func (s *currencyRateService) GetAllCurrencyRate(ctx context.Context, currency string) ([]dtos.CurrencyRate, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateService.GetAllCurrencyRate")
+	defer span.End()
+
+	currencyRates, err := s.currencyRateRepository.GetAllCurrencyRate(ctx, currency)
+	if err != nil {
+		return []dtos.CurrencyRate{}, wrapError(err, "failed to get the currency rate")
+	}
+
+	var currencyRateDTOs []dtos.CurrencyRate
+	for _, r := range currencyRates {
+		currencyRateDTOs = append(currencyRateDTOs, toCurrencyRateDTO(r))
+	}
+
+	if len(currencyRateDTOs) == 0 {
+		return []dtos.CurrencyRate{}, nil
+	}
+
+	return currencyRateDTOs, nil
+}
+
+func (s *currencyRateService) GetCurrencyRateById(ctx context.Context, currencyRateId int) (dtos.CurrencyRate, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateService.GetCurrencyRateById")
+	defer span.End()
+
+	currencyRate, err := s.currencyRateRepository.GetCurrencyRateById(ctx, currencyRateId)
+	if err != nil {
+		return dtos.CurrencyRate{}, wrapError(err, "failed to get the currency rate")
+	}
+
+	return toCurrencyRateDTO(currencyRate), nil
+}
+
+func (s *currencyRateService) CreateCurrencyRate(ctx context.Context, req dtos.CreateCurrencyRateRequest) (dtos.CurrencyRateResponse, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateService.CreateCurrencyRate")
+	defer span.End()
+
+	data := entities.CurrencyRate{
+		Date:     dateOf(req.Date),
+		Currency: req.Currency,
+		Rate:     req.Rate,
+	}
+
+	if err := validateRateCurrency(data.Currency); err != nil {
+		return dtos.CurrencyRateResponse{}, err
+	}
+
+	currencyRate, err := s.currencyRateRepository.CreateCurrencyRate(ctx, data)
+	if err != nil {
+		return dtos.CurrencyRateResponse{}, wrapError(err, "failed to save the currency rate")
+	}
+
+	return dtos.CurrencyRateResponse{
+		Id: currencyRate.Id,
+	}, nil
+}
+
+func (s *currencyRateService) UpdateCurrencyRate(ctx context.Context, currencyRateId int, req dtos.UpdateCurrencyRateRequest) (dtos.CurrencyRateResponse, error) {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateService.UpdateCurrencyRate")
+	defer span.End()
+
+	currencyRate, err := s.currencyRateRepository.GetCurrencyRateById(ctx, currencyRateId)
+	if err != nil {
+		return dtos.CurrencyRateResponse{}, wrapError(err, "failed to get the currency rate")
+	}
+
+	data := entities.CurrencyRate{
+		Id:       currencyRateId,
+		Date:     dateOf(helpers.DefaultIfEmpty(req.Date, currencyRate.Date)),
+		Currency: helpers.DefaultIfEmpty(req.Currency, currencyRate.Currency),
+		Rate:     helpers.DefaultIfEmpty(req.Rate, currencyRate.Rate),
+	}
+
+	if err := validateRateCurrency(data.Currency); err != nil {
+		return dtos.CurrencyRateResponse{}, err
+	}
+
+	updatedCurrencyRate, err := s.currencyRateRepository.UpdateCurrencyRate(ctx, data)
+	if err != nil {
+		return dtos.CurrencyRateResponse{}, wrapError(err, "failed to save the currency rate")
+	}
+
+	return dtos.CurrencyRateResponse{
+		Id: updatedCurrencyRate.Id,
+	}, nil
+}
+
+func (s *currencyRateService) DeleteCurrencyRate(ctx context.Context, currencyRateId int) error {
+	ctx, span := telemetry.Start(ctx, "CurrencyRateService.DeleteCurrencyRate")
+	defer span.End()
+
+	currencyRate, err := s.currencyRateRepository.GetCurrencyRateById(ctx, currencyRateId)
+	if err != nil {
+		return wrapError(err, "failed to get the currency rate")
+	}
+
+	err = s.currencyRateRepository.DeleteCurrencyRate(ctx, currencyRate.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete the currency rate")
+	}
+
+	return nil
+}
+
+func toCurrencyRateDTO(r entities.CurrencyRate) dtos.CurrencyRate {
+	return dtos.CurrencyRate{
+		Id:       r.Id,
+		Date:     r.Date,
+		Currency: r.Currency,
+		Rate:     r.Rate,
+	}
+}
+
+func validateRateCurrency(currency string) error {
+	if currency == money.BaseCurrency {
+		return NewValidationError("base_currency_rate", "the base currency does not need a rate", utils.FieldError{
+			Field:   "currency",
+			Rule:    "ne",
+			Message: "must not be " + money.BaseCurrency,
+		})
+	}
+	return nil
+}
+
+// dateOf drops the time of day, since a rate applies to a whole day.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// incomeRateTable loads the rates of every currency the incomes are in.
+func incomeRateTable(ctx context.Context, repository repositories.CurrencyRateRepository, incomes []entities.Income) (rateTable, error) {
+	var currencies []string
+	seen := map[string]bool{}
+	for _, i := range incomes {
+		if i.Currency != "" && i.Currency != money.BaseCurrency && !seen[i.Currency] {
+			seen[i.Currency] = true
+			currencies = append(currencies, i.Currency)
+		}
+	}
+
+	rates, err := repository.GetCurrencyRatesByCurrencies(ctx, currencies)
+	if err != nil {
+		return nil, wrapError(err, "failed to get the currency rate")
+	}
+	return newRateTable(rates), nil
+}
+
+// rateTable holds the rates of several currencies, each sorted by date, so a
+// list of incomes can be converted without a query per income.
+type rateTable map[string][]entities.CurrencyRate
+
+func newRateTable(rates []entities.CurrencyRate) rateTable {
+	table := rateTable{}
+	for _, r := range rates {
+		table[r.Currency] = append(table[r.Currency], r)
+	}
+	for _, rs := range table {
+		sort.Slice(rs, func(i, j int) bool { return rs[i].Date.Before(rs[j].Date) })
+	}
+	return table
+}
+
+// rateOn returns the latest rate of currency on or before date, and the date
+// it was set. The base currency always converts at one.
+func (t rateTable) rateOn(currency string, date time.Time) (money.Rate, time.Time, bool) {
+	if currency == "" || currency == money.BaseCurrency {
+		return money.RateOne, dateOf(date), true
+	}
+	rates := t[currency]
+	day := dateOf(date)
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].Date.After(day) })
+	if i == 0 {
+		return 0, time.Time{}, false
+	}
+	return rates[i-1].Rate, rates[i-1].Date, true
+}
@@ -7,106 +7,55 @@ import (
 	"mtii-backend/entities"
 	"mtii-backend/helpers"
 	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"slices"
 )
 
 type DetailService interface {
-	GetAllDetail(ctx context.Context) ([]dtos.Detail, error)
+	GetAllDetail(ctx context.Context, includeIncome bool) ([]dtos.Detail, error)
 	GetDetailById(ctx context.Context, detailId int) (dtos.Detail, error)
 	CreateDetail(ctx context.Context, req dtos.CreateDetailRequest) (dtos.DetailResponse, error)
 	UpdateDetail(ctx context.Context, detailId int, req dtos.UpdateDetailRequest) (dtos.DetailResponse, error)
 	DeleteDetail(ctx context.Context, detailId int) error
+
+	GetIncomeDetails(ctx context.Context, incomeInvoiceIdNumber int) (dtos.IncomeDetailList, error)
+	CreateIncomeDetail(ctx context.Context, incomeInvoiceIdNumber int, req dtos.CreateIncomeDetailRequest) (dtos.IncomeDetail, error)
+	UpdateIncomeDetail(ctx context.Context, incomeInvoiceIdNumber int, detailId int, req dtos.UpdateIncomeDetailRequest) (dtos.IncomeDetail, error)
+	DeleteIncomeDetail(ctx context.Context, incomeInvoiceIdNumber int, detailId int) error
+	ReorderIncomeDetails(ctx context.Context, incomeInvoiceIdNumber int, req dtos.ReorderIncomeDetailsRequest) (dtos.IncomeDetailList, error)
 }
 
 type detailService struct {
 	detailRepository repositories.DetailRepository
+	incomeRepository repositories.IncomeRepository
+	commissionEngine CommissionEngine
 }
 
 func NewDetailService(
 	detailRepository repositories.DetailRepository,
+	incomeRepository repositories.IncomeRepository,
+	commissionEngine CommissionEngine,
 ) DetailService {
 	return &detailService{
 		detailRepository: detailRepository,
+		incomeRepository: incomeRepository,
+		commissionEngine: commissionEngine,
 	}
 }
 
-func (s *detailService) GetAllDetail(ctx context.Context) ([]dtos.Detail, error) {
-	details, err := s.detailRepository.GetAllDetail(ctx)
+func (s *detailService) GetAllDetail(ctx context.Context, includeIncome bool) ([]dtos.Detail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailService.GetAllDetail")
+	defer span.End()
+
+	details, err := s.detailRepository.GetAllDetail(ctx, includeIncome)
 	if err != nil {
-		return []dtos.Detail{}, fmt.Errorf("failed to get detail: %w", err)
+		return []dtos.Detail{}, wrapError(err, "failed to get detail")
 	}
 
 	var detailDTOs []dtos.Detail
 	for _, d := range details {
-		detailDTOs = append(detailDTOs, dtos.Detail{
-			Id:          d.Id,
-			Description: d.Description,
-			Notes:       d.Notes,
-			Quantity:    d.Quantity,
-			UnitPrice:   d.UnitPrice,
-			Income: dtos.Income{
-				QuotationIdNumber:          d.Income.QuotationIdNumber,
-				QuotationIssueDate:         d.Income.QuotationIssueDate,
-				QuotationDueDate:           d.Income.QuotationDueDate,
-				InvoiceIdNumber:            d.Income.InvoiceIdNumber,
-				InvoiceIssueDate:           d.Income.InvoiceIssueDate,
-				InvoiceDueDate:             d.Income.InvoiceDueDate,
-				ReceiptIssueDate:           d.Income.ReceiptIssueDate,
-				ReceiptIdNumber:            d.Income.ReceiptIdNumber,
-				AgencyTaxPayerIdNumber:     d.Income.AgencyTaxPayerIdNumber,
-				InfluencerPostingDate:      d.Income.InfluencerPostingDate,
-				AgencyAgencyName:           d.Income.AgencyAgencyName,
-				AgencyAddress:              d.Income.AgencyAddress,
-				AgencyPhoneNumber:          d.Income.AgencyPhoneNumber,
-				ContactorContactorName:     d.Income.ContactorContactorName,
-				ContactorPhoneNumber:       d.Income.ContactorPhoneNumber,
-				ContactorLine:              d.Income.ContactorLine,
-				ContactorEmail:             d.Income.ContactorEmail,
-				BrandBrandName:             d.Income.BrandBrandName,
-				BrandProduct:               d.Income.BrandProduct,
-				TransactionReferenceNumber: d.Income.TransactionReferenceNumber,
-				TermsAndConditions:         d.Income.TermsAndConditions,
-				TotalPaymentAmount:         d.Income.TotalPaymentAmount,
-				NotesForTheTotalPayment:    d.Income.NotesForTheTotalPayment,
-				FirstPayment:               d.Income.FirstPayment,
-				NotesForTheFirstPayment:    d.Income.NotesForTheFirstPayment,
-				SecondPayment:              d.Income.SecondPayment,
-				NotesForTheSecondPayment:   d.Income.NotesForTheSecondPayment,
-				UnpaidPaymentAmount:        d.Income.UnpaidPaymentAmount,
-				NotesForTheUnpaidPayment:   d.Income.NotesForTheUnpaidPayment,
-				Platform: dtos.Platform{
-					Id:   d.Income.Platform.Id,
-					Name: d.Income.Platform.Name,
-				},
-				Status: dtos.Status{
-					Id:   d.Income.Status.Id,
-					Name: d.Income.Status.Name,
-				},
-				PaymentMethod: dtos.PaymentMethod{
-					Id:   d.Income.PaymentMethod.Id,
-					Name: d.Income.PaymentMethod.Name,
-				},
-				Receiver: dtos.Receiver{
-					Id:         d.Income.Receiver.Id,
-					Name:       d.Income.Receiver.Name,
-					Address:    d.Income.Receiver.Address,
-					Email:      d.Income.Receiver.Email,
-					Phone:      d.Income.Receiver.Phone,
-					TaxPayerId: d.Income.Receiver.TaxPayerId,
-				},
-				SalePerson: dtos.SalePerson{
-					Id:   d.Income.SalePerson.Id,
-					Name: d.Income.SalePerson.Name,
-				},
-				Channel: dtos.Channel{
-					Id:   d.Income.Channel.Id,
-					Name: d.Income.Channel.Name,
-				},
-				Bank: dtos.Bank{
-					Id:   d.Income.Bank.Id,
-					Name: d.Income.Bank.Name,
-				},
-			},
-		})
+		detailDTOs = append(detailDTOs, toDetailDTO(d, includeIncome))
 	}
 
 	if len(detailDTOs) == 0 {
@@ -117,95 +66,50 @@ func (s *detailService) GetAllDetail(ctx context.Context) ([]dtos.Detail, error)
 }
 
 func (s *detailService) GetDetailById(ctx context.Context, detailId int) (dtos.Detail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailService.GetDetailById")
+	defer span.End()
+
 	detail, err := s.detailRepository.GetDetailById(ctx, detailId)
 	if err != nil {
-		return dtos.Detail{}, fmt.Errorf("failed to get detail: %w", err)
-	}
-
-	return dtos.Detail{
-		Id:          detail.Id,
-		Description: detail.Description,
-		Notes:       detail.Notes,
-		Quantity:    detail.Quantity,
-		UnitPrice:   detail.UnitPrice,
-		Income: dtos.Income{
-			QuotationIdNumber:          detail.Income.QuotationIdNumber,
-			QuotationIssueDate:         detail.Income.QuotationIssueDate,
-			QuotationDueDate:           detail.Income.QuotationDueDate,
-			InvoiceIdNumber:            detail.Income.InvoiceIdNumber,
-			InvoiceIssueDate:           detail.Income.InvoiceIssueDate,
-			InvoiceDueDate:             detail.Income.InvoiceDueDate,
-			ReceiptIssueDate:           detail.Income.ReceiptIssueDate,
-			ReceiptIdNumber:            detail.Income.ReceiptIdNumber,
-			AgencyTaxPayerIdNumber:     detail.Income.AgencyTaxPayerIdNumber,
-			InfluencerPostingDate:      detail.Income.InfluencerPostingDate,
-			AgencyAgencyName:           detail.Income.AgencyAgencyName,
-			AgencyAddress:              detail.Income.AgencyAddress,
-			AgencyPhoneNumber:          detail.Income.AgencyPhoneNumber,
-			ContactorContactorName:     detail.Income.ContactorContactorName,
-			ContactorPhoneNumber:       detail.Income.ContactorPhoneNumber,
-			ContactorLine:              detail.Income.ContactorLine,
-			ContactorEmail:             detail.Income.ContactorEmail,
-			BrandBrandName:             detail.Income.BrandBrandName,
-			BrandProduct:               detail.Income.BrandProduct,
-			TransactionReferenceNumber: detail.Income.TransactionReferenceNumber,
-			TermsAndConditions:         detail.Income.TermsAndConditions,
-			TotalPaymentAmount:         detail.Income.TotalPaymentAmount,
-			NotesForTheTotalPayment:    detail.Income.NotesForTheTotalPayment,
-			FirstPayment:               detail.Income.FirstPayment,
-			NotesForTheFirstPayment:    detail.Income.NotesForTheFirstPayment,
-			SecondPayment:              detail.Income.SecondPayment,
-			NotesForTheSecondPayment:   detail.Income.NotesForTheSecondPayment,
-			UnpaidPaymentAmount:        detail.Income.UnpaidPaymentAmount,
-			NotesForTheUnpaidPayment:   detail.Income.NotesForTheUnpaidPayment,
-			Platform: dtos.Platform{
-				Id:   detail.Income.Platform.Id,
-				Name: detail.Income.Platform.Name,
-			},
-			Status: dtos.Status{
-				Id:   detail.Income.Status.Id,
-				Name: detail.Income.Status.Name,
-			},
-			PaymentMethod: dtos.PaymentMethod{
-				Id:   detail.Income.PaymentMethod.Id,
-				Name: detail.Income.PaymentMethod.Name,
-			},
-			Receiver: dtos.Receiver{
-				Id:         detail.Income.Receiver.Id,
-				Name:       detail.Income.Receiver.Name,
-				Address:    detail.Income.Receiver.Address,
-				Email:      detail.Income.Receiver.Email,
-				Phone:      detail.Income.Receiver.Phone,
-				TaxPayerId: detail.Income.Receiver.TaxPayerId,
-			},
-			SalePerson: dtos.SalePerson{
-				Id:   detail.Income.SalePerson.Id,
-				Name: detail.Income.SalePerson.Name,
-			},
-			Channel: dtos.Channel{
-				Id:   detail.Income.Channel.Id,
-				Name: detail.Income.Channel.Name,
-			},
-			Bank: dtos.Bank{
-				Id:   detail.Income.Bank.Id,
-				Name: detail.Income.Bank.Name,
-			},
-		},
-	}, nil
+		return dtos.Detail{}, wrapError(err, "failed to get detail")
+	}
+
+	return toDetailDTO(detail, true), nil
 }
 
 func (s *detailService) CreateDetail(ctx context.Context, req dtos.CreateDetailRequest) (dtos.DetailResponse, error) {
+	ctx, span := telemetry.Start(ctx, "DetailService.CreateDetail")
+	defer span.End()
+
+	position, err := s.detailRepository.GetMaxDetailPosition(ctx, req.IncomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.DetailResponse{}, wrapError(err, "failed to get detail")
+	}
+
 	data := entities.Detail{
 		Description:           req.Description,
 		Notes:                 req.Notes,
 		Quantity:              req.Quantity,
+		Unit:                  req.Unit,
 		UnitPrice:             req.UnitPrice,
+		Category:              req.Category,
+		DiscountType:          req.DiscountType,
+		DiscountValue:         req.DiscountValue,
+		VatExempt:             req.VatExempt,
+		Position:              position + 1,
 		IncomeInvoiceIdNumber: req.IncomeInvoiceIdNumber,
 	}
 
-	detail, err := s.detailRepository.CreateDetail(ctx, data)
+	if err := validateDiscount("", data.DiscountType, data.DiscountValue, lineTotal(data)); err != nil {
+		return dtos.DetailResponse{}, err
+	}
+
+	detail, err := s.detailRepository.CreateDetail(ctx, data, withholdingTax)
 	if err != nil {
-		return dtos.DetailResponse{}, fmt.Errorf("failed to save detail: %w", err)
+		return dtos.DetailResponse{}, wrapError(err, "failed to save detail")
+	}
+	if err := s.recalculateCommissions(ctx, detail.IncomeInvoiceIdNumber); err != nil {
+		return dtos.DetailResponse{}, err
 	}
 
 	return dtos.DetailResponse{
@@ -214,9 +118,12 @@ func (s *detailService) CreateDetail(ctx context.Context, req dtos.CreateDetailR
 }
 
 func (s *detailService) UpdateDetail(ctx context.Context, detailId int, req dtos.UpdateDetailRequest) (dtos.DetailResponse, error) {
+	ctx, span := telemetry.Start(ctx, "DetailService.UpdateDetail")
+	defer span.End()
+
 	detail, err := s.detailRepository.GetDetailById(ctx, detailId)
 	if err != nil {
-		return dtos.DetailResponse{}, fmt.Errorf("failed to get detail: %w", err)
+		return dtos.DetailResponse{}, wrapError(err, "failed to get detail")
 	}
 
 	data := entities.Detail{
@@ -224,13 +131,32 @@ func (s *detailService) UpdateDetail(ctx context.Context, detailId int, req dtos
 		Description:           helpers.DefaultIfEmpty(req.Description, detail.Description),
 		Notes:                 helpers.DefaultIfEmpty(req.Notes, detail.Notes),
 		Quantity:              helpers.DefaultIfEmpty(req.Quantity, detail.Quantity),
+		Unit:                  helpers.DefaultIfEmpty(req.Unit, detail.Unit),
 		UnitPrice:             helpers.DefaultIfEmpty(req.UnitPrice, detail.UnitPrice),
+		Category:              helpers.DefaultIfEmpty(req.Category, detail.Category),
+		DiscountType:          normalizeDiscountType(helpers.DefaultIfEmpty(req.DiscountType, detail.DiscountType)),
+		DiscountValue:         helpers.DefaultIfEmpty(req.DiscountValue, detail.DiscountValue),
+		VatExempt:             detail.VatExempt,
+		Position:              detail.Position,
 		IncomeInvoiceIdNumber: helpers.DefaultIfEmpty(req.IncomeInvoiceIdNumber, detail.IncomeInvoiceIdNumber),
 	}
+	if req.VatExempt != nil {
+		data.VatExempt = *req.VatExempt
+	}
+	if data.DiscountType == "" {
+		data.DiscountValue = 0
+	}
+
+	if err := validateDiscount("", data.DiscountType, data.DiscountValue, lineTotal(data)); err != nil {
+		return dtos.DetailResponse{}, err
+	}
 
-	updatedDetail, err := s.detailRepository.UpdateDetail(ctx, data)
+	updatedDetail, err := s.detailRepository.UpdateDetail(ctx, data, withholdingTax)
 	if err != nil {
-		return dtos.DetailResponse{}, fmt.Errorf("failed to save detail: %w", err)
+		return dtos.DetailResponse{}, wrapError(err, "failed to save detail")
+	}
+	if err := s.recalculateCommissions(ctx, detail.IncomeInvoiceIdNumber, data.IncomeInvoiceIdNumber); err != nil {
+		return dtos.DetailResponse{}, err
 	}
 
 	return dtos.DetailResponse{
@@ -239,15 +165,253 @@ func (s *detailService) UpdateDetail(ctx context.Context, detailId int, req dtos
 }
 
 func (s *detailService) DeleteDetail(ctx context.Context, detailId int) error {
+	ctx, span := telemetry.Start(ctx, "DetailService.DeleteDetail")
+	defer span.End()
+
 	detail, err := s.detailRepository.GetDetailById(ctx, detailId)
 	if err != nil {
-		return fmt.Errorf("failed to get detail: %w", err)
+		return wrapError(err, "failed to get detail")
+	}
+
+	err = s.detailRepository.DeleteDetail(ctx, detail.Id, withholdingTax)
+	if err != nil {
+		return wrapError(err, "failed to delete detail")
+	}
+
+	return s.recalculateCommissions(ctx, detail.IncomeInvoiceIdNumber)
+}
+
+func (s *detailService) GetIncomeDetails(ctx context.Context, incomeInvoiceIdNumber int) (dtos.IncomeDetailList, error) {
+	ctx, span := telemetry.Start(ctx, "DetailService.GetIncomeDetails")
+	defer span.End()
+
+	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.IncomeDetailList{}, wrapError(err, "failed to get income")
+	}
+
+	details, err := s.detailRepository.GetDetailsByIncomeInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.IncomeDetailList{}, wrapError(err, "failed to get detail")
+	}
+
+	return toIncomeDetailList(income, details), nil
+}
+
+func (s *detailService) CreateIncomeDetail(ctx context.Context, incomeInvoiceIdNumber int, req dtos.CreateIncomeDetailRequest) (dtos.IncomeDetail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailService.CreateIncomeDetail")
+	defer span.End()
+
+	if _, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber); err != nil {
+		return dtos.IncomeDetail{}, wrapError(err, "failed to get income")
+	}
+
+	position, err := s.detailRepository.GetMaxDetailPosition(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.IncomeDetail{}, wrapError(err, "failed to get detail")
+	}
+
+	data := entities.Detail{
+		Description:           req.Description,
+		Notes:                 req.Notes,
+		Quantity:              req.Quantity,
+		Unit:                  req.Unit,
+		UnitPrice:             req.UnitPrice,
+		Category:              req.Category,
+		DiscountType:          req.DiscountType,
+		DiscountValue:         req.DiscountValue,
+		VatExempt:             req.VatExempt,
+		Position:              position + 1,
+		IncomeInvoiceIdNumber: incomeInvoiceIdNumber,
+	}
+
+	if err := validateDiscount("", data.DiscountType, data.DiscountValue, lineTotal(data)); err != nil {
+		return dtos.IncomeDetail{}, err
+	}
+
+	detail, err := s.detailRepository.CreateDetail(ctx, data, withholdingTax)
+	if err != nil {
+		return dtos.IncomeDetail{}, wrapError(err, "failed to save detail")
+	}
+	if err := s.recalculateCommissions(ctx, incomeInvoiceIdNumber); err != nil {
+		return dtos.IncomeDetail{}, err
+	}
+
+	return toIncomeDetailDTO(detail), nil
+}
+
+func (s *detailService) UpdateIncomeDetail(ctx context.Context, incomeInvoiceIdNumber int, detailId int, req dtos.UpdateIncomeDetailRequest) (dtos.IncomeDetail, error) {
+	ctx, span := telemetry.Start(ctx, "DetailService.UpdateIncomeDetail")
+	defer span.End()
+
+	detail, err := s.getIncomeDetail(ctx, incomeInvoiceIdNumber, detailId)
+	if err != nil {
+		return dtos.IncomeDetail{}, err
+	}
+
+	data := entities.Detail{
+		Id:                    detail.Id,
+		Description:           helpers.DefaultIfEmpty(req.Description, detail.Description),
+		Notes:                 helpers.DefaultIfEmpty(req.Notes, detail.Notes),
+		Quantity:              helpers.DefaultIfEmpty(req.Quantity, detail.Quantity),
+		Unit:                  helpers.DefaultIfEmpty(req.Unit, detail.Unit),
+		UnitPrice:             helpers.DefaultIfEmpty(req.UnitPrice, detail.UnitPrice),
+		Category:              helpers.DefaultIfEmpty(req.Category, detail.Category),
+		DiscountType:          normalizeDiscountType(helpers.DefaultIfEmpty(req.DiscountType, detail.DiscountType)),
+		DiscountValue:         helpers.DefaultIfEmpty(req.DiscountValue, detail.DiscountValue),
+		VatExempt:             detail.VatExempt,
+		Position:              detail.Position,
+		IncomeInvoiceIdNumber: detail.IncomeInvoiceIdNumber,
+	}
+	if req.VatExempt != nil {
+		data.VatExempt = *req.VatExempt
+	}
+	if data.DiscountType == "" {
+		data.DiscountValue = 0
+	}
+
+	if err := validateDiscount("", data.DiscountType, data.DiscountValue, lineTotal(data)); err != nil {
+		return dtos.IncomeDetail{}, err
+	}
+
+	updatedDetail, err := s.detailRepository.UpdateDetail(ctx, data, withholdingTax)
+	if err != nil {
+		return dtos.IncomeDetail{}, wrapError(err, "failed to save detail")
 	}
+	if err := s.recalculateCommissions(ctx, incomeInvoiceIdNumber); err != nil {
+		return dtos.IncomeDetail{}, err
+	}
+
+	return toIncomeDetailDTO(updatedDetail), nil
+}
+
+func (s *detailService) DeleteIncomeDetail(ctx context.Context, incomeInvoiceIdNumber int, detailId int) error {
+	ctx, span := telemetry.Start(ctx, "DetailService.DeleteIncomeDetail")
+	defer span.End()
 
-	err = s.detailRepository.DeleteDetail(ctx, detail.Id)
+	detail, err := s.getIncomeDetail(ctx, incomeInvoiceIdNumber, detailId)
 	if err != nil {
-		return fmt.Errorf("failed to delete detail: %w", err)
+		return err
 	}
 
-	return nil
+	err = s.detailRepository.DeleteDetail(ctx, detail.Id, withholdingTax)
+	if err != nil {
+		return wrapError(err, "failed to delete detail")
+	}
+
+	return s.recalculateCommissions(ctx, detail.IncomeInvoiceIdNumber)
+}
+
+func (s *detailService) ReorderIncomeDetails(ctx context.Context, incomeInvoiceIdNumber int, req dtos.ReorderIncomeDetailsRequest) (dtos.IncomeDetailList, error) {
+	ctx, span := telemetry.Start(ctx, "DetailService.ReorderIncomeDetails")
+	defer span.End()
+
+	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.IncomeDetailList{}, wrapError(err, "failed to get income")
+	}
+
+	details, err := s.detailRepository.GetDetailsByIncomeInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.IncomeDetailList{}, wrapError(err, "failed to get detail")
+	}
+
+	// The new order must name every line of the income exactly once.
+	remaining := map[int]bool{}
+	for _, d := range details {
+		remaining[d.Id] = true
+	}
+	for i, detailId := range req.DetailIds {
+		if !remaining[detailId] {
+			return dtos.IncomeDetailList{}, NewValidationError("invalid_detail_order", "detail ids must list every line of the income once", utils.FieldError{
+				Field:   fmt.Sprintf("detail_ids[%d]", i),
+				Rule:    "exists",
+				Message: fmt.Sprintf("detail %d does not belong to this income or is listed twice", detailId),
+			})
+		}
+		delete(remaining, detailId)
+	}
+	if len(remaining) > 0 {
+		return dtos.IncomeDetailList{}, NewValidationError("invalid_detail_order", "detail ids must list every line of the income once", utils.FieldError{
+			Field:   "detail_ids",
+			Rule:    "complete",
+			Message: fmt.Sprintf("%d line(s) of the income are missing", len(remaining)),
+		})
+	}
+
+	if err := s.detailRepository.ReorderDetails(ctx, incomeInvoiceIdNumber, req.DetailIds); err != nil {
+		return dtos.IncomeDetailList{}, wrapError(err, "failed to reorder detail")
+	}
+	if err := recalculateCommissions(ctx, s.commissionEngine, income); err != nil {
+		return dtos.IncomeDetailList{}, err
+	}
+
+	details, err = s.detailRepository.GetDetailsByIncomeInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.IncomeDetailList{}, wrapError(err, "failed to get detail")
+	}
+
+	return toIncomeDetailList(income, details), nil
+}
+
+// recalculateCommissions refreshes the commissions of the incomes whose
+// lines changed, the revenue they pay commission on being the lines' total.
+func (s *detailService) recalculateCommissions(ctx context.Context, incomeInvoiceIdNumbers ...int) error {
+	var incomes []entities.Income
+	for n, number := range incomeInvoiceIdNumbers {
+		if slices.Contains(incomeInvoiceIdNumbers[:n], number) {
+			continue
+		}
+		income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, number)
+		if err != nil {
+			return wrapError(err, "failed to get income")
+		}
+		incomes = append(incomes, income)
+	}
+	return recalculateCommissions(ctx, s.commissionEngine, incomes...)
+}
+
+// getIncomeDetail loads a line and checks it belongs to the given income.
+func (s *detailService) getIncomeDetail(ctx context.Context, incomeInvoiceIdNumber int, detailId int) (entities.Detail, error) {
+	detail, err := s.detailRepository.GetDetailById(ctx, detailId)
+	if err != nil {
+		return entities.Detail{}, wrapError(err, "failed to get detail")
+	}
+	if detail.IncomeInvoiceIdNumber != incomeInvoiceIdNumber {
+		return entities.Detail{}, NewNotFoundError("not_found", "detail does not belong to this income")
+	}
+	return detail, nil
+}
+
+func toDetailDTO(d entities.Detail, includeIncome bool) dtos.Detail {
+	detail := dtos.Detail{
+		Id:                    d.Id,
+		Description:           d.Description,
+		Notes:                 d.Notes,
+		Quantity:              d.Quantity,
+		Unit:                  d.Unit,
+		UnitPrice:             d.UnitPrice,
+		Category:              d.Category,
+		DiscountType:          d.DiscountType,
+		DiscountValue:         d.DiscountValue,
+		VatExempt:             d.VatExempt,
+		Position:              d.Position,
+		LineTotal:             lineTotal(d),
+		DiscountAmount:        lineTotal(d) - lineNet(d),
+		NetAmount:             lineNet(d),
+		IncomeInvoiceIdNumber: d.IncomeInvoiceIdNumber,
+	}
+	if includeIncome {
+		income := toIncomeDTO(d.Income)
+		detail.Income = &income
+	}
+	return detail
+}
+
+func toIncomeDetailList(income entities.Income, details []entities.Detail) dtos.IncomeDetailList {
+	return dtos.IncomeDetailList{
+		InvoiceIdNumber: income.InvoiceIdNumber,
+		Details:         toIncomeDetailDTOs(details),
+		IncomeTotals:    incomeTotals(income.DiscountType, income.DiscountValue, details),
+	}
 }
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/repositories"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeDetailRepository keeps the withholding tax of the incomes in incomes
+// up to date with their lines, as the repository does.
+type fakeDetailRepository struct {
+	repositories.DetailRepository
+	details map[int]entities.Detail
+	nextId  int
+	incomes *fakeIncomeRepository
+}
+
+func (f *fakeDetailRepository) saveWithholdingTax(incomeInvoiceIdNumber int, withholdingTax repositories.WithholdingTaxFunc) {
+	income, ok := f.incomes.existing[incomeInvoiceIdNumber]
+	if !ok {
+		return
+	}
+	income.Details, _ = f.GetDetailsByIncomeInvoiceIdNumber(context.Background(), incomeInvoiceIdNumber)
+	income.WithholdingTaxAmount = withholdingTax(income, income.Details)
+	f.incomes.existing[incomeInvoiceIdNumber] = income
+}
+
+func (f *fakeDetailRepository) GetDetailById(ctx context.Context, detailId int) (entities.Detail, error) {
+	if detail, ok := f.details[detailId]; ok {
+		return detail, nil
+	}
+	return entities.Detail{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeDetailRepository) GetDetailsByIncomeInvoiceIdNumber(ctx context.Context, incomeInvoiceIdNumber int) ([]entities.Detail, error) {
+	var details []entities.Detail
+	for _, d := range f.details {
+		if d.IncomeInvoiceIdNumber == incomeInvoiceIdNumber {
+			details = append(details, d)
+		}
+	}
+	return details, nil
+}
+
+func (f *fakeDetailRepository) GetMaxDetailPosition(ctx context.Context, incomeInvoiceIdNumber int) (int, error) {
+	return len(f.details), nil
+}
+
+func (f *fakeDetailRepository) CreateDetail(ctx context.Context, detail entities.Detail, withholdingTax repositories.WithholdingTaxFunc) (entities.Detail, error) {
+	f.nextId++
+	detail.Id = f.nextId
+	f.details[detail.Id] = detail
+	f.saveWithholdingTax(detail.IncomeInvoiceIdNumber, withholdingTax)
+	return detail, nil
+}
+
+func (f *fakeDetailRepository) UpdateDetail(ctx context.Context, detail entities.Detail, withholdingTax repositories.WithholdingTaxFunc) (entities.Detail, error) {
+	old := f.details[detail.Id]
+	f.details[detail.Id] = detail
+	f.saveWithholdingTax(old.IncomeInvoiceIdNumber, withholdingTax)
+	f.saveWithholdingTax(detail.IncomeInvoiceIdNumber, withholdingTax)
+	return detail, nil
+}
+
+func (f *fakeDetailRepository) DeleteDetail(ctx context.Context, detailId int, withholdingTax repositories.WithholdingTaxFunc) error {
+	old := f.details[detailId]
+	delete(f.details, detailId)
+	f.saveWithholdingTax(old.IncomeInvoiceIdNumber, withholdingTax)
+	return nil
+}
+
+func (f *fakeDetailRepository) ReorderDetails(ctx context.Context, incomeInvoiceIdNumber int, detailIds []int) error {
+	return nil
+}
+
+// TestLineEditsRecalculateCommissions edits the lines of an income paid in
+// March and one paid in April; each edit must refresh the months of the
+// incomes it touches.
+func TestLineEditsRecalculateCommissions(t *testing.T) {
+	ctx := context.Background()
+	paid := func(number int, month time.Month) entities.Income {
+		return entities.Income{InvoiceIdNumber: number, SalePersonId: 7, ReceiptIssueDate: time.Date(2024, month, 10, 0, 0, 0, 0, time.UTC)}
+	}
+	engine := &fakeCommissionEngine{}
+	incomes := &fakeIncomeRepository{existing: map[int]entities.Income{1: paid(1, time.March), 2: paid(2, time.April)}}
+	service := &detailService{
+		detailRepository: &fakeDetailRepository{details: map[int]entities.Detail{}, incomes: incomes},
+		incomeRepository: incomes,
+		commissionEngine: engine,
+	}
+
+	check := func(name string, err error, want ...string) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !slices.Equal(engine.months, want) {
+			t.Errorf("%s recalculated %v, want %v", name, engine.months, want)
+		}
+		engine.months = nil
+	}
+
+	line := dtos.CreateIncomeDetailRequest{Description: "Post", Quantity: 1, UnitPrice: amount(t, "1000")}
+	created, err := service.CreateIncomeDetail(ctx, 1, line)
+	check("CreateIncomeDetail", err, "2024-03")
+
+	_, err = service.UpdateIncomeDetail(ctx, 1, created.Id, dtos.UpdateIncomeDetailRequest{UnitPrice: amount(t, "2000")})
+	check("UpdateIncomeDetail", err, "2024-03")
+
+	_, err = service.ReorderIncomeDetails(ctx, 1, dtos.ReorderIncomeDetailsRequest{DetailIds: []int{created.Id}})
+	check("ReorderIncomeDetails", err, "2024-03")
+
+	_, err = service.UpdateDetail(ctx, created.Id, dtos.UpdateDetailRequest{IncomeInvoiceIdNumber: 2})
+	check("UpdateDetail moving the line", err, "2024-03", "2024-04")
+
+	err = service.DeleteIncomeDetail(ctx, 2, created.Id)
+	check("DeleteIncomeDetail", err, "2024-04")
+
+	response, err := service.CreateDetail(ctx, dtos.CreateDetailRequest{Description: "Post", Quantity: 1, UnitPrice: amount(t, "1000"), IncomeInvoiceIdNumber: 2})
+	check("CreateDetail", err, "2024-04")
+
+	err = service.DeleteDetail(ctx, response.Id)
+	check("DeleteDetail", err, "2024-04")
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mtii-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+type ErrorKind string
+
+const (
+	KindNotFound   ErrorKind = "not_found"
+	KindConflict   ErrorKind = "conflict"
+	KindValidation ErrorKind = "validation"
+	KindForbidden  ErrorKind = "forbidden"
+)
+
+// DomainError is a service failure the caller can act on. Code is a stable,
+// machine-readable identifier; Fields lists per-field validation problems.
+type DomainError struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []utils.FieldError
+	Err     error
+}
+
+func (e *DomainError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+func NewNotFoundError(code, message string) error {
+	return &DomainError{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func NewConflictError(code, message string) error {
+	return &DomainError{Kind: KindConflict, Code: code, Message: message}
+}
+
+func NewValidationError(code, message string, fields ...utils.FieldError) error {
+	return &DomainError{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+func NewForbiddenError(code, message string) error {
+	return &DomainError{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// wrapError adds message as context to a repository error and classifies the
+// failures callers can act on: missing rows, duplicate keys and references to
+// rows that do not exist.
+func wrapError(err error, message string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &DomainError{Kind: KindNotFound, Code: "not_found", Message: message, Err: err}
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &DomainError{Kind: KindConflict, Code: "duplicate_key", Message: message, Err: err}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return &DomainError{Kind: KindValidation, Code: "invalid_reference", Message: message, Err: err}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"time"
+)
+
+// MaxAttachmentSize is the largest file accepted as an expense attachment.
+const MaxAttachmentSize = 10 << 20
+
+type ExpenseService interface {
+	GetAllExpense(ctx context.Context, query dtos.ExpenseQuery) ([]dtos.Expense, error)
+	GetExpenseById(ctx context.Context, expenseId int) (dtos.Expense, error)
+	CreateExpense(ctx context.Context, req dtos.CreateExpenseRequest) (dtos.ExpenseResponse, error)
+	UpdateExpense(ctx context.Context, expenseId int, req dtos.UpdateExpenseRequest) (dtos.ExpenseResponse, error)
+	DeleteExpense(ctx context.Context, expenseId int) error
+	CreateExpenseAttachment(ctx context.Context, expenseId int, fileName, contentType string, data []byte) (dtos.ExpenseAttachment, error)
+	GetExpenseAttachment(ctx context.Context, expenseId, attachmentId int) (dtos.ExpenseAttachmentFile, error)
+	DeleteExpenseAttachment(ctx context.Context, expenseId, attachmentId int) error
+}
+
+type expenseService struct {
+	expenseRepository repositories.ExpenseRepository
+	incomeRepository  repositories.IncomeRepository
+}
+
+func NewExpenseService(
+	expenseRepository repositories.ExpenseRepository,
+	incomeRepository repositories.IncomeRepository,
+) ExpenseService {
+	return &expenseService{
+		expenseRepository: expenseRepository,
+		incomeRepository:  incomeRepository,
+	}
+}
+
+func (s *expenseService) GetAllExpense(ctx context.Context, query dtos.ExpenseQuery) ([]dtos.Expense, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseService.GetAllExpense")
+	defer span.End()
+
+	expenses, err := s.expenseRepository.GetAllExpense(ctx, repositories.ExpenseFilter{
+		IncomeInvoiceIdNumber: query.IncomeInvoiceIdNumber,
+		InfluencerId:          query.InfluencerId,
+		VendorId:              query.VendorId,
+		Unpaid:                query.Unpaid,
+	})
+	if err != nil {
+		return []dtos.Expense{}, wrapError(err, "failed to get expense")
+	}
+
+	var expenseDTOs []dtos.Expense
+	for _, e := range expenses {
+		expenseDTOs = append(expenseDTOs, toExpenseDTO(e))
+	}
+
+	if len(expenseDTOs) == 0 {
+		return []dtos.Expense{}, nil
+	}
+
+	return expenseDTOs, nil
+}
+
+func (s *expenseService) GetExpenseById(ctx context.Context, expenseId int) (dtos.Expense, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseService.GetExpenseById")
+	defer span.End()
+
+	expense, err := s.expenseRepository.GetExpenseById(ctx, expenseId)
+	if err != nil {
+		return dtos.Expense{}, wrapError(err, "failed to get expense")
+	}
+
+	return toExpenseDTO(expense), nil
+}
+
+func (s *expenseService) CreateExpense(ctx context.Context, req dtos.CreateExpenseRequest) (dtos.ExpenseResponse, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseService.CreateExpense")
+	defer span.End()
+
+	if _, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, req.IncomeInvoiceIdNumber); err != nil {
+		return dtos.ExpenseResponse{}, referenceError(err, "income_invoice_id_number", "income", req.IncomeInvoiceIdNumber)
+	}
+
+	data := entities.Expense{
+		IncomeInvoiceIdNumber: req.IncomeInvoiceIdNumber,
+		InfluencerId:          req.InfluencerId,
+		VendorId:              req.VendorId,
+		Category:              req.Category,
+		Description:           req.Description,
+		Amount:                req.Amount,
+		WithholdingTaxRate:    req.WithholdingTaxRate,
+		DueDate:               req.DueDate,
+		PaidDate:              req.PaidDate,
+	}
+	if err := applyWithholdingTax(&data); err != nil {
+		return dtos.ExpenseResponse{}, err
+	}
+
+	expense, err := s.expenseRepository.CreateExpense(ctx, data)
+	if err != nil {
+		return dtos.ExpenseResponse{}, wrapError(err, "failed to save expense")
+	}
+
+	return dtos.ExpenseResponse{
+		Id: expense.Id,
+	}, nil
+}
+
+func (s *expenseService) UpdateExpense(ctx context.Context, expenseId int, req dtos.UpdateExpenseRequest) (dtos.ExpenseResponse, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseService.UpdateExpense")
+	defer span.End()
+
+	expense, err := s.expenseRepository.GetExpenseById(ctx, expenseId)
+	if err != nil {
+		return dtos.ExpenseResponse{}, wrapError(err, "failed to get expense")
+	}
+
+	data := entities.Expense{
+		Id:                    expenseId,
+		IncomeInvoiceIdNumber: expense.IncomeInvoiceIdNumber,
+		InfluencerId:          optionalId(req.InfluencerId, expense.InfluencerId),
+		VendorId:              optionalId(req.VendorId, expense.VendorId),
+		Category:              helpers.DefaultIfEmpty(req.Category, expense.Category),
+		Description:           helpers.DefaultIfEmpty(req.Description, expense.Description),
+		Amount:                helpers.DefaultIfEmpty(req.Amount, expense.Amount),
+		WithholdingTaxRate:    expense.WithholdingTaxRate,
+		DueDate:               helpers.DefaultIfEmpty(req.DueDate, expense.DueDate),
+		PaidDate:              helpers.DefaultIfEmpty(req.PaidDate, expense.PaidDate),
+	}
+	if req.WithholdingTaxRate != nil {
+		data.WithholdingTaxRate = *req.WithholdingTaxRate
+	}
+	if err := applyWithholdingTax(&data); err != nil {
+		return dtos.ExpenseResponse{}, err
+	}
+
+	updatedExpense, err := s.expenseRepository.UpdateExpense(ctx, data)
+	if err != nil {
+		return dtos.ExpenseResponse{}, wrapError(err, "failed to save expense")
+	}
+
+	return dtos.ExpenseResponse{
+		Id: updatedExpense.Id,
+	}, nil
+}
+
+func (s *expenseService) DeleteExpense(ctx context.Context, expenseId int) error {
+	ctx, span := telemetry.Start(ctx, "ExpenseService.DeleteExpense")
+	defer span.End()
+
+	expense, err := s.expenseRepository.GetExpenseById(ctx, expenseId)
+	if err != nil {
+		return wrapError(err, "failed to get expense")
+	}
+
+	err = s.expenseRepository.DeleteExpense(ctx, expense.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete expense")
+	}
+
+	return nil
+}
+
+func (s *expenseService) CreateExpenseAttachment(ctx context.Context, expenseId int, fileName, contentType string, data []byte) (dtos.ExpenseAttachment, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseService.CreateExpenseAttachment")
+	defer span.End()
+
+	if len(data) > MaxAttachmentSize {
+		return dtos.ExpenseAttachment{}, NewValidationError("attachment_too_large", "attachment is too large", utils.FieldError{
+			Field:   "file",
+			Rule:    "max",
+			Message: fmt.Sprintf("must be at most %d MB", MaxAttachmentSize>>20),
+		})
+	}
+
+	expense, err := s.expenseRepository.GetExpenseById(ctx, expenseId)
+	if err != nil {
+		return dtos.ExpenseAttachment{}, wrapError(err, "failed to get expense")
+	}
+
+	attachment, err := s.expenseRepository.CreateExpenseAttachment(ctx, entities.ExpenseAttachment{
+		ExpenseId:   expense.Id,
+		FileName:    fileName,
+		ContentType: helpers.DefaultIfEmpty(contentType, "application/octet-stream"),
+		Size:        int64(len(data)),
+		Data:        data,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return dtos.ExpenseAttachment{}, wrapError(err, "failed to save expense attachment")
+	}
+
+	return toExpenseAttachmentDTO(attachment), nil
+}
+
+func (s *expenseService) GetExpenseAttachment(ctx context.Context, expenseId, attachmentId int) (dtos.ExpenseAttachmentFile, error) {
+	ctx, span := telemetry.Start(ctx, "ExpenseService.GetExpenseAttachment")
+	defer span.End()
+
+	attachment, err := s.expenseRepository.GetExpenseAttachment(ctx, expenseId, attachmentId)
+	if err != nil {
+		return dtos.ExpenseAttachmentFile{}, wrapError(err, "failed to get expense attachment")
+	}
+
+	return dtos.ExpenseAttachmentFile{
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Data:        attachment.Data,
+	}, nil
+}
+
+func (s *expenseService) DeleteExpenseAttachment(ctx context.Context, expenseId, attachmentId int) error {
+	ctx, span := telemetry.Start(ctx, "ExpenseService.DeleteExpenseAttachment")
+	defer span.End()
+
+	if err := s.expenseRepository.DeleteExpenseAttachment(ctx, expenseId, attachmentId); err != nil {
+		return wrapError(err, "failed to delete expense attachment")
+	}
+
+	return nil
+}
+
+// applyWithholdingTax checks the payee and the withholding tax rate and
+// computes the amount withheld.
+func applyWithholdingTax(expense *entities.Expense) error {
+	if expense.InfluencerId != nil && expense.VendorId != nil {
+		return NewValidationError("invalid_payee", "an expense is paid to an influencer or a vendor, not both", utils.FieldError{
+			Field:   "vendor_id",
+			Rule:    "excluded_with",
+			Message: "cannot be set together with influencer_id",
+		})
+	}
+	if expense.WithholdingTaxRate > money.FromInt(100) {
+		return NewValidationError("invalid_withholding_tax", "withholding tax rate cannot exceed 100", utils.FieldError{
+			Field:   "withholding_tax_rate",
+			Rule:    "max",
+			Message: fmt.Sprintf("must be between 0 and 100, got %s", expense.WithholdingTaxRate),
+		})
+	}
+	expense.WithholdingTaxAmount = expense.Amount.Percent(expense.WithholdingTaxRate)
+	return nil
+}
+
+// optionalId applies a partial update to a nullable reference: nil keeps
+// current and 0 clears it.
+func optionalId(update, current *int) *int {
+	switch {
+	case update == nil:
+		return current
+	case *update == 0:
+		return nil
+	}
+	return update
+}
+
+func toExpenseDTO(e entities.Expense) dtos.Expense {
+	expense := dtos.Expense{
+		Id:                    e.Id,
+		IncomeInvoiceIdNumber: e.IncomeInvoiceIdNumber,
+		InfluencerId:          e.InfluencerId,
+		VendorId:              e.VendorId,
+		Category:              e.Category,
+		Description:           e.Description,
+		Currency:              e.Income.Currency,
+		Amount:                e.Amount,
+		WithholdingTaxRate:    e.WithholdingTaxRate,
+		WithholdingTaxAmount:  e.WithholdingTaxAmount,
+		NetPayable:            e.Amount - e.WithholdingTaxAmount,
+		DueDate:               e.DueDate,
+		PaidDate:              e.PaidDate,
+		Attachments:           []dtos.ExpenseAttachment{},
+	}
+	switch {
+	case e.Influencer != nil:
+		expense.PayeeName = e.Influencer.Name
+	case e.Vendor != nil:
+		expense.PayeeName = e.Vendor.Name
+	}
+	for _, a := range e.Attachments {
+		expense.Attachments = append(expense.Attachments, toExpenseAttachmentDTO(a))
+	}
+	return expense
+}
+
+func toExpenseAttachmentDTO(a entities.ExpenseAttachment) dtos.ExpenseAttachment {
+	return dtos.ExpenseAttachment{
+		Id:          a.Id,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		CreatedAt:   a.CreatedAt,
+	}
+}
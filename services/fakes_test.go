@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/repositories"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The fakes embed the repository interfaces so that each implements only
+// the methods a test reaches; any other call panics.
+
+type fakeIncomeRepository struct {
+	repositories.IncomeRepository
+	paid     []entities.Income
+	existing map[int]entities.Income
+}
+
+func (f *fakeIncomeRepository) GetIncomeByInvoiceIdNumber(ctx context.Context, incomeInvoiceIdNumber int) (entities.Income, error) {
+	if income, ok := f.existing[incomeInvoiceIdNumber]; ok {
+		return income, nil
+	}
+	return entities.Income{}, gorm.ErrRecordNotFound
+}
+
+// CreateIncome stores a quotation under the negative of its quotation
+// number, as the repository does.
+func (f *fakeIncomeRepository) CreateIncome(ctx context.Context, income entities.Income) (entities.Income, error) {
+	if income.Stage == repositories.IncomeStageQuotation && income.InvoiceIdNumber == 0 {
+		income.InvoiceIdNumber = -income.QuotationIdNumber
+	}
+	f.existing[income.InvoiceIdNumber] = income
+	return income, nil
+}
+
+func (f *fakeIncomeRepository) UpdateIncomeWithNewInvoiceIdNumber(ctx context.Context, income entities.Income, oldInvoiceIdNumber int) (entities.Income, error) {
+	delete(f.existing, oldInvoiceIdNumber)
+	f.existing[income.InvoiceIdNumber] = income
+	return income, nil
+}
+
+func (f *fakeIncomeRepository) GetIncomeByReceiptIdNumber(ctx context.Context, receiptIdNumber int) (entities.Income, error) {
+	for _, income := range f.existing {
+		if income.ReceiptIdNumber == receiptIdNumber {
+			return income, nil
+		}
+	}
+	return entities.Income{}, gorm.ErrRecordNotFound
+}
+
+// SaveStage numbers the income past the highest number of its series and
+// moves it to its new invoice number, as the repository does.
+func (f *fakeIncomeRepository) SaveStage(ctx context.Context, income entities.Income, from string, oldInvoiceIdNumber int) (entities.Income, error) {
+	if f.existing[oldInvoiceIdNumber].Stage != from {
+		return entities.Income{}, repositories.ErrStageChanged
+	}
+	switch {
+	case income.Stage == repositories.IncomeStageInvoice && income.InvoiceIdNumber <= 0:
+		for n := range f.existing {
+			income.InvoiceIdNumber = max(income.InvoiceIdNumber, n)
+		}
+		income.InvoiceIdNumber++
+	case income.Stage == repositories.IncomeStageReceipt && income.ReceiptIdNumber == 0:
+		for _, i := range f.existing {
+			income.ReceiptIdNumber = max(income.ReceiptIdNumber, i.ReceiptIdNumber)
+		}
+		income.ReceiptIdNumber++
+	}
+	delete(f.existing, oldInvoiceIdNumber)
+	f.existing[income.InvoiceIdNumber] = income
+	return income, nil
+}
+
+func (f *fakeIncomeRepository) GetWithheldIncomes(ctx context.Context, filter repositories.TaxMonthFilter) ([]entities.Income, error) {
+	var incomes []entities.Income
+	for _, i := range f.existing {
+		if i.WithholdingTaxAmount > 0 {
+			incomes = append(incomes, i)
+		}
+	}
+	return incomes, nil
+}
+
+func (f *fakeIncomeRepository) GetPaidIncomesBySalePerson(ctx context.Context, salePersonId int, from, to time.Time) ([]entities.Income, error) {
+	var incomes []entities.Income
+	for _, i := range f.paid {
+		if i.SalePersonId == salePersonId && !i.ReceiptIssueDate.Before(from) && !i.ReceiptIssueDate.After(to) {
+			incomes = append(incomes, i)
+		}
+	}
+	return incomes, nil
+}
+
+type fakeCommissionRepository struct {
+	repositories.CommissionRepository
+	assignments []entities.SalePersonCommissionPlan
+	replaced    []entities.Commission
+}
+
+func (f *fakeCommissionRepository) GetSalePersonCommissionPlans(ctx context.Context, salePersonId int) ([]entities.SalePersonCommissionPlan, error) {
+	return f.assignments, nil
+}
+
+func (f *fakeCommissionRepository) ReplaceCommissions(ctx context.Context, salePersonId int, from, to time.Time, commissions []entities.Commission) error {
+	f.replaced = commissions
+	return nil
+}
+
+type fakeCurrencyRateRepository struct {
+	repositories.CurrencyRateRepository
+	rates []entities.CurrencyRate
+}
+
+func (f *fakeCurrencyRateRepository) GetCurrencyRatesByCurrencies(ctx context.Context, currencies []string) ([]entities.CurrencyRate, error) {
+	return f.rates, nil
+}
+
+type fakeImportBatchRepository struct {
+	repositories.ImportBatchRepository
+	lookupIds  map[string]int
+	batch      entities.ImportBatch
+	dependents int64
+	created    [][]repositories.ImportedIncome
+	rolledBack []int
+}
+
+func (f *fakeImportBatchRepository) GetLookupIds(ctx context.Context, model any) (map[string]int, error) {
+	return f.lookupIds, nil
+}
+
+func (f *fakeImportBatchRepository) CreateImportBatch(ctx context.Context, batch entities.ImportBatch, incomes []repositories.ImportedIncome) (entities.ImportBatch, error) {
+	f.created = append(f.created, incomes)
+	batch.Id = len(f.created)
+	return batch, nil
+}
+
+func (f *fakeImportBatchRepository) GetImportBatchById(ctx context.Context, importBatchId int) (entities.ImportBatch, error) {
+	return f.batch, nil
+}
+
+func (f *fakeImportBatchRepository) CountImportBatchDependents(ctx context.Context, importBatchId int) (int64, error) {
+	return f.dependents, nil
+}
+
+func (f *fakeImportBatchRepository) RollbackImportBatch(ctx context.Context, importBatchId int) ([]entities.Income, error) {
+	f.rolledBack = append(f.rolledBack, importBatchId)
+	return nil, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mtii-backend/entities"
+	"mtii-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// linkCustomer points income at the agency, contact and brand given by id
+// and copies their current details into its agency, contactor and brand
+// fields. Those fields are snapshots: later edits to the master records do
+// not change incomes that were already issued.
+//
+// A contact or brand given without an agency selects its own agency. When
+// the agency changes, links to the previous agency's contact and brand are
+// dropped unless new ones are given.
+func (s *incomeService) linkCustomer(ctx context.Context, income *entities.Income, agencyId, contactId, brandId *int) error {
+	var contact *entities.Contact
+	if contactId != nil {
+		c, err := s.contactRepository.GetContactById(ctx, *contactId)
+		if err != nil {
+			return referenceError(err, "contact_id", "contact", *contactId)
+		}
+		contact = &c
+	}
+
+	var brand *entities.Brand
+	if brandId != nil {
+		b, err := s.brandRepository.GetBrandById(ctx, *brandId)
+		if err != nil {
+			return referenceError(err, "brand_id", "brand", *brandId)
+		}
+		brand = &b
+	}
+
+	switch {
+	case agencyId != nil:
+	case contact != nil:
+		agencyId = &contact.AgencyId
+	case brand != nil:
+		agencyId = &brand.AgencyId
+	}
+
+	if agencyId != nil {
+		agency, err := s.agencyRepository.GetAgencyById(ctx, *agencyId)
+		if err != nil {
+			return referenceError(err, "agency_id", "agency", *agencyId)
+		}
+		if income.AgencyId == nil || *income.AgencyId != agency.Id {
+			income.ContactId = nil
+			income.BrandId = nil
+		}
+		income.AgencyId = &agency.Id
+		income.AgencyAgencyName = agency.Name
+		income.AgencyAddress = agency.Address
+		income.AgencyPhoneNumber = agency.PhoneNumber
+		income.AgencyTaxPayerIdNumber = agency.TaxPayerId
+		income.AgencyBranchNumber = agency.BranchNumber
+	}
+
+	if contact != nil {
+		if income.AgencyId == nil || contact.AgencyId != *income.AgencyId {
+			return agencyMismatchError("contact_id", "contact", contact.Id)
+		}
+		income.ContactId = &contact.Id
+		income.ContactorContactorName = contact.Name
+		income.ContactorPhoneNumber = contact.PhoneNumber
+		income.ContactorLine = contact.Line
+		income.ContactorEmail = contact.Email
+	}
+
+	if brand != nil {
+		if income.AgencyId == nil || brand.AgencyId != *income.AgencyId {
+			return agencyMismatchError("brand_id", "brand", brand.Id)
+		}
+		income.BrandId = &brand.Id
+		income.BrandBrandName = brand.Name
+	}
+
+	return nil
+}
+
+// referenceError reports an id in the request that matches no record as a
+// validation error on field rather than a missing resource.
+func referenceError(err error, field, resource string, id int) error {
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return wrapError(err, "failed to get "+resource)
+	}
+	return NewValidationError("invalid_reference", resource+" does not exist", utils.FieldError{
+		Field:   field,
+		Rule:    "exists",
+		Message: fmt.Sprintf("there is no %s with id %d", resource, id),
+	})
+}
+
+func agencyMismatchError(field, resource string, id int) error {
+	return NewValidationError("agency_mismatch", resource+" belongs to another agency", utils.FieldError{
+		Field:   field,
+		Rule:    "agency",
+		Message: fmt.Sprintf("%s %d does not belong to the income's agency", resource, id),
+	})
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mtii-backend/entities"
+	"mtii-backend/exports"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/promptpay"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"time"
+)
+
+// invoiceQRSize is the width in pixels of the PromptPay QR code printed on
+// invoices.
+const invoiceQRSize = 512
+
+// GetInvoicePDF prints an income as its current stage's document: a
+// quotation, an invoice or a receipt. Invoices with a balance left carry
+// the PromptPay QR code GetPromptPay returns, when the receiver takes
+// PromptPay; the document is printed without it otherwise.
+func (s *incomeService) GetInvoicePDF(ctx context.Context, incomeInvoiceIdNumber int) ([]byte, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.GetInvoicePDF")
+	defer span.End()
+
+	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return nil, wrapError(err, "failed to get income")
+	}
+
+	table := invoiceTable(income)
+	if income.Stage == repositories.IncomeStageInvoice {
+		if payment, err := promptPay(income); err == nil {
+			png, err := promptpay.PNG(payment.Payload, invoiceQRSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to draw PromptPay QR code: %w", err)
+			}
+			table.Image = png
+			table.Caption = fmt.Sprintf("Scan to pay %s %s by PromptPay to %s", payment.Amount, payment.Currency, payment.ReceiverName)
+			if payment.WithholdingTaxAmount > 0 {
+				table.Caption += fmt.Sprintf(", net of %s withholding tax", payment.WithholdingTaxAmount)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := exports.Write(&buf, exports.FormatPDF, table); err != nil {
+		return nil, fmt.Errorf("failed to print income: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// invoiceTable lays an income out as a table of its lines followed by its
+// totals.
+func invoiceTable(income entities.Income) exports.Table {
+	currency := helpers.DefaultIfEmpty(income.Currency, money.DefaultCurrency)
+	var title, number string
+	var issued, due time.Time
+	switch income.Stage {
+	case repositories.IncomeStageQuotation:
+		title, number, issued, due = "Quotation", fmt.Sprint(income.QuotationIdNumber), income.QuotationIssueDate, income.QuotationDueDate
+	case repositories.IncomeStageReceipt:
+		title, number, issued = "Receipt", fmt.Sprint(income.ReceiptIdNumber), income.ReceiptIssueDate
+	default:
+		title, number, issued, due = "Invoice", fmt.Sprint(income.InvoiceIdNumber), income.InvoiceIssueDate, income.InvoiceDueDate
+	}
+
+	header := []string{
+		"No. " + number,
+		"Issued " + invoiceDate(issued),
+	}
+	if !due.IsZero() {
+		header = append(header, "Due "+invoiceDate(due))
+	}
+	if income.ReceiverId != 0 {
+		header = append(header, "From "+income.Receiver.Name)
+	}
+	header = append(header, "To "+income.AgencyAgencyName)
+	if income.AgencyAddress != "" {
+		header = append(header, income.AgencyAddress)
+	}
+	if income.AgencyTaxPayerIdNumber != "" {
+		header = append(header, fmt.Sprintf("Tax ID %s, branch %s", income.AgencyTaxPayerIdNumber, income.AgencyBranchNumber))
+	}
+
+	rows := make([][]any, 0, len(income.Details)+6)
+	for _, d := range income.Details {
+		rows = append(rows, []any{d.Description, d.Quantity, d.UnitPrice, lineNet(d)})
+	}
+	totals := incomeTotals(income.DiscountType, income.DiscountValue, income.Details)
+	rows = append(rows, []any{"Subtotal", nil, nil, totals.Subtotal})
+	if totals.DiscountAmount > 0 {
+		rows = append(rows, []any{"Discount", nil, nil, -totals.DiscountAmount})
+	}
+	rows = append(rows, []any{fmt.Sprintf("VAT %s%%", VatRate), nil, nil, totals.VatAmount})
+	if income.WithholdingTaxAmount > 0 {
+		rows = append(rows, []any{fmt.Sprintf("Withholding tax %s%%", income.WithholdingTaxRate), nil, nil, -income.WithholdingTaxAmount})
+	}
+	if income.Stage == repositories.IncomeStageInvoice {
+		rows = append(rows, []any{"Balance due", nil, nil, income.UnpaidPaymentAmount})
+	}
+
+	return exports.Table{
+		Title:  title,
+		Header: header,
+		Columns: []exports.Column{
+			{Title: "Description", Width: 60},
+			{Title: "Quantity", Width: 10},
+			{Title: "Unit price", Width: 15},
+			{Title: "Amount (" + currency + ")", Width: 15},
+		},
+		Rows:   rows,
+		Footer: []any{"Total", nil, nil, totals.GrandTotal},
+	}
+}
+
+func invoiceDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
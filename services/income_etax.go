@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/etax"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/taxid"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	ETaxTaxInvoice = "tax_invoice"
+	ETaxReceipt    = "receipt"
+)
+
+// postcodePattern finds a Thai postcode, five digits standing alone, in a
+// free-text address.
+var postcodePattern = regexp.MustCompile(`(?:^|\D)(\d{5})(?:\D|$)`)
+
+// GetETaxInvoice writes an income as an ETDA e-Tax document: the receiver
+// is the seller, the agency snapshot the buyer and the details the lines.
+// The document is checked against the schema's rules before it is
+// returned, and signed when the query asks for it.
+func (s *incomeService) GetETaxInvoice(ctx context.Context, incomeInvoiceIdNumber int, query dtos.ETaxInvoiceQuery) ([]byte, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.GetETaxInvoice")
+	defer span.End()
+
+	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return nil, wrapError(err, "failed to get income")
+	}
+
+	if currency := helpers.DefaultIfEmpty(income.Currency, money.DefaultCurrency); currency != money.BaseCurrency {
+		return nil, NewValidationError("etax_currency", fmt.Sprintf("e-Tax invoices are issued in %s, not %s", money.BaseCurrency, currency))
+	}
+	if income.ReceiverId == 0 {
+		return nil, NewValidationError("etax_seller_missing", "set the receiver issuing the income first")
+	}
+	if income.Stage == repositories.IncomeStageQuotation {
+		return nil, NewConflictError("income_not_invoiced", "convert the quotation to an invoice before issuing its tax invoice")
+	}
+
+	document := etax.ExchangedDocument{
+		ID:               strconv.Itoa(income.InvoiceIdNumber),
+		Name:             "ใบแจ้งหนี้/ใบกำกับภาษี",
+		TypeCode:         etax.TypeInvoiceTaxInvoice,
+		IssueDateTime:    etax.DateTime(income.InvoiceIssueDate),
+		CreationDateTime: etax.DateTime(time.Now()),
+	}
+	if query.Type == ETaxReceipt {
+		if !isPaid(income) || income.ReceiptIdNumber == 0 {
+			return nil, NewConflictError("income_unpaid", "a receipt is issued once the income is paid in full")
+		}
+		document.ID = strconv.Itoa(income.ReceiptIdNumber)
+		document.Name = "ใบเสร็จรับเงิน/ใบกำกับภาษี"
+		document.TypeCode = etax.TypeReceiptTaxInvoice
+		document.IssueDateTime = etax.DateTime(income.ReceiptIssueDate)
+	}
+
+	invoice := etax.Invoice{
+		Document: document,
+		Transaction: etax.SupplyChainTradeTransaction{
+			Agreement: etax.HeaderTradeAgreement{
+				Seller: etaxParty(income.Receiver.Name, income.Receiver.TaxPayerId, income.Receiver.BranchNumber, income.Receiver.Address),
+				Buyer:  etaxParty(income.AgencyAgencyName, income.AgencyTaxPayerIdNumber, income.AgencyBranchNumber, income.AgencyAddress),
+			},
+			Settlement: etaxSettlement(income),
+			Lines:      etaxLines(income.Details),
+		},
+	}
+	if income.Receiver.Email != "" {
+		invoice.Transaction.Agreement.Seller.Contact = &etax.TradeContact{Email: income.Receiver.Email}
+	}
+	if query.Type != ETaxReceipt && !income.InvoiceDueDate.IsZero() {
+		invoice.Transaction.Settlement.PaymentTerms = &etax.PaymentTerms{DueDateDateTime: etax.DateTime(income.InvoiceDueDate)}
+	}
+
+	data, err := etax.Marshal(invoice)
+	var invalid *etax.ValidationError
+	if errors.As(err, &invalid) {
+		fields := make([]utils.FieldError, len(invalid.Problems))
+		for i, p := range invalid.Problems {
+			fields[i] = utils.FieldError{Field: p.Path, Rule: "schema", Message: p.Message}
+		}
+		return nil, NewValidationError("etax_invalid", "the income cannot be written as a valid e-Tax invoice", fields...)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to write e-Tax invoice: %w", err)
+	}
+
+	if !query.Sign {
+		return data, nil
+	}
+	certPath := os.Getenv("ETAX_CERT_PATH")
+	if certPath == "" {
+		return nil, NewValidationError("etax_signing_not_configured", "e-Tax invoices cannot be signed until ETAX_CERT_PATH names the signing certificate")
+	}
+	signer, err := etax.LoadSigner(certPath, os.Getenv("ETAX_CERT_PASSWORD"))
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(data)
+}
+
+// etaxParty describes a seller or buyer. Parties without a Thai tax ID are
+// identified by the OTHR scheme.
+func etaxParty(name string, taxId taxid.ID, branch, address string) etax.TradeParty {
+	party := etax.TradeParty{
+		Name:  name,
+		TaxId: etax.SchemeID{SchemeID: etax.SchemeOther, Value: "N/A"},
+		Address: etax.PostalAddress{
+			LineOne:   address,
+			CountryID: etax.SchemeID{SchemeID: "3166-1 alpha-2", Value: etax.CountryTH},
+		},
+	}
+	if !taxId.IsZero() {
+		party.TaxId = etax.SchemeID{SchemeID: etax.SchemeTaxId, Value: taxId.String() + helpers.DefaultIfEmpty(branch, taxid.HeadOffice)}
+	}
+	if matches := postcodePattern.FindAllStringSubmatch(address, -1); len(matches) > 0 {
+		party.Address.Postcode = matches[len(matches)-1][1]
+	}
+	return party
+}
+
+// etaxSettlement carries the income's totals: VAT on the taxable amount,
+// a zero-rated entry for exempt lines and the document discount.
+func etaxSettlement(income entities.Income) etax.HeaderTradeSettlement {
+	totals := incomeTotals(income.DiscountType, income.DiscountValue, income.Details)
+	settlement := etax.HeaderTradeSettlement{
+		Currency: etax.CurrencyCode{ListID: "ISO 4217 3A", Value: money.BaseCurrency},
+		Summation: etax.MonetarySummation{
+			LineTotalAmount:      totals.Subtotal,
+			AllowanceTotalAmount: totals.DiscountAmount,
+			TaxBasisTotalAmount:  totals.VatableAmount + totals.VatExemptAmount,
+			TaxTotalAmount:       totals.VatAmount,
+			GrandTotalAmount:     totals.GrandTotal,
+		},
+	}
+
+	if totals.VatableAmount > 0 || totals.VatExemptAmount == 0 {
+		settlement.Taxes = append(settlement.Taxes, etaxTax(VatRate, totals.VatableAmount, totals.VatAmount))
+	}
+	if totals.VatExemptAmount > 0 {
+		settlement.Taxes = append(settlement.Taxes, etaxTax(0, totals.VatExemptAmount, 0))
+	}
+	if totals.DiscountAmount > 0 {
+		settlement.Allowances = []etax.AllowanceCharge{{ActualAmount: totals.DiscountAmount, Reason: "Discount"}}
+	}
+	return settlement
+}
+
+func etaxTax(rate, basis, amount money.Amount) etax.TradeTax {
+	return etax.TradeTax{TypeCode: etax.VatTypeCode, CalculatedRate: rate, BasisAmount: &basis, CalculatedAmount: &amount}
+}
+
+// etaxLines lists the details in order. Units are free text here, so every
+// line is billed in the generic unit code.
+func etaxLines(details []entities.Detail) []etax.LineItem {
+	lines := make([]etax.LineItem, len(details))
+	for i, d := range details {
+		rate := VatRate
+		if d.VatExempt {
+			rate = 0
+		}
+		line := etax.LineItem{
+			LineID:     strconv.Itoa(i + 1),
+			Product:    etax.Product{Name: d.Description, Description: d.Notes},
+			Agreement:  etax.LineTradeAgreement{GrossPrice: d.UnitPrice},
+			Delivery:   etax.LineTradeDelivery{BilledQuantity: etax.Quantity{UnitCode: etax.UnitCodeUnit, Value: d.Quantity}},
+			Settlement: etax.LineSettlement{Tax: etax.TradeTax{TypeCode: etax.VatTypeCode, CalculatedRate: rate}, NetTotal: lineNet(d)},
+		}
+		if discount := lineTotal(d) - lineNet(d); discount > 0 {
+			line.Settlement.Allowances = []etax.AllowanceCharge{{ActualAmount: discount, Reason: "Discount"}}
+		}
+		lines[i] = line
+	}
+	return lines
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/exports"
+	"mtii-backend/helpers"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"slices"
+	"strings"
+)
+
+const (
+	LangEnglish = "en"
+	LangThai    = "th"
+)
+
+// incomeExportLine is what a row of the export is read from: the income,
+// the totals of all its lines and, in a flattened export, one line.
+type incomeExportLine struct {
+	income repositories.IncomeExportRow
+	totals dtos.IncomeTotals
+	detail entities.Detail
+}
+
+// incomeExportColumn is a column an income export can include. Detail
+// columns are only available when each line has a row of its own.
+type incomeExportColumn struct {
+	key    string
+	en, th string
+	width  float64
+	detail bool
+	value  func(l incomeExportLine) any
+}
+
+func (c incomeExportColumn) title(lang string) string {
+	if lang == LangThai {
+		return c.th
+	}
+	return c.en
+}
+
+// incomeExportColumns are the export's columns in their default order.
+var incomeExportColumns = []incomeExportColumn{
+	{key: "invoice_id_number", en: "Invoice no.", th: "เลขที่ใบแจ้งหนี้", width: 12, value: func(l incomeExportLine) any { return l.income.InvoiceIdNumber }},
+	{key: "invoice_issue_date", en: "Invoice date", th: "วันที่ใบแจ้งหนี้", width: 12, value: func(l incomeExportLine) any { return l.income.InvoiceIssueDate }},
+	{key: "invoice_due_date", en: "Due date", th: "วันครบกำหนด", width: 12, value: func(l incomeExportLine) any { return l.income.InvoiceDueDate }},
+	{key: "quotation_id_number", en: "Quotation no.", th: "เลขที่ใบเสนอราคา", width: 12, value: func(l incomeExportLine) any { return l.income.QuotationIdNumber }},
+	{key: "quotation_issue_date", en: "Quotation date", th: "วันที่ใบเสนอราคา", width: 12, value: func(l incomeExportLine) any { return l.income.QuotationIssueDate }},
+	{key: "receipt_id_number", en: "Receipt no.", th: "เลขที่ใบเสร็จรับเงิน", width: 12, value: func(l incomeExportLine) any { return l.income.ReceiptIdNumber }},
+	{key: "receipt_issue_date", en: "Receipt date", th: "วันที่ใบเสร็จรับเงิน", width: 12, value: func(l incomeExportLine) any { return l.income.ReceiptIssueDate }},
+	{key: "influencer_posting_date", en: "Posting date", th: "วันที่โพสต์", width: 12, value: func(l incomeExportLine) any { return l.income.InfluencerPostingDate }},
+	{key: "agency", en: "Agency", th: "เอเจนซี่", width: 30, value: func(l incomeExportLine) any { return l.income.AgencyAgencyName }},
+	{key: "agency_tax_id", en: "Agency tax ID", th: "เลขประจำตัวผู้เสียภาษี", width: 15, value: func(l incomeExportLine) any { return l.income.AgencyTaxPayerIdNumber.String() }},
+	{key: "agency_branch", en: "Agency branch", th: "สาขา", width: 8, value: func(l incomeExportLine) any { return l.income.AgencyBranchNumber }},
+	{key: "contact", en: "Contact", th: "ผู้ติดต่อ", width: 20, value: func(l incomeExportLine) any { return l.income.ContactorContactorName }},
+	{key: "contact_email", en: "Contact email", th: "อีเมลผู้ติดต่อ", width: 25, value: func(l incomeExportLine) any { return l.income.ContactorEmail }},
+	{key: "brand", en: "Brand", th: "แบรนด์", width: 20, value: func(l incomeExportLine) any { return l.income.BrandBrandName }},
+	{key: "product", en: "Product", th: "สินค้า", width: 20, value: func(l incomeExportLine) any { return l.income.BrandProduct }},
+	{key: "transaction_reference_number", en: "Transaction reference", th: "เลขอ้างอิงการชำระเงิน", width: 14, value: func(l incomeExportLine) any { return l.income.TransactionReferenceNumber }},
+	{key: "platform", en: "Platform", th: "แพลตฟอร์ม", width: 14, value: func(l incomeExportLine) any { return l.income.PlatformName }},
+	{key: "channel", en: "Channel", th: "ช่องทาง", width: 14, value: func(l incomeExportLine) any { return l.income.ChannelName }},
+	{key: "status", en: "Status", th: "สถานะ", width: 12, value: func(l incomeExportLine) any { return l.income.StatusName }},
+	{key: "payment_method", en: "Payment method", th: "วิธีการชำระเงิน", width: 14, value: func(l incomeExportLine) any { return l.income.PaymentMethodName }},
+	{key: "receiver", en: "Receiver", th: "ผู้รับเงิน", width: 20, value: func(l incomeExportLine) any { return l.income.ReceiverName }},
+	{key: "sale_person", en: "Sale person", th: "พนักงานขาย", width: 16, value: func(l incomeExportLine) any { return l.income.SalePersonName }},
+	{key: "bank", en: "Bank", th: "ธนาคาร", width: 14, value: func(l incomeExportLine) any { return l.income.BankName }},
+	{key: "currency", en: "Currency", th: "สกุลเงิน", width: 8, value: func(l incomeExportLine) any { return l.income.Currency }},
+	{key: "subtotal", en: "Subtotal", th: "รวมเป็นเงิน", width: 14, value: func(l incomeExportLine) any { return l.totals.Subtotal }},
+	{key: "discount_amount", en: "Discount", th: "ส่วนลด", width: 12, value: func(l incomeExportLine) any { return l.totals.DiscountAmount }},
+	{key: "vatable_amount", en: "Vatable amount", th: "มูลค่าที่ต้องเสียภาษี", width: 14, value: func(l incomeExportLine) any { return l.totals.VatableAmount }},
+	{key: "vat_exempt_amount", en: "VAT exempt amount", th: "มูลค่าที่ได้รับยกเว้นภาษี", width: 14, value: func(l incomeExportLine) any { return l.totals.VatExemptAmount }},
+	{key: "vat_amount", en: "VAT", th: "ภาษีมูลค่าเพิ่ม", width: 12, value: func(l incomeExportLine) any { return l.totals.VatAmount }},
+	{key: "grand_total", en: "Grand total", th: "จำนวนเงินรวมทั้งสิ้น", width: 14, value: func(l incomeExportLine) any { return l.totals.GrandTotal }},
+	{key: "withholding_tax_amount", en: "Withholding tax", th: "ภาษีหัก ณ ที่จ่าย", width: 12, value: func(l incomeExportLine) any { return l.income.WithholdingTaxAmount }},
+	{key: "total_payment_amount", en: "Total payment", th: "ยอดชำระทั้งหมด", width: 14, value: func(l incomeExportLine) any { return l.income.TotalPaymentAmount }},
+	{key: "unpaid_payment_amount", en: "Unpaid", th: "ยอดค้างชำระ", width: 14, value: func(l incomeExportLine) any { return l.income.UnpaidPaymentAmount }},
+	{key: "detail_description", en: "Description", th: "รายการ", width: 30, detail: true, value: func(l incomeExportLine) any { return l.detail.Description }},
+	{key: "detail_category", en: "Category", th: "หมวดหมู่", width: 14, detail: true, value: func(l incomeExportLine) any { return l.detail.Category }},
+	{key: "detail_quantity", en: "Quantity", th: "จำนวน", width: 8, detail: true, value: func(l incomeExportLine) any { return l.detail.Quantity }},
+	{key: "detail_unit", en: "Unit", th: "หน่วย", width: 8, detail: true, value: func(l incomeExportLine) any { return l.detail.Unit }},
+	{key: "detail_unit_price", en: "Unit price", th: "ราคาต่อหน่วย", width: 12, detail: true, value: func(l incomeExportLine) any { return l.detail.UnitPrice }},
+	{key: "detail_discount_amount", en: "Line discount", th: "ส่วนลดรายการ", width: 12, detail: true, value: func(l incomeExportLine) any { return lineTotal(l.detail) - lineNet(l.detail) }},
+	{key: "detail_net_amount", en: "Line amount", th: "จำนวนเงิน", width: 14, detail: true, value: func(l incomeExportLine) any { return lineNet(l.detail) }},
+	{key: "detail_vat_exempt", en: "VAT exempt", th: "ยกเว้นภาษี", width: 8, detail: true, value: func(l incomeExportLine) any { return l.detail.VatExempt }},
+}
+
+// ExportIncomes writes the incomes the list endpoint returns for the same
+// filters to w as CSV, the default, or XLSX. Lookups are written by name.
+// The incomes are streamed from the database, holding only the lines of
+// one income at a time, which its totals are computed from. Nothing is
+// written to w when the query is invalid.
+func (s *incomeService) ExportIncomes(ctx context.Context, query dtos.IncomeExportQuery, w io.Writer) error {
+	ctx, span := telemetry.Start(ctx, "IncomeService.ExportIncomes")
+	defer span.End()
+
+	columns, err := selectIncomeExportColumns(query.Columns, query.Details)
+	if err != nil {
+		return err
+	}
+	lang := helpers.DefaultIfEmpty(query.Lang, LangEnglish)
+	titles := make([]exports.Column, len(columns))
+	for i, c := range columns {
+		titles[i] = exports.Column{Title: c.title(lang), Width: c.width}
+	}
+
+	rw, err := exports.NewRowWriter(w, helpers.DefaultIfEmpty(query.Format, exports.FormatCSV), titles)
+	if err != nil {
+		return wrapError(err, "failed to export income")
+	}
+
+	write := func(l incomeExportLine) error {
+		cells := make([]any, len(columns))
+		for i, c := range columns {
+			cells[i] = c.value(l)
+		}
+		return rw.WriteRow(cells)
+	}
+
+	// The rows of an income arrive together, one per line; they are held
+	// until the next income starts so its totals can be computed.
+	var pending []repositories.IncomeExportRow
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		details := make([]entities.Detail, 0, len(pending))
+		for _, row := range pending {
+			if row.DetailId != 0 {
+				details = append(details, exportDetail(row))
+			}
+		}
+		income := pending[0]
+		totals := incomeTotals(income.DiscountType, income.DiscountValue, details)
+		pending = pending[:0]
+
+		if !query.Details || len(details) == 0 {
+			return write(incomeExportLine{income: income, totals: totals})
+		}
+		for _, d := range details {
+			if err := write(incomeExportLine{income: income, totals: totals, detail: d}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = s.incomeRepository.StreamIncomeExport(ctx, query.TaxId, func(row repositories.IncomeExportRow) error {
+		if len(pending) > 0 && pending[0].InvoiceIdNumber != row.InvoiceIdNumber {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		pending = append(pending, row)
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return wrapError(err, "failed to export income")
+	}
+	if err := rw.Close(); err != nil {
+		return wrapError(err, "failed to export income")
+	}
+	return nil
+}
+
+// selectIncomeExportColumns looks up the comma-separated column keys, or
+// returns every column the export's layout allows when there are none.
+func selectIncomeExportColumns(keys string, details bool) ([]incomeExportColumn, error) {
+	if strings.TrimSpace(keys) == "" {
+		var columns []incomeExportColumn
+		for _, c := range incomeExportColumns {
+			if details || !c.detail {
+				columns = append(columns, c)
+			}
+		}
+		return columns, nil
+	}
+
+	var columns []incomeExportColumn
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		i := slices.IndexFunc(incomeExportColumns, func(c incomeExportColumn) bool { return c.key == key })
+		if i < 0 {
+			known := make([]string, len(incomeExportColumns))
+			for j, c := range incomeExportColumns {
+				known[j] = c.key
+			}
+			return nil, NewValidationError("invalid_columns", "unknown export column", utils.FieldError{
+				Field:   "columns",
+				Rule:    "oneof",
+				Message: fmt.Sprintf("%q is not one of %s", key, strings.Join(known, " ")),
+			})
+		}
+		if incomeExportColumns[i].detail && !details {
+			return nil, NewValidationError("invalid_columns", "detail columns need details=true", utils.FieldError{
+				Field:   "columns",
+				Rule:    "details",
+				Message: fmt.Sprintf("%q is a detail column, which needs one row per line", key),
+			})
+		}
+		columns = append(columns, incomeExportColumns[i])
+	}
+	return columns, nil
+}
+
+func exportDetail(row repositories.IncomeExportRow) entities.Detail {
+	return entities.Detail{
+		Id:            row.DetailId,
+		Description:   row.DetailDescription,
+		Category:      row.DetailCategory,
+		Quantity:      row.DetailQuantity,
+		Unit:          row.DetailUnit,
+		UnitPrice:     row.DetailUnitPrice,
+		DiscountType:  row.DetailDiscountType,
+		DiscountValue: row.DetailDiscountValue,
+		VatExempt:     row.DetailVatExempt,
+	}
+}
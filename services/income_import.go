@@ -0,0 +1,688 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/taxid"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/xuri/excelize/v2"
+)
+
+// Where an import batch came from.
+const (
+	ImportSourceAPI = "api"
+	ImportSourceCLI = "cli"
+)
+
+// MaxImportSize is the largest sheet accepted for import, in bytes.
+const MaxImportSize = 20 << 20
+
+// importValidator checks imported rows against the same binding rules the
+// API applies to a CreateIncomeRequest.
+var importValidator = newImportValidator()
+
+func newImportValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(utils.JSONFieldName)
+	if err := taxid.RegisterValidations(v); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// importField is a column that fills a field of CreateIncomeRequest or,
+// prefixed with detail_, of IncomeDetailRequest. Columns are named after
+// the fields' JSON names.
+type importField struct {
+	index  []int
+	detail bool
+}
+
+var importFields = func() map[string]importField {
+	fields := map[string]importField{}
+	add := func(t reflect.Type, prefix string, detail bool) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := utils.JSONFieldName(f)
+			if name == "" || f.Type.Kind() == reflect.Slice || (detail && name == "id") {
+				continue
+			}
+			fields[prefix+name] = importField{index: f.Index, detail: detail}
+		}
+	}
+	add(reflect.TypeOf(dtos.CreateIncomeRequest{}), "", false)
+	add(reflect.TypeOf(dtos.IncomeDetailRequest{}), "detail_", true)
+	return fields
+}()
+
+// importLookup is a column holding the name of a lookup record, which the
+// import turns into the id in field. Records of a kind can be created when
+// no record has the name.
+type importLookup struct {
+	column string
+	field  string
+	model  any
+	kind   string
+}
+
+var importLookups = []importLookup{
+	{column: "platform", field: "platform_id", model: &entities.Platform{}, kind: repositories.ImportRecordPlatform},
+	{column: "status", field: "status_id", model: &entities.Status{}, kind: repositories.ImportRecordStatus},
+	{column: "payment_method", field: "payment_method_id", model: &entities.PaymentMethod{}},
+	{column: "receiver", field: "receiver_id", model: &entities.Receiver{}},
+	{column: "sale_person", field: "sale_person_id", model: &entities.SalePerson{}},
+	{column: "channel", field: "channel_id", model: &entities.Channel{}},
+	{column: "bank", field: "bank_id", model: &entities.Bank{}},
+}
+
+// importColumn is a column of the sheet being imported: a field, a lookup,
+// or neither when it is skipped.
+type importColumn struct {
+	key    string
+	field  *importField
+	lookup *importLookup
+}
+
+// importIncome is the income described by a group of rows sharing an
+// invoice number. Row is its first row and detailRows the row of each of
+// its details.
+type importIncome struct {
+	row         int
+	req         dtos.CreateIncomeRequest
+	detailRows  []int
+	newPlatform string
+	newStatus   string
+}
+
+// filledOnSave lists the lookup fields of the lookup records the import
+// creates, whose ids are filled in on save.
+func (i *importIncome) filledOnSave() []string {
+	var filled []string
+	if i.newPlatform != "" {
+		filled = append(filled, "platform_id")
+	}
+	if i.newStatus != "" {
+		filled = append(filled, "status_id")
+	}
+	return filled
+}
+
+// ImportIncomes reads a sheet of incomes, one row per income or, when it
+// has detail_ columns, one row per line with the income's columns read from
+// its first row. Every row is checked before anything is saved; an import
+// with errors saves nothing and reports them by row. On a dry run the
+// result reports the errors instead of failing.
+func (s *incomeService) ImportIncomes(ctx context.Context, r io.Reader, opts dtos.ImportOptions) (dtos.ImportResult, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.ImportIncomes")
+	defer span.End()
+
+	// Read one byte past the limit to tell when the file is larger.
+	data, err := io.ReadAll(io.LimitReader(r, MaxImportSize+1))
+	if err != nil {
+		return dtos.ImportResult{}, fmt.Errorf("failed to read import file: %w", err)
+	}
+	if len(data) > MaxImportSize {
+		return dtos.ImportResult{}, NewValidationError("import_too_large", "the file is too large", utils.FieldError{
+			Field: "file", Rule: "max", Message: fmt.Sprintf("must be at most %d MB", MaxImportSize>>20),
+		})
+	}
+
+	records, err := readImportSheet(opts.FileName, bytes.NewReader(data))
+	if err != nil {
+		return dtos.ImportResult{}, err
+	}
+	if len(records) == 0 {
+		return dtos.ImportResult{}, NewValidationError("empty_import", "the file has no header row")
+	}
+
+	columns, err := importColumns(records[0], opts.Mapping)
+	if err != nil {
+		return dtos.ImportResult{}, err
+	}
+	hasDetails := slices.ContainsFunc(columns, func(c importColumn) bool { return c.field != nil && c.field.detail })
+
+	lookupIds := map[string]map[string]int{}
+	for _, c := range columns {
+		if c.lookup == nil {
+			continue
+		}
+		ids, err := s.importBatchRepository.GetLookupIds(ctx, c.lookup.model)
+		if err != nil {
+			return dtos.ImportResult{}, wrapError(err, "failed to get "+c.lookup.column+" names")
+		}
+		lookupIds[c.lookup.column] = ids
+	}
+
+	result := dtos.ImportResult{
+		DryRun:           opts.DryRun,
+		CreatedPlatforms: []string{},
+		CreatedStatuses:  []string{},
+		Errors:           []dtos.ImportRowError{},
+	}
+	rowErrors := map[int][]utils.FieldError{}
+	rowInvoices := map[int]int{}
+
+	// newNames spells each record to create as the file first did.
+	newNames := map[string]map[string]string{
+		repositories.ImportRecordPlatform: {},
+		repositories.ImportRecordStatus:   {},
+	}
+	newName := func(kind, name string) string {
+		key := strings.ToLower(name)
+		if spelled, ok := newNames[kind][key]; ok {
+			return spelled
+		}
+		newNames[kind][key] = name
+		switch kind {
+		case repositories.ImportRecordPlatform:
+			result.CreatedPlatforms = append(result.CreatedPlatforms, name)
+		case repositories.ImportRecordStatus:
+			result.CreatedStatuses = append(result.CreatedStatuses, name)
+		}
+		return name
+	}
+
+	var incomes []*importIncome
+	byInvoice := map[int]*importIncome{}
+	for i, record := range records[1:] {
+		row := i + 2
+		if isBlankRow(record) {
+			continue
+		}
+		result.Rows++
+
+		var invoiceIdNumber int
+		for j, c := range columns {
+			if c.key != "invoice_id_number" || j >= len(record) {
+				continue
+			}
+			if err := setImportValue(reflect.ValueOf(&invoiceIdNumber).Elem(), record[j]); err != nil {
+				rowErrors[row] = append(rowErrors[row], importFormatError(c.key, err))
+			}
+		}
+		if invoiceIdNumber == 0 {
+			if len(rowErrors[row]) == 0 {
+				rowErrors[row] = append(rowErrors[row], utils.FieldError{Field: "invoice_id_number", Rule: "required", Message: "is required"})
+			}
+			continue
+		}
+		rowInvoices[row] = invoiceIdNumber
+
+		income := byInvoice[invoiceIdNumber]
+		if income != nil && !hasDetails {
+			rowErrors[row] = append(rowErrors[row], utils.FieldError{
+				Field:   "invoice_id_number",
+				Rule:    "unique",
+				Message: fmt.Sprintf("repeats row %d", income.row),
+			})
+			continue
+		}
+
+		if income == nil {
+			income = &importIncome{row: row}
+			byInvoice[invoiceIdNumber] = income
+			incomes = append(incomes, income)
+
+			req := reflect.ValueOf(&income.req).Elem()
+			for j, c := range columns {
+				if j >= len(record) || strings.TrimSpace(record[j]) == "" {
+					continue
+				}
+				value := strings.TrimSpace(record[j])
+				switch {
+				case c.field != nil && !c.field.detail:
+					if err := setImportValue(req.FieldByIndex(c.field.index), value); err != nil {
+						rowErrors[row] = append(rowErrors[row], importFormatError(c.key, err))
+					}
+				case c.lookup != nil:
+					if id, ok := lookupIds[c.lookup.column][strings.ToLower(value)]; ok {
+						req.FieldByIndex(importFields[c.lookup.field].index).SetInt(int64(id))
+						continue
+					}
+					switch {
+					case opts.CreateMissing && c.lookup.kind == repositories.ImportRecordPlatform:
+						income.newPlatform = newName(c.lookup.kind, value)
+					case opts.CreateMissing && c.lookup.kind == repositories.ImportRecordStatus:
+						income.newStatus = newName(c.lookup.kind, value)
+					default:
+						rowErrors[row] = append(rowErrors[row], utils.FieldError{
+							Field:   c.key,
+							Rule:    "exists",
+							Message: fmt.Sprintf("there is no %s named %q", strings.ReplaceAll(c.key, "_", " "), value),
+						})
+					}
+				}
+			}
+		}
+
+		if hasDetails {
+			var detail dtos.IncomeDetailRequest
+			v := reflect.ValueOf(&detail).Elem()
+			for j, c := range columns {
+				if c.field == nil || !c.field.detail || j >= len(record) || strings.TrimSpace(record[j]) == "" {
+					continue
+				}
+				if err := setImportValue(v.FieldByIndex(c.field.index), strings.TrimSpace(record[j])); err != nil {
+					rowErrors[row] = append(rowErrors[row], importFormatError(c.key, err))
+				}
+			}
+			if detail != (dtos.IncomeDetailRequest{}) {
+				income.req.Details = append(income.req.Details, detail)
+				income.detailRows = append(income.detailRows, row)
+			}
+		}
+	}
+
+	imported := make([]repositories.ImportedIncome, 0, len(incomes))
+	for _, income := range incomes {
+		if checkImportIncome(ctx, income, rowErrors) {
+			continue
+		}
+		data, err := s.newIncome(ctx, income.req)
+		if err == nil {
+			err = checkInvoiceFields(data, income.filledOnSave()...)
+		}
+		if err != nil {
+			var domainErr *DomainError
+			if !errors.As(err, &domainErr) {
+				return dtos.ImportResult{}, err
+			}
+			rowErrors[income.row] = append(rowErrors[income.row], importDomainErrors(domainErr)...)
+			continue
+		}
+		imported = append(imported, repositories.ImportedIncome{
+			Income:      data,
+			NewPlatform: income.newPlatform,
+			NewStatus:   income.newStatus,
+		})
+	}
+	result.Incomes = len(incomes)
+
+	var fields []utils.FieldError
+	for row := 1; row <= len(records); row++ {
+		if len(rowErrors[row]) == 0 {
+			continue
+		}
+		result.Errors = append(result.Errors, dtos.ImportRowError{Row: row, InvoiceIdNumber: rowInvoices[row], Fields: rowErrors[row]})
+		for _, fe := range rowErrors[row] {
+			fe.Field = fmt.Sprintf("rows[%d].%s", row, fe.Field)
+			fields = append(fields, fe)
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	if len(fields) > 0 {
+		return dtos.ImportResult{}, NewValidationError("invalid_import", "the file has invalid rows", fields...)
+	}
+	if len(imported) == 0 {
+		return dtos.ImportResult{}, NewValidationError("empty_import", "the file has no incomes")
+	}
+
+	batch, err := s.importBatchRepository.CreateImportBatch(ctx, entities.ImportBatch{
+		FileName: opts.FileName,
+		Source:   opts.Source,
+	}, imported)
+	if err != nil {
+		return dtos.ImportResult{}, wrapError(err, "failed to save import")
+	}
+	result.BatchId = batch.Id
+
+	saved := make([]entities.Income, len(imported))
+	for i, income := range imported {
+		saved[i] = income.Income
+	}
+	if err := recalculateCommissions(ctx, s.commissionEngine, saved...); err != nil {
+		return dtos.ImportResult{}, err
+	}
+
+	return result, nil
+}
+
+// checkImportIncome applies the binding rules to an income and its details,
+// adding failures to the rows they came from. It reports whether any row of
+// the income has errors.
+func checkImportIncome(ctx context.Context, income *importIncome, rowErrors map[int][]utils.FieldError) bool {
+	req := income.req
+	req.Details = nil
+	var errs validator.ValidationErrors
+	if err := importValidator.StructCtx(ctx, req); errors.As(err, &errs) {
+		for _, fe := range utils.ValidationFieldErrors(errs) {
+			if !reported(rowErrors[income.row], fe.Field) {
+				rowErrors[income.row] = append(rowErrors[income.row], fe)
+			}
+		}
+	}
+
+	for i, detail := range income.req.Details {
+		if err := importValidator.StructCtx(ctx, detail); errors.As(err, &errs) {
+			row := income.detailRows[i]
+			for _, fe := range utils.ValidationFieldErrors(errs) {
+				fe.Field = "detail_" + fe.Field
+				if !reported(rowErrors[row], fe.Field) {
+					rowErrors[row] = append(rowErrors[row], fe)
+				}
+			}
+		}
+	}
+
+	if len(rowErrors[income.row]) > 0 {
+		return true
+	}
+	for _, row := range income.detailRows {
+		if len(rowErrors[row]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// reported tells whether a row already has an error for field or for the
+// lookup column filling it, so that a cell which could not be read is not
+// reported again as missing.
+func reported(errs []utils.FieldError, field string) bool {
+	for _, fe := range errs {
+		if fe.Field == field {
+			return true
+		}
+		for _, l := range importLookups {
+			if l.field == field && fe.Field == l.column {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *incomeService) GetAllImportBatch(ctx context.Context) ([]dtos.ImportBatch, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.GetAllImportBatch")
+	defer span.End()
+
+	batches, err := s.importBatchRepository.GetAllImportBatch(ctx)
+	if err != nil {
+		return []dtos.ImportBatch{}, wrapError(err, "failed to get import batches")
+	}
+
+	res := make([]dtos.ImportBatch, len(batches))
+	for i, b := range batches {
+		res[i] = toImportBatchDTO(b)
+	}
+	return res, nil
+}
+
+// RollbackImportBatch deletes the incomes of an import, refusing once
+// expenses, influencer assignments or bank payments have been recorded
+// against them.
+func (s *incomeService) RollbackImportBatch(ctx context.Context, importBatchId int) (dtos.ImportBatch, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.RollbackImportBatch")
+	defer span.End()
+
+	batch, err := s.importBatchRepository.GetImportBatchById(ctx, importBatchId)
+	if err != nil {
+		return dtos.ImportBatch{}, wrapError(err, "failed to get import batch")
+	}
+	if !batch.RolledBackAt.IsZero() {
+		return dtos.ImportBatch{}, NewConflictError("import_batch_rolled_back", "import batch is already rolled back")
+	}
+
+	dependents, err := s.importBatchRepository.CountImportBatchDependents(ctx, importBatchId)
+	if err != nil {
+		return dtos.ImportBatch{}, wrapError(err, "failed to check import batch")
+	}
+	if dependents > 0 {
+		return dtos.ImportBatch{}, NewConflictError("import_batch_in_use",
+			fmt.Sprintf("%d expenses, influencer assignments or bank payments refer to incomes of this import", dependents))
+	}
+
+	incomes, err := s.importBatchRepository.RollbackImportBatch(ctx, importBatchId)
+	if err != nil {
+		return dtos.ImportBatch{}, wrapError(err, "failed to roll back import batch")
+	}
+	if err := recalculateCommissions(ctx, s.commissionEngine, incomes...); err != nil {
+		return dtos.ImportBatch{}, err
+	}
+
+	batch, err = s.importBatchRepository.GetImportBatchById(ctx, importBatchId)
+	if err != nil {
+		return dtos.ImportBatch{}, wrapError(err, "failed to get import batch")
+	}
+	return toImportBatchDTO(batch), nil
+}
+
+func toImportBatchDTO(b entities.ImportBatch) dtos.ImportBatch {
+	return dtos.ImportBatch{
+		Id:           b.Id,
+		FileName:     b.FileName,
+		Source:       b.Source,
+		Incomes:      b.Incomes,
+		CreatedAt:    b.CreatedAt,
+		RolledBackAt: b.RolledBackAt,
+	}
+}
+
+// readImportSheet reads the rows of a CSV file or of the first sheet of an
+// XLSX workbook, telling them apart by the file name's extension.
+func readImportSheet(name string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		br := bufio.NewReader(r)
+		// Spreadsheet programs start UTF-8 CSV files with a byte order mark.
+		if bom, err := br.Peek(3); err == nil && string(bom) == "\ufeff" {
+			br.Discard(3)
+		}
+		reader := csv.NewReader(br)
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, NewValidationError("invalid_import_file", "the file is not valid CSV", utils.FieldError{
+				Field: "file", Rule: "csv", Message: err.Error(),
+			})
+		}
+		return records, nil
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, NewValidationError("invalid_import_file", "the file is not a valid XLSX workbook", utils.FieldError{
+				Field: "file", Rule: "xlsx", Message: err.Error(),
+			})
+		}
+		defer f.Close()
+		// Raw values keep dates as serial numbers and amounts unformatted.
+		rows, err := f.GetRows(f.GetSheetName(0), excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workbook: %w", err)
+		}
+		return rows, nil
+	}
+	return nil, NewValidationError("invalid_import_file", "the file must be CSV or XLSX", utils.FieldError{
+		Field: "file", Rule: "oneof", Message: "must have a .csv or .xlsx extension",
+	})
+}
+
+// importColumns resolves the header row. Headers are matched after
+// mapping, ignoring case and treating spaces and dashes as underscores.
+func importColumns(header []string, mapping map[string]string) ([]importColumn, error) {
+	mapped := make(map[string]string, len(mapping))
+	for from, to := range mapping {
+		mapped[normalizeImportHeader(from)] = normalizeImportHeader(to)
+	}
+
+	columns := make([]importColumn, len(header))
+	seen := map[string]bool{}
+	var fields []utils.FieldError
+	for i, h := range header {
+		key := normalizeImportHeader(h)
+		if to, ok := mapped[key]; ok {
+			key = to
+		}
+		if key == "" {
+			continue
+		}
+		if seen[key] {
+			fields = append(fields, utils.FieldError{Field: key, Rule: "unique", Message: "is filled by more than one column"})
+			continue
+		}
+		seen[key] = true
+
+		columns[i].key = key
+		if f, ok := importFields[key]; ok {
+			columns[i].field = &f
+			continue
+		}
+		if j := slices.IndexFunc(importLookups, func(l importLookup) bool { return l.column == key }); j >= 0 {
+			columns[i].lookup = &importLookups[j]
+			continue
+		}
+		fields = append(fields, utils.FieldError{
+			Field:   strings.TrimSpace(h),
+			Rule:    "column",
+			Message: "is not an income field; map it to one or to \"\" to skip it",
+		})
+	}
+	if !seen["invoice_id_number"] {
+		fields = append(fields, utils.FieldError{Field: "invoice_id_number", Rule: "required", Message: "column is required"})
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid_import_columns", "the file's columns do not match income fields", fields...)
+	}
+	return columns, nil
+}
+
+func normalizeImportHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(h)
+}
+
+func isBlankRow(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// setImportValue parses a cell into a field of a request. Blank cells
+// leave the field unset.
+func setImportValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		p := reflect.New(v.Type().Elem())
+		if err := setImportValue(p.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+
+	switch v.Type() {
+	case reflect.TypeOf(time.Time{}):
+		t, err := parseImportDate(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case reflect.TypeOf(money.Amount(0)):
+		a, err := money.Parse(strings.ReplaceAll(raw, ",", ""))
+		if err != nil {
+			return errors.New("must be an amount such as 1500.00")
+		}
+		v.SetInt(int64(a))
+		return nil
+	case reflect.TypeOf(taxid.ID("")):
+		v.SetString(taxid.Normalize(raw))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.ReplaceAll(raw, ",", ""))
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		switch strings.ToLower(raw) {
+		case "true", "yes", "y", "1":
+			v.SetBool(true)
+		case "false", "no", "n", "0":
+			v.SetBool(false)
+		default:
+			return errors.New("must be true or false")
+		}
+	default:
+		return fmt.Errorf("cannot be imported")
+	}
+	return nil
+}
+
+var importDateLayouts = []string{time.RFC3339, "2006-01-02", "2006-01-02 15:04:05", "02/01/2006"}
+
+// parseImportDate reads a date as ISO 8601, as day/month/year, or as the
+// serial number a spreadsheet stores. Thai spreadsheets often write Buddhist
+// Era years.
+func parseImportDate(raw string) (time.Time, error) {
+	if serial, err := strconv.ParseFloat(raw, 64); err == nil {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+	for _, layout := range importDateLayouts {
+		t, err := time.Parse(layout, raw)
+		if err != nil {
+			continue
+		}
+		return fromBuddhistEra(t), nil
+	}
+	return time.Time{}, errors.New("must be a date such as 2026-10-19 or 19/10/2026")
+}
+
+// fromBuddhistEra converts a date written with a Buddhist Era year, which
+// runs 543 years ahead, to the Gregorian calendar. Dates with years up to
+// 2400 are taken to be Gregorian already.
+func fromBuddhistEra(t time.Time) time.Time {
+	if t.Year() > 2400 {
+		return t.AddDate(-543, 0, 0)
+	}
+	return t
+}
+
+func importFormatError(field string, err error) utils.FieldError {
+	return utils.FieldError{Field: field, Rule: "format", Message: err.Error()}
+}
+
+// importDomainErrors describes why an income of the sheet cannot be saved.
+func importDomainErrors(err *DomainError) []utils.FieldError {
+	if len(err.Fields) > 0 {
+		return err.Fields
+	}
+	field := ""
+	if err.Code == "invoice_id_number_taken" {
+		field = "invoice_id_number"
+	}
+	return []utils.FieldError{{Field: field, Rule: err.Code, Message: err.Message}}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"strings"
+	"testing"
+	"time"
+)
+
+const importHeader = "invoice_id_number,quotation_id_number,quotation_issue_date,quotation_due_date," +
+	"agency_tax_payer_id_number,agency_agency_name,agency_address,agency_phone_number," +
+	"contactor_contactor_name,contactor_phone_number,contactor_line,contactor_email," +
+	"brand_brand_name,brand_product,terms_and_conditions,total_payment_amount," +
+	"platform,status_id,payment_method_id,receiver_id,sale_person_id,channel_id,bank_id"
+
+func importRow(invoice, taxId, platform string) string {
+	return invoice + ",1,2026-10-01,2026-10-15," +
+		taxId + ",Agency,Bangkok 10110,021234567," +
+		"Somchai,0812345678,somchai,somchai@example.com," +
+		"Brand,Product,Net 30,1070.00," +
+		platform + ",1,1,1,1,1,1"
+}
+
+func importSheet(rows ...string) *strings.Reader {
+	return strings.NewReader(importHeader + "\n" + strings.Join(rows, "\n") + "\n")
+}
+
+func newImportService() (*incomeService, *fakeImportBatchRepository, *fakeCommissionEngine) {
+	batches := &fakeImportBatchRepository{lookupIds: map[string]int{"tiktok": 1}}
+	engine := &fakeCommissionEngine{}
+	service := &incomeService{
+		incomeRepository:      &fakeIncomeRepository{existing: map[int]entities.Income{500: {InvoiceIdNumber: 500}}},
+		importBatchRepository: batches,
+		commissionEngine:      engine,
+	}
+	return service, batches, engine
+}
+
+func TestImportIncomesDryRunSavesNothing(t *testing.T) {
+	service, batches, engine := newImportService()
+
+	result, err := service.ImportIncomes(context.Background(), importSheet(
+		importRow("101", "0105553000415", "TikTok"),
+		importRow("102", "0105553000415", "Instagram"),
+	), dtos.ImportOptions{FileName: "incomes.csv", DryRun: true, CreateMissing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Rows != 2 || result.Incomes != 2 || len(result.Errors) != 0 {
+		t.Errorf("result = %+v, want 2 valid rows", result)
+	}
+	if len(result.CreatedPlatforms) != 1 || result.CreatedPlatforms[0] != "Instagram" {
+		t.Errorf("created platforms = %v, want the missing one reported", result.CreatedPlatforms)
+	}
+	if result.BatchId != 0 || len(batches.created) != 0 {
+		t.Errorf("a dry run saved batch %d", result.BatchId)
+	}
+	if len(engine.months) != 0 {
+		t.Errorf("a dry run recalculated commissions for %v", engine.months)
+	}
+}
+
+func TestImportIncomesReportsRowErrors(t *testing.T) {
+	sheet := func() *strings.Reader {
+		return importSheet(
+			importRow("101", "0105553000415", "TikTok"),
+			importRow("102", "0105553000416", "TikTok"),
+			importRow("500", "0105553000415", "TikTok"),
+			importRow("103", "0105553000415", "Unknown"),
+			importRow("101", "0105553000415", "TikTok"),
+		)
+	}
+	wantErrors := map[int]string{
+		3: "agency_tax_payer_id_number",
+		4: "invoice_id_number",
+		5: "platform",
+		6: "invoice_id_number",
+	}
+
+	service, batches, _ := newImportService()
+	result, err := service.ImportIncomes(context.Background(), sheet(), dtos.ImportOptions{FileName: "incomes.csv", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != len(wantErrors) {
+		t.Fatalf("errors = %+v, want rows 3 to 6 reported", result.Errors)
+	}
+	for _, e := range result.Errors {
+		if len(e.Fields) == 0 || e.Fields[0].Field != wantErrors[e.Row] {
+			t.Errorf("row %d: errors %+v, want one on %s", e.Row, e.Fields, wantErrors[e.Row])
+		}
+	}
+
+	// The same file imported for real is refused as a whole.
+	_, err = service.ImportIncomes(context.Background(), sheet(), dtos.ImportOptions{FileName: "incomes.csv"})
+	var domainErr *DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != "invalid_import" {
+		t.Fatalf("error = %v, want invalid_import", err)
+	}
+	if len(batches.created) != 0 {
+		t.Error("an import with invalid rows saved a batch")
+	}
+}
+
+func TestImportIncomesSavesOneBatch(t *testing.T) {
+	service, batches, _ := newImportService()
+
+	result, err := service.ImportIncomes(context.Background(), importSheet(
+		importRow("101", "0105553000415", "TikTok"),
+		importRow("102", "0105553000415", "Instagram"),
+	), dtos.ImportOptions{FileName: "incomes.csv", Source: ImportSourceAPI, CreateMissing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.BatchId != 1 || len(batches.created) != 1 {
+		t.Fatalf("saved %d batches, want the file saved as one", len(batches.created))
+	}
+	imported := batches.created[0]
+	if len(imported) != 2 || imported[0].Income.InvoiceIdNumber != 101 || imported[1].Income.InvoiceIdNumber != 102 {
+		t.Fatalf("imported %+v, want incomes 101 and 102", imported)
+	}
+	if imported[0].Income.PlatformId != 1 || imported[0].NewPlatform != "" {
+		t.Errorf("income 101 platform = %d %q, want the existing TikTok", imported[0].Income.PlatformId, imported[0].NewPlatform)
+	}
+	if imported[1].NewPlatform != "Instagram" {
+		t.Errorf("income 102 new platform = %q, want Instagram", imported[1].NewPlatform)
+	}
+}
+
+func TestRollbackImportBatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		batch      entities.ImportBatch
+		dependents int64
+		code       string
+	}{
+		{"rolls back", entities.ImportBatch{Id: 7}, 0, ""},
+		{"refuses a batch already rolled back", entities.ImportBatch{Id: 7, RolledBackAt: time.Now()}, 0, "import_batch_rolled_back"},
+		{"refuses once records refer to its incomes", entities.ImportBatch{Id: 7}, 2, "import_batch_in_use"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, batches, _ := newImportService()
+			batches.batch, batches.dependents = tt.batch, tt.dependents
+
+			_, err := service.RollbackImportBatch(context.Background(), 7)
+			var domainErr *DomainError
+			switch {
+			case tt.code == "" && err != nil:
+				t.Fatal(err)
+			case tt.code != "" && (!errors.As(err, &domainErr) || domainErr.Code != tt.code):
+				t.Fatalf("error = %v, want %s", err, tt.code)
+			}
+
+			wantRolledBack := 0
+			if tt.code == "" {
+				wantRolledBack = 1
+			}
+			if len(batches.rolledBack) != wantRolledBack {
+				t.Errorf("rolled back %v", batches.rolledBack)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// createStage is the stage an income created from req has reached: a
+// receipt when it has a receipt number, an invoice when it has been
+// invoiced and a quotation otherwise.
+func createStage(req dtos.CreateIncomeRequest) string {
+	switch {
+	case req.ReceiptIdNumber != 0:
+		return repositories.IncomeStageReceipt
+	case !req.InvoiceIssueDate.IsZero():
+		return repositories.IncomeStageInvoice
+	}
+	return repositories.IncomeStageQuotation
+}
+
+// stageField is a request field set only once the income reaches its
+// stage, and whether the request sets it.
+type stageField struct {
+	Name string
+	Set  bool
+}
+
+// checkStageFields rejects the invoice fields of a quotation and the
+// receipt fields of a quotation or an invoice. They are set by converting
+// the quotation to an invoice and by issuing the receipt, which number the
+// income and check its dates and balance.
+func checkStageFields(stage string, invoiceFields, receiptFields []stageField) error {
+	var problems []utils.FieldError
+	reject := func(fields []stageField, message string) {
+		for _, f := range fields {
+			if f.Set {
+				problems = append(problems, utils.FieldError{Field: f.Name, Rule: "stage", Message: message})
+			}
+		}
+	}
+	if stage == repositories.IncomeStageQuotation {
+		reject(invoiceFields, "is set by converting the quotation to an invoice")
+	}
+	if stage != repositories.IncomeStageReceipt {
+		reject(receiptFields, "is set by issuing the receipt")
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return NewValidationError("field_before_stage", fmt.Sprintf("the income is at the %s stage and cannot take the fields of a later one", stage), problems...)
+}
+
+// checkInvoiceFields rejects an invoice or a receipt that leaves out a field
+// a quotation may do without: what is sold and on which terms, its total
+// and its lookups. filled names the fields that are filled in when the
+// income is saved.
+func checkInvoiceFields(income entities.Income, filled ...string) error {
+	if income.Stage == repositories.IncomeStageQuotation {
+		return nil
+	}
+	required := []stageField{
+		{"brand_product", income.BrandProduct != ""},
+		{"terms_and_conditions", income.TermsAndConditions != ""},
+		{"total_payment_amount", !income.TotalPaymentAmount.IsZero()},
+		{"platform_id", income.PlatformId != 0},
+		{"status_id", income.StatusId != 0},
+		{"payment_method_id", income.PaymentMethodId != 0},
+		{"receiver_id", income.ReceiverId != 0},
+		{"sale_person_id", income.SalePersonId != 0},
+		{"channel_id", income.ChannelId != 0},
+		{"bank_id", income.BankId != 0},
+	}
+	var problems []utils.FieldError
+	for _, f := range required {
+		if !f.Set && !slices.Contains(filled, f.Name) {
+			problems = append(problems, utils.FieldError{Field: f.Name, Rule: "required", Message: "is required once the income is invoiced"})
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return NewValidationError("field_required_for_stage", fmt.Sprintf("the income is at the %s stage and is missing fields only a quotation may leave out", income.Stage), problems...)
+}
+
+// ConvertToInvoice invoices a quotation. The invoice takes the next invoice
+// number unless the request gives one, or the quotation was saved under
+// one before quotations had their own series. It is dated today unless the
+// request dates it, and its balance is the total less any payment already
+// recorded.
+func (s *incomeService) ConvertToInvoice(ctx context.Context, incomeInvoiceIdNumber int, req dtos.ConvertToInvoiceRequest) (dtos.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.ConvertToInvoice")
+	defer span.End()
+
+	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.Income{}, wrapError(err, "failed to get income")
+	}
+	if income.Stage != repositories.IncomeStageQuotation {
+		return dtos.Income{}, NewConflictError("income_not_quotation", fmt.Sprintf("only a quotation can be converted to an invoice, the income is already at the %s stage", income.Stage))
+	}
+
+	invoiceIdNumber := req.InvoiceIdNumber
+	if invoiceIdNumber == 0 && income.InvoiceIdNumber > 0 {
+		invoiceIdNumber = income.InvoiceIdNumber
+	} else if invoiceIdNumber != 0 && invoiceIdNumber != income.InvoiceIdNumber {
+		if err := s.checkInvoiceIdNumberFree(ctx, invoiceIdNumber); err != nil {
+			return dtos.Income{}, err
+		}
+	}
+
+	issueDate := helpers.DefaultIfEmpty(req.InvoiceIssueDate, dateOf(time.Now()))
+	if dateOf(req.InvoiceDueDate).Before(dateOf(issueDate)) {
+		return dtos.Income{}, NewValidationError("invalid_invoice_due_date", "invoice due date cannot be before its issue date", utils.FieldError{
+			Field:   "invoice_due_date",
+			Rule:    "gtefield",
+			Message: fmt.Sprintf("must be on or after the invoice issue date %s", issueDate.Format("2006-01-02")),
+		})
+	}
+
+	income.InvoiceIdNumber = invoiceIdNumber
+	income.InvoiceIssueDate = issueDate
+	income.InvoiceDueDate = req.InvoiceDueDate
+	income.Stage = repositories.IncomeStageInvoice
+	if err := checkInvoiceFields(income); err != nil {
+		return dtos.Income{}, err
+	}
+	if income.UnpaidPaymentAmount.IsZero() {
+		income.UnpaidPaymentAmount = max(income.TotalPaymentAmount-income.FirstPayment-income.SecondPayment, 0)
+	}
+
+	updated, err := s.saveStage(ctx, income, repositories.IncomeStageQuotation, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.Income{}, err
+	}
+	return s.toIncomeDTOWithBase(ctx, updated)
+}
+
+// IssueReceipt issues the receipt of an invoice paid in full, recording
+// the request's final payment first when the balance was paid outside bank
+// reconciliation. The receipt takes the next receipt number unless the
+// request gives one, and is dated the day the balance was paid, or today
+// when no payment date was recorded.
+func (s *incomeService) IssueReceipt(ctx context.Context, incomeInvoiceIdNumber int, req dtos.IssueReceiptRequest) (dtos.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.IssueReceipt")
+	defer span.End()
+
+	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.Income{}, wrapError(err, "failed to get income")
+	}
+	switch income.Stage {
+	case repositories.IncomeStageQuotation:
+		return dtos.Income{}, NewConflictError("income_not_invoiced", "convert the quotation to an invoice before issuing its receipt")
+	case repositories.IncomeStageReceipt:
+		return dtos.Income{}, NewConflictError("receipt_already_issued", fmt.Sprintf("receipt %d has already been issued for the income", income.ReceiptIdNumber))
+	}
+	before := income
+	if req.FinalPayment > 0 {
+		if _, err := applyPayment(&income, req.FinalPayment, helpers.DefaultIfEmpty(req.NotesForTheFinalPayment, "Paid in full"), "final_payment"); err != nil {
+			return dtos.Income{}, err
+		}
+	}
+	if !income.UnpaidPaymentAmount.IsZero() {
+		return dtos.Income{}, NewConflictError("income_unpaid", fmt.Sprintf("a receipt is issued once the income is paid in full, %s is still unpaid", income.UnpaidPaymentAmount))
+	}
+
+	if req.ReceiptIdNumber != 0 {
+		if _, err := s.incomeRepository.GetIncomeByReceiptIdNumber(ctx, req.ReceiptIdNumber); err == nil {
+			return dtos.Income{}, NewConflictError("receipt_id_number_taken", "receipt id number must be unique")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return dtos.Income{}, wrapError(err, "failed to get income")
+		}
+	}
+
+	issueDate := helpers.DefaultIfEmpty(req.ReceiptIssueDate, helpers.DefaultIfEmpty(income.ReceiptIssueDate, dateOf(time.Now())))
+	if dateOf(issueDate).Before(dateOf(income.InvoiceIssueDate)) {
+		return dtos.Income{}, NewValidationError("invalid_receipt_issue_date", "receipt issue date cannot be before the invoice issue date", utils.FieldError{
+			Field:   "receipt_issue_date",
+			Rule:    "gtefield",
+			Message: fmt.Sprintf("must be on or after the invoice issue date %s", income.InvoiceIssueDate.Format("2006-01-02")),
+		})
+	}
+
+	income.ReceiptIdNumber = req.ReceiptIdNumber
+	income.ReceiptIssueDate = issueDate
+	income.Stage = repositories.IncomeStageReceipt
+
+	updated, err := s.saveStage(ctx, income, repositories.IncomeStageInvoice, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.Income{}, err
+	}
+	if err := recalculateCommissions(ctx, s.commissionEngine, before, updated); err != nil {
+		return dtos.Income{}, err
+	}
+
+	return s.toIncomeDTOWithBase(ctx, updated)
+}
+
+// saveStage saves an income that has moved on from the stage from,
+// numbering it and moving it and its records to its new invoice number
+// when it was renumbered, and returns it as stored. It fails with a
+// conflict when another request moved the income on first.
+func (s *incomeService) saveStage(ctx context.Context, income entities.Income, from string, oldInvoiceIdNumber int) (entities.Income, error) {
+	saved, err := s.incomeRepository.SaveStage(ctx, incomeRow(income), from, oldInvoiceIdNumber)
+	if errors.Is(err, repositories.ErrStageChanged) {
+		return entities.Income{}, NewConflictError("income_stage_changed", "the income was moved to another stage by another request, reload it and try again")
+	} else if err != nil {
+		return entities.Income{}, wrapError(err, "failed to update income")
+	}
+
+	updated, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, saved.InvoiceIdNumber)
+	if err != nil {
+		return entities.Income{}, wrapError(err, "failed to get income")
+	}
+	return updated, nil
+}
+
+// incomeRow is the income without its preloaded associations, so that
+// saving it writes the incomes row alone.
+func incomeRow(i entities.Income) entities.Income {
+	i.Platform, i.Status, i.PaymentMethod = entities.Platform{}, entities.Status{}, entities.PaymentMethod{}
+	i.Receiver, i.SalePerson, i.Channel, i.Bank = entities.Receiver{}, entities.SalePerson{}, entities.Channel{}, entities.Bank{}
+	i.Agency, i.Contact, i.Brand, i.ImportBatch = nil, nil, nil, nil
+	i.Details, i.Expenses = nil, nil
+	return i
+}
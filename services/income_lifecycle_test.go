@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestUpdateIncomeRejectsLaterStageFields(t *testing.T) {
+	day := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	service := &incomeService{incomeRepository: &fakeIncomeRepository{existing: map[int]entities.Income{
+		-1: {InvoiceIdNumber: -1, QuotationIdNumber: 1, Stage: repositories.IncomeStageQuotation},
+		2:  {InvoiceIdNumber: 2, Stage: repositories.IncomeStageInvoice},
+	}}}
+
+	// Every stage field is set; only those of a later stage may be listed.
+	req := dtos.UpdateIncomeRequest{
+		QuotationIdNumber:  5,
+		InvoiceIdNumber:    7,
+		InvoiceIssueDate:   day,
+		InvoiceDueDate:     day,
+		ReceiptIdNumber:    9,
+		ReceiptIssueDate:   day,
+		QuotationIssueDate: day,
+	}
+	tests := []struct {
+		name       string
+		number     int
+		wantFields []string
+	}{
+		{"quotation", -1, []string{"invoice_id_number", "invoice_issue_date", "invoice_due_date", "receipt_id_number", "receipt_issue_date"}},
+		{"invoice", 2, []string{"receipt_id_number"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.UpdateIncome(context.Background(), tt.number, req)
+			var domainErr *DomainError
+			if !errors.As(err, &domainErr) || domainErr.Code != "field_before_stage" {
+				t.Fatalf("UpdateIncome = %v, want field_before_stage", err)
+			}
+			var fields []string
+			for _, f := range domainErr.Fields {
+				fields = append(fields, f.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestCreateQuotationRejectsInvoiceNumber(t *testing.T) {
+	service := &incomeService{incomeRepository: &fakeIncomeRepository{}}
+	_, err := service.CreateIncome(context.Background(), dtos.CreateIncomeRequest{InvoiceIdNumber: 7})
+	var domainErr *DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != "field_before_stage" || len(domainErr.Fields) != 1 || domainErr.Fields[0].Field != "invoice_id_number" {
+		t.Errorf("CreateIncome = %v, want invoice_id_number refused", err)
+	}
+}
+
+func TestReceiptForInvoicePaidOutsideBankReconciliation(t *testing.T) {
+	ctx := context.Background()
+	incomes := &fakeIncomeRepository{existing: map[int]entities.Income{
+		-1: {
+			InvoiceIdNumber: -1, QuotationIdNumber: 1, Stage: repositories.IncomeStageQuotation,
+			BrandProduct: "Serum", TermsAndConditions: "Net 30", TotalPaymentAmount: money.FromInt(1070), FirstPayment: money.FromInt(500),
+			PlatformId: 1, StatusId: 1, PaymentMethodId: 1, ReceiverId: 1, SalePersonId: 1, ChannelId: 1, BankId: 1,
+		},
+	}}
+	service := &incomeService{
+		incomeRepository:       incomes,
+		currencyRateRepository: &fakeCurrencyRateRepository{},
+		commissionEngine:       &fakeCommissionEngine{},
+	}
+	day := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	invoice, err := service.ConvertToInvoice(ctx, -1, dtos.ConvertToInvoiceRequest{InvoiceIssueDate: day, InvoiceDueDate: day})
+	if err != nil {
+		t.Fatalf("ConvertToInvoice: %v", err)
+	}
+	if invoice.InvoiceIdNumber != 1 || invoice.UnpaidPaymentAmount != money.FromInt(570) {
+		t.Fatalf("invoice %d owes %s, want invoice 1 owing 570.00", invoice.InvoiceIdNumber, invoice.UnpaidPaymentAmount)
+	}
+
+	var domainErr *DomainError
+	if _, err := service.IssueReceipt(ctx, 1, dtos.IssueReceiptRequest{}); !errors.As(err, &domainErr) || domainErr.Code != "income_unpaid" {
+		t.Fatalf("IssueReceipt without the final payment = %v, want income_unpaid", err)
+	}
+	if _, err := service.IssueReceipt(ctx, 1, dtos.IssueReceiptRequest{FinalPayment: money.FromInt(600)}); !errors.As(err, &domainErr) || domainErr.Code != "payment_exceeds_balance" {
+		t.Fatalf("IssueReceipt overpaying = %v, want payment_exceeds_balance", err)
+	}
+
+	receipt, err := service.IssueReceipt(ctx, 1, dtos.IssueReceiptRequest{ReceiptIssueDate: day, FinalPayment: money.FromInt(570), NotesForTheFinalPayment: "Cash"})
+	if err != nil {
+		t.Fatalf("IssueReceipt: %v", err)
+	}
+	if receipt.Stage != repositories.IncomeStageReceipt || receipt.ReceiptIdNumber != 1 {
+		t.Errorf("income is at the %s stage with receipt %d, want receipt 1", receipt.Stage, receipt.ReceiptIdNumber)
+	}
+	if receipt.UnpaidPaymentAmount != 0 || receipt.SecondPayment != money.FromInt(570) || receipt.NotesForTheSecondPayment != "Cash" {
+		t.Errorf("unpaid %s, second payment %s %q, want 0.00 and 570.00 \"Cash\"", receipt.UnpaidPaymentAmount, receipt.SecondPayment, receipt.NotesForTheSecondPayment)
+	}
+}
+
+func TestCreateBareQuotation(t *testing.T) {
+	ctx := context.Background()
+	service := &incomeService{
+		incomeRepository:       &fakeIncomeRepository{existing: map[int]entities.Income{}},
+		currencyRateRepository: &fakeCurrencyRateRepository{},
+		commissionEngine:       &fakeCommissionEngine{},
+	}
+	day := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	// The client and the quotation's dates are all a quotation needs.
+	req := dtos.CreateIncomeRequest{
+		QuotationIdNumber:      3,
+		QuotationIssueDate:     day,
+		QuotationDueDate:       day,
+		AgencyTaxPayerIdNumber: "0105553000415",
+		AgencyAgencyName:       "Agency",
+		AgencyAddress:          "Bangkok",
+		AgencyPhoneNumber:      "021234567",
+		ContactorContactorName: "Contact",
+		ContactorPhoneNumber:   "0812345678",
+		ContactorLine:          "contact",
+		ContactorEmail:         "contact@example.com",
+		BrandBrandName:         "Brand",
+	}
+	if err := importValidator.StructCtx(ctx, req); err != nil {
+		t.Fatalf("binding a bare quotation: %v", err)
+	}
+	quotation, err := service.CreateIncome(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateIncome: %v", err)
+	}
+	if quotation.Stage != repositories.IncomeStageQuotation || quotation.InvoiceIdNumber != -3 {
+		t.Errorf("income is a %s stored under %d, want a quotation under -3", quotation.Stage, quotation.InvoiceIdNumber)
+	}
+
+	wantFields := []string{"brand_product", "terms_and_conditions", "total_payment_amount", "platform_id", "status_id", "payment_method_id", "receiver_id", "sale_person_id", "channel_id", "bank_id"}
+	fieldsOf := func(err error) []string {
+		var domainErr *DomainError
+		if !errors.As(err, &domainErr) || domainErr.Code != "field_required_for_stage" {
+			t.Fatalf("err = %v, want field_required_for_stage", err)
+		}
+		var fields []string
+		for _, f := range domainErr.Fields {
+			fields = append(fields, f.Field)
+		}
+		return fields
+	}
+
+	_, err = service.ConvertToInvoice(ctx, -3, dtos.ConvertToInvoiceRequest{InvoiceIssueDate: day, InvoiceDueDate: day})
+	if fields := fieldsOf(err); !slices.Equal(fields, wantFields) {
+		t.Errorf("ConvertToInvoice refused %v, want %v", fields, wantFields)
+	}
+
+	invoice := req
+	invoice.QuotationIdNumber = 4
+	invoice.InvoiceIssueDate, invoice.InvoiceDueDate = day, day
+	_, err = service.CreateIncome(ctx, invoice)
+	if fields := fieldsOf(err); !slices.Equal(fields, wantFields) {
+		t.Errorf("CreateIncome of an invoice refused %v, want %v", fields, wantFields)
+	}
+}
+
+func TestRenumberQuotationMovesIt(t *testing.T) {
+	ctx := context.Background()
+	incomes := &fakeIncomeRepository{existing: map[int]entities.Income{
+		-3: {InvoiceIdNumber: -3, QuotationIdNumber: 3, Stage: repositories.IncomeStageQuotation},
+	}}
+	service := &incomeService{
+		incomeRepository:       incomes,
+		currencyRateRepository: &fakeCurrencyRateRepository{},
+		commissionEngine:       &fakeCommissionEngine{},
+	}
+
+	updated, err := service.UpdateIncome(ctx, -3, dtos.UpdateIncomeRequest{QuotationIdNumber: 8})
+	if err != nil {
+		t.Fatalf("UpdateIncome: %v", err)
+	}
+	if updated.InvoiceIdNumber != -8 || updated.QuotationIdNumber != 8 {
+		t.Errorf("quotation %d is stored under %d, want 8 under -8", updated.QuotationIdNumber, updated.InvoiceIdNumber)
+	}
+	if _, ok := incomes.existing[-3]; ok {
+		t.Error("the quotation is still stored under -3")
+	}
+}
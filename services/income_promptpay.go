@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/promptpay"
+	"mtii-backend/telemetry"
+)
+
+// GetPromptPay returns the PromptPay QR payload for what the client still
+// owes on an income. When the client withholds tax, the amount is the
+// balance net of it, which the bank reconciliation settles in full.
+func (s *incomeService) GetPromptPay(ctx context.Context, incomeInvoiceIdNumber int) (dtos.PromptPay, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.GetPromptPay")
+	defer span.End()
+
+	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.PromptPay{}, wrapError(err, "failed to get income")
+	}
+
+	return promptPay(income)
+}
+
+// promptPay builds the PromptPay payment of an income loaded with its
+// receiver.
+func promptPay(income entities.Income) (dtos.PromptPay, error) {
+	if helpers.DefaultIfEmpty(income.Currency, money.DefaultCurrency) != promptpay.Currency {
+		return dtos.PromptPay{}, NewValidationError("promptpay_currency", "PromptPay only takes payments in "+promptpay.Currency)
+	}
+	if income.ReceiverId == 0 || income.Receiver.PromptPayId == "" {
+		return dtos.PromptPay{}, NewValidationError("promptpay_missing", "set the PromptPay ID of the income's receiver first")
+	}
+
+	amount, withheld := promptPayAmount(income)
+	if amount <= 0 {
+		return dtos.PromptPay{}, NewConflictError("income_paid", "income has no outstanding balance")
+	}
+
+	payload, err := promptpay.Payload(income.Receiver.PromptPayId, amount)
+	if err != nil {
+		return dtos.PromptPay{}, NewValidationError("promptpay_invalid", err.Error())
+	}
+
+	return dtos.PromptPay{
+		IncomeInvoiceIdNumber: income.InvoiceIdNumber,
+		ReceiverName:          income.Receiver.Name,
+		PromptPayId:           income.Receiver.PromptPayId,
+		UnpaidPaymentAmount:   income.UnpaidPaymentAmount,
+		WithholdingTaxAmount:  withheld,
+		Amount:                amount,
+		Currency:              promptpay.Currency,
+		Payload:               payload,
+	}, nil
+}
+
+// promptPayAmount is the transfer that settles the income's balance and
+// the withholding tax deducted from it. applyBankPayment takes a transfer
+// short of the balance by exactly the tax as paying it in full.
+func promptPayAmount(income entities.Income) (amount, withheld money.Amount) {
+	if income.WithholdingTaxAmount > 0 && income.UnpaidPaymentAmount > income.WithholdingTaxAmount {
+		return income.UnpaidPaymentAmount - income.WithholdingTaxAmount, income.WithholdingTaxAmount
+	}
+	return income.UnpaidPaymentAmount, 0
+}
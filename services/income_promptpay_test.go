@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"testing"
+)
+
+func TestPromptPayAmount(t *testing.T) {
+	tests := []struct {
+		name                 string
+		unpaid, withholding  string
+		wantAmount, withheld string
+	}{
+		{name: "no withholding tax", unpaid: "1070.00", withholding: "0", wantAmount: "1070.00", withheld: "0"},
+		{name: "net of withholding tax", unpaid: "1070.00", withholding: "30.00", wantAmount: "1040.00", withheld: "30.00"},
+		// A balance no larger than the tax is what a short payment left.
+		{name: "balance below the tax", unpaid: "20.00", withholding: "30.00", wantAmount: "20.00", withheld: "0"},
+		{name: "paid", unpaid: "0", withholding: "30.00", wantAmount: "0", withheld: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			income := entities.Income{UnpaidPaymentAmount: amount(t, tt.unpaid), WithholdingTaxAmount: amount(t, tt.withholding)}
+			got, withheld := promptPayAmount(income)
+			if got != amount(t, tt.wantAmount) || withheld != amount(t, tt.withheld) {
+				t.Errorf("promptPayAmount = %s, %s, want %s, %s", got, withheld, tt.wantAmount, tt.withheld)
+			}
+		})
+	}
+}
+
+func TestGetInvoicePDF(t *testing.T) {
+	invoice := entities.Income{
+		InvoiceIdNumber:      1001,
+		Stage:                repositories.IncomeStageInvoice,
+		AgencyAgencyName:     "Acme",
+		ReceiverId:           1,
+		Receiver:             entities.Receiver{Name: "MTII", PromptPayId: "0812345678"},
+		TotalPaymentAmount:   money.FromInt(107),
+		UnpaidPaymentAmount:  money.FromInt(107),
+		WithholdingTaxAmount: money.FromInt(3),
+		Details:              []entities.Detail{{Description: "Post", Quantity: 1, UnitPrice: money.FromInt(100)}},
+	}
+	paid := invoice
+	paid.InvoiceIdNumber = 1002
+	paid.UnpaidPaymentAmount = 0
+	noPromptPay := invoice
+	noPromptPay.InvoiceIdNumber = 1003
+	noPromptPay.Receiver.PromptPayId = ""
+	quotation := invoice
+	quotation.InvoiceIdNumber = 1004
+	quotation.Stage = repositories.IncomeStageQuotation
+
+	service := &incomeService{incomeRepository: &fakeIncomeRepository{existing: map[int]entities.Income{
+		1001: invoice, 1002: paid, 1003: noPromptPay, 1004: quotation,
+	}}}
+
+	tests := []struct {
+		name   string
+		number int
+		wantQR bool
+	}{
+		{name: "invoice with a balance", number: 1001, wantQR: true},
+		{name: "paid invoice", number: 1002},
+		{name: "receiver without PromptPay", number: 1003},
+		{name: "quotation", number: 1004},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := service.GetInvoicePDF(context.Background(), tt.number)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(document, []byte("%PDF-")) {
+				t.Fatalf("document starts with %q, want a PDF", document[:min(len(document), 8)])
+			}
+			if got := bytes.Contains(document, []byte("/Subtype /Image")); got != tt.wantQR {
+				t.Errorf("document has an image = %v, want %v", got, tt.wantQR)
+			}
+		})
+	}
+
+	if _, err := service.GetInvoicePDF(context.Background(), 9999); err == nil {
+		t.Error("GetInvoicePDF of an unknown income succeeded")
+	}
+}
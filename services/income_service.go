@@ -2,316 +2,656 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"mtii-backend/dtos"
 	"mtii-backend/entities"
 	"mtii-backend/helpers"
+	"mtii-backend/money"
 	"mtii-backend/repositories"
+	"mtii-backend/taxid"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
 
 	"gorm.io/gorm"
 )
 
 type IncomeService interface {
-	GetAllIncome(ctx context.Context) ([]dtos.Income, error)
+	GetAllIncome(ctx context.Context, query dtos.IncomeQuery) ([]dtos.Income, error)
 	GetIncomeByInvoiceIdNumber(ctx context.Context, incomeInvoiceIdNumber int) (dtos.Income, error)
-	CreateIncome(ctx context.Context, req dtos.CreateIncomeRequest) (dtos.IncomeResponse, error)
-	UpdateIncome(ctx context.Context, incomeInvoiceIdNumber int, req dtos.UpdateIncomeRequest) (dtos.IncomeResponse, error)
+	CreateIncome(ctx context.Context, req dtos.CreateIncomeRequest) (dtos.Income, error)
+	UpdateIncome(ctx context.Context, incomeInvoiceIdNumber int, req dtos.UpdateIncomeRequest) (dtos.Income, error)
 	DeleteIncome(ctx context.Context, incomeInvoiceIdNumber int) error
+	ConvertToInvoice(ctx context.Context, incomeInvoiceIdNumber int, req dtos.ConvertToInvoiceRequest) (dtos.Income, error)
+	IssueReceipt(ctx context.Context, incomeInvoiceIdNumber int, req dtos.IssueReceiptRequest) (dtos.Income, error)
+	ExportIncomes(ctx context.Context, query dtos.IncomeExportQuery, w io.Writer) error
+	ImportIncomes(ctx context.Context, r io.Reader, opts dtos.ImportOptions) (dtos.ImportResult, error)
+	GetAllImportBatch(ctx context.Context) ([]dtos.ImportBatch, error)
+	RollbackImportBatch(ctx context.Context, importBatchId int) (dtos.ImportBatch, error)
+	GetPromptPay(ctx context.Context, incomeInvoiceIdNumber int) (dtos.PromptPay, error)
+	GetETaxInvoice(ctx context.Context, incomeInvoiceIdNumber int, query dtos.ETaxInvoiceQuery) ([]byte, error)
+	GetInvoicePDF(ctx context.Context, incomeInvoiceIdNumber int) ([]byte, error)
 }
 
 type incomeService struct {
-	incomeRepository repositories.IncomeRepository
+	incomeRepository       repositories.IncomeRepository
+	currencyRateRepository repositories.CurrencyRateRepository
+	agencyRepository       repositories.AgencyRepository
+	contactRepository      repositories.ContactRepository
+	brandRepository        repositories.BrandRepository
+	importBatchRepository  repositories.ImportBatchRepository
+	commissionEngine       CommissionEngine
 }
 
 func NewIncomeService(
 	incomeRepository repositories.IncomeRepository,
+	currencyRateRepository repositories.CurrencyRateRepository,
+	agencyRepository repositories.AgencyRepository,
+	contactRepository repositories.ContactRepository,
+	brandRepository repositories.BrandRepository,
+	importBatchRepository repositories.ImportBatchRepository,
+	commissionEngine CommissionEngine,
 ) IncomeService {
 	return &incomeService{
-		incomeRepository: incomeRepository,
+		incomeRepository:       incomeRepository,
+		currencyRateRepository: currencyRateRepository,
+		agencyRepository:       agencyRepository,
+		contactRepository:      contactRepository,
+		brandRepository:        brandRepository,
+		importBatchRepository:  importBatchRepository,
+		commissionEngine:       commissionEngine,
 	}
 }
 
-func (s *incomeService) GetAllIncome(ctx context.Context) ([]dtos.Income, error) {
-	incomes, err := s.incomeRepository.GetAllIncome(ctx)
-	if err != nil {
-		return []dtos.Income{}, fmt.Errorf("failed to get income: %w", err)
-	}
-
-	var incomeDTOs []dtos.Income
-	for _, i := range incomes {
-		incomeDTOs = append(incomeDTOs, dtos.Income{
-			QuotationIdNumber:          i.QuotationIdNumber,
-			QuotationIssueDate:         i.QuotationIssueDate,
-			QuotationDueDate:           i.QuotationDueDate,
-			InvoiceIdNumber:            i.InvoiceIdNumber,
-			InvoiceIssueDate:           i.InvoiceIssueDate,
-			InvoiceDueDate:             i.InvoiceDueDate,
-			ReceiptIssueDate:           i.ReceiptIssueDate,
-			ReceiptIdNumber:            i.ReceiptIdNumber,
-			AgencyTaxPayerIdNumber:     i.AgencyTaxPayerIdNumber,
-			InfluencerPostingDate:      i.InfluencerPostingDate,
-			AgencyAgencyName:           i.AgencyAgencyName,
-			AgencyAddress:              i.AgencyAddress,
-			AgencyPhoneNumber:          i.AgencyPhoneNumber,
-			ContactorContactorName:     i.ContactorContactorName,
-			ContactorPhoneNumber:       i.ContactorPhoneNumber,
-			ContactorLine:              i.ContactorLine,
-			ContactorEmail:             i.ContactorEmail,
-			BrandBrandName:             i.BrandBrandName,
-			BrandProduct:               i.BrandProduct,
-			TransactionReferenceNumber: i.TransactionReferenceNumber,
-			TermsAndConditions:         i.TermsAndConditions,
-			TotalPaymentAmount:         i.TotalPaymentAmount,
-			NotesForTheTotalPayment:    i.NotesForTheTotalPayment,
-			FirstPayment:               i.FirstPayment,
-			NotesForTheFirstPayment:    i.NotesForTheFirstPayment,
-			SecondPayment:              i.SecondPayment,
-			NotesForTheSecondPayment:   i.NotesForTheSecondPayment,
-			UnpaidPaymentAmount:        i.UnpaidPaymentAmount,
-			NotesForTheUnpaidPayment:   i.NotesForTheUnpaidPayment,
-			Platform: dtos.Platform{
-				Id:   i.Platform.Id,
-				Name: i.Platform.Name,
-			},
-			Status: dtos.Status{
-				Id:   i.Status.Id,
-				Name: i.Status.Name,
-			},
-			PaymentMethod: dtos.PaymentMethod{
-				Id:   i.PaymentMethod.Id,
-				Name: i.PaymentMethod.Name,
-			},
-			Receiver: dtos.Receiver{
-				Id:         i.Receiver.Id,
-				Name:       i.Receiver.Name,
-				Address:    i.Receiver.Address,
-				Email:      i.Receiver.Email,
-				Phone:      i.Receiver.Phone,
-				TaxPayerId: i.Receiver.TaxPayerId,
-			},
-			SalePerson: dtos.SalePerson{
-				Id:   i.SalePerson.Id,
-				Name: i.SalePerson.Name,
-			},
-			Channel: dtos.Channel{
-				Id:   i.Channel.Id,
-				Name: i.Channel.Name,
-			},
-			Bank: dtos.Bank{
-				Id:   i.Bank.Id,
-				Name: i.Bank.Name,
-			},
-		})
-	}
+func (s *incomeService) GetAllIncome(ctx context.Context, query dtos.IncomeQuery) ([]dtos.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.GetAllIncome")
+	defer span.End()
 
-	if len(incomeDTOs) == 0 {
-		return []dtos.Income{}, nil
+	incomes, err := s.incomeRepository.GetAllIncome(ctx, query.TaxId)
+	if err != nil {
+		return []dtos.Income{}, wrapError(err, "failed to get income")
 	}
 
-	return incomeDTOs, nil
+	return s.toIncomeDTOs(ctx, incomes)
 }
 
 func (s *incomeService) GetIncomeByInvoiceIdNumber(ctx context.Context, incomeId int) (dtos.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.GetIncomeByInvoiceIdNumber")
+	defer span.End()
+
 	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeId)
 	if err != nil {
-		return dtos.Income{}, fmt.Errorf("failed to get income: %w", err)
-	}
-
-	return dtos.Income{
-		QuotationIdNumber:          income.QuotationIdNumber,
-		QuotationIssueDate:         income.QuotationIssueDate,
-		QuotationDueDate:           income.QuotationDueDate,
-		InvoiceIdNumber:            income.InvoiceIdNumber,
-		InvoiceIssueDate:           income.InvoiceIssueDate,
-		InvoiceDueDate:             income.InvoiceDueDate,
-		ReceiptIssueDate:           income.ReceiptIssueDate,
-		ReceiptIdNumber:            income.ReceiptIdNumber,
-		AgencyTaxPayerIdNumber:     income.AgencyTaxPayerIdNumber,
-		InfluencerPostingDate:      income.InfluencerPostingDate,
-		AgencyAgencyName:           income.AgencyAgencyName,
-		AgencyAddress:              income.AgencyAddress,
-		AgencyPhoneNumber:          income.AgencyPhoneNumber,
-		ContactorContactorName:     income.ContactorContactorName,
-		ContactorPhoneNumber:       income.ContactorPhoneNumber,
-		ContactorLine:              income.ContactorLine,
-		ContactorEmail:             income.ContactorEmail,
-		BrandBrandName:             income.BrandBrandName,
-		BrandProduct:               income.BrandProduct,
-		TransactionReferenceNumber: income.TransactionReferenceNumber,
-		TermsAndConditions:         income.TermsAndConditions,
-		TotalPaymentAmount:         income.TotalPaymentAmount,
-		NotesForTheTotalPayment:    income.NotesForTheTotalPayment,
-		FirstPayment:               income.FirstPayment,
-		NotesForTheFirstPayment:    income.NotesForTheFirstPayment,
-		SecondPayment:              income.SecondPayment,
-		NotesForTheSecondPayment:   income.NotesForTheSecondPayment,
-		UnpaidPaymentAmount:        income.UnpaidPaymentAmount,
-		NotesForTheUnpaidPayment:   income.NotesForTheUnpaidPayment,
-		Platform: dtos.Platform{
-			Id:   income.Platform.Id,
-			Name: income.Platform.Name,
-		},
-		Status: dtos.Status{
-			Id:   income.Status.Id,
-			Name: income.Status.Name,
-		},
-		PaymentMethod: dtos.PaymentMethod{
-			Id:   income.PaymentMethod.Id,
-			Name: income.PaymentMethod.Name,
-		},
-		Receiver: dtos.Receiver{
-			Id:         income.Receiver.Id,
-			Name:       income.Receiver.Name,
-			Address:    income.Receiver.Address,
-			Email:      income.Receiver.Email,
-			Phone:      income.Receiver.Phone,
-			TaxPayerId: income.Receiver.TaxPayerId,
-		},
-		SalePerson: dtos.SalePerson{
-			Id:   income.SalePerson.Id,
-			Name: income.SalePerson.Name,
-		},
-		Channel: dtos.Channel{
-			Id:   income.Channel.Id,
-			Name: income.Channel.Name,
-		},
-		Bank: dtos.Bank{
-			Id:   income.Bank.Id,
-			Name: income.Bank.Name,
-		},
-	}, nil
+		return dtos.Income{}, wrapError(err, "failed to get income")
+	}
+
+	return s.toIncomeDTOWithBase(ctx, income)
 }
 
-func (s *incomeService) CreateIncome(ctx context.Context, req dtos.CreateIncomeRequest) (dtos.IncomeResponse, error) {
-	_, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, req.InvoiceIdNumber)
-	if err == nil {
-		return dtos.IncomeResponse{}, fmt.Errorf("invoice id number must be unique")
-	} else if err != gorm.ErrRecordNotFound {
-		return dtos.IncomeResponse{}, fmt.Errorf("failed to get income: %w", err)
+func (s *incomeService) CreateIncome(ctx context.Context, req dtos.CreateIncomeRequest) (dtos.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.CreateIncome")
+	defer span.End()
+
+	// A quotation is numbered as an invoice when it is converted, and
+	// receipted after that. Imports keep the numbers of their sheet.
+	stage := createStage(req)
+	err := checkStageFields(stage,
+		[]stageField{{"invoice_id_number", req.InvoiceIdNumber != 0}},
+		[]stageField{{"receipt_issue_date", stage == repositories.IncomeStageQuotation && !req.ReceiptIssueDate.IsZero()}})
+	if err != nil {
+		return dtos.Income{}, err
 	}
 
-	data := entities.Income{
-		QuotationIdNumber:          req.QuotationIdNumber,
-		QuotationIssueDate:         req.QuotationIssueDate,
-		QuotationDueDate:           req.QuotationDueDate,
-		InvoiceIdNumber:            req.InvoiceIdNumber,
-		InvoiceIssueDate:           req.InvoiceIssueDate,
-		InvoiceDueDate:             req.InvoiceDueDate,
-		ReceiptIssueDate:           req.ReceiptIssueDate,
-		ReceiptIdNumber:            req.ReceiptIdNumber,
-		AgencyTaxPayerIdNumber:     req.AgencyTaxPayerIdNumber,
-		InfluencerPostingDate:      req.InfluencerPostingDate,
-		AgencyAgencyName:           req.AgencyAgencyName,
-		AgencyAddress:              req.AgencyAddress,
-		AgencyPhoneNumber:          req.AgencyPhoneNumber,
-		ContactorContactorName:     req.ContactorContactorName,
-		ContactorPhoneNumber:       req.ContactorPhoneNumber,
-		ContactorLine:              req.ContactorLine,
-		ContactorEmail:             req.ContactorEmail,
-		BrandBrandName:             req.BrandBrandName,
-		BrandProduct:               req.BrandProduct,
-		TransactionReferenceNumber: req.TransactionReferenceNumber,
-		TermsAndConditions:         req.TermsAndConditions,
-		TotalPaymentAmount:         req.TotalPaymentAmount,
-		NotesForTheTotalPayment:    req.NotesForTheTotalPayment,
-		FirstPayment:               req.FirstPayment,
-		NotesForTheFirstPayment:    req.NotesForTheFirstPayment,
-		SecondPayment:              req.SecondPayment,
-		NotesForTheSecondPayment:   req.NotesForTheSecondPayment,
-		UnpaidPaymentAmount:        req.UnpaidPaymentAmount,
-		NotesForTheUnpaidPayment:   req.NotesForTheUnpaidPayment,
-		PlatformId:                 req.PlatformId,
-		StatusId:                   req.StatusId,
-		PaymentMethodId:            req.PaymentMethodId,
-		ReceiverId:                 req.ReceiverId,
-		SalePersonId:               req.SalePersonId,
-		ChannelId:                  req.ChannelId,
-		BankId:                     req.BankId,
+	data, err := s.newIncome(ctx, req)
+	if err != nil {
+		return dtos.Income{}, err
+	}
+	if err := checkInvoiceFields(data); err != nil {
+		return dtos.Income{}, err
 	}
 
+	// The income and its lines are inserted in the same transaction.
 	income, err := s.incomeRepository.CreateIncome(ctx, data)
 	if err != nil {
-		return dtos.IncomeResponse{}, fmt.Errorf("failed to save income: %w", err)
+		return dtos.Income{}, wrapError(err, "failed to save income")
+	}
+
+	created, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, income.InvoiceIdNumber)
+	if err != nil {
+		return dtos.Income{}, wrapError(err, "failed to get income")
+	}
+	if err := recalculateCommissions(ctx, s.commissionEngine, created); err != nil {
+		return dtos.Income{}, err
+	}
+
+	return s.toIncomeDTOWithBase(ctx, created)
+}
+
+// newIncome builds the income described by req and checks it can be
+// saved: its invoice number must be free, its links to master data must
+// exist and its pricing must add up. An income without its stage's number
+// is numbered when it is saved.
+func (s *incomeService) newIncome(ctx context.Context, req dtos.CreateIncomeRequest) (entities.Income, error) {
+	if req.InvoiceIdNumber != 0 {
+		if err := s.checkInvoiceIdNumberFree(ctx, req.InvoiceIdNumber); err != nil {
+			return entities.Income{}, err
+		}
+	}
+
+	data := entities.Income{
+		QuotationIdNumber:            req.QuotationIdNumber,
+		QuotationIssueDate:           req.QuotationIssueDate,
+		QuotationDueDate:             req.QuotationDueDate,
+		InvoiceIdNumber:              req.InvoiceIdNumber,
+		InvoiceIssueDate:             req.InvoiceIssueDate,
+		InvoiceDueDate:               req.InvoiceDueDate,
+		ReceiptIssueDate:             req.ReceiptIssueDate,
+		ReceiptIdNumber:              req.ReceiptIdNumber,
+		Stage:                        createStage(req),
+		AgencyTaxPayerIdNumber:       req.AgencyTaxPayerIdNumber,
+		AgencyBranchNumber:           helpers.DefaultIfEmpty(req.AgencyBranchNumber, taxid.HeadOffice),
+		InfluencerPostingDate:        req.InfluencerPostingDate,
+		AgencyAgencyName:             req.AgencyAgencyName,
+		AgencyAddress:                req.AgencyAddress,
+		AgencyPhoneNumber:            req.AgencyPhoneNumber,
+		ContactorContactorName:       req.ContactorContactorName,
+		ContactorPhoneNumber:         req.ContactorPhoneNumber,
+		ContactorLine:                req.ContactorLine,
+		ContactorEmail:               req.ContactorEmail,
+		BrandBrandName:               req.BrandBrandName,
+		BrandProduct:                 req.BrandProduct,
+		TransactionReferenceNumber:   req.TransactionReferenceNumber,
+		TermsAndConditions:           req.TermsAndConditions,
+		TotalPaymentAmount:           req.TotalPaymentAmount,
+		NotesForTheTotalPayment:      req.NotesForTheTotalPayment,
+		FirstPayment:                 req.FirstPayment,
+		NotesForTheFirstPayment:      req.NotesForTheFirstPayment,
+		SecondPayment:                req.SecondPayment,
+		NotesForTheSecondPayment:     req.NotesForTheSecondPayment,
+		UnpaidPaymentAmount:          req.UnpaidPaymentAmount,
+		NotesForTheUnpaidPayment:     req.NotesForTheUnpaidPayment,
+		Currency:                     helpers.DefaultIfEmpty(req.Currency, money.DefaultCurrency),
+		DiscountType:                 req.DiscountType,
+		DiscountValue:                req.DiscountValue,
+		WithholdingTaxRate:           req.WithholdingTaxRate,
+		WithholdingCertificateNumber: req.WithholdingCertificateNumber,
+		WithholdingCertificateDate:   req.WithholdingCertificateDate,
+		PlatformId:                   req.PlatformId,
+		StatusId:                     req.StatusId,
+		PaymentMethodId:              req.PaymentMethodId,
+		ReceiverId:                   req.ReceiverId,
+		SalePersonId:                 req.SalePersonId,
+		ChannelId:                    req.ChannelId,
+		BankId:                       req.BankId,
+		Details:                      toDetailEntities(req.Details),
+	}
+
+	if err := s.linkCustomer(ctx, &data, req.AgencyId, req.ContactId, req.BrandId); err != nil {
+		return entities.Income{}, err
+	}
+
+	if err := validatePricing(data.DiscountType, data.DiscountValue, data.Details); err != nil {
+		return entities.Income{}, err
+	}
+
+	if req.ValidateTotal {
+		if err := validateIncomeTotal(data.DiscountType, data.DiscountValue, data.Details, data.TotalPaymentAmount); err != nil {
+			return entities.Income{}, err
+		}
+	}
+
+	if err := applyIncomeWithholdingTax(&data, data.Details); err != nil {
+		return entities.Income{}, err
 	}
 
-	return dtos.IncomeResponse{
-		InvoiceIdNumber: income.InvoiceIdNumber,
-	}, nil
+	return data, nil
+}
+
+func (s *incomeService) checkInvoiceIdNumberFree(ctx context.Context, invoiceIdNumber int) error {
+	_, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, invoiceIdNumber)
+	if err == nil {
+		return NewConflictError("invoice_id_number_taken", "invoice id number must be unique")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return wrapError(err, "failed to get income")
+	}
+	return nil
 }
 
-func (s *incomeService) UpdateIncome(ctx context.Context, incomeInvoiceIdNumber int, req dtos.UpdateIncomeRequest) (dtos.IncomeResponse, error) {
+func (s *incomeService) UpdateIncome(ctx context.Context, incomeInvoiceIdNumber int, req dtos.UpdateIncomeRequest) (dtos.Income, error) {
+	ctx, span := telemetry.Start(ctx, "IncomeService.UpdateIncome")
+	defer span.End()
+
 	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
 	if err != nil {
-		return dtos.IncomeResponse{}, fmt.Errorf("failed to get income: %w", err)
+		return dtos.Income{}, wrapError(err, "failed to get income")
+	}
+
+	// The receipt issue date of an invoice is the day it was paid, which
+	// its receipt takes, so only a quotation is refused one.
+	err = checkStageFields(income.Stage,
+		[]stageField{
+			{"invoice_id_number", req.InvoiceIdNumber != 0},
+			{"invoice_issue_date", !req.InvoiceIssueDate.IsZero()},
+			{"invoice_due_date", !req.InvoiceDueDate.IsZero()},
+		},
+		[]stageField{
+			{"receipt_id_number", req.ReceiptIdNumber != 0},
+			{"receipt_issue_date", income.Stage == repositories.IncomeStageQuotation && !req.ReceiptIssueDate.IsZero()},
+		})
+	if err != nil {
+		return dtos.Income{}, err
 	}
 
 	data := entities.Income{
-		InvoiceIdNumber:            helpers.DefaultIfEmpty(req.InvoiceIdNumber, incomeInvoiceIdNumber),
-		QuotationIdNumber:          helpers.DefaultIfEmpty(req.QuotationIdNumber, income.QuotationIdNumber),
-		QuotationIssueDate:         helpers.DefaultIfEmpty(req.QuotationIssueDate, income.QuotationIssueDate),
-		QuotationDueDate:           helpers.DefaultIfEmpty(req.QuotationDueDate, income.QuotationDueDate),
-		InvoiceIssueDate:           helpers.DefaultIfEmpty(req.InvoiceIssueDate, income.InvoiceIssueDate),
-		InvoiceDueDate:             helpers.DefaultIfEmpty(req.InvoiceDueDate, income.InvoiceDueDate),
-		ReceiptIssueDate:           helpers.DefaultIfEmpty(req.ReceiptIssueDate, income.ReceiptIssueDate),
-		ReceiptIdNumber:            helpers.DefaultIfEmpty(req.ReceiptIdNumber, income.ReceiptIdNumber),
-		AgencyTaxPayerIdNumber:     helpers.DefaultIfEmpty(req.AgencyTaxPayerIdNumber, income.AgencyTaxPayerIdNumber),
-		InfluencerPostingDate:      helpers.DefaultIfEmpty(req.InfluencerPostingDate, income.InfluencerPostingDate),
-		AgencyAgencyName:           helpers.DefaultIfEmpty(req.AgencyAgencyName, income.AgencyAgencyName),
-		AgencyAddress:              helpers.DefaultIfEmpty(req.AgencyAddress, income.AgencyAddress),
-		AgencyPhoneNumber:          helpers.DefaultIfEmpty(req.AgencyPhoneNumber, income.AgencyPhoneNumber),
-		ContactorContactorName:     helpers.DefaultIfEmpty(req.ContactorContactorName, income.ContactorContactorName),
-		ContactorPhoneNumber:       helpers.DefaultIfEmpty(req.ContactorPhoneNumber, income.ContactorPhoneNumber),
-		ContactorLine:              helpers.DefaultIfEmpty(req.ContactorLine, income.ContactorLine),
-		ContactorEmail:             helpers.DefaultIfEmpty(req.ContactorEmail, income.ContactorEmail),
-		BrandBrandName:             helpers.DefaultIfEmpty(req.BrandBrandName, income.BrandBrandName),
-		BrandProduct:               helpers.DefaultIfEmpty(req.BrandProduct, income.BrandProduct),
-		TransactionReferenceNumber: helpers.DefaultIfEmpty(req.TransactionReferenceNumber, income.TransactionReferenceNumber),
-		TermsAndConditions:         helpers.DefaultIfEmpty(req.TermsAndConditions, income.TermsAndConditions),
-		TotalPaymentAmount:         helpers.DefaultIfEmpty(req.TotalPaymentAmount, income.TotalPaymentAmount),
-		NotesForTheTotalPayment:    helpers.DefaultIfEmpty(req.NotesForTheTotalPayment, income.NotesForTheTotalPayment),
-		FirstPayment:               helpers.DefaultIfEmpty(req.FirstPayment, income.FirstPayment),
-		NotesForTheFirstPayment:    helpers.DefaultIfEmpty(req.NotesForTheFirstPayment, income.NotesForTheFirstPayment),
-		SecondPayment:              helpers.DefaultIfEmpty(req.SecondPayment, income.SecondPayment),
-		NotesForTheSecondPayment:   helpers.DefaultIfEmpty(req.NotesForTheSecondPayment, income.NotesForTheSecondPayment),
-		UnpaidPaymentAmount:        helpers.DefaultIfEmpty(req.UnpaidPaymentAmount, income.UnpaidPaymentAmount),
-		NotesForTheUnpaidPayment:   helpers.DefaultIfEmpty(req.NotesForTheUnpaidPayment, income.NotesForTheUnpaidPayment),
-		PlatformId:                 helpers.DefaultIfEmpty(req.PlatformId, income.PlatformId),
-		StatusId:                   helpers.DefaultIfEmpty(req.StatusId, income.StatusId),
-		PaymentMethodId:            helpers.DefaultIfEmpty(req.PaymentMethodId, income.PaymentMethodId),
-		ReceiverId:                 helpers.DefaultIfEmpty(req.ReceiverId, income.ReceiverId),
-		SalePersonId:               helpers.DefaultIfEmpty(req.SalePersonId, income.SalePersonId),
-		ChannelId:                  helpers.DefaultIfEmpty(req.ChannelId, income.ChannelId),
-		BankId:                     helpers.DefaultIfEmpty(req.BankId, income.BankId),
+		InvoiceIdNumber:              helpers.DefaultIfEmpty(req.InvoiceIdNumber, incomeInvoiceIdNumber),
+		QuotationIdNumber:            helpers.DefaultIfEmpty(req.QuotationIdNumber, income.QuotationIdNumber),
+		QuotationIssueDate:           helpers.DefaultIfEmpty(req.QuotationIssueDate, income.QuotationIssueDate),
+		QuotationDueDate:             helpers.DefaultIfEmpty(req.QuotationDueDate, income.QuotationDueDate),
+		InvoiceIssueDate:             helpers.DefaultIfEmpty(req.InvoiceIssueDate, income.InvoiceIssueDate),
+		InvoiceDueDate:               helpers.DefaultIfEmpty(req.InvoiceDueDate, income.InvoiceDueDate),
+		ReceiptIssueDate:             helpers.DefaultIfEmpty(req.ReceiptIssueDate, income.ReceiptIssueDate),
+		ReceiptIdNumber:              helpers.DefaultIfEmpty(req.ReceiptIdNumber, income.ReceiptIdNumber),
+		Stage:                        income.Stage,
+		AgencyTaxPayerIdNumber:       helpers.DefaultIfEmpty(req.AgencyTaxPayerIdNumber, income.AgencyTaxPayerIdNumber),
+		AgencyBranchNumber:           helpers.DefaultIfEmpty(req.AgencyBranchNumber, income.AgencyBranchNumber),
+		InfluencerPostingDate:        helpers.DefaultIfEmpty(req.InfluencerPostingDate, income.InfluencerPostingDate),
+		AgencyAgencyName:             helpers.DefaultIfEmpty(req.AgencyAgencyName, income.AgencyAgencyName),
+		AgencyAddress:                helpers.DefaultIfEmpty(req.AgencyAddress, income.AgencyAddress),
+		AgencyPhoneNumber:            helpers.DefaultIfEmpty(req.AgencyPhoneNumber, income.AgencyPhoneNumber),
+		ContactorContactorName:       helpers.DefaultIfEmpty(req.ContactorContactorName, income.ContactorContactorName),
+		ContactorPhoneNumber:         helpers.DefaultIfEmpty(req.ContactorPhoneNumber, income.ContactorPhoneNumber),
+		ContactorLine:                helpers.DefaultIfEmpty(req.ContactorLine, income.ContactorLine),
+		ContactorEmail:               helpers.DefaultIfEmpty(req.ContactorEmail, income.ContactorEmail),
+		BrandBrandName:               helpers.DefaultIfEmpty(req.BrandBrandName, income.BrandBrandName),
+		BrandProduct:                 helpers.DefaultIfEmpty(req.BrandProduct, income.BrandProduct),
+		TransactionReferenceNumber:   helpers.DefaultIfEmpty(req.TransactionReferenceNumber, income.TransactionReferenceNumber),
+		TermsAndConditions:           helpers.DefaultIfEmpty(req.TermsAndConditions, income.TermsAndConditions),
+		TotalPaymentAmount:           helpers.DefaultIfEmpty(req.TotalPaymentAmount, income.TotalPaymentAmount),
+		NotesForTheTotalPayment:      helpers.DefaultIfEmpty(req.NotesForTheTotalPayment, income.NotesForTheTotalPayment),
+		FirstPayment:                 helpers.DefaultIfEmpty(req.FirstPayment, income.FirstPayment),
+		NotesForTheFirstPayment:      helpers.DefaultIfEmpty(req.NotesForTheFirstPayment, income.NotesForTheFirstPayment),
+		SecondPayment:                helpers.DefaultIfEmpty(req.SecondPayment, income.SecondPayment),
+		NotesForTheSecondPayment:     helpers.DefaultIfEmpty(req.NotesForTheSecondPayment, income.NotesForTheSecondPayment),
+		UnpaidPaymentAmount:          helpers.DefaultIfEmpty(req.UnpaidPaymentAmount, income.UnpaidPaymentAmount),
+		NotesForTheUnpaidPayment:     helpers.DefaultIfEmpty(req.NotesForTheUnpaidPayment, income.NotesForTheUnpaidPayment),
+		Currency:                     helpers.DefaultIfEmpty(req.Currency, income.Currency),
+		DiscountType:                 normalizeDiscountType(helpers.DefaultIfEmpty(req.DiscountType, income.DiscountType)),
+		DiscountValue:                helpers.DefaultIfEmpty(req.DiscountValue, income.DiscountValue),
+		WithholdingTaxRate:           income.WithholdingTaxRate,
+		WithholdingCertificateNumber: helpers.DefaultIfEmpty(req.WithholdingCertificateNumber, income.WithholdingCertificateNumber),
+		WithholdingCertificateDate:   helpers.DefaultIfEmpty(req.WithholdingCertificateDate, income.WithholdingCertificateDate),
+		PlatformId:                   helpers.DefaultIfEmpty(req.PlatformId, income.PlatformId),
+		StatusId:                     helpers.DefaultIfEmpty(req.StatusId, income.StatusId),
+		PaymentMethodId:              helpers.DefaultIfEmpty(req.PaymentMethodId, income.PaymentMethodId),
+		ReceiverId:                   helpers.DefaultIfEmpty(req.ReceiverId, income.ReceiverId),
+		SalePersonId:                 helpers.DefaultIfEmpty(req.SalePersonId, income.SalePersonId),
+		ChannelId:                    helpers.DefaultIfEmpty(req.ChannelId, income.ChannelId),
+		BankId:                       helpers.DefaultIfEmpty(req.BankId, income.BankId),
+		AgencyId:                     income.AgencyId,
+		ContactId:                    income.ContactId,
+		BrandId:                      income.BrandId,
+		ImportBatchId:                income.ImportBatchId,
+	}
+
+	// A quotation is stored under the negative of its quotation number, so
+	// renumbering it moves it and its records.
+	if income.Stage == repositories.IncomeStageQuotation && incomeInvoiceIdNumber == -income.QuotationIdNumber {
+		data.InvoiceIdNumber = -data.QuotationIdNumber
+	}
+
+	if err := s.linkCustomer(ctx, &data, req.AgencyId, req.ContactId, req.BrandId); err != nil {
+		return dtos.Income{}, err
+	}
+
+	details := income.Details
+	if req.Details != nil {
+		details, err = mergeDetails(income.Details, req.Details)
+		if err != nil {
+			return dtos.Income{}, err
+		}
+	}
+
+	if data.DiscountType == "" {
+		data.DiscountValue = 0
+	}
+	if err := validatePricing(data.DiscountType, data.DiscountValue, details); err != nil {
+		return dtos.Income{}, err
+	}
+
+	if req.ValidateTotal {
+		if err := validateIncomeTotal(data.DiscountType, data.DiscountValue, details, data.TotalPaymentAmount); err != nil {
+			return dtos.Income{}, err
+		}
+	}
+
+	if req.WithholdingTaxRate != nil {
+		data.WithholdingTaxRate = *req.WithholdingTaxRate
+	}
+	if err := applyIncomeWithholdingTax(&data, details); err != nil {
+		return dtos.Income{}, err
 	}
 
 	var updatedIncome entities.Income
-	if data.InvoiceIdNumber != incomeInvoiceIdNumber {
+	switch {
+	case req.Details != nil:
+		data.Details = details
+		updatedIncome, err = s.incomeRepository.SaveIncomeWithDetails(ctx, data, incomeInvoiceIdNumber)
+		if err != nil {
+			return dtos.Income{}, wrapError(err, "failed to update income")
+		}
+	case data.InvoiceIdNumber != incomeInvoiceIdNumber:
 		updatedIncome, err = s.incomeRepository.UpdateIncomeWithNewInvoiceIdNumber(ctx, data, incomeInvoiceIdNumber)
 		if err != nil {
-			return dtos.IncomeResponse{}, fmt.Errorf("failed to update income: %w", err)
+			return dtos.Income{}, wrapError(err, "failed to update income")
 		}
-	} else {
+	default:
 		updatedIncome, err = s.incomeRepository.UpdateIncome(ctx, data)
 		if err != nil {
-			return dtos.IncomeResponse{}, fmt.Errorf("failed to update income: %w", err)
+			return dtos.Income{}, wrapError(err, "failed to update income")
 		}
 	}
 
-	return dtos.IncomeResponse{
-		InvoiceIdNumber: updatedIncome.InvoiceIdNumber,
-	}, nil
+	updated, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, updatedIncome.InvoiceIdNumber)
+	if err != nil {
+		return dtos.Income{}, wrapError(err, "failed to get income")
+	}
+	if err := recalculateCommissions(ctx, s.commissionEngine, income, updated); err != nil {
+		return dtos.Income{}, err
+	}
+
+	return s.toIncomeDTOWithBase(ctx, updated)
 }
 
 func (s *incomeService) DeleteIncome(ctx context.Context, incomeInvoiceIdNumber int) error {
+	ctx, span := telemetry.Start(ctx, "IncomeService.DeleteIncome")
+	defer span.End()
+
 	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, incomeInvoiceIdNumber)
 	if err != nil {
-		return fmt.Errorf("failed to get income: %w", err)
+		return wrapError(err, "failed to get income")
 	}
 
 	err = s.incomeRepository.DeleteIncome(ctx, income.InvoiceIdNumber)
 	if err != nil {
-		return fmt.Errorf("failed to delete income: %w", err)
+		return wrapError(err, "failed to delete income")
+	}
+	return recalculateCommissions(ctx, s.commissionEngine, income)
+}
+
+// recalculateCommissions refreshes the commission months of the paid
+// incomes given, which are an income as it was and as it is after a
+// change. Every month is refreshed even when one fails, and the first
+// failure is returned so the caller does not report stale commissions as
+// done; recalculating the period repairs it.
+func recalculateCommissions(ctx context.Context, engine CommissionEngine, incomes ...entities.Income) error {
+	var first error
+	done := map[string]bool{}
+	for _, i := range incomes {
+		if !isPaid(i) {
+			continue
+		}
+		month := monthOf(i.ReceiptIssueDate)
+		key := fmt.Sprintf("%d/%s", i.SalePersonId, month.Format("2006-01"))
+		if done[key] {
+			continue
+		}
+		done[key] = true
+		if err := engine.RecalculateMonth(ctx, i.SalePersonId, month); err != nil && first == nil {
+			first = fmt.Errorf("failed to recalculate the %s commissions of sale person %d: %w", month.Format("2006-01"), i.SalePersonId, err)
+		}
+	}
+	return first
+}
+
+// toIncomeDTOs converts incomes together with their base-currency amounts,
+// loading the rates of all their currencies in one query.
+func (s *incomeService) toIncomeDTOs(ctx context.Context, incomes []entities.Income) ([]dtos.Income, error) {
+	table, err := incomeRateTable(ctx, s.currencyRateRepository, incomes)
+	if err != nil {
+		return []dtos.Income{}, err
+	}
+
+	incomeDTOs := []dtos.Income{}
+	for _, i := range incomes {
+		income := toIncomeDTO(i)
+		income.Base = baseAmounts(i, income.IncomeTotals, income.Profitability, table)
+		incomeDTOs = append(incomeDTOs, income)
+	}
+	return incomeDTOs, nil
+}
+
+func (s *incomeService) toIncomeDTOWithBase(ctx context.Context, i entities.Income) (dtos.Income, error) {
+	incomeDTOs, err := s.toIncomeDTOs(ctx, []entities.Income{i})
+	if err != nil {
+		return dtos.Income{}, err
+	}
+	return incomeDTOs[0], nil
+}
+
+// baseAmounts converts an income to the base currency, or returns nil when
+// no rate covers its invoice date.
+func baseAmounts(i entities.Income, totals dtos.IncomeTotals, profitability dtos.IncomeProfitability, rates rateTable) *dtos.IncomeBaseAmounts {
+	invoiceRate, invoiceRateDate, ok := rates.rateOn(i.Currency, i.InvoiceIssueDate)
+	if !ok {
+		return nil
+	}
+	paymentRate, paymentRateDate, ok := rates.rateOn(i.Currency, i.ReceiptIssueDate)
+	if i.ReceiptIssueDate.IsZero() || !ok {
+		paymentRate, paymentRateDate = invoiceRate, invoiceRateDate
+	}
+
+	return &dtos.IncomeBaseAmounts{
+		Currency:            money.BaseCurrency,
+		InvoiceRate:         invoiceRate,
+		InvoiceRateDate:     invoiceRateDate,
+		PaymentRate:         paymentRate,
+		PaymentRateDate:     paymentRateDate,
+		TotalPaymentAmount:  invoiceRate.Convert(i.TotalPaymentAmount),
+		FirstPayment:        paymentRate.Convert(i.FirstPayment),
+		SecondPayment:       paymentRate.Convert(i.SecondPayment),
+		UnpaidPaymentAmount: invoiceRate.Convert(i.UnpaidPaymentAmount),
+		Subtotal:            invoiceRate.Convert(totals.Subtotal),
+		DiscountAmount:      invoiceRate.Convert(totals.DiscountAmount),
+		VatAmount:           invoiceRate.Convert(totals.VatAmount),
+		GrandTotal:          invoiceRate.Convert(totals.GrandTotal),
+		Revenue:             invoiceRate.Convert(profitability.Revenue),
+		Expenses:            invoiceRate.Convert(profitability.Expenses),
+		GrossMargin:         invoiceRate.Convert(profitability.GrossMargin),
+	}
+}
+
+func toIncomeDTO(i entities.Income) dtos.Income {
+	income := dtos.Income{
+		QuotationIdNumber:            i.QuotationIdNumber,
+		QuotationIssueDate:           i.QuotationIssueDate,
+		QuotationDueDate:             i.QuotationDueDate,
+		InvoiceIdNumber:              i.InvoiceIdNumber,
+		InvoiceIssueDate:             i.InvoiceIssueDate,
+		InvoiceDueDate:               i.InvoiceDueDate,
+		ReceiptIssueDate:             i.ReceiptIssueDate,
+		ReceiptIdNumber:              i.ReceiptIdNumber,
+		Stage:                        i.Stage,
+		AgencyTaxPayerIdNumber:       i.AgencyTaxPayerIdNumber,
+		AgencyBranchNumber:           i.AgencyBranchNumber,
+		InfluencerPostingDate:        i.InfluencerPostingDate,
+		AgencyAgencyName:             i.AgencyAgencyName,
+		AgencyAddress:                i.AgencyAddress,
+		AgencyPhoneNumber:            i.AgencyPhoneNumber,
+		ContactorContactorName:       i.ContactorContactorName,
+		ContactorPhoneNumber:         i.ContactorPhoneNumber,
+		ContactorLine:                i.ContactorLine,
+		ContactorEmail:               i.ContactorEmail,
+		BrandBrandName:               i.BrandBrandName,
+		BrandProduct:                 i.BrandProduct,
+		AgencyId:                     i.AgencyId,
+		ContactId:                    i.ContactId,
+		BrandId:                      i.BrandId,
+		TransactionReferenceNumber:   i.TransactionReferenceNumber,
+		TermsAndConditions:           i.TermsAndConditions,
+		TotalPaymentAmount:           i.TotalPaymentAmount,
+		NotesForTheTotalPayment:      i.NotesForTheTotalPayment,
+		FirstPayment:                 i.FirstPayment,
+		NotesForTheFirstPayment:      i.NotesForTheFirstPayment,
+		SecondPayment:                i.SecondPayment,
+		NotesForTheSecondPayment:     i.NotesForTheSecondPayment,
+		UnpaidPaymentAmount:          i.UnpaidPaymentAmount,
+		NotesForTheUnpaidPayment:     i.NotesForTheUnpaidPayment,
+		Currency:                     i.Currency,
+		DiscountType:                 i.DiscountType,
+		DiscountValue:                i.DiscountValue,
+		WithholdingTaxRate:           i.WithholdingTaxRate,
+		WithholdingTaxAmount:         i.WithholdingTaxAmount,
+		WithholdingCertificateNumber: i.WithholdingCertificateNumber,
+		WithholdingCertificateDate:   i.WithholdingCertificateDate,
+		Platform: dtos.Platform{
+			Id:   i.Platform.Id,
+			Name: i.Platform.Name,
+		},
+		Status: dtos.Status{
+			Id:   i.Status.Id,
+			Name: i.Status.Name,
+		},
+		PaymentMethod: dtos.PaymentMethod{
+			Id:   i.PaymentMethod.Id,
+			Name: i.PaymentMethod.Name,
+		},
+		Receiver: dtos.Receiver{
+			Id:         i.Receiver.Id,
+			Name:       i.Receiver.Name,
+			Address:    i.Receiver.Address,
+			Email:      i.Receiver.Email,
+			Phone:      i.Receiver.Phone,
+			TaxPayerId: i.Receiver.TaxPayerId,
+		},
+		SalePerson: dtos.SalePerson{
+			Id:   i.SalePerson.Id,
+			Name: i.SalePerson.Name,
+		},
+		Channel: dtos.Channel{
+			Id:   i.Channel.Id,
+			Name: i.Channel.Name,
+		},
+		Bank: dtos.Bank{
+			Id:   i.Bank.Id,
+			Name: i.Bank.Name,
+		},
+	}
+	income.Details = toIncomeDetailDTOs(i.Details)
+	income.IncomeTotals = incomeTotals(i.DiscountType, i.DiscountValue, i.Details)
+	income.Profitability = incomeProfitability(income.IncomeTotals, i.Expenses)
+	return income
+}
+
+func toIncomeDetailDTOs(details []entities.Detail) []dtos.IncomeDetail {
+	detailDTOs := []dtos.IncomeDetail{}
+	for _, d := range details {
+		detailDTOs = append(detailDTOs, toIncomeDetailDTO(d))
 	}
+	return detailDTOs
+}
 
+func toIncomeDetailDTO(d entities.Detail) dtos.IncomeDetail {
+	return dtos.IncomeDetail{
+		Id:             d.Id,
+		Description:    d.Description,
+		Notes:          d.Notes,
+		Quantity:       d.Quantity,
+		Unit:           d.Unit,
+		UnitPrice:      d.UnitPrice,
+		Category:       d.Category,
+		DiscountType:   d.DiscountType,
+		DiscountValue:  d.DiscountValue,
+		VatExempt:      d.VatExempt,
+		Position:       d.Position,
+		LineTotal:      lineTotal(d),
+		DiscountAmount: lineTotal(d) - lineNet(d),
+		NetAmount:      lineNet(d),
+	}
+}
+
+func toDetailEntities(reqs []dtos.IncomeDetailRequest) []entities.Detail {
+	details := []entities.Detail{}
+	for i, d := range reqs {
+		details = append(details, entities.Detail{
+			Description:   d.Description,
+			Notes:         d.Notes,
+			Quantity:      d.Quantity,
+			Unit:          d.Unit,
+			UnitPrice:     d.UnitPrice,
+			Category:      d.Category,
+			DiscountType:  d.DiscountType,
+			DiscountValue: d.DiscountValue,
+			VatExempt:     d.VatExempt,
+			Position:      i + 1,
+		})
+	}
+	return details
+}
+
+// mergeDetails applies the requested lines on top of the current ones. A
+// line with an id must already belong to the income; a line without one is
+// new.
+func mergeDetails(current []entities.Detail, reqs []dtos.IncomeDetailRequest) ([]entities.Detail, error) {
+	existing := map[int]bool{}
+	for _, d := range current {
+		existing[d.Id] = true
+	}
+
+	details := []entities.Detail{}
+	for i, d := range reqs {
+		if d.Id != 0 && !existing[d.Id] {
+			return nil, NewValidationError("detail_not_found", "detail does not belong to this income", utils.FieldError{
+				Field:   fmt.Sprintf("details[%d].id", i),
+				Rule:    "exists",
+				Message: fmt.Sprintf("detail %d does not belong to this income", d.Id),
+			})
+		}
+		details = append(details, entities.Detail{
+			Id:            d.Id,
+			Description:   d.Description,
+			Notes:         d.Notes,
+			Quantity:      d.Quantity,
+			Unit:          d.Unit,
+			UnitPrice:     d.UnitPrice,
+			Category:      d.Category,
+			DiscountType:  d.DiscountType,
+			DiscountValue: d.DiscountValue,
+			VatExempt:     d.VatExempt,
+			Position:      i + 1,
+		})
+	}
+	return details, nil
+}
+
+// applyIncomeWithholdingTax checks the rate the client withholds and
+// computes the tax from the lines' amount before VAT, after discounts.
+func applyIncomeWithholdingTax(income *entities.Income, details []entities.Detail) error {
+	if income.WithholdingTaxRate > money.FromInt(100) {
+		return NewValidationError("invalid_withholding_tax", "withholding tax rate cannot exceed 100", utils.FieldError{
+			Field:   "withholding_tax_rate",
+			Rule:    "max",
+			Message: fmt.Sprintf("must be between 0 and 100, got %s", income.WithholdingTaxRate),
+		})
+	}
+	income.WithholdingTaxAmount = withholdingTax(*income, details)
+	return nil
+}
+
+// withholdingTax is the tax withheld at the income's rate from its lines'
+// amount before VAT, after discounts.
+func withholdingTax(income entities.Income, details []entities.Detail) money.Amount {
+	totals := incomeTotals(income.DiscountType, income.DiscountValue, details)
+	return (totals.VatableAmount + totals.VatExemptAmount).Percent(income.WithholdingTaxRate)
+}
+
+func validateIncomeTotal(discountType string, discountValue money.Amount, details []entities.Detail, total money.Amount) error {
+	grandTotal := incomeTotals(discountType, discountValue, details).GrandTotal
+	if grandTotal != total {
+		return NewValidationError("details_total_mismatch", "line totals do not match the total payment amount", utils.FieldError{
+			Field:   "details",
+			Rule:    "total",
+			Message: fmt.Sprintf("lines add up to %s after discounts and VAT but total_payment_amount is %s", grandTotal, total),
+		})
+	}
 	return nil
 }
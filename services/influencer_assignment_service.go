@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"mtii-backend/utils"
+	"time"
+)
+
+type InfluencerAssignmentService interface {
+	GetAllInfluencerAssignment(ctx context.Context, query dtos.InfluencerAssignmentQuery) ([]dtos.InfluencerAssignment, error)
+	GetInfluencerAssignmentById(ctx context.Context, assignmentId int) (dtos.InfluencerAssignment, error)
+	GetInfluencerJobs(ctx context.Context, influencerId int, query dtos.InfluencerAssignmentQuery) ([]dtos.InfluencerJob, error)
+	CreateInfluencerAssignment(ctx context.Context, req dtos.CreateInfluencerAssignmentRequest) (dtos.InfluencerAssignmentResponse, error)
+	UpdateInfluencerAssignment(ctx context.Context, assignmentId int, req dtos.UpdateInfluencerAssignmentRequest) (dtos.InfluencerAssignmentResponse, error)
+	DeleteInfluencerAssignment(ctx context.Context, assignmentId int) error
+}
+
+type influencerAssignmentService struct {
+	influencerAssignmentRepository repositories.InfluencerAssignmentRepository
+	influencerRepository           repositories.InfluencerRepository
+	incomeRepository               repositories.IncomeRepository
+}
+
+func NewInfluencerAssignmentService(
+	influencerAssignmentRepository repositories.InfluencerAssignmentRepository,
+	influencerRepository repositories.InfluencerRepository,
+	incomeRepository repositories.IncomeRepository,
+) InfluencerAssignmentService {
+	return &influencerAssignmentService{
+		influencerAssignmentRepository: influencerAssignmentRepository,
+		influencerRepository:           influencerRepository,
+		incomeRepository:               incomeRepository,
+	}
+}
+
+func (s *influencerAssignmentService) GetAllInfluencerAssignment(ctx context.Context, query dtos.InfluencerAssignmentQuery) ([]dtos.InfluencerAssignment, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentService.GetAllInfluencerAssignment")
+	defer span.End()
+
+	filter, err := assignmentFilter(query)
+	if err != nil {
+		return []dtos.InfluencerAssignment{}, err
+	}
+
+	assignments, err := s.influencerAssignmentRepository.GetAllInfluencerAssignment(ctx, filter)
+	if err != nil {
+		return []dtos.InfluencerAssignment{}, wrapError(err, "failed to get influencer assignment")
+	}
+
+	var assignmentDTOs []dtos.InfluencerAssignment
+	for _, a := range assignments {
+		assignmentDTOs = append(assignmentDTOs, toInfluencerAssignmentDTO(a))
+	}
+
+	if len(assignmentDTOs) == 0 {
+		return []dtos.InfluencerAssignment{}, nil
+	}
+
+	return assignmentDTOs, nil
+}
+
+func (s *influencerAssignmentService) GetInfluencerAssignmentById(ctx context.Context, assignmentId int) (dtos.InfluencerAssignment, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentService.GetInfluencerAssignmentById")
+	defer span.End()
+
+	assignment, err := s.influencerAssignmentRepository.GetInfluencerAssignmentById(ctx, assignmentId)
+	if err != nil {
+		return dtos.InfluencerAssignment{}, wrapError(err, "failed to get influencer assignment")
+	}
+
+	return toInfluencerAssignmentDTO(assignment), nil
+}
+
+// GetInfluencerJobs lists the jobs of one influencer with the income each
+// belongs to, e.g. everything posted this quarter.
+func (s *influencerAssignmentService) GetInfluencerJobs(ctx context.Context, influencerId int, query dtos.InfluencerAssignmentQuery) ([]dtos.InfluencerJob, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentService.GetInfluencerJobs")
+	defer span.End()
+
+	if _, err := s.influencerRepository.GetInfluencerById(ctx, influencerId); err != nil {
+		return []dtos.InfluencerJob{}, wrapError(err, "failed to get influencer")
+	}
+
+	query.InfluencerId = influencerId
+	filter, err := assignmentFilter(query)
+	if err != nil {
+		return []dtos.InfluencerJob{}, err
+	}
+
+	assignments, err := s.influencerAssignmentRepository.GetAllInfluencerAssignment(ctx, filter)
+	if err != nil {
+		return []dtos.InfluencerJob{}, wrapError(err, "failed to get influencer assignment")
+	}
+
+	jobs := []dtos.InfluencerJob{}
+	for _, a := range assignments {
+		job := dtos.InfluencerJob{
+			AssignmentId:          a.Id,
+			IncomeInvoiceIdNumber: a.IncomeInvoiceIdNumber,
+			DetailId:              a.DetailId,
+			AgencyName:            a.Income.AgencyAgencyName,
+			BrandName:             a.Income.BrandBrandName,
+			BrandProduct:          a.Income.BrandProduct,
+			Platform: dtos.Platform{
+				Id:   a.Income.Platform.Id,
+				Name: a.Income.Platform.Name,
+			},
+			Status: dtos.Status{
+				Id:   a.Income.Status.Id,
+				Name: a.Income.Status.Name,
+			},
+			Deliverables: a.Deliverables,
+			PostingDate:  a.PostingDate,
+			Notes:        a.Notes,
+		}
+		if a.Detail != nil {
+			job.DetailDescription = a.Detail.Description
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (s *influencerAssignmentService) CreateInfluencerAssignment(ctx context.Context, req dtos.CreateInfluencerAssignmentRequest) (dtos.InfluencerAssignmentResponse, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentService.CreateInfluencerAssignment")
+	defer span.End()
+
+	if _, err := s.influencerRepository.GetInfluencerById(ctx, req.InfluencerId); err != nil {
+		return dtos.InfluencerAssignmentResponse{}, referenceError(err, "influencer_id", "influencer", req.InfluencerId)
+	}
+
+	income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, req.IncomeInvoiceIdNumber)
+	if err != nil {
+		return dtos.InfluencerAssignmentResponse{}, referenceError(err, "income_invoice_id_number", "income", req.IncomeInvoiceIdNumber)
+	}
+
+	if err := checkAssignmentDetail(income, req.DetailId); err != nil {
+		return dtos.InfluencerAssignmentResponse{}, err
+	}
+
+	data := entities.InfluencerAssignment{
+		InfluencerId:          req.InfluencerId,
+		IncomeInvoiceIdNumber: req.IncomeInvoiceIdNumber,
+		DetailId:              req.DetailId,
+		Deliverables:          req.Deliverables,
+		PostingDate:           helpers.DefaultIfEmpty(req.PostingDate, income.InfluencerPostingDate),
+		Notes:                 req.Notes,
+	}
+
+	assignment, err := s.influencerAssignmentRepository.CreateInfluencerAssignment(ctx, data)
+	if err != nil {
+		return dtos.InfluencerAssignmentResponse{}, wrapError(err, "failed to save influencer assignment")
+	}
+
+	return dtos.InfluencerAssignmentResponse{
+		Id: assignment.Id,
+	}, nil
+}
+
+func (s *influencerAssignmentService) UpdateInfluencerAssignment(ctx context.Context, assignmentId int, req dtos.UpdateInfluencerAssignmentRequest) (dtos.InfluencerAssignmentResponse, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentService.UpdateInfluencerAssignment")
+	defer span.End()
+
+	assignment, err := s.influencerAssignmentRepository.GetInfluencerAssignmentById(ctx, assignmentId)
+	if err != nil {
+		return dtos.InfluencerAssignmentResponse{}, wrapError(err, "failed to get influencer assignment")
+	}
+
+	data := entities.InfluencerAssignment{
+		Id:                    assignmentId,
+		InfluencerId:          helpers.DefaultIfEmpty(req.InfluencerId, assignment.InfluencerId),
+		IncomeInvoiceIdNumber: assignment.IncomeInvoiceIdNumber,
+		DetailId:              assignment.DetailId,
+		Deliverables:          helpers.DefaultIfEmpty(req.Deliverables, assignment.Deliverables),
+		PostingDate:           helpers.DefaultIfEmpty(req.PostingDate, assignment.PostingDate),
+		Notes:                 helpers.DefaultIfEmpty(req.Notes, assignment.Notes),
+	}
+
+	if req.InfluencerId != 0 && req.InfluencerId != assignment.InfluencerId {
+		if _, err := s.influencerRepository.GetInfluencerById(ctx, req.InfluencerId); err != nil {
+			return dtos.InfluencerAssignmentResponse{}, referenceError(err, "influencer_id", "influencer", req.InfluencerId)
+		}
+	}
+
+	if req.DetailId != nil {
+		data.DetailId = req.DetailId
+		if *req.DetailId == 0 {
+			data.DetailId = nil
+		}
+		income, err := s.incomeRepository.GetIncomeByInvoiceIdNumber(ctx, assignment.IncomeInvoiceIdNumber)
+		if err != nil {
+			return dtos.InfluencerAssignmentResponse{}, wrapError(err, "failed to get income")
+		}
+		if err := checkAssignmentDetail(income, data.DetailId); err != nil {
+			return dtos.InfluencerAssignmentResponse{}, err
+		}
+	}
+
+	updatedAssignment, err := s.influencerAssignmentRepository.UpdateInfluencerAssignment(ctx, data)
+	if err != nil {
+		return dtos.InfluencerAssignmentResponse{}, wrapError(err, "failed to save influencer assignment")
+	}
+
+	return dtos.InfluencerAssignmentResponse{
+		Id: updatedAssignment.Id,
+	}, nil
+}
+
+func (s *influencerAssignmentService) DeleteInfluencerAssignment(ctx context.Context, assignmentId int) error {
+	ctx, span := telemetry.Start(ctx, "InfluencerAssignmentService.DeleteInfluencerAssignment")
+	defer span.End()
+
+	assignment, err := s.influencerAssignmentRepository.GetInfluencerAssignmentById(ctx, assignmentId)
+	if err != nil {
+		return wrapError(err, "failed to get influencer assignment")
+	}
+
+	err = s.influencerAssignmentRepository.DeleteInfluencerAssignment(ctx, assignment.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete influencer assignment")
+	}
+
+	return nil
+}
+
+// checkAssignmentDetail rejects a line item that is not part of income.
+func checkAssignmentDetail(income entities.Income, detailId *int) error {
+	if detailId == nil {
+		return nil
+	}
+	for _, d := range income.Details {
+		if d.Id == *detailId {
+			return nil
+		}
+	}
+	return NewValidationError("invalid_reference", "detail does not belong to the income", utils.FieldError{
+		Field:   "detail_id",
+		Rule:    "exists",
+		Message: fmt.Sprintf("income %d has no line item %d", income.InvoiceIdNumber, *detailId),
+	})
+}
+
+func assignmentFilter(query dtos.InfluencerAssignmentQuery) (repositories.InfluencerAssignmentFilter, error) {
+	filter := repositories.InfluencerAssignmentFilter{
+		InfluencerId:          query.InfluencerId,
+		IncomeInvoiceIdNumber: query.IncomeInvoiceIdNumber,
+		PlatformId:            query.PlatformId,
+		From:                  query.From,
+		To:                    query.To,
+	}
+	if query.Quarter != "" {
+		from, to, err := parseQuarter(query.Quarter)
+		if err != nil {
+			return repositories.InfluencerAssignmentFilter{}, err
+		}
+		filter.From, filter.To = from, to
+	}
+	return filter, nil
+}
+
+// parseQuarter reads a quarter such as 2026-Q3 and returns its first and
+// last day.
+func parseQuarter(quarter string) (time.Time, time.Time, error) {
+	var year, q int
+	if n, _ := fmt.Sscanf(quarter, "%4d-Q%1d", &year, &q); n != 2 || q < 1 || q > 4 || len(quarter) != 7 {
+		return time.Time{}, time.Time{}, NewValidationError("invalid_quarter", "quarter must look like 2026-Q3", utils.FieldError{
+			Field:   "quarter",
+			Rule:    "quarter",
+			Message: fmt.Sprintf("%q is not a quarter such as 2026-Q3", quarter),
+		})
+	}
+	from := time.Date(year, time.Month(3*(q-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 3, -1), nil
+}
+
+func toInfluencerAssignmentDTO(a entities.InfluencerAssignment) dtos.InfluencerAssignment {
+	return dtos.InfluencerAssignment{
+		Id:                    a.Id,
+		InfluencerId:          a.InfluencerId,
+		InfluencerName:        a.Influencer.Name,
+		IncomeInvoiceIdNumber: a.IncomeInvoiceIdNumber,
+		DetailId:              a.DetailId,
+		Deliverables:          a.Deliverables,
+		PostingDate:           a.PostingDate,
+		Notes:                 a.Notes,
+	}
+}
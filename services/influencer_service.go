@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+)
+
+type InfluencerService interface {
+	GetAllInfluencer(ctx context.Context, query dtos.InfluencerQuery) ([]dtos.Influencer, error)
+	GetInfluencerById(ctx context.Context, influencerId int) (dtos.Influencer, error)
+	CreateInfluencer(ctx context.Context, req dtos.CreateInfluencerRequest) (dtos.InfluencerResponse, error)
+	UpdateInfluencer(ctx context.Context, influencerId int, req dtos.UpdateInfluencerRequest) (dtos.InfluencerResponse, error)
+	DeleteInfluencer(ctx context.Context, influencerId int) error
+}
+
+type influencerService struct {
+	influencerRepository repositories.InfluencerRepository
+}
+
+func NewInfluencerService(
+	influencerRepository repositories.InfluencerRepository,
+) InfluencerService {
+	return &influencerService{
+		influencerRepository: influencerRepository,
+	}
+}
+
+func (s *influencerService) GetAllInfluencer(ctx context.Context, query dtos.InfluencerQuery) ([]dtos.Influencer, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerService.GetAllInfluencer")
+	defer span.End()
+
+	influencers, err := s.influencerRepository.GetAllInfluencer(ctx, query.PlatformId)
+	if err != nil {
+		return []dtos.Influencer{}, wrapError(err, "failed to get influencer")
+	}
+
+	var influencerDTOs []dtos.Influencer
+	for _, i := range influencers {
+		influencerDTOs = append(influencerDTOs, toInfluencerDTO(i))
+	}
+
+	if len(influencerDTOs) == 0 {
+		return []dtos.Influencer{}, nil
+	}
+
+	return influencerDTOs, nil
+}
+
+func (s *influencerService) GetInfluencerById(ctx context.Context, influencerId int) (dtos.Influencer, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerService.GetInfluencerById")
+	defer span.End()
+
+	influencer, err := s.influencerRepository.GetInfluencerById(ctx, influencerId)
+	if err != nil {
+		return dtos.Influencer{}, wrapError(err, "failed to get influencer")
+	}
+
+	return toInfluencerDTO(influencer), nil
+}
+
+func (s *influencerService) CreateInfluencer(ctx context.Context, req dtos.CreateInfluencerRequest) (dtos.InfluencerResponse, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerService.CreateInfluencer")
+	defer span.End()
+
+	data := entities.Influencer{
+		Name:              req.Name,
+		Email:             req.Email,
+		PhoneNumber:       req.PhoneNumber,
+		BankId:            req.BankId,
+		BankAccountName:   req.BankAccountName,
+		BankAccountNumber: req.BankAccountNumber,
+		Handles:           toInfluencerHandleEntities(req.Handles),
+		RateCard:          toInfluencerRateEntities(req.RateCard),
+	}
+
+	influencer, err := s.influencerRepository.CreateInfluencer(ctx, data)
+	if err != nil {
+		return dtos.InfluencerResponse{}, wrapError(err, "failed to save influencer")
+	}
+
+	return dtos.InfluencerResponse{
+		Id: influencer.Id,
+	}, nil
+}
+
+func (s *influencerService) UpdateInfluencer(ctx context.Context, influencerId int, req dtos.UpdateInfluencerRequest) (dtos.InfluencerResponse, error) {
+	ctx, span := telemetry.Start(ctx, "InfluencerService.UpdateInfluencer")
+	defer span.End()
+
+	influencer, err := s.influencerRepository.GetInfluencerById(ctx, influencerId)
+	if err != nil {
+		return dtos.InfluencerResponse{}, wrapError(err, "failed to get influencer")
+	}
+
+	data := entities.Influencer{
+		Id:                influencerId,
+		Name:              helpers.DefaultIfEmpty(req.Name, influencer.Name),
+		Email:             helpers.DefaultIfEmpty(req.Email, influencer.Email),
+		PhoneNumber:       helpers.DefaultIfEmpty(req.PhoneNumber, influencer.PhoneNumber),
+		BankId:            influencer.BankId,
+		BankAccountName:   helpers.DefaultIfEmpty(req.BankAccountName, influencer.BankAccountName),
+		BankAccountNumber: helpers.DefaultIfEmpty(req.BankAccountNumber, influencer.BankAccountNumber),
+		Handles:           toInfluencerHandleEntities(req.Handles),
+		RateCard:          toInfluencerRateEntities(req.RateCard),
+	}
+	if req.BankId != nil {
+		data.BankId = req.BankId
+	}
+
+	updatedInfluencer, err := s.influencerRepository.UpdateInfluencer(ctx, data, req.Handles != nil, req.RateCard != nil)
+	if err != nil {
+		return dtos.InfluencerResponse{}, wrapError(err, "failed to save influencer")
+	}
+
+	return dtos.InfluencerResponse{
+		Id: updatedInfluencer.Id,
+	}, nil
+}
+
+// DeleteInfluencer removes an influencer with their handles and rate card.
+// The database refuses while the influencer is still assigned to a job.
+func (s *influencerService) DeleteInfluencer(ctx context.Context, influencerId int) error {
+	ctx, span := telemetry.Start(ctx, "InfluencerService.DeleteInfluencer")
+	defer span.End()
+
+	influencer, err := s.influencerRepository.GetInfluencerById(ctx, influencerId)
+	if err != nil {
+		return wrapError(err, "failed to get influencer")
+	}
+
+	err = s.influencerRepository.DeleteInfluencer(ctx, influencer.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete influencer")
+	}
+
+	return nil
+}
+
+func toInfluencerDTO(i entities.Influencer) dtos.Influencer {
+	handles := []dtos.InfluencerHandle{}
+	for _, h := range i.Handles {
+		handles = append(handles, dtos.InfluencerHandle{
+			PlatformId:    h.PlatformId,
+			Handle:        h.Handle,
+			FollowerCount: h.FollowerCount,
+		})
+	}
+	rateCard := []dtos.InfluencerRate{}
+	for _, r := range i.RateCard {
+		rateCard = append(rateCard, dtos.InfluencerRate{
+			PlatformId:  r.PlatformId,
+			Deliverable: r.Deliverable,
+			Rate:        r.Rate,
+		})
+	}
+
+	return dtos.Influencer{
+		Id:                i.Id,
+		Name:              i.Name,
+		Email:             i.Email,
+		PhoneNumber:       i.PhoneNumber,
+		BankId:            i.BankId,
+		BankAccountName:   i.BankAccountName,
+		BankAccountNumber: i.BankAccountNumber,
+		Handles:           handles,
+		RateCard:          rateCard,
+	}
+}
+
+func toInfluencerHandleEntities(handles []dtos.InfluencerHandle) []entities.InfluencerHandle {
+	var result []entities.InfluencerHandle
+	for _, h := range handles {
+		result = append(result, entities.InfluencerHandle{
+			PlatformId:    h.PlatformId,
+			Handle:        h.Handle,
+			FollowerCount: h.FollowerCount,
+		})
+	}
+	return result
+}
+
+func toInfluencerRateEntities(rates []dtos.InfluencerRate) []entities.InfluencerRate {
+	var result []entities.InfluencerRate
+	for _, r := range rates {
+		result = append(result, entities.InfluencerRate{
+			PlatformId:  r.PlatformId,
+			Deliverable: r.Deliverable,
+			Rate:        r.Rate,
+		})
+	}
+	return result
+}
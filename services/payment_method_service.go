@@ -2,10 +2,10 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"mtii-backend/dtos"
 	"mtii-backend/entities"
 	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
 )
 
 type PaymentMethodService interface {
@@ -29,9 +29,12 @@ func NewPaymentMethodService(
 }
 
 func (s *paymentMethodService) GetAllPaymentMethod(ctx context.Context) ([]dtos.PaymentMethod, error) {
+	ctx, span := telemetry.Start(ctx, "PaymentMethodService.GetAllPaymentMethod")
+	defer span.End()
+
 	paymentMethods, err := s.paymentMethodRepository.GetAllPaymentMethod(ctx)
 	if err != nil {
-		return []dtos.PaymentMethod{}, fmt.Errorf("failed to get payment method: %w", err)
+		return []dtos.PaymentMethod{}, wrapError(err, "failed to get payment method")
 	}
 
 	var paymentMethodDTOs []dtos.PaymentMethod
@@ -50,9 +53,12 @@ func (s *paymentMethodService) GetAllPaymentMethod(ctx context.Context) ([]dtos.
 }
 
 func (s *paymentMethodService) GetPaymentMethodById(ctx context.Context, paymentMethodId int) (dtos.PaymentMethod, error) {
+	ctx, span := telemetry.Start(ctx, "PaymentMethodService.GetPaymentMethodById")
+	defer span.End()
+
 	paymentMethod, err := s.paymentMethodRepository.GetPaymentMethodById(ctx, paymentMethodId)
 	if err != nil {
-		return dtos.PaymentMethod{}, fmt.Errorf("failed to get payment method: %w", err)
+		return dtos.PaymentMethod{}, wrapError(err, "failed to get payment method")
 	}
 
 	return dtos.PaymentMethod{
@@ -62,13 +68,16 @@ func (s *paymentMethodService) GetPaymentMethodById(ctx context.Context, payment
 }
 
 func (s *paymentMethodService) CreatePaymentMethod(ctx context.Context, req dtos.PaymentMethodRequest) (dtos.PaymentMethodResponse, error) {
+	ctx, span := telemetry.Start(ctx, "PaymentMethodService.CreatePaymentMethod")
+	defer span.End()
+
 	data := entities.PaymentMethod{
 		Name: req.Name,
 	}
 
 	paymentMethod, err := s.paymentMethodRepository.CreatePaymentMethod(ctx, data)
 	if err != nil {
-		return dtos.PaymentMethodResponse{}, fmt.Errorf("failed to save payment method: %w", err)
+		return dtos.PaymentMethodResponse{}, wrapError(err, "failed to save payment method")
 	}
 
 	return dtos.PaymentMethodResponse{
@@ -77,9 +86,12 @@ func (s *paymentMethodService) CreatePaymentMethod(ctx context.Context, req dtos
 }
 
 func (s *paymentMethodService) UpdatePaymentMethod(ctx context.Context, paymentMethodId int, req dtos.PaymentMethodRequest) (dtos.PaymentMethodResponse, error) {
+	ctx, span := telemetry.Start(ctx, "PaymentMethodService.UpdatePaymentMethod")
+	defer span.End()
+
 	_, err := s.paymentMethodRepository.GetPaymentMethodById(ctx, paymentMethodId)
 	if err != nil {
-		return dtos.PaymentMethodResponse{}, fmt.Errorf("failed to get payment method: %w", err)
+		return dtos.PaymentMethodResponse{}, wrapError(err, "failed to get payment method")
 	}
 
 	data := entities.PaymentMethod{
@@ -89,7 +101,7 @@ func (s *paymentMethodService) UpdatePaymentMethod(ctx context.Context, paymentM
 
 	paymentMethod, err := s.paymentMethodRepository.UpdatePaymentMethod(ctx, data)
 	if err != nil {
-		return dtos.PaymentMethodResponse{}, fmt.Errorf("failed to save payment method: %w", err)
+		return dtos.PaymentMethodResponse{}, wrapError(err, "failed to save payment method")
 	}
 
 	return dtos.PaymentMethodResponse{
@@ -98,14 +110,17 @@ func (s *paymentMethodService) UpdatePaymentMethod(ctx context.Context, paymentM
 }
 
 func (s *paymentMethodService) DeletePaymentMethod(ctx context.Context, paymentMethodId int) error {
+	ctx, span := telemetry.Start(ctx, "PaymentMethodService.DeletePaymentMethod")
+	defer span.End()
+
 	paymentMethod, err := s.paymentMethodRepository.GetPaymentMethodById(ctx, paymentMethodId)
 	if err != nil {
-		return fmt.Errorf("failed to get payment method: %w", err)
+		return wrapError(err, "failed to get payment method")
 	}
 
 	err = s.paymentMethodRepository.DeletePaymentMethod(ctx, paymentMethod.Id)
 	if err != nil {
-		return fmt.Errorf("failed to delete payment method: %w", err)
+		return wrapError(err, "failed to delete payment method")
 	}
 
 	return nil
@@ -2,10 +2,10 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"mtii-backend/dtos"
 	"mtii-backend/entities"
 	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
 )
 
 type PlatformService interface {
@@ -29,9 +29,12 @@ func NewPlatformService(
 }
 
 func (s *platformService) GetAllPlatform(ctx context.Context) ([]dtos.Platform, error) {
+	ctx, span := telemetry.Start(ctx, "PlatformService.GetAllPlatform")
+	defer span.End()
+
 	platforms, err := s.platformRepository.GetAllPlatform(ctx)
 	if err != nil {
-		return []dtos.Platform{}, fmt.Errorf("failed to get platform: %w", err)
+		return []dtos.Platform{}, wrapError(err, "failed to get platform")
 	}
 
 	var platformDTOs []dtos.Platform
@@ -50,9 +53,12 @@ func (s *platformService) GetAllPlatform(ctx context.Context) ([]dtos.Platform,
 }
 
 func (s *platformService) GetPlatformById(ctx context.Context, platformId int) (dtos.Platform, error) {
+	ctx, span := telemetry.Start(ctx, "PlatformService.GetPlatformById")
+	defer span.End()
+
 	platform, err := s.platformRepository.GetPlatformById(ctx, platformId)
 	if err != nil {
-		return dtos.Platform{}, fmt.Errorf("failed to get platform: %w", err)
+		return dtos.Platform{}, wrapError(err, "failed to get platform")
 	}
 
 	return dtos.Platform{
@@ -62,13 +68,16 @@ func (s *platformService) GetPlatformById(ctx context.Context, platformId int) (
 }
 
 func (s *platformService) CreatePlatform(ctx context.Context, req dtos.PlatformRequest) (dtos.PlatformResponse, error) {
+	ctx, span := telemetry.Start(ctx, "PlatformService.CreatePlatform")
+	defer span.End()
+
 	data := entities.Platform{
 		Name: req.Name,
 	}
 
 	platform, err := s.platformRepository.CreatePlatform(ctx, data)
 	if err != nil {
-		return dtos.PlatformResponse{}, fmt.Errorf("failed to save platform: %w", err)
+		return dtos.PlatformResponse{}, wrapError(err, "failed to save platform")
 	}
 
 	return dtos.PlatformResponse{
@@ -77,9 +86,12 @@ func (s *platformService) CreatePlatform(ctx context.Context, req dtos.PlatformR
 }
 
 func (s *platformService) UpdatePlatform(ctx context.Context, platformId int, req dtos.PlatformRequest) (dtos.PlatformResponse, error) {
+	ctx, span := telemetry.Start(ctx, "PlatformService.UpdatePlatform")
+	defer span.End()
+
 	_, err := s.platformRepository.GetPlatformById(ctx, platformId)
 	if err != nil {
-		return dtos.PlatformResponse{}, fmt.Errorf("failed to get platform: %w", err)
+		return dtos.PlatformResponse{}, wrapError(err, "failed to get platform")
 	}
 
 	data := entities.Platform{
@@ -89,7 +101,7 @@ func (s *platformService) UpdatePlatform(ctx context.Context, platformId int, re
 
 	platform, err := s.platformRepository.UpdatePlatform(ctx, data)
 	if err != nil {
-		return dtos.PlatformResponse{}, fmt.Errorf("failed to save platform: %w", err)
+		return dtos.PlatformResponse{}, wrapError(err, "failed to save platform")
 	}
 
 	return dtos.PlatformResponse{
@@ -98,14 +110,17 @@ func (s *platformService) UpdatePlatform(ctx context.Context, platformId int, re
 }
 
 func (s *platformService) DeletePlatform(ctx context.Context, platformId int) error {
+	ctx, span := telemetry.Start(ctx, "PlatformService.DeletePlatform")
+	defer span.End()
+
 	platform, err := s.platformRepository.GetPlatformById(ctx, platformId)
 	if err != nil {
-		return fmt.Errorf("failed to get platform: %w", err)
+		return wrapError(err, "failed to get platform")
 	}
 
 	err = s.platformRepository.DeletePlatform(ctx, platform.Id)
 	if err != nil {
-		return fmt.Errorf("failed to delete platform: %w", err)
+		return wrapError(err, "failed to delete platform")
 	}
 
 	return nil
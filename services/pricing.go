@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/utils"
+)
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+
+	// discountNone is accepted by partial updates to clear a discount; it is
+	// stored as an empty discount type.
+	discountNone = "none"
+)
+
+// VatRate is the Thai VAT rate, in percent, charged on lines that are not
+// exempt.
+var VatRate = money.FromInt(7)
+
+// discountAmount is the discount taken off base, never more than base.
+func discountAmount(discountType string, discountValue, base money.Amount) money.Amount {
+	var discount money.Amount
+	switch discountType {
+	case DiscountPercent:
+		discount = base.Percent(discountValue)
+	case DiscountFixed:
+		discount = discountValue
+	}
+	return min(max(discount, 0), base)
+}
+
+func lineTotal(d entities.Detail) money.Amount {
+	return d.UnitPrice.Mul(d.Quantity)
+}
+
+func lineNet(d entities.Detail) money.Amount {
+	gross := lineTotal(d)
+	return gross - discountAmount(d.DiscountType, d.DiscountValue, gross)
+}
+
+// incomeTotals applies the document discount to the lines' net amounts and
+// charges VAT on the taxable part. The document discount is split between
+// taxable and exempt lines in proportion to their net amounts.
+func incomeTotals(discountType string, discountValue money.Amount, details []entities.Detail) dtos.IncomeTotals {
+	var subtotal, taxable money.Amount
+	for _, d := range details {
+		net := lineNet(d)
+		subtotal += net
+		if !d.VatExempt {
+			taxable += net
+		}
+	}
+
+	discount := discountAmount(discountType, discountValue, subtotal)
+	taxable -= discount.MulDiv(taxable, subtotal)
+	vat := taxable.Percent(VatRate)
+
+	return dtos.IncomeTotals{
+		Subtotal:        subtotal,
+		DiscountAmount:  discount,
+		VatableAmount:   taxable,
+		VatExemptAmount: subtotal - discount - taxable,
+		VatAmount:       vat,
+		GrandTotal:      subtotal - discount + vat,
+	}
+}
+
+// normalizeDiscountType maps the "none" accepted by updates to the stored
+// empty value.
+func normalizeDiscountType(discountType string) string {
+	if discountType == discountNone {
+		return ""
+	}
+	return discountType
+}
+
+// validateDiscount rejects percentages above 100 and fixed discounts larger
+// than the amount they apply to. field prefixes the reported field names.
+func validateDiscount(field, discountType string, discountValue, base money.Amount) error {
+	switch discountType {
+	case DiscountPercent:
+		if discountValue > money.FromInt(100) {
+			return NewValidationError("invalid_discount", "discount percentage cannot exceed 100", utils.FieldError{
+				Field:   field + "discount_value",
+				Rule:    "max",
+				Message: fmt.Sprintf("a percentage discount must be between 0 and 100, got %s", discountValue),
+			})
+		}
+	case DiscountFixed:
+		if discountValue > base {
+			return NewValidationError("invalid_discount", "discount cannot exceed the amount it applies to", utils.FieldError{
+				Field:   field + "discount_value",
+				Rule:    "max",
+				Message: fmt.Sprintf("a fixed discount of %s is larger than %s", discountValue, base),
+			})
+		}
+	case "":
+		if discountValue != 0 {
+			return NewValidationError("invalid_discount", "discount value needs a discount type", utils.FieldError{
+				Field:   field + "discount_type",
+				Rule:    "required_with",
+				Message: "discount_type must be percent or fixed when discount_value is set",
+			})
+		}
+	}
+	return nil
+}
+
+// validatePricing checks every line discount and the document discount.
+func validatePricing(discountType string, discountValue money.Amount, details []entities.Detail) error {
+	for i, d := range details {
+		if err := validateDiscount(fmt.Sprintf("details[%d].", i), d.DiscountType, d.DiscountValue, lineTotal(d)); err != nil {
+			return err
+		}
+	}
+	return validateDiscount("", discountType, discountValue, incomeTotals("", 0, details).Subtotal)
+}
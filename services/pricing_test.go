@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"testing"
+)
+
+func amount(t *testing.T, s string) money.Amount {
+	t.Helper()
+	a, err := money.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestIncomeTotals(t *testing.T) {
+	line := func(price string, quantity int, exempt bool, discountType, discountValue string) entities.Detail {
+		return entities.Detail{
+			UnitPrice:     amount(t, price),
+			Quantity:      quantity,
+			VatExempt:     exempt,
+			DiscountType:  discountType,
+			DiscountValue: amount(t, discountValue),
+		}
+	}
+	totals := func(subtotal, discount, vatable, exempt, vat, grand string) dtos.IncomeTotals {
+		return dtos.IncomeTotals{
+			Subtotal:        amount(t, subtotal),
+			DiscountAmount:  amount(t, discount),
+			VatableAmount:   amount(t, vatable),
+			VatExemptAmount: amount(t, exempt),
+			VatAmount:       amount(t, vat),
+			GrandTotal:      amount(t, grand),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		discountType  string
+		discountValue string
+		details       []entities.Detail
+		want          dtos.IncomeTotals
+	}{
+		{
+			name:    "VAT charged on top of a taxable line",
+			details: []entities.Detail{line("1000", 1, false, "", "0")},
+			want:    totals("1000", "0", "1000", "0", "70", "1070"),
+		},
+		{
+			name:    "exempt lines carry no VAT",
+			details: []entities.Detail{line("250", 2, true, "", "0")},
+			want:    totals("500", "0", "0", "500", "0", "500"),
+		},
+		{
+			name:    "percentage line discount",
+			details: []entities.Detail{line("333.33", 3, false, DiscountPercent, "10")},
+			want:    totals("899.99", "0", "899.99", "0", "63.00", "962.99"),
+		},
+		{
+			name:    "fixed line discount",
+			details: []entities.Detail{line("100", 2, false, DiscountFixed, "50")},
+			want:    totals("150", "0", "150", "0", "10.50", "160.50"),
+		},
+		{
+			name:          "percentage document discount split by net amount",
+			discountType:  DiscountPercent,
+			discountValue: "10",
+			details:       []entities.Detail{line("1000", 1, false, "", "0"), line("500", 1, true, "", "0")},
+			want:          totals("1500", "150", "900", "450", "63", "1413"),
+		},
+		{
+			name:          "fixed document discount split with rounding",
+			discountType:  DiscountFixed,
+			discountValue: "100",
+			details:       []entities.Detail{line("200", 1, false, "", "0"), line("100", 1, true, "", "0")},
+			want:          totals("300", "100", "133.33", "66.67", "9.33", "209.33"),
+		},
+		{
+			name:          "full discount leaves nothing to tax",
+			discountType:  DiscountPercent,
+			discountValue: "100",
+			details:       []entities.Detail{line("99.99", 1, false, "", "0")},
+			want:          totals("99.99", "99.99", "0", "0", "0", "0"),
+		},
+		{
+			name: "no lines",
+			want: totals("0", "0", "0", "0", "0", "0"),
+		},
+		{
+			name:    "half a satang of VAT rounds up",
+			details: []entities.Detail{line("14.50", 1, false, "", "0")},
+			want:    totals("14.50", "0", "14.50", "0", "1.02", "15.52"),
+		},
+		{
+			name:    "less than half a satang of VAT rounds down",
+			details: []entities.Detail{line("14.49", 1, false, "", "0")},
+			want:    totals("14.49", "0", "14.49", "0", "1.01", "15.50"),
+		},
+		{
+			name:    "VAT on a single satang",
+			details: []entities.Detail{line("0.07", 1, false, "", "0")},
+			want:    totals("0.07", "0", "0.07", "0", "0", "0.07"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var discountValue money.Amount
+			if tt.discountValue != "" {
+				discountValue = amount(t, tt.discountValue)
+			}
+			got := incomeTotals(tt.discountType, discountValue, tt.details)
+			if got != tt.want {
+				t.Errorf("incomeTotals() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscountAmount(t *testing.T) {
+	tests := []struct {
+		discountType  string
+		discountValue string
+		base          string
+		want          string
+	}{
+		{DiscountPercent, "12.5", "80", "10"},
+		{DiscountPercent, "10", "0.05", "0.01"},
+		{DiscountPercent, "10", "0.04", "0"},
+		{DiscountFixed, "30", "80", "30"},
+		{DiscountFixed, "500", "80", "80"},
+		{DiscountFixed, "-5", "80", "0"},
+		{"", "30", "80", "0"},
+	}
+	for _, tt := range tests {
+		got := discountAmount(tt.discountType, amount(t, tt.discountValue), amount(t, tt.base))
+		if want := amount(t, tt.want); got != want {
+			t.Errorf("discountAmount(%q, %s, %s) = %s, want %s", tt.discountType, tt.discountValue, tt.base, got, want)
+		}
+	}
+}
+
+func TestValidateDiscount(t *testing.T) {
+	tests := []struct {
+		discountType  string
+		discountValue string
+		base          string
+		valid         bool
+	}{
+		{DiscountPercent, "100", "80", true},
+		{DiscountPercent, "100.01", "80", false},
+		{DiscountFixed, "80", "80", true},
+		{DiscountFixed, "80.01", "80", false},
+		{"", "0", "80", true},
+		{"", "5", "80", false},
+	}
+	for _, tt := range tests {
+		err := validateDiscount("", tt.discountType, amount(t, tt.discountValue), amount(t, tt.base))
+		var domain *DomainError
+		switch {
+		case tt.valid && err != nil:
+			t.Errorf("validateDiscount(%q, %s, %s) = %v", tt.discountType, tt.discountValue, tt.base, err)
+		case !tt.valid && (!errors.As(err, &domain) || domain.Kind != KindValidation):
+			t.Errorf("validateDiscount(%q, %s, %s) = %v, want a validation error", tt.discountType, tt.discountValue, tt.base, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+)
+
+// incomeProfitability compares a job's revenue, its totals before VAT, with
+// the expenses paid out of it. Withholding tax is part of the expense: it is
+// paid to the Revenue Department instead of the payee.
+func incomeProfitability(totals dtos.IncomeTotals, expenses []entities.Expense) dtos.IncomeProfitability {
+	var spent money.Amount
+	for _, e := range expenses {
+		spent += e.Amount
+	}
+	revenue := totals.Subtotal - totals.DiscountAmount
+	return dtos.IncomeProfitability{
+		Revenue:            revenue,
+		Expenses:           spent,
+		GrossMargin:        revenue - spent,
+		GrossMarginPercent: marginPercent(revenue-spent, revenue),
+	}
+}
+
+// marginPercent is margin as a percentage of revenue with two decimals, or
+// zero when there is no revenue.
+func marginPercent(margin, revenue money.Amount) money.Amount {
+	return margin.MulDiv(money.FromInt(100), revenue)
+}
@@ -2,15 +2,17 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"mtii-backend/dtos"
 	"mtii-backend/entities"
 	"mtii-backend/helpers"
+	"mtii-backend/promptpay"
 	"mtii-backend/repositories"
+	"mtii-backend/taxid"
+	"mtii-backend/telemetry"
 )
 
 type ReceiverService interface {
-	GetAllReceiver(ctx context.Context) ([]dtos.Receiver, error)
+	GetAllReceiver(ctx context.Context, query dtos.ReceiverQuery) ([]dtos.Receiver, error)
 	GetReceiverById(ctx context.Context, receiverId int) (dtos.Receiver, error)
 	CreateReceiver(ctx context.Context, req dtos.CreateReceiverRequest) (dtos.ReceiverResponse, error)
 	UpdateReceiver(ctx context.Context, receiverId int, req dtos.UpdateReceiverRequest) (dtos.ReceiverResponse, error)
@@ -29,21 +31,26 @@ func NewReceiverService(
 	}
 }
 
-func (s *receiverService) GetAllReceiver(ctx context.Context) ([]dtos.Receiver, error) {
-	receivers, err := s.receiverRepository.GetAllReceiver(ctx)
+func (s *receiverService) GetAllReceiver(ctx context.Context, query dtos.ReceiverQuery) ([]dtos.Receiver, error) {
+	ctx, span := telemetry.Start(ctx, "ReceiverService.GetAllReceiver")
+	defer span.End()
+
+	receivers, err := s.receiverRepository.GetAllReceiver(ctx, query.TaxId)
 	if err != nil {
-		return []dtos.Receiver{}, fmt.Errorf("failed to get receiver: %w", err)
+		return []dtos.Receiver{}, wrapError(err, "failed to get receiver")
 	}
 
 	var receiverDTOs []dtos.Receiver
 	for _, r := range receivers {
 		receiverDTOs = append(receiverDTOs, dtos.Receiver{
-			Id:         r.Id,
-			Name:       r.Name,
-			Address:    r.Address,
-			Email:      r.Email,
-			Phone:      r.Phone,
-			TaxPayerId: r.TaxPayerId,
+			Id:           r.Id,
+			Name:         r.Name,
+			Address:      r.Address,
+			Email:        r.Email,
+			Phone:        r.Phone,
+			TaxPayerId:   r.TaxPayerId,
+			BranchNumber: r.BranchNumber,
+			PromptPayId:  r.PromptPayId,
 		})
 	}
 
@@ -55,33 +62,43 @@ func (s *receiverService) GetAllReceiver(ctx context.Context) ([]dtos.Receiver,
 }
 
 func (s *receiverService) GetReceiverById(ctx context.Context, receiverId int) (dtos.Receiver, error) {
+	ctx, span := telemetry.Start(ctx, "ReceiverService.GetReceiverById")
+	defer span.End()
+
 	receiver, err := s.receiverRepository.GetReceiverById(ctx, receiverId)
 	if err != nil {
-		return dtos.Receiver{}, fmt.Errorf("failed to get receiver: %w", err)
+		return dtos.Receiver{}, wrapError(err, "failed to get receiver")
 	}
 
 	return dtos.Receiver{
-		Id:         receiver.Id,
-		Name:       receiver.Name,
-		Address:    receiver.Address,
-		Email:      receiver.Email,
-		Phone:      receiver.Phone,
-		TaxPayerId: receiver.TaxPayerId,
+		Id:           receiver.Id,
+		Name:         receiver.Name,
+		Address:      receiver.Address,
+		Email:        receiver.Email,
+		Phone:        receiver.Phone,
+		TaxPayerId:   receiver.TaxPayerId,
+		BranchNumber: receiver.BranchNumber,
+		PromptPayId:  receiver.PromptPayId,
 	}, nil
 }
 
 func (s *receiverService) CreateReceiver(ctx context.Context, req dtos.CreateReceiverRequest) (dtos.ReceiverResponse, error) {
+	ctx, span := telemetry.Start(ctx, "ReceiverService.CreateReceiver")
+	defer span.End()
+
 	data := entities.Receiver{
-		Name:       req.Name,
-		Address:    req.Address,
-		Email:      req.Email,
-		Phone:      req.Phone,
-		TaxPayerId: req.TaxPayerId,
+		Name:         req.Name,
+		Address:      req.Address,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		TaxPayerId:   req.TaxPayerId,
+		BranchNumber: helpers.DefaultIfEmpty(req.BranchNumber, taxid.HeadOffice),
+		PromptPayId:  promptpay.Normalize(req.PromptPayId),
 	}
 
 	receiver, err := s.receiverRepository.CreateReceiver(ctx, data)
 	if err != nil {
-		return dtos.ReceiverResponse{}, fmt.Errorf("failed to save receiver: %w", err)
+		return dtos.ReceiverResponse{}, wrapError(err, "failed to save receiver")
 	}
 
 	return dtos.ReceiverResponse{
@@ -90,23 +107,28 @@ func (s *receiverService) CreateReceiver(ctx context.Context, req dtos.CreateRec
 }
 
 func (s *receiverService) UpdateReceiver(ctx context.Context, receiverId int, req dtos.UpdateReceiverRequest) (dtos.ReceiverResponse, error) {
+	ctx, span := telemetry.Start(ctx, "ReceiverService.UpdateReceiver")
+	defer span.End()
+
 	receiver, err := s.receiverRepository.GetReceiverById(ctx, receiverId)
 	if err != nil {
-		return dtos.ReceiverResponse{}, fmt.Errorf("failed to get receiver: %w", err)
+		return dtos.ReceiverResponse{}, wrapError(err, "failed to get receiver")
 	}
 
 	data := entities.Receiver{
-		Id:         receiverId,
-		Name:       helpers.DefaultIfEmpty(req.Name, receiver.Name),
-		Address:    helpers.DefaultIfEmpty(req.Address, receiver.Address),
-		Email:      helpers.DefaultIfEmpty(req.Email, receiver.Email),
-		Phone:      helpers.DefaultIfEmpty(req.Phone, receiver.Phone),
-		TaxPayerId: helpers.DefaultIfEmpty(req.TaxPayerId, receiver.TaxPayerId),
+		Id:           receiverId,
+		Name:         helpers.DefaultIfEmpty(req.Name, receiver.Name),
+		Address:      helpers.DefaultIfEmpty(req.Address, receiver.Address),
+		Email:        helpers.DefaultIfEmpty(req.Email, receiver.Email),
+		Phone:        helpers.DefaultIfEmpty(req.Phone, receiver.Phone),
+		TaxPayerId:   helpers.DefaultIfEmpty(req.TaxPayerId, receiver.TaxPayerId),
+		BranchNumber: helpers.DefaultIfEmpty(req.BranchNumber, receiver.BranchNumber),
+		PromptPayId:  helpers.DefaultIfEmpty(promptpay.Normalize(req.PromptPayId), receiver.PromptPayId),
 	}
 
 	updatedReceiver, err := s.receiverRepository.UpdateReceiver(ctx, data)
 	if err != nil {
-		return dtos.ReceiverResponse{}, fmt.Errorf("failed to save receiver: %w", err)
+		return dtos.ReceiverResponse{}, wrapError(err, "failed to save receiver")
 	}
 
 	return dtos.ReceiverResponse{
@@ -115,14 +137,17 @@ func (s *receiverService) UpdateReceiver(ctx context.Context, receiverId int, re
 }
 
 func (s *receiverService) DeleteReceiver(ctx context.Context, receiverId int) error {
+	ctx, span := telemetry.Start(ctx, "ReceiverService.DeleteReceiver")
+	defer span.End()
+
 	receiver, err := s.receiverRepository.GetReceiverById(ctx, receiverId)
 	if err != nil {
-		return fmt.Errorf("failed to get receiver: %w", err)
+		return wrapError(err, "failed to get receiver")
 	}
 
 	err = s.receiverRepository.DeleteReceiver(ctx, receiver.Id)
 	if err != nil {
-		return fmt.Errorf("failed to delete receiver: %w", err)
+		return wrapError(err, "failed to delete receiver")
 	}
 
 	return nil
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"sort"
+)
+
+const (
+	GroupByPlatform   = "platform"
+	GroupByChannel    = "channel"
+	GroupBySalePerson = "sale_person"
+	GroupByMonth      = "month"
+)
+
+const (
+	DateBasisInvoice = "invoice"
+	DateBasisReceipt = "receipt"
+	DateBasisPosting = "posting"
+)
+
+// dateBasisColumns maps a report's date basis to the income date it reads.
+var dateBasisColumns = map[string]string{
+	DateBasisInvoice: "invoice_issue_date",
+	DateBasisReceipt: "receipt_issue_date",
+	DateBasisPosting: "influencer_posting_date",
+}
+
+type ReportService interface {
+	GetProfitabilityReport(ctx context.Context, query dtos.ProfitabilityQuery) (dtos.ProfitabilityReport, error)
+	GetRevenueReport(ctx context.Context, query dtos.RevenueQuery) (dtos.RevenueReport, error)
+	GetARAgingReport(ctx context.Context, query dtos.ARAgingQuery) (dtos.ARAgingReport, error)
+	GetARAgingInvoices(ctx context.Context, query dtos.ARAgingInvoiceQuery) ([]dtos.ARAgingInvoice, error)
+	GetDSOReport(ctx context.Context, query dtos.DSOQuery) (dtos.DSOReport, error)
+	GetCashFlowReport(ctx context.Context, query dtos.CashFlowQuery) (dtos.CashFlowReport, error)
+	GetSalesVatReport(ctx context.Context, query dtos.TaxReportQuery) (dtos.SalesVatReport, error)
+	GetWithholdingTaxRegister(ctx context.Context, query dtos.TaxReportQuery) (dtos.WithholdingTaxRegister, error)
+}
+
+type reportService struct {
+	incomeRepository       repositories.IncomeRepository
+	currencyRateRepository repositories.CurrencyRateRepository
+	reportRepository       repositories.ReportRepository
+	receiverRepository     repositories.ReceiverRepository
+}
+
+func NewReportService(
+	incomeRepository repositories.IncomeRepository,
+	currencyRateRepository repositories.CurrencyRateRepository,
+	reportRepository repositories.ReportRepository,
+	receiverRepository repositories.ReceiverRepository,
+) ReportService {
+	return &reportService{
+		incomeRepository:       incomeRepository,
+		currencyRateRepository: currencyRateRepository,
+		reportRepository:       reportRepository,
+		receiverRepository:     receiverRepository,
+	}
+}
+
+// GetProfitabilityReport groups the jobs invoiced in the period by platform,
+// channel or sales person. Each job is converted to the base currency at
+// the rate on its invoice date.
+func (s *reportService) GetProfitabilityReport(ctx context.Context, query dtos.ProfitabilityQuery) (dtos.ProfitabilityReport, error) {
+	ctx, span := telemetry.Start(ctx, "ReportService.GetProfitabilityReport")
+	defer span.End()
+
+	groupBy := query.GroupBy
+	if groupBy == "" {
+		groupBy = GroupByPlatform
+	}
+
+	incomes, err := s.incomeRepository.GetIncomesByInvoiceDate(ctx, query.From, query.To)
+	if err != nil {
+		return dtos.ProfitabilityReport{}, wrapError(err, "failed to get income")
+	}
+
+	rates, err := incomeRateTable(ctx, s.currencyRateRepository, incomes)
+	if err != nil {
+		return dtos.ProfitabilityReport{}, err
+	}
+
+	report := dtos.ProfitabilityReport{
+		GroupBy:             groupBy,
+		From:                query.From,
+		To:                  query.To,
+		Currency:            money.BaseCurrency,
+		Rows:                []dtos.ProfitabilityRow{},
+		Total:               dtos.ProfitabilityRow{Name: "Total"},
+		UnconvertedInvoices: []int{},
+	}
+	rows := map[int]*dtos.ProfitabilityRow{}
+	for _, i := range incomes {
+		rate, _, ok := rates.rateOn(i.Currency, i.InvoiceIssueDate)
+		if !ok {
+			report.UnconvertedInvoices = append(report.UnconvertedInvoices, i.InvoiceIdNumber)
+			continue
+		}
+		profitability := incomeProfitability(incomeTotals(i.DiscountType, i.DiscountValue, i.Details), i.Expenses)
+
+		id, name := profitabilityGroup(i, groupBy)
+		row, ok := rows[id]
+		if !ok {
+			row = &dtos.ProfitabilityRow{Id: id, Name: name}
+			rows[id] = row
+		}
+		for _, r := range []*dtos.ProfitabilityRow{row, &report.Total} {
+			r.Jobs++
+			r.Revenue += rate.Convert(profitability.Revenue)
+			r.Expenses += rate.Convert(profitability.Expenses)
+		}
+	}
+
+	for _, row := range rows {
+		report.Rows = append(report.Rows, finishProfitabilityRow(*row))
+	}
+	sort.Slice(report.Rows, func(a, b int) bool {
+		if report.Rows[a].Revenue != report.Rows[b].Revenue {
+			return report.Rows[a].Revenue > report.Rows[b].Revenue
+		}
+		return report.Rows[a].Id < report.Rows[b].Id
+	})
+	report.Total = finishProfitabilityRow(report.Total)
+
+	return report, nil
+}
+
+// GetRevenueReport totals incomes by period or dimension on their invoice,
+// receipt or posting date. The totals are computed by the database, so the
+// report costs one query however many incomes it covers.
+func (s *reportService) GetRevenueReport(ctx context.Context, query dtos.RevenueQuery) (dtos.RevenueReport, error) {
+	ctx, span := telemetry.Start(ctx, "ReportService.GetRevenueReport")
+	defer span.End()
+
+	groupBy := helpers.DefaultIfEmpty(query.GroupBy, GroupByMonth)
+	dateBasis := helpers.DefaultIfEmpty(query.DateBasis, DateBasisInvoice)
+
+	totals, err := s.reportRepository.GetRevenueTotals(ctx, repositories.RevenueFilter{
+		GroupBy:    groupBy,
+		DateColumn: dateBasisColumns[dateBasis],
+		From:       query.From,
+		To:         query.To,
+	})
+	if err != nil {
+		return dtos.RevenueReport{}, wrapError(err, "failed to get revenue")
+	}
+
+	report := dtos.RevenueReport{
+		GroupBy:   groupBy,
+		DateBasis: dateBasis,
+		From:      query.From,
+		To:        query.To,
+		Currency:  money.BaseCurrency,
+		Rows:      []dtos.RevenueRow{},
+		Total:     dtos.RevenueRow{Name: "Total"},
+	}
+	for _, t := range totals {
+		row := dtos.RevenueRow{
+			Key:         t.GroupKey,
+			Name:        t.GroupName,
+			Invoices:    t.Invoices,
+			Invoiced:    t.Invoiced,
+			Received:    t.Received,
+			Outstanding: t.Outstanding,
+		}
+		report.Rows = append(report.Rows, row)
+		report.Total.Invoices += row.Invoices
+		report.Total.Invoiced += row.Invoiced
+		report.Total.Received += row.Received
+		report.Total.Outstanding += row.Outstanding
+		report.UnconvertedInvoices += t.Unconverted
+	}
+
+	return report, nil
+}
+
+func profitabilityGroup(i entities.Income, groupBy string) (int, string) {
+	switch groupBy {
+	case GroupByChannel:
+		return i.ChannelId, i.Channel.Name
+	case GroupBySalePerson:
+		return i.SalePersonId, i.SalePerson.Name
+	}
+	return i.PlatformId, i.Platform.Name
+}
+
+func finishProfitabilityRow(row dtos.ProfitabilityRow) dtos.ProfitabilityRow {
+	row.GrossMargin = row.Revenue - row.Expenses
+	row.GrossMarginPercent = marginPercent(row.GrossMargin, row.Revenue)
+	return row
+}
@@ -2,10 +2,10 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"mtii-backend/dtos"
 	"mtii-backend/entities"
 	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
 )
 
 type SalePersonService interface {
@@ -29,9 +29,12 @@ func NewSalePersonService(
 }
 
 func (s *salePersonService) GetAllSalePerson(ctx context.Context) ([]dtos.SalePerson, error) {
+	ctx, span := telemetry.Start(ctx, "SalePersonService.GetAllSalePerson")
+	defer span.End()
+
 	salePeople, err := s.salePersonRepository.GetAllSalePerson(ctx)
 	if err != nil {
-		return []dtos.SalePerson{}, fmt.Errorf("failed to get sale person: %w", err)
+		return []dtos.SalePerson{}, wrapError(err, "failed to get sale person")
 	}
 
 	var salePersonDTOs []dtos.SalePerson
@@ -50,9 +53,12 @@ func (s *salePersonService) GetAllSalePerson(ctx context.Context) ([]dtos.SalePe
 }
 
 func (s *salePersonService) GetSalePersonById(ctx context.Context, salePersonId int) (dtos.SalePerson, error) {
+	ctx, span := telemetry.Start(ctx, "SalePersonService.GetSalePersonById")
+	defer span.End()
+
 	salePerson, err := s.salePersonRepository.GetSalePersonById(ctx, salePersonId)
 	if err != nil {
-		return dtos.SalePerson{}, fmt.Errorf("failed to get sale method: %w", err)
+		return dtos.SalePerson{}, wrapError(err, "failed to get sale method")
 	}
 
 	return dtos.SalePerson{
@@ -62,13 +68,16 @@ func (s *salePersonService) GetSalePersonById(ctx context.Context, salePersonId
 }
 
 func (s *salePersonService) CreateSalePerson(ctx context.Context, req dtos.SalePersonRequest) (dtos.SalePersonResponse, error) {
+	ctx, span := telemetry.Start(ctx, "SalePersonService.CreateSalePerson")
+	defer span.End()
+
 	data := entities.SalePerson{
 		Name: req.Name,
 	}
 
 	salePerson, err := s.salePersonRepository.CreateSalePerson(ctx, data)
 	if err != nil {
-		return dtos.SalePersonResponse{}, fmt.Errorf("failed to save sale person: %w", err)
+		return dtos.SalePersonResponse{}, wrapError(err, "failed to save sale person")
 	}
 
 	return dtos.SalePersonResponse{
@@ -77,9 +86,12 @@ func (s *salePersonService) CreateSalePerson(ctx context.Context, req dtos.SaleP
 }
 
 func (s *salePersonService) UpdateSalePerson(ctx context.Context, salePersonId int, req dtos.SalePersonRequest) (dtos.SalePersonResponse, error) {
+	ctx, span := telemetry.Start(ctx, "SalePersonService.UpdateSalePerson")
+	defer span.End()
+
 	_, err := s.salePersonRepository.GetSalePersonById(ctx, salePersonId)
 	if err != nil {
-		return dtos.SalePersonResponse{}, fmt.Errorf("failed to get sale person: %w", err)
+		return dtos.SalePersonResponse{}, wrapError(err, "failed to get sale person")
 	}
 
 	data := entities.SalePerson{
@@ -89,7 +101,7 @@ func (s *salePersonService) UpdateSalePerson(ctx context.Context, salePersonId i
 
 	salePerson, err := s.salePersonRepository.UpdateSalePerson(ctx, data)
 	if err != nil {
-		return dtos.SalePersonResponse{}, fmt.Errorf("failed to save sale person: %w", err)
+		return dtos.SalePersonResponse{}, wrapError(err, "failed to save sale person")
 	}
 
 	return dtos.SalePersonResponse{
@@ -98,14 +110,17 @@ func (s *salePersonService) UpdateSalePerson(ctx context.Context, salePersonId i
 }
 
 func (s *salePersonService) DeleteSalePerson(ctx context.Context, salePersonId int) error {
+	ctx, span := telemetry.Start(ctx, "SalePersonService.DeleteSalePerson")
+	defer span.End()
+
 	salePerson, err := s.salePersonRepository.GetSalePersonById(ctx, salePersonId)
 	if err != nil {
-		return fmt.Errorf("failed to get sale person: %w", err)
+		return wrapError(err, "failed to get sale person")
 	}
 
 	err = s.salePersonRepository.DeleteSalePerson(ctx, salePerson.Id)
 	if err != nil {
-		return fmt.Errorf("failed to delete sale person: %w", err)
+		return wrapError(err, "failed to delete sale person")
 	}
 
 	return nil
@@ -2,10 +2,10 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"mtii-backend/dtos"
 	"mtii-backend/entities"
 	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
 )
 
 type StatusService interface {
@@ -29,9 +29,12 @@ func NewStatusService(
 }
 
 func (s *statusService) GetAllStatus(ctx context.Context) ([]dtos.Status, error) {
+	ctx, span := telemetry.Start(ctx, "StatusService.GetAllStatus")
+	defer span.End()
+
 	statuses, err := s.statusRepository.GetAllStatus(ctx)
 	if err != nil {
-		return []dtos.Status{}, fmt.Errorf("failed to get status: %w", err)
+		return []dtos.Status{}, wrapError(err, "failed to get status")
 	}
 
 	var statusDTOs []dtos.Status
@@ -50,9 +53,12 @@ func (s *statusService) GetAllStatus(ctx context.Context) ([]dtos.Status, error)
 }
 
 func (s *statusService) GetStatusById(ctx context.Context, statusId int) (dtos.Status, error) {
+	ctx, span := telemetry.Start(ctx, "StatusService.GetStatusById")
+	defer span.End()
+
 	status, err := s.statusRepository.GetStatusById(ctx, statusId)
 	if err != nil {
-		return dtos.Status{}, fmt.Errorf("failed to get status: %w", err)
+		return dtos.Status{}, wrapError(err, "failed to get status")
 	}
 
 	return dtos.Status{
@@ -62,13 +68,16 @@ func (s *statusService) GetStatusById(ctx context.Context, statusId int) (dtos.S
 }
 
 func (s *statusService) CreateStatus(ctx context.Context, req dtos.StatusRequest) (dtos.StatusResponse, error) {
+	ctx, span := telemetry.Start(ctx, "StatusService.CreateStatus")
+	defer span.End()
+
 	data := entities.Status{
 		Name: req.Name,
 	}
 
 	status, err := s.statusRepository.CreateStatus(ctx, data)
 	if err != nil {
-		return dtos.StatusResponse{}, fmt.Errorf("failed to save status: %w", err)
+		return dtos.StatusResponse{}, wrapError(err, "failed to save status")
 	}
 
 	return dtos.StatusResponse{
@@ -77,9 +86,12 @@ func (s *statusService) CreateStatus(ctx context.Context, req dtos.StatusRequest
 }
 
 func (s *statusService) UpdateStatus(ctx context.Context, statusId int, req dtos.StatusRequest) (dtos.StatusResponse, error) {
+	ctx, span := telemetry.Start(ctx, "StatusService.UpdateStatus")
+	defer span.End()
+
 	_, err := s.statusRepository.GetStatusById(ctx, statusId)
 	if err != nil {
-		return dtos.StatusResponse{}, fmt.Errorf("failed to get status: %w", err)
+		return dtos.StatusResponse{}, wrapError(err, "failed to get status")
 	}
 
 	data := entities.Status{
@@ -89,7 +101,7 @@ func (s *statusService) UpdateStatus(ctx context.Context, statusId int, req dtos
 
 	status, err := s.statusRepository.UpdateStatus(ctx, data)
 	if err != nil {
-		return dtos.StatusResponse{}, fmt.Errorf("failed to save status: %w", err)
+		return dtos.StatusResponse{}, wrapError(err, "failed to save status")
 	}
 
 	return dtos.StatusResponse{
@@ -98,14 +110,17 @@ func (s *statusService) UpdateStatus(ctx context.Context, statusId int, req dtos
 }
 
 func (s *statusService) DeleteStatus(ctx context.Context, statusId int) error {
+	ctx, span := telemetry.Start(ctx, "StatusService.DeleteStatus")
+	defer span.End()
+
 	status, err := s.statusRepository.GetStatusById(ctx, statusId)
 	if err != nil {
-		return fmt.Errorf("failed to get status: %w", err)
+		return wrapError(err, "failed to get status")
 	}
 
 	err = s.statusRepository.DeleteStatus(ctx, status.Id)
 	if err != nil {
-		return fmt.Errorf("failed to delete status: %w", err)
+		return wrapError(err, "failed to delete status")
 	}
 
 	return nil
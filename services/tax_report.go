@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
+	"sort"
+	"time"
+)
+
+// GetSalesVatReport lists the tax invoices of a month with the buyer's tax
+// ID and branch, the amounts before VAT and the VAT, converted to the base
+// currency at the rate on the tax point. The tax point is the invoice date,
+// or the receipt date for services taxed when they are paid.
+func (s *reportService) GetSalesVatReport(ctx context.Context, query dtos.TaxReportQuery) (dtos.SalesVatReport, error) {
+	ctx, span := telemetry.Start(ctx, "ReportService.GetSalesVatReport")
+	defer span.End()
+
+	dateBasis := helpers.DefaultIfEmpty(query.DateBasis, DateBasisInvoice)
+	from := monthOf(query.Month)
+	report := dtos.SalesVatReport{
+		Month:               from.Format("2006-01"),
+		DateBasis:           dateBasis,
+		Currency:            money.BaseCurrency,
+		Lines:               []dtos.SalesVatLine{},
+		UnconvertedInvoices: []int{},
+	}
+
+	receiver, err := s.taxReportReceiver(ctx, query.ReceiverId)
+	if err != nil {
+		return dtos.SalesVatReport{}, err
+	}
+	report.Receiver = receiver
+
+	incomes, err := s.incomeRepository.GetIncomesForTaxMonth(ctx, repositories.TaxMonthFilter{
+		DateColumn: dateBasisColumns[dateBasis],
+		From:       from,
+		To:         from.AddDate(0, 1, -1),
+		ReceiverId: query.ReceiverId,
+	})
+	if err != nil {
+		return dtos.SalesVatReport{}, wrapError(err, "failed to get income")
+	}
+
+	rates, err := incomeRateTable(ctx, s.currencyRateRepository, incomes)
+	if err != nil {
+		return dtos.SalesVatReport{}, err
+	}
+
+	for _, i := range incomes {
+		date := i.InvoiceIssueDate
+		if dateBasis == DateBasisReceipt {
+			date = i.ReceiptIssueDate
+		}
+		rate, _, ok := rates.rateOn(i.Currency, date)
+		if !ok {
+			report.UnconvertedInvoices = append(report.UnconvertedInvoices, i.InvoiceIdNumber)
+			continue
+		}
+
+		totals := incomeTotals(i.DiscountType, i.DiscountValue, i.Details)
+		line := dtos.SalesVatLine{
+			Sequence:        len(report.Lines) + 1,
+			Date:            date,
+			InvoiceIdNumber: i.InvoiceIdNumber,
+			ReceiptIdNumber: i.ReceiptIdNumber,
+			CustomerName:    i.AgencyAgencyName,
+			CustomerTaxId:   i.AgencyTaxPayerIdNumber,
+			CustomerBranch:  i.AgencyBranchNumber,
+			Currency:        helpers.DefaultIfEmpty(i.Currency, money.BaseCurrency),
+			Rate:            rate,
+			SalesVatTotals: dtos.SalesVatTotals{
+				VatableAmount:   rate.Convert(totals.VatableAmount),
+				VatExemptAmount: rate.Convert(totals.VatExemptAmount),
+				VatAmount:       rate.Convert(totals.VatAmount),
+			},
+		}
+		line.Total = line.VatableAmount + line.VatExemptAmount + line.VatAmount
+		report.Lines = append(report.Lines, line)
+
+		report.Total.VatableAmount += line.VatableAmount
+		report.Total.VatExemptAmount += line.VatExemptAmount
+		report.Total.VatAmount += line.VatAmount
+		report.Total.Total += line.Total
+	}
+
+	return report, nil
+}
+
+// GetWithholdingTaxRegister lists the tax clients withheld from incomes and
+// the 50 Tawi certificates received for it, in the month of the certificate
+// or, while it is outstanding, of the receipt. Amounts are converted to the
+// base currency at the rate on the receipt date, when the tax was withheld,
+// falling back to the invoice rate.
+func (s *reportService) GetWithholdingTaxRegister(ctx context.Context, query dtos.TaxReportQuery) (dtos.WithholdingTaxRegister, error) {
+	ctx, span := telemetry.Start(ctx, "ReportService.GetWithholdingTaxRegister")
+	defer span.End()
+
+	from := monthOf(query.Month)
+	register := dtos.WithholdingTaxRegister{
+		Month:               from.Format("2006-01"),
+		Currency:            money.BaseCurrency,
+		Lines:               []dtos.WithholdingTaxLine{},
+		UnconvertedInvoices: []int{},
+	}
+
+	receiver, err := s.taxReportReceiver(ctx, query.ReceiverId)
+	if err != nil {
+		return dtos.WithholdingTaxRegister{}, err
+	}
+	register.Receiver = receiver
+
+	incomes, err := s.incomeRepository.GetWithheldIncomes(ctx, repositories.TaxMonthFilter{
+		From:       from,
+		To:         from.AddDate(0, 1, -1),
+		ReceiverId: query.ReceiverId,
+	})
+	if err != nil {
+		return dtos.WithholdingTaxRegister{}, wrapError(err, "failed to get income")
+	}
+
+	rates, err := incomeRateTable(ctx, s.currencyRateRepository, incomes)
+	if err != nil {
+		return dtos.WithholdingTaxRegister{}, err
+	}
+
+	sort.SliceStable(incomes, func(a, b int) bool {
+		return withholdingDate(incomes[a]).Before(withholdingDate(incomes[b]))
+	})
+	for _, i := range incomes {
+		rate, _, ok := rates.rateOn(i.Currency, i.ReceiptIssueDate)
+		if !ok {
+			rate, _, ok = rates.rateOn(i.Currency, i.InvoiceIssueDate)
+		}
+		if !ok {
+			register.UnconvertedInvoices = append(register.UnconvertedInvoices, i.InvoiceIdNumber)
+			continue
+		}
+
+		line := dtos.WithholdingTaxLine{
+			Sequence:           len(register.Lines) + 1,
+			CertificateNumber:  i.WithholdingCertificateNumber,
+			CertificateDate:    i.WithholdingCertificateDate,
+			Received:           !i.WithholdingCertificateDate.IsZero(),
+			PayerName:          i.AgencyAgencyName,
+			PayerTaxId:         i.AgencyTaxPayerIdNumber,
+			PayerBranch:        i.AgencyBranchNumber,
+			InvoiceIdNumber:    i.InvoiceIdNumber,
+			ReceiptIdNumber:    i.ReceiptIdNumber,
+			ReceiptIssueDate:   i.ReceiptIssueDate,
+			WithholdingTaxRate: i.WithholdingTaxRate,
+			TaxAmount:          rate.Convert(i.WithholdingTaxAmount),
+		}
+		totals := incomeTotals(i.DiscountType, i.DiscountValue, i.Details)
+		line.BaseAmount = rate.Convert(totals.VatableAmount + totals.VatExemptAmount)
+		register.Lines = append(register.Lines, line)
+
+		register.Total.BaseAmount += line.BaseAmount
+		register.Total.TaxAmount += line.TaxAmount
+		if line.Received {
+			register.Total.Received += line.TaxAmount
+		} else {
+			register.Total.Outstanding += line.TaxAmount
+		}
+	}
+
+	return register, nil
+}
+
+// taxReportReceiver loads the receiver a tax report is narrowed to, or
+// returns nil when it covers every receiver.
+func (s *reportService) taxReportReceiver(ctx context.Context, receiverId int) (*dtos.Receiver, error) {
+	if receiverId == 0 {
+		return nil, nil
+	}
+	r, err := s.receiverRepository.GetReceiverById(ctx, receiverId)
+	if err != nil {
+		return nil, referenceError(err, "receiver_id", "receiver", receiverId)
+	}
+	return &dtos.Receiver{
+		Id:           r.Id,
+		Name:         r.Name,
+		Address:      r.Address,
+		Email:        r.Email,
+		Phone:        r.Phone,
+		TaxPayerId:   r.TaxPayerId,
+		BranchNumber: r.BranchNumber,
+	}, nil
+}
+
+// withholdingDate is the date tax withheld from an income is registered on:
+// the certificate date, or the receipt date until the certificate arrives.
+func withholdingDate(i entities.Income) time.Time {
+	if !i.WithholdingCertificateDate.IsZero() {
+		return i.WithholdingCertificateDate
+	}
+	return i.ReceiptIssueDate
+}
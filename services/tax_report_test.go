@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/money"
+	"mtii-backend/repositories"
+	"testing"
+	"time"
+)
+
+// Withholding tax is taken from the amount before VAT, after discounts.
+func TestApplyIncomeWithholdingTax(t *testing.T) {
+	details := []entities.Detail{
+		{UnitPrice: amount(t, "1000"), Quantity: 1},
+		{UnitPrice: amount(t, "500"), Quantity: 1, VatExempt: true},
+	}
+	tests := []struct {
+		rate          string
+		discountType  string
+		discountValue string
+		want          string
+	}{
+		{"3", "", "0", "45"},
+		{"3", DiscountPercent, "10", "40.50"},
+		{"1.5", DiscountFixed, "0.33", "22.50"}, // 22.495 rounds up
+		{"0", "", "0", "0"},
+	}
+	for _, tt := range tests {
+		income := entities.Income{
+			WithholdingTaxRate: amount(t, tt.rate),
+			DiscountType:       tt.discountType,
+			DiscountValue:      amount(t, tt.discountValue),
+		}
+		if err := applyIncomeWithholdingTax(&income, details); err != nil {
+			t.Fatal(err)
+		}
+		if want := amount(t, tt.want); income.WithholdingTaxAmount != want {
+			t.Errorf("withholding %s%% with discount %s %s = %s, want %s", tt.rate, tt.discountType, tt.discountValue, income.WithholdingTaxAmount, want)
+		}
+	}
+
+	income := entities.Income{WithholdingTaxRate: amount(t, "100.01")}
+	if err := applyIncomeWithholdingTax(&income, details); err == nil {
+		t.Error("a withholding tax rate above 100 was accepted")
+	}
+}
+
+// TestLineEditsUpdateWithholdingTax changes the line of an income its
+// client withholds 3% of and checks the register and the PromptPay amount
+// follow.
+func TestLineEditsUpdateWithholdingTax(t *testing.T) {
+	ctx := context.Background()
+	incomes := &fakeIncomeRepository{existing: map[int]entities.Income{1: {
+		InvoiceIdNumber:     1,
+		Stage:               repositories.IncomeStageInvoice,
+		Currency:            money.BaseCurrency,
+		ReceiverId:          3,
+		Receiver:            entities.Receiver{Id: 3, PromptPayId: "0801234567"},
+		UnpaidPaymentAmount: amount(t, "1070"),
+		WithholdingTaxRate:  amount(t, "3"),
+	}}}
+	details := &detailService{
+		detailRepository: &fakeDetailRepository{details: map[int]entities.Detail{}, incomes: incomes},
+		incomeRepository: incomes,
+		commissionEngine: &fakeCommissionEngine{},
+	}
+	reports := &reportService{incomeRepository: incomes, currencyRateRepository: &fakeCurrencyRateRepository{}}
+	payments := &incomeService{incomeRepository: incomes}
+
+	check := func(name, base, withheld, transfer string) {
+		t.Helper()
+		register, err := reports.GetWithholdingTaxRegister(ctx, dtos.TaxReportQuery{Month: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(register.Lines) != 1 || register.Lines[0].BaseAmount != amount(t, base) || register.Lines[0].TaxAmount != amount(t, withheld) {
+			t.Errorf("%s: register lines = %+v, want %s withheld from %s", name, register.Lines, withheld, base)
+		}
+
+		payment, err := payments.GetPromptPay(ctx, 1)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if payment.WithholdingTaxAmount != amount(t, withheld) || payment.Amount != amount(t, transfer) {
+			t.Errorf("%s: PromptPay withholds %s and asks for %s, want %s and %s", name, payment.WithholdingTaxAmount, payment.Amount, withheld, transfer)
+		}
+	}
+
+	line, err := details.CreateIncomeDetail(ctx, 1, dtos.CreateIncomeDetailRequest{Description: "Post", Quantity: 1, UnitPrice: amount(t, "1000")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("line added", "1000", "30", "1040")
+
+	if _, err := details.UpdateIncomeDetail(ctx, 1, line.Id, dtos.UpdateIncomeDetailRequest{UnitPrice: amount(t, "500")}); err != nil {
+		t.Fatal(err)
+	}
+	check("price lowered", "500", "15", "1055")
+
+	if _, err := details.CreateDetail(ctx, dtos.CreateDetailRequest{Description: "Story", Quantity: 2, UnitPrice: amount(t, "250"), IncomeInvoiceIdNumber: 1}); err != nil {
+		t.Fatal(err)
+	}
+	check("second line added", "1000", "30", "1040")
+
+	if err := details.DeleteIncomeDetail(ctx, 1, line.Id); err != nil {
+		t.Fatal(err)
+	}
+	check("first line removed", "500", "15", "1055")
+}
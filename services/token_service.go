@@ -2,7 +2,7 @@ package services
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"strconv"
 	"sync"
@@ -59,7 +59,7 @@ func (ts *tokenService) GenerateToken(userId int) string {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tx, err := token.SignedString([]byte(ts.secretKey))
 	if err != nil {
-		log.Println(err)
+		slog.Error("failed to sign token", "user_id", userId, "error", err)
 	}
 	return tx
 }
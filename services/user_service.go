@@ -2,10 +2,11 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"mtii-backend/dtos"
 	"mtii-backend/helpers"
+	"mtii-backend/metrics"
 	"mtii-backend/repositories"
+	"mtii-backend/telemetry"
 	"time"
 )
 
@@ -29,16 +30,22 @@ func NewUserService(
 }
 
 func (s *userService) VerifyCredential(ctx context.Context, req dtos.LoginRequest) (dtos.LoginResponse, error) {
+	ctx, span := telemetry.Start(ctx, "UserService.VerifyCredential")
+	defer span.End()
+
 	user, err := s.userRepository.GetUserByUsername(ctx, req.Username)
 	if err != nil {
-		return dtos.LoginResponse{}, fmt.Errorf("username not registered")
+		metrics.LoginAttemptsTotal.WithLabelValues("failure").Inc()
+		return dtos.LoginResponse{}, NewForbiddenError("invalid_credentials", "username not registered")
 	}
 
 	checkPassword, err := helpers.CheckPassword(user.Password, []byte(req.Password))
 	if err != nil || !checkPassword {
-		return dtos.LoginResponse{}, fmt.Errorf("wrong password")
+		metrics.LoginAttemptsTotal.WithLabelValues("failure").Inc()
+		return dtos.LoginResponse{}, NewForbiddenError("invalid_credentials", "wrong password")
 	}
 
+	metrics.LoginAttemptsTotal.WithLabelValues("success").Inc()
 	token := s.tokenService.GenerateToken(user.Id)
 	return dtos.LoginResponse{
 		Token:     token,
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"mtii-backend/dtos"
+	"mtii-backend/entities"
+	"mtii-backend/helpers"
+	"mtii-backend/repositories"
+	"mtii-backend/taxid"
+	"mtii-backend/telemetry"
+)
+
+type VendorService interface {
+	GetAllVendor(ctx context.Context, query dtos.VendorQuery) ([]dtos.Vendor, error)
+	GetVendorById(ctx context.Context, vendorId int) (dtos.Vendor, error)
+	CreateVendor(ctx context.Context, req dtos.CreateVendorRequest) (dtos.VendorResponse, error)
+	UpdateVendor(ctx context.Context, vendorId int, req dtos.UpdateVendorRequest) (dtos.VendorResponse, error)
+	DeleteVendor(ctx context.Context, vendorId int) error
+}
+
+type vendorService struct {
+	vendorRepository repositories.VendorRepository
+}
+
+func NewVendorService(
+	vendorRepository repositories.VendorRepository,
+) VendorService {
+	return &vendorService{
+		vendorRepository: vendorRepository,
+	}
+}
+
+func (s *vendorService) GetAllVendor(ctx context.Context, query dtos.VendorQuery) ([]dtos.Vendor, error) {
+	ctx, span := telemetry.Start(ctx, "VendorService.GetAllVendor")
+	defer span.End()
+
+	vendors, err := s.vendorRepository.GetAllVendor(ctx, query.TaxId)
+	if err != nil {
+		return []dtos.Vendor{}, wrapError(err, "failed to get vendor")
+	}
+
+	var vendorDTOs []dtos.Vendor
+	for _, v := range vendors {
+		vendorDTOs = append(vendorDTOs, toVendorDTO(v))
+	}
+
+	if len(vendorDTOs) == 0 {
+		return []dtos.Vendor{}, nil
+	}
+
+	return vendorDTOs, nil
+}
+
+func (s *vendorService) GetVendorById(ctx context.Context, vendorId int) (dtos.Vendor, error) {
+	ctx, span := telemetry.Start(ctx, "VendorService.GetVendorById")
+	defer span.End()
+
+	vendor, err := s.vendorRepository.GetVendorById(ctx, vendorId)
+	if err != nil {
+		return dtos.Vendor{}, wrapError(err, "failed to get vendor")
+	}
+
+	return toVendorDTO(vendor), nil
+}
+
+func (s *vendorService) CreateVendor(ctx context.Context, req dtos.CreateVendorRequest) (dtos.VendorResponse, error) {
+	ctx, span := telemetry.Start(ctx, "VendorService.CreateVendor")
+	defer span.End()
+
+	data := entities.Vendor{
+		Name:              req.Name,
+		Address:           req.Address,
+		PhoneNumber:       req.PhoneNumber,
+		Email:             req.Email,
+		TaxPayerId:        req.TaxPayerId,
+		BranchNumber:      helpers.DefaultIfEmpty(req.BranchNumber, taxid.HeadOffice),
+		BankId:            req.BankId,
+		BankAccountName:   req.BankAccountName,
+		BankAccountNumber: req.BankAccountNumber,
+	}
+
+	vendor, err := s.vendorRepository.CreateVendor(ctx, data)
+	if err != nil {
+		return dtos.VendorResponse{}, wrapError(err, "failed to save vendor")
+	}
+
+	return dtos.VendorResponse{
+		Id: vendor.Id,
+	}, nil
+}
+
+func (s *vendorService) UpdateVendor(ctx context.Context, vendorId int, req dtos.UpdateVendorRequest) (dtos.VendorResponse, error) {
+	ctx, span := telemetry.Start(ctx, "VendorService.UpdateVendor")
+	defer span.End()
+
+	vendor, err := s.vendorRepository.GetVendorById(ctx, vendorId)
+	if err != nil {
+		return dtos.VendorResponse{}, wrapError(err, "failed to get vendor")
+	}
+
+	data := entities.Vendor{
+		Id:                vendorId,
+		Name:              helpers.DefaultIfEmpty(req.Name, vendor.Name),
+		Address:           helpers.DefaultIfEmpty(req.Address, vendor.Address),
+		PhoneNumber:       helpers.DefaultIfEmpty(req.PhoneNumber, vendor.PhoneNumber),
+		Email:             helpers.DefaultIfEmpty(req.Email, vendor.Email),
+		TaxPayerId:        helpers.DefaultIfEmpty(req.TaxPayerId, vendor.TaxPayerId),
+		BranchNumber:      helpers.DefaultIfEmpty(req.BranchNumber, vendor.BranchNumber),
+		BankId:            vendor.BankId,
+		BankAccountName:   helpers.DefaultIfEmpty(req.BankAccountName, vendor.BankAccountName),
+		BankAccountNumber: helpers.DefaultIfEmpty(req.BankAccountNumber, vendor.BankAccountNumber),
+	}
+	if req.BankId != nil {
+		data.BankId = req.BankId
+	}
+
+	updatedVendor, err := s.vendorRepository.UpdateVendor(ctx, data)
+	if err != nil {
+		return dtos.VendorResponse{}, wrapError(err, "failed to save vendor")
+	}
+
+	return dtos.VendorResponse{
+		Id: updatedVendor.Id,
+	}, nil
+}
+
+func (s *vendorService) DeleteVendor(ctx context.Context, vendorId int) error {
+	ctx, span := telemetry.Start(ctx, "VendorService.DeleteVendor")
+	defer span.End()
+
+	vendor, err := s.vendorRepository.GetVendorById(ctx, vendorId)
+	if err != nil {
+		return wrapError(err, "failed to get vendor")
+	}
+
+	err = s.vendorRepository.DeleteVendor(ctx, vendor.Id)
+	if err != nil {
+		return wrapError(err, "failed to delete vendor")
+	}
+
+	return nil
+}
+
+func toVendorDTO(v entities.Vendor) dtos.Vendor {
+	return dtos.Vendor{
+		Id:                v.Id,
+		Name:              v.Name,
+		Address:           v.Address,
+		PhoneNumber:       v.PhoneNumber,
+		Email:             v.Email,
+		TaxPayerId:        v.TaxPayerId,
+		BranchNumber:      v.BranchNumber,
+		BankId:            v.BankId,
+		BankAccountName:   v.BankAccountName,
+		BankAccountNumber: v.BankAccountNumber,
+	}
+}
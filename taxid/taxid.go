@@ -0,0 +1,96 @@
+package taxid
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// HeadOffice is the branch number of a taxpayer's head office.
+const HeadOffice = "00000"
+
+// ID is a 13-digit Thai tax identification number, kept as a string so
+// leading zeros survive. JSON input may separate the digits with dashes or
+// spaces; they are removed when decoding.
+type ID string
+
+// Normalize removes the dashes and spaces people type between digit groups.
+func Normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Valid reports whether s is 13 digits with a correct check digit: the
+// first twelve digits are weighted 13 down to 2 and the last digit is
+// (11 - sum mod 11) mod 10.
+func Valid(s string) bool {
+	if len(s) != 13 || !isDigits(s) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(s[i]-'0') * (13 - i)
+	}
+	return int(s[12]-'0') == (11-sum%11)%10
+}
+
+// ValidBranch reports whether s is a 5-digit branch number.
+func ValidBranch(s string) bool {
+	return len(s) == 5 && isDigits(s)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (id ID) Valid() bool {
+	return Valid(string(id))
+}
+
+func (id ID) IsZero() bool {
+	return id == ""
+}
+
+func (id ID) String() string {
+	return string(id)
+}
+
+// UnmarshalJSON also accepts a bare number from clients written when the
+// field was an int; such IDs fail validation if they lost a leading zero.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else if s == "null" {
+		return nil
+	} else if !isDigits(s) {
+		return &json.UnmarshalTypeError{Value: "number " + s, Type: reflect.TypeOf(ID(""))}
+	}
+	*id = ID(Normalize(s))
+	return nil
+}
+
+// RegisterValidations adds the "taxid" and "branch" binding rules.
+func RegisterValidations(v *validator.Validate) error {
+	if err := v.RegisterValidation("taxid", func(fl validator.FieldLevel) bool {
+		return Valid(fl.Field().String())
+	}); err != nil {
+		return err
+	}
+	return v.RegisterValidation("branch", func(fl validator.FieldLevel) bool {
+		return ValidBranch(fl.Field().String())
+	})
+}
@@ -0,0 +1,87 @@
+package taxid
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"0105553000415", true},
+		{"3101200034213", true},
+		{"0994000159803", true},
+		{"1101700214567", true},
+		{"1000000000050", true}, // sum mod 11 is 1, so the check digit is 0
+		{"1000000000131", true}, // sum mod 11 is 0, so the check digit is 1
+		{"0105553000416", false},
+		{"3101200034210", false},
+		{"1000000000130", false},
+		{"0000000000000", false},
+		{"010555300041", false},
+		{"01055530004150", false},
+		{"0-1055-53000-41-5", false},
+		{"010555300041a", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.valid {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.valid)
+		}
+	}
+}
+
+func TestValidBranch(t *testing.T) {
+	tests := []struct {
+		branch string
+		valid  bool
+	}{
+		{HeadOffice, true},
+		{"00001", true},
+		{"12345", true},
+		{"0000", false},
+		{"000001", false},
+		{"0000a", false},
+		{"-0001", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidBranch(tt.branch); got != tt.valid {
+			t.Errorf("ValidBranch(%q) = %v, want %v", tt.branch, got, tt.valid)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ID
+	}{
+		{`"0105553000415"`, "0105553000415"},
+		{`"0-1055-53000-41-5"`, "0105553000415"},
+		{`"0 1055 53000 41 5"`, "0105553000415"},
+		{`3101200034213`, "3101200034213"},
+		{`105553000415`, "105553000415"},
+		{`null`, "unchanged"},
+	}
+	for _, tt := range tests {
+		got := ID("unchanged")
+		if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+			t.Errorf("json.Unmarshal(%s) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("json.Unmarshal(%s) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	var id ID
+	if err := json.Unmarshal([]byte(`1.5`), &id); err == nil {
+		t.Errorf("json.Unmarshal(1.5) = %q, want an error", id)
+	}
+	if ID("105553000415").Valid() {
+		t.Error("an ID that lost its leading zero is valid")
+	}
+}
@@ -0,0 +1,16 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TracerName = "mtii-backend"
+
+// Start opens a span named after the calling layer and method, e.g.
+// "IncomeService.GetAllIncome". The caller must end the returned span.
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name)
+}
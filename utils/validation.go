@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationFieldErrors describes each failed rule in terms of the field's
+// JSON name.
+func ValidationFieldErrors(errs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: validationMessage(fe),
+		})
+	}
+	return fields
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is not set", snakeCase(fe.Param()))
+	case "required_with":
+		fields := strings.Fields(fe.Param())
+		for i, f := range fields {
+			fields[i] = snakeCase(f)
+		}
+		return fmt.Sprintf("is required when %s is set", strings.Join(fields, " or "))
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", fe.Param())
+	case "taxid":
+		return "must be a 13-digit Thai tax ID with a valid check digit"
+	case "branch":
+		return "must be a 5-digit branch number, 00000 for the head office"
+	case "promptpay":
+		return "must be a 10-digit mobile number or a 13-digit tax ID"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("failed the %s=%s rule", fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("failed the %s rule", fe.Tag())
+}
+
+// snakeCase turns a Go field name such as AgencyId into its JSON name.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// JSONFieldName names struct fields in validation errors by their JSON
+// name, as clients send them.
+func JSONFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}